@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// adaptiveMinSamples is the number of observations an endpoint needs before
+// its timeout is derived from latency rather than the ceiling.
+const adaptiveMinSamples = 20
+
+// defaultLatencyWindow is the statistics window used when adaptive features
+// need latency data and WithLatencyStats was not given.
+const defaultLatencyWindow = time.Minute
+
+// adaptiveTimeout derives per-endpoint timeouts from observed latency.
+type adaptiveTimeout struct {
+	percentile float64
+	multiplier float64
+	floor      time.Duration
+	ceiling    time.Duration
+
+	// latency holds the network latency of single attempts; call
+	// durations, which include cache hits and retry backoff, would skew it.
+	latency *latencyStats
+}
+
+// WithAdaptiveTimeout tunes each endpoint's timeout continuously to
+// percentile latency times multiplier, clamped to [floor, ceiling]. Until an
+// endpoint has enough observations the ceiling is used. A per-request
+// WithRequestTimeout takes precedence. The latency of each attempt that
+// reached the server is tracked per endpoint, over the WithLatencyStats
+// window or one minute; Client.Stats is enabled as well.
+func WithAdaptiveTimeout(percentile, multiplier float64, floor, ceiling time.Duration) ClientOption {
+	return func(c *Client) error {
+		if percentile <= 0 || percentile >= 1 {
+			return fmt.Errorf("adaptive timeout percentile %v must be between 0 and 1 exclusive", percentile)
+		}
+		if multiplier <= 0 {
+			return fmt.Errorf("adaptive timeout multiplier %v must be positive", multiplier)
+		}
+		if floor <= 0 {
+			return errors.New("adaptive timeout floor must be positive")
+		}
+		if ceiling < floor {
+			return fmt.Errorf("adaptive timeout ceiling %v is below floor %v", ceiling, floor)
+		}
+
+		c.adaptiveTimeout = &adaptiveTimeout{
+			percentile: percentile,
+			multiplier: multiplier,
+			floor:      floor,
+			ceiling:    ceiling,
+		}
+		return nil
+	}
+}
+
+// configureAdaptiveTimeout sets up the latency tracking WithAdaptiveTimeout
+// needs.
+func (c *Client) configureAdaptiveTimeout() {
+	if c.adaptiveTimeout == nil {
+		return
+	}
+	if c.latency == nil {
+		c.latency = newLatencyStats(defaultLatencyWindow)
+	}
+	c.adaptiveTimeout.latency = newLatencyStats(c.latency.window)
+}
+
+// observeAttemptLatency records how long an attempt took on the network for
+// the adaptive timeout. Attempts answered without the server, such as
+// cache hits, and attempts ended by the caller's context are skipped; timed
+// out attempts count, so a slowing endpoint raises its timeout.
+func (c *Client) observeAttemptLatency(ctx context.Context, cl *call, res attemptResult, start time.Time) {
+	if c.adaptiveTimeout == nil || ctx.Err() != nil {
+		return
+	}
+	var clientErr *Error
+	if res.response == nil && !(errors.As(res.err, &clientErr) && clientErr.IsTimeout()) {
+		return
+	}
+	if res.response != nil && (res.response.FromCache || res.response.Stale || res.response.FromFallback) {
+		return
+	}
+	now := time.Now()
+	c.adaptiveTimeout.latency.observe(cl.endpoint, "", now, sample{duration: now.Sub(start), failed: res.err != nil})
+}
+
+// timeoutFor returns the adaptive timeout for an endpoint.
+func (a *adaptiveTimeout) timeoutFor(stats *latencyStats, endpoint string, now time.Time) time.Duration {
+	h, ok := stats.distribution(endpoint, now)
+	if !ok || h.total < adaptiveMinSamples {
+		return a.ceiling
+	}
+
+	timeout := time.Duration(float64(h.percentile(a.percentile)) * a.multiplier)
+	if timeout < a.floor {
+		return a.floor
+	}
+	if timeout > a.ceiling {
+		return a.ceiling
+	}
+	return timeout
+}
@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveTimeout_TimeoutFor(t *testing.T) {
+	a := &adaptiveTimeout{percentile: 0.99, multiplier: 3, floor: 50 * time.Millisecond, ceiling: 5 * time.Second}
+	now := time.Now()
+
+	t.Run("uses ceiling without enough samples", func(t *testing.T) {
+		stats := newLatencyStats(time.Minute)
+		stats.observe("GET /a", "", now, sample{duration: time.Millisecond})
+
+		assert.Equal(t, 5*time.Second, a.timeoutFor(stats, "GET /a", now))
+		assert.Equal(t, 5*time.Second, a.timeoutFor(stats, "GET /unknown", now))
+	})
+
+	t.Run("scales observed percentile", func(t *testing.T) {
+		stats := newLatencyStats(time.Minute)
+		for i := 0; i < adaptiveMinSamples; i++ {
+			stats.observe("GET /a", "", now, sample{duration: 80 * time.Millisecond})
+		}
+
+		assert.Equal(t, 300*time.Millisecond, a.timeoutFor(stats, "GET /a", now))
+	})
+
+	t.Run("clamps to floor and ceiling", func(t *testing.T) {
+		stats := newLatencyStats(time.Minute)
+		for i := 0; i < adaptiveMinSamples; i++ {
+			stats.observe("GET /fast", "", now, sample{duration: time.Millisecond})
+			stats.observe("GET /slow", "", now, sample{duration: 10 * time.Second})
+		}
+
+		assert.Equal(t, 50*time.Millisecond, a.timeoutFor(stats, "GET /fast", now))
+		assert.Equal(t, 5*time.Second, a.timeoutFor(stats, "GET /slow", now))
+	})
+}
+
+func TestWithAdaptiveTimeout(t *testing.T) {
+	t.Run("times out outliers once latency is learned", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) > adaptiveMinSamples {
+				time.Sleep(300 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAdaptiveTimeout(0.99, 2, 50*time.Millisecond, 10*time.Second),
+		)
+		require.NoError(t, err)
+
+		for i := 0; i < adaptiveMinSamples; i++ {
+			_, err = client.Get(context.Background(), "/items", nil)
+			require.NoError(t, err)
+		}
+
+		start := time.Now()
+		_, err = client.Get(context.Background(), "/items", nil)
+
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 250*time.Millisecond)
+	})
+
+	t.Run("learns from attempts, not retry backoff", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1)%2 == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAdaptiveTimeout(0.99, 2, 20*time.Millisecond, 10*time.Second),
+			WithRetry(&RetryPolicy{MaxAttempts: 2, InitialDelay: 100 * time.Millisecond, MaxDelay: 100 * time.Millisecond, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+
+		for i := 0; i < adaptiveMinSamples/2; i++ {
+			_, err = client.Get(context.Background(), "/items", nil)
+			require.NoError(t, err)
+		}
+
+		a := client.adaptiveTimeout
+		assert.Equal(t, 20*time.Millisecond, a.timeoutFor(a.latency, "GET /items", time.Now()))
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			percentile float64
+			multiplier float64
+			floor      time.Duration
+			ceiling    time.Duration
+			wantErr    string
+		}{
+			{"percentile too high", 1, 2, time.Millisecond, time.Second, "must be between 0 and 1"},
+			{"zero multiplier", 0.9, 0, time.Millisecond, time.Second, "multiplier 0 must be positive"},
+			{"zero floor", 0.9, 2, 0, time.Second, "floor must be positive"},
+			{"ceiling below floor", 0.9, 2, time.Second, time.Millisecond, "is below floor"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := New(
+					WithBaseURL("https://api.example.com"),
+					WithAdaptiveTimeout(tt.percentile, tt.multiplier, tt.floor, tt.ceiling),
+				)
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes one completed request for an audit trail. Payloads
+// are recorded only as HMAC-SHA256 digests under the key given to
+// WithAuditSink, so records can be retained without storing sensitive data
+// and low-entropy payloads, such as card numbers, cannot be recovered from
+// them by guessing without the key.
+type AuditRecord struct {
+	Time             time.Time     `json:"time"`
+	Actor            string        `json:"actor,omitempty"`
+	RequestID        string        `json:"request_id,omitempty"`
+	ThirdParty       string        `json:"third_party,omitempty"`
+	Operation        string        `json:"operation,omitempty"`
+	Classification   string        `json:"classification,omitempty"`
+	Method           string        `json:"method"`
+	URL              string        `json:"url"`
+	StatusCode       int           `json:"status_code,omitempty"`
+	Duration         time.Duration `json:"duration_ns"`
+	RequestBodyHash  string        `json:"request_body_hmac_sha256,omitempty"`
+	ResponseBodyHash string        `json:"response_body_hmac_sha256,omitempty"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// AuditSink stores audit records, e.g. in a file, message queue or database.
+// Record is called synchronously once per request; slow sinks should buffer.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// AuditSinkFunc is a function that implements AuditSink.
+type AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+// Record implements AuditSink.
+func (f AuditSinkFunc) Record(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// JSONAuditSink writes audit records to w as JSON lines.
+// It is safe for concurrent use across goroutines.
+type JSONAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditSink creates a sink writing one JSON record per line to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{enc: json.NewEncoder(w)}
+}
+
+// Record implements AuditSink.
+func (s *JSONAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(record)
+}
+
+// minAuditHashKeyLen is the minimum length of the key of WithAuditSink.
+const minAuditHashKeyLen = 32
+
+// WithAuditSink records an AuditRecord for every request to sink, with
+// payloads digested by HMAC-SHA256 under hashKey. hashKey must be at least
+// 32 random bytes, kept secret and stable, e.g. from a secrets manager, so
+// digests of the same payload can be compared across records. Sink
+// failures are logged and do not fail the request.
+func WithAuditSink(sink AuditSink, hashKey []byte) ClientOption {
+	return func(c *Client) error {
+		if sink == nil {
+			return errors.New("audit sink cannot be nil")
+		}
+		if len(hashKey) < minAuditHashKeyLen {
+			return fmt.Errorf("audit hash key must be at least %d bytes", minAuditHashKeyLen)
+		}
+		c.auditSink = sink
+		c.auditHashKey = bytes.Clone(hashKey)
+		return nil
+	}
+}
+
+// auditActorKey is the context key for the audit actor.
+type auditActorKey struct{}
+
+// WithAuditActor records who is making requests with ctx, e.g. a user or
+// service account ID, in their audit records.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// GetAuditActor retrieves the audit actor from the context.
+func GetAuditActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(auditActorKey{}).(string); ok {
+		return actor
+	}
+	return ""
+}
+
+// audit sends the record for a completed call to the audit sink.
+func (c *Client) audit(ctx context.Context, cl *call, resp *Response, start time.Time, duration time.Duration, err error) {
+	if c.auditSink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:            start,
+		Actor:           GetAuditActor(ctx),
+		RequestID:       GetRequestID(ctx),
+		ThirdParty:      c.thirdPartyCode,
+		Operation:       cl.operation,
+		Method:          cl.method,
+		URL:             cl.url,
+		Duration:        duration,
+		RequestBodyHash: hashBody(c.auditHashKey, cl.body),
+	}
+	if cl.classification != DataPublic {
+		record.Classification = cl.classification.String()
+	}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+		record.ResponseBodyHash = hashBody(c.auditHashKey, resp.Body)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	sinkErr := c.runHook(ctx, "audit sink", func() error { return c.auditSink.Record(ctx, record) })
+	if sinkErr != nil && c.logEnabled(ctx, slog.LevelWarn) {
+		c.logger.Log(ctx, slog.LevelWarn, "http_audit_error",
+			slog.String("method", cl.method),
+			slog.String("url", cl.url),
+			slog.String("error", sinkErr.Error()),
+		)
+	}
+}
+
+// hashBody returns the hex HMAC-SHA256 of body under key, or "" if body is
+// empty.
+func hashBody(key, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuditKey is an HMAC key for WithAuditSink.
+var testAuditKey = bytes.Repeat([]byte("k"), minAuditHashKeyLen)
+
+func TestWithAuditSink(t *testing.T) {
+	t.Run("records request metadata and payload hashes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"pay_1"}`))
+		}))
+		defer server.Close()
+
+		var records []AuditRecord
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithThirdPartyCode("psp"),
+			WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) error {
+				records = append(records, record)
+				return nil
+			}), testAuditKey),
+		)
+		require.NoError(t, err)
+
+		ctx := WithAuditActor(WithRequestID(context.Background(), "req-1"), "user-7")
+		_, err = client.Post(ctx, "/payments", map[string]int{"amount": 100}, nil)
+		require.NoError(t, err)
+
+		require.Len(t, records, 1)
+		record := records[0]
+		assert.Equal(t, "user-7", record.Actor)
+		assert.Equal(t, "req-1", record.RequestID)
+		assert.Equal(t, "psp", record.ThirdParty)
+		assert.Equal(t, http.MethodPost, record.Method)
+		assert.Equal(t, server.URL+"/payments", record.URL)
+		assert.Equal(t, http.StatusCreated, record.StatusCode)
+		assert.Positive(t, record.Duration)
+		mac := hmac.New(sha256.New, testAuditKey)
+		mac.Write([]byte(`{"amount":100}`))
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), record.RequestBodyHash)
+		unkeyed := sha256.Sum256([]byte(`{"amount":100}`))
+		assert.NotEqual(t, hex.EncodeToString(unkeyed[:]), record.RequestBodyHash)
+		mac = hmac.New(sha256.New, testAuditKey)
+		mac.Write([]byte(`{"id":"pay_1"}`))
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), record.ResponseBodyHash)
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		server := newStatusServer(http.StatusBadRequest)
+		defer server.Close()
+
+		var sink bytes.Buffer
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAuditSink(NewJSONAuditSink(&sink), testAuditKey))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/kyc", nil)
+		require.Error(t, err)
+
+		var record AuditRecord
+		require.NoError(t, json.Unmarshal(sink.Bytes(), &record))
+		assert.Equal(t, http.StatusBadRequest, record.StatusCode)
+		assert.NotEmpty(t, record.Error)
+		assert.Empty(t, record.RequestBodyHash)
+	})
+
+	t.Run("logs sink failures without failing the request", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) error {
+				return errors.New("queue unavailable")
+			}), testAuditKey),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/kyc", nil)
+
+		require.NoError(t, err)
+		entry := logger.LastEntry()
+		assert.Equal(t, "http_audit_error", entry.Msg)
+		assert.Equal(t, "queue unavailable", entry.Attrs["error"])
+	})
+
+	t.Run("returns error for nil sink", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAuditSink(nil, testAuditKey))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "audit sink cannot be nil")
+	})
+
+	t.Run("returns error for short hash key", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAuditSink(NewJSONAuditSink(io.Discard), []byte("short")))
+
+		assert.ErrorContains(t, err, "at least 32 bytes")
+	})
+}
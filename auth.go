@@ -3,7 +3,12 @@ package httpclient
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 // AuthProvider applies authentication to HTTP requests.
@@ -68,13 +73,156 @@ func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
 }
 
 // TokenAuth returns an AuthProvider that fetches tokens from a TokenSource.
+// With WithAuthRetry, a token the server rejects is passed to the source's
+// InvalidateToken if it implements TokenInvalidator.
 func TokenAuth(source TokenSource) AuthProvider {
-	return AuthFunc(func(req *http.Request) error {
-		token, err := source.Token(req.Context())
-		if err != nil {
-			return err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
+	return &tokenAuth{source: source}
+}
+
+type tokenAuth struct {
+	source TokenSource
+}
+
+// Apply implements AuthProvider.
+func (a *tokenAuth) Apply(req *http.Request) error {
+	token, err := a.source.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// InvalidateAuth implements AuthInvalidator.
+func (a *tokenAuth) InvalidateAuth(req *http.Request) {
+	if inv, ok := a.source.(TokenInvalidator); ok {
+		inv.InvalidateToken(bearerToken(req))
+	}
+}
+
+// AuthInvalidator is implemented by AuthProviders whose credentials can go
+// stale, such as TokenAuth. WithAuthRetry calls InvalidateAuth with a
+// request the server answered 401, so the next Apply uses fresh credentials.
+type AuthInvalidator interface {
+	InvalidateAuth(req *http.Request)
+}
+
+// TokenInvalidator is implemented by TokenSources that cache tokens.
+// InvalidateToken discards token, or the cached token whatever it is if
+// token is empty; a token that was already replaced is left alone, so
+// concurrent rejections cause one fetch.
+type TokenInvalidator interface {
+	InvalidateToken(token string)
+}
+
+// CachedTokenSource returns a TokenSource that fetches a token from source
+// once and reuses it until it is invalidated, which WithAuthRetry does when
+// the server rejects it. It is safe for concurrent use.
+func CachedTokenSource(source TokenSource) TokenSource {
+	return &cachedTokenSource{source: source}
+}
+
+type cachedTokenSource struct {
+	source TokenSource
+
+	mu    sync.Mutex
+	token string
+}
+
+// Token implements TokenSource.
+func (s *cachedTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
+
+// InvalidateToken implements TokenInvalidator.
+func (s *cachedTokenSource) InvalidateToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token == "" || token == s.token {
+		s.token = ""
+	}
+}
+
+// bearerToken returns the Bearer token req was sent with, or "".
+func bearerToken(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	token, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+// WithAuthRetry retries a request answered 401 once with fresh credentials,
+// the usual handling of expiring bearer tokens: the provider, if it is an
+// AuthInvalidator such as TokenAuth or JWTAuth, discards the rejected
+// credentials and the request is sent again. A second 401 is returned. It
+// does not apply during RotateAuth's overlap, which already retries 401s.
+func WithAuthRetry() ClientOption {
+	return func(c *Client) error {
+		c.authRetry = true
 		return nil
+	}
+}
+
+// authState is the set of credentials in use. It is replaced atomically and
+// never mutated, so readers need no locking.
+type authState struct {
+	primary       AuthProvider
+	fallback      AuthProvider
+	fallbackUntil time.Time
+}
+
+// providers returns the provider to use and, during a rotation's grace
+// period, the provider to fall back to on 401.
+func (s *authState) providers(now time.Time) (AuthProvider, AuthProvider) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.fallback == nil {
+		return s.primary, nil
+	}
+	if now.After(s.fallbackUntil) {
+		return s.fallback, nil
+	}
+	return s.primary, s.fallback
+}
+
+// SetAuth atomically replaces the client's authentication provider.
+// In-flight requests finish with the provider they started with.
+func (c *Client) SetAuth(auth AuthProvider) error {
+	if auth == nil {
+		return errors.New("auth provider cannot be nil")
+	}
+	c.auth.Store(&authState{primary: auth})
+	return nil
+}
+
+// RotateAuth moves the client from oldAuth to newAuth without downtime.
+// For the overlap period requests keep using oldAuth, and any 401 is retried
+// once with newAuth; after the first success with newAuth, or once overlap
+// has elapsed, only newAuth is used.
+func (c *Client) RotateAuth(oldAuth, newAuth AuthProvider, overlap time.Duration) error {
+	if oldAuth == nil || newAuth == nil {
+		return errors.New("auth providers cannot be nil")
+	}
+	if overlap <= 0 {
+		return fmt.Errorf("rotation overlap must be positive, got %v", overlap)
+	}
+
+	c.auth.Store(&authState{
+		primary:       oldAuth,
+		fallback:      newAuth,
+		fallbackUntil: time.Now().Add(overlap),
 	})
+	return nil
 }
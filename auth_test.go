@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -226,3 +229,275 @@ func TestTokenSource(t *testing.T) {
 		assert.Contains(t, err.Error(), "token fetch failed")
 	})
 }
+
+// newKeyServer accepts only the bearer tokens in valid and records every token it sees.
+func newKeyServer(valid *atomic.Value) (*httptest.Server, *[]string) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		seen = append(seen, token)
+		for _, v := range valid.Load().([]string) {
+			if token == "Bearer "+v {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	return server, &seen
+}
+
+func TestClient_SetAuth(t *testing.T) {
+	t.Run("swaps provider for subsequent requests", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"old", "new"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuth("old")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		require.NoError(t, client.SetAuth(BearerAuth("new")))
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Bearer old", "Bearer new"}, *seen)
+	})
+
+	t.Run("returns error for nil provider", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		err = client.SetAuth(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "auth provider cannot be nil")
+	})
+}
+
+func TestClient_RotateAuth(t *testing.T) {
+	t.Run("keeps old credential during overlap", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"old", "new"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		require.NoError(t, client.RotateAuth(BearerAuth("old"), BearerAuth("new"), time.Minute))
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer old"}, *seen)
+	})
+
+	t.Run("falls back to new credential on 401 and promotes it", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"new"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		require.NoError(t, client.RotateAuth(BearerAuth("old"), BearerAuth("new"), time.Minute))
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Bearer old", "Bearer new", "Bearer new"}, *seen)
+	})
+
+	t.Run("uses only new credential after overlap", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"old", "new"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		require.NoError(t, client.RotateAuth(BearerAuth("old"), BearerAuth("new"), 10*time.Millisecond))
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer new"}, *seen)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		err = client.RotateAuth(nil, BearerAuth("new"), time.Minute)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "auth providers cannot be nil")
+
+		err = client.RotateAuth(BearerAuth("old"), BearerAuth("new"), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rotation overlap must be positive")
+	})
+}
+
+func TestWithAuthRetry(t *testing.T) {
+	// tokens returns a TokenSource handing out the given tokens in turn.
+	tokens := func(fetches *atomic.Int32, tokens ...string) TokenSource {
+		return TokenSourceFunc(func(ctx context.Context) (string, error) {
+			n := int(fetches.Add(1))
+			return tokens[min(n, len(tokens))-1], nil
+		})
+	}
+
+	t.Run("refreshes cached token on 401 and retries once", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"fresh"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		var fetches atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(CachedTokenSource(tokens(&fetches, "expired", "fresh")))),
+			WithAuthRetry(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Bearer expired", "Bearer fresh", "Bearer fresh"}, *seen)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("returns second 401", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		var fetches atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(CachedTokenSource(tokens(&fetches, "a", "b", "c")))),
+			WithAuthRetry(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusUnauthorized, clientErr.StatusCode)
+		assert.Equal(t, []string{"Bearer a", "Bearer b"}, *seen)
+	})
+
+	t.Run("resends the body", func(t *testing.T) {
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+
+		var fetches atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(tokens(&fetches, "expired", "fresh"))),
+			WithAuthRetry(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/orders", map[string]int{"id": 1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"id":1}`, `{"id":1}`}, bodies)
+	})
+
+	t.Run("does not retry without the option", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"fresh"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		var fetches atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(CachedTokenSource(tokens(&fetches, "expired", "fresh")))),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.Error(t, err)
+		assert.Equal(t, []string{"Bearer expired"}, *seen)
+	})
+
+	t.Run("does not retry static credentials", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAuth(BearerAuth("static")), WithAuthRetry())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.Error(t, err)
+		assert.Equal(t, []string{"Bearer static"}, *seen)
+	})
+}
+
+func TestCachedTokenSource(t *testing.T) {
+	t.Run("ignores invalidation of a replaced token", func(t *testing.T) {
+		var fetches atomic.Int32
+		source := CachedTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("t%d", fetches.Add(1)), nil
+		}))
+		inv := source.(TokenInvalidator)
+
+		token, err := source.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "t1", token)
+
+		inv.InvalidateToken("t1")
+		inv.InvalidateToken("t1")
+		token, err = source.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "t2", token)
+
+		inv.InvalidateToken("")
+		token, err = source.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "t3", token)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		var fetches atomic.Int32
+		source := CachedTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+			if fetches.Add(1) == 1 {
+				return "", errors.New("unavailable")
+			}
+			return "token", nil
+		}))
+
+		_, err := source.Token(context.Background())
+		require.Error(t, err)
+		token, err := source.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "token", token)
+	})
+}
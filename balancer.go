@@ -0,0 +1,271 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// Balancer spreads requests over the base URLs given to WithBalancer, e.g.
+// an API's regional hosts. Implementations must be safe for concurrent use.
+type Balancer interface {
+	// Pick returns the index, below n, of the base URL for the next attempt.
+	Pick(n int) int
+	// Done reports that an attempt sent to base URL i finished, with its
+	// error if it failed.
+	Done(i int, err error)
+}
+
+// TargetStats counts the attempts sent to one base URL of WithBalancer.
+type TargetStats struct {
+	Requests uint64
+	// Failures counts attempts that failed to connect or got a 5xx response.
+	Failures uint64
+	// Pending is the number of attempts in flight.
+	Pending int64
+}
+
+// balancing holds the WithBalancer base URLs and their statistics.
+type balancing struct {
+	balancer Balancer
+	bases    []*url.URL
+	stats    []targetCounters
+}
+
+type targetCounters struct {
+	requests atomic.Uint64
+	failures atomic.Uint64
+	pending  atomic.Int64
+}
+
+// WithBalancer sends each attempt to the base URL picked by balancer, e.g.
+// RoundRobin, and counts attempts per base URL in Client.Stats. The first
+// base URL also serves as the client's base URL. Requests to absolute URLs
+// or with WithRequestBaseURL are not balanced.
+func WithBalancer(balancer Balancer, baseURLs ...string) ClientOption {
+	return func(c *Client) error {
+		if balancer == nil {
+			return errors.New("balancer cannot be nil")
+		}
+		if len(baseURLs) == 0 {
+			return errors.New("balancer requires at least one base URL")
+		}
+		if w, ok := balancer.(*weightedBalancer); ok && len(w.weights) != len(baseURLs) {
+			return fmt.Errorf("balancer has %d weights for %d base URLs", len(w.weights), len(baseURLs))
+		}
+		if err := WithBaseURL(baseURLs[0])(c); err != nil {
+			return err
+		}
+
+		bases := []*url.URL{c.baseURL}
+		for _, baseURL := range baseURLs[1:] {
+			if baseURL == "" {
+				return errors.New("base URL cannot be empty")
+			}
+			u, err := url.Parse(baseURL)
+			if err != nil {
+				return err
+			}
+			if err := normalizeURLHost(u); err != nil {
+				return err
+			}
+			bases = append(bases, u)
+		}
+		c.balancing = &balancing{
+			balancer: balancer,
+			bases:    bases,
+			stats:    make([]targetCounters, len(bases)),
+		}
+		return nil
+	}
+}
+
+// baseURLs returns the base URLs of WithBaseURLs or WithBalancer, or nil.
+func (c *Client) baseURLs() []*url.URL {
+	if c.balancing != nil {
+		return c.balancing.bases
+	}
+	if c.failover != nil && len(c.failover.bases) > 0 {
+		return c.failover.bases
+	}
+	return nil
+}
+
+// targetURLs returns the request URL under every base URL, or nil when the
+// call goes to its own URL only. Every target is checked like the request
+// URL, so failing over cannot send the request where it may not go.
+func (c *Client) targetURLs(path string, cfg *requestConfig) ([]string, error) {
+	bases := c.baseURLs()
+	if len(bases) == 0 || cfg.baseURL != "" {
+		return nil, nil
+	}
+	if _, ok := absoluteURL(path); ok {
+		return nil, nil
+	}
+	urls := make([]string, len(bases))
+	for i, base := range bases {
+		u, err := c.joinPath(base, path)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.addQuery(u, cfg); err != nil {
+			return nil, err
+		}
+		if err := c.checkURL(u, cfg.classification); err != nil {
+			return nil, err
+		}
+		urls[i] = u.String()
+	}
+	return urls, nil
+}
+
+// targetedAttempt sends one attempt of the call to the base URL chosen by
+// the balancer or failover, if the call has several.
+func (c *Client) targetedAttempt(ctx context.Context, cl *call, attempt int) attemptResult {
+	switch {
+	case cl.targetURLs == nil:
+		return c.limitedAttempt(ctx, cl, attempt)
+	case c.balancing != nil:
+		return c.balancedAttempt(ctx, cl, attempt)
+	default:
+		return c.failoverAttempt(ctx, cl, attempt)
+	}
+}
+
+// balancedAttempt sends one attempt to the base URL the balancer picks.
+func (c *Client) balancedAttempt(ctx context.Context, cl *call, attempt int) attemptResult {
+	b := c.balancing
+	var i int
+	err := c.runHook(ctx, "balancer", func() error {
+		i = b.balancer.Pick(len(b.bases))
+		return nil
+	})
+	if err != nil {
+		return attemptResult{err: c.wrapError(err, cl.method, cl.url)}
+	}
+	if i < 0 || i >= len(b.bases) {
+		i = 0
+	}
+	cl.url = cl.targetURLs[i]
+	cl.host = b.bases[i].Host
+
+	stats := &b.stats[i]
+	stats.requests.Add(1)
+	stats.pending.Add(1)
+	res := c.limitedAttempt(ctx, cl, attempt)
+	stats.pending.Add(-1)
+	if isFailoverSignal(ctx, res) {
+		stats.failures.Add(1)
+	}
+	_ = c.runHook(ctx, "balancer", func() error {
+		b.balancer.Done(i, res.err)
+		return nil
+	})
+	return res
+}
+
+// targetStats returns the statistics of every base URL by URL.
+func (b *balancing) targetStats() map[string]TargetStats {
+	stats := make(map[string]TargetStats, len(b.bases))
+	for i, base := range b.bases {
+		stats[base.String()] = TargetStats{
+			Requests: b.stats[i].requests.Load(),
+			Failures: b.stats[i].failures.Load(),
+			Pending:  b.stats[i].pending.Load(),
+		}
+	}
+	return stats
+}
+
+// RoundRobin returns a Balancer that takes the base URLs in turn.
+func RoundRobin() Balancer {
+	return &roundRobinBalancer{}
+}
+
+type roundRobinBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Pick(n int) int {
+	return int((b.next.Add(1) - 1) % uint64(n))
+}
+
+func (b *roundRobinBalancer) Done(int, error) {}
+
+// Weighted returns a Balancer that sends each base URL a share of attempts
+// proportional to its weight, given in the order of the base URLs, and
+// interleaves them smoothly rather than in bursts. A weight of 0 takes the
+// base URL out of rotation.
+func Weighted(weights ...int) Balancer {
+	return &weightedBalancer{weights: weights, current: make([]int, len(weights))}
+}
+
+// weightedBalancer implements smooth weighted round-robin.
+type weightedBalancer struct {
+	weights []int
+
+	mu      sync.Mutex
+	current []int
+}
+
+func (b *weightedBalancer) Pick(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	best, total := 0, 0
+	for i := range min(n, len(b.weights)) {
+		weight := max(b.weights[i], 0)
+		b.current[i] += weight
+		total += weight
+		if b.current[i] > b.current[best] {
+			best = i
+		}
+	}
+	b.current[best] -= total
+	return best
+}
+
+func (b *weightedBalancer) Done(int, error) {}
+
+// LeastPending returns a Balancer that picks the base URL with the fewest
+// attempts in flight, taking them in turn when tied, so a slow region gets
+// less traffic.
+func LeastPending() Balancer {
+	return &leastPendingBalancer{}
+}
+
+type leastPendingBalancer struct {
+	mu      sync.Mutex
+	pending []int
+	next    int
+}
+
+func (b *leastPendingBalancer) Pick(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for len(b.pending) < n {
+		b.pending = append(b.pending, 0)
+	}
+	best := b.next % n
+	for j := range n {
+		i := (b.next + j) % n
+		if b.pending[i] < b.pending[best] {
+			best = i
+		}
+	}
+	b.next = (best + 1) % n
+	b.pending[best]++
+	return best
+}
+
+func (b *leastPendingBalancer) Done(i int, _ error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i < len(b.pending) && b.pending[i] > 0 {
+		b.pending[i]--
+	}
+}
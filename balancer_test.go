@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancers(t *testing.T) {
+	t.Run("round robin takes base URLs in turn", func(t *testing.T) {
+		b := RoundRobin()
+
+		var picks []int
+		for range 5 {
+			picks = append(picks, b.Pick(3))
+		}
+		assert.Equal(t, []int{0, 1, 2, 0, 1}, picks)
+	})
+
+	t.Run("weighted interleaves by weight", func(t *testing.T) {
+		b := Weighted(5, 1, 1)
+
+		var picks []int
+		for range 7 {
+			picks = append(picks, b.Pick(3))
+		}
+		assert.Equal(t, []int{0, 0, 1, 0, 2, 0, 0}, picks)
+	})
+
+	t.Run("weighted skips zero weights", func(t *testing.T) {
+		b := Weighted(0, 1)
+
+		for range 3 {
+			assert.Equal(t, 1, b.Pick(2))
+		}
+	})
+
+	t.Run("least pending avoids busy base URLs", func(t *testing.T) {
+		b := LeastPending()
+
+		assert.Equal(t, 0, b.Pick(2))
+		assert.Equal(t, 1, b.Pick(2))
+		assert.Equal(t, 0, b.Pick(2))
+		b.Done(1, nil)
+		assert.Equal(t, 1, b.Pick(2))
+		assert.Equal(t, 1, b.Pick(2))
+	})
+}
+
+func TestWithBalancer(t *testing.T) {
+	t.Run("spreads requests and records stats", func(t *testing.T) {
+		var okStatus, okHits, failStatus, failHits atomic.Int32
+		okStatus.Store(http.StatusOK)
+		failStatus.Store(http.StatusInternalServerError)
+		healthy := newCountingServer(&okStatus, &okHits)
+		defer healthy.Close()
+		failing := newCountingServer(&failStatus, &failHits)
+		defer failing.Close()
+
+		client, err := New(
+			WithBalancer(RoundRobin(), healthy.URL+"/v1", failing.URL+"/v1"),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		for range 4 {
+			_, _ = client.Get(context.Background(), "/orders", nil)
+		}
+		assert.Equal(t, int32(2), okHits.Load())
+		assert.Equal(t, int32(2), failHits.Load())
+
+		targets := client.Stats().Targets
+		assert.Equal(t, TargetStats{Requests: 2}, targets[healthy.URL+"/v1"])
+		assert.Equal(t, TargetStats{Requests: 2, Failures: 2}, targets[failing.URL+"/v1"])
+	})
+
+	t.Run("leaves absolute URLs alone", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(
+			WithBalancer(RoundRobin(), "http://a.example.invalid", "http://b.example.invalid"),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), server.URL+"/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/orders", string(resp.Body))
+		assert.Zero(t, client.Stats().Targets["http://a.example.invalid"].Requests)
+	})
+
+	t.Run("rejects mismatched weights", func(t *testing.T) {
+		_, err := New(WithBalancer(Weighted(1, 2, 3), "https://a.example.com", "https://b.example.com"))
+		require.Error(t, err)
+	})
+
+	t.Run("rejects failover with balancing", func(t *testing.T) {
+		_, err := New(
+			WithBalancer(RoundRobin(), "https://a.example.com", "https://b.example.com"),
+			WithBaseURLs("https://a.example.com", "https://c.example.com"),
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("requires base URLs", func(t *testing.T) {
+		_, err := New(WithBalancer(RoundRobin()))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,300 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// EncodedBody is a request body produced by a BodyEncoder.
+type EncodedBody struct {
+	Body        io.Reader
+	ContentType string
+	// Headers are set on the request after the client's own, e.g. SOAPAction.
+	Headers map[string]string
+}
+
+// BodyEncoder encodes the request bodies it recognizes. EncodeBody returns
+// nil, nil for bodies it does not handle so the next encoder is consulted.
+type BodyEncoder interface {
+	EncodeBody(body any) (*EncodedBody, error)
+}
+
+// BodyEncoderFunc adapts a function to a BodyEncoder.
+type BodyEncoderFunc func(body any) (*EncodedBody, error)
+
+// EncodeBody implements BodyEncoder.
+func (f BodyEncoderFunc) EncodeBody(body any) (*EncodedBody, error) {
+	return f(body)
+}
+
+// defaultBodyEncoders are consulted, in order, after any WithBodyEncoder
+// encoders. The JSON encoder accepts every body, so it must stay last.
+var defaultBodyEncoders = []BodyEncoder{
+	BodyEncoderFunc(encodeXMLBody),
+	BodyEncoderFunc(encodeSOAPBody),
+	BodyEncoderFunc(encodeMultipartBody),
+	BodyEncoderFunc(encodeFormBody),
+	BodyEncoderFunc(encodeRawBody),
+	BodyEncoderFunc(encodeJSONBody),
+}
+
+// WithBodyEncoder adds an encoder for request bodies, e.g. for protobuf
+// messages or a vendor envelope. Encoders run in the order they were added
+// and before the built-in XML, SOAP, multipart, form, raw and JSON encoders,
+// so they may also take over types the client already supports.
+func WithBodyEncoder(enc BodyEncoder) ClientOption {
+	return func(c *Client) error {
+		if enc == nil {
+			return errors.New("body encoder cannot be nil")
+		}
+		c.bodyEncoders = append(c.bodyEncoders, enc)
+		return nil
+	}
+}
+
+// encodeWithChain encodes body with the first encoder that accepts it.
+// WithSOAPDefaults wrapping applies after custom encoders.
+func (c *Client) encodeWithChain(body any) (*EncodedBody, error) {
+	encoders := c.bodyEncoders
+	if c.soapDefaults != nil {
+		encoders = append(encoders[:len(encoders):len(encoders)], BodyEncoderFunc(c.soapDefaults.encodeBody))
+	}
+	for _, encoders := range [][]BodyEncoder{encoders, defaultBodyEncoders} {
+		for _, enc := range encoders {
+			encoded, err := enc.EncodeBody(body)
+			if err != nil {
+				return nil, err
+			}
+			if encoded != nil {
+				return encoded, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no body encoder accepts %T", body)
+}
+
+func encodeXMLBody(body any) (*EncodedBody, error) {
+	if !IsXMLBody(body) {
+		return nil, nil
+	}
+	reader, contentType, err := EncodeXMLBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: reader, ContentType: contentType}, nil
+}
+
+func encodeSOAPBody(body any) (*EncodedBody, error) {
+	if !IsSOAPBody(body) {
+		return nil, nil
+	}
+	reader, contentType, headers, err := EncodeSOAPBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: reader, ContentType: contentType, Headers: headers}, nil
+}
+
+// multipartBody wraps parts to be sent as multipart/form-data.
+type multipartBody struct {
+	parts []Part
+}
+
+// MultipartBody creates a multipart/form-data body, e.g. a file upload with
+// metadata fields. Each part's FormName and FileName become its
+// Content-Disposition unless Headers already sets one; other Headers, such
+// as Content-Type, are sent as given.
+func MultipartBody(parts ...Part) any {
+	return &multipartBody{parts: parts}
+}
+
+func encodeMultipartBody(body any) (*EncodedBody, error) {
+	mb, ok := body.(*multipartBody)
+	if !ok {
+		return nil, nil
+	}
+	if len(mb.parts) > MaxMultipartParts {
+		return nil, fmt.Errorf("multipart body exceeds %d parts", MaxMultipartParts)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for i, part := range mb.parts {
+		if part.Body == nil {
+			return nil, fmt.Errorf("multipart part %d has no body", i)
+		}
+		header := textproto.MIMEHeader(part.Headers.Clone())
+		if header == nil {
+			header = make(textproto.MIMEHeader)
+		}
+		if part.FormName != "" && header.Get("Content-Disposition") == "" {
+			params := map[string]string{"name": part.FormName}
+			if part.FileName != "" {
+				params["filename"] = part.FileName
+			}
+			header.Set("Content-Disposition", mime.FormatMediaType("form-data", params))
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, part.Body); err != nil {
+			return nil, fmt.Errorf("writing multipart part %d: %w", i, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: &buf, ContentType: mw.FormDataContentType()}, nil
+}
+
+func encodeFormBody(body any) (*EncodedBody, error) {
+	form, ok := body.(url.Values)
+	if !ok {
+		return nil, nil
+	}
+	return &EncodedBody{Body: strings.NewReader(form.Encode()), ContentType: "application/x-www-form-urlencoded"}, nil
+}
+
+// encodeRawBody sends bytes, strings, readers and RawBody unchanged.
+func encodeRawBody(body any) (*EncodedBody, error) {
+	switch v := body.(type) {
+	case []byte:
+		return &EncodedBody{Body: bytes.NewReader(v)}, nil
+	case string:
+		return &EncodedBody{Body: strings.NewReader(v)}, nil
+	case io.Reader:
+		return &EncodedBody{Body: v}, nil
+	case RawBody:
+		return &EncodedBody{Body: bytes.NewReader(v.Data), ContentType: v.ContentType}, nil
+	case *RawBody:
+		return &EncodedBody{Body: bytes.NewReader(v.Data), ContentType: v.ContentType}, nil
+	}
+	return nil, nil
+}
+
+// TextBodyEncoder returns an encoder that sends bodies implementing
+// encoding.TextMarshaler, but not json.Marshaler, as plain text. Without
+// it, such bodies are sent as a JSON string. Add it with WithBodyEncoder.
+func TextBodyEncoder() BodyEncoder {
+	return BodyEncoderFunc(encodeTextBody)
+}
+
+func encodeTextBody(body any) (*EncodedBody, error) {
+	tm, ok := body.(encoding.TextMarshaler)
+	if !ok {
+		return nil, nil
+	}
+	if _, isJSON := body.(json.Marshaler); isJSON {
+		return nil, nil
+	}
+	text, err := tm.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: bytes.NewReader(text), ContentType: "text/plain; charset=utf-8"}, nil
+}
+
+// encodeJSONBody encodes any other body as JSON.
+func encodeJSONBody(body any) (*EncodedBody, error) {
+	if err := validateBodyType(reflect.TypeOf(body)); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: bytes.NewReader(data), ContentType: "application/json"}, nil
+}
+
+// RawBody sends Data exactly as given, with ContentType if it is set and
+// the client's default content type otherwise. Use it for payloads that
+// are already encoded, e.g. a signed document that must not be re-marshaled.
+type RawBody struct {
+	Data        []byte
+	ContentType string
+}
+
+// maxBodyTypeChecks bounds the types inspected when validating a body type.
+const maxBodyTypeChecks = 1000
+
+type bodyTypeCheck struct {
+	t    reflect.Type
+	path string
+}
+
+// validateBodyType reports why values of root cannot be encoded as JSON,
+// e.g. a channel or a struct field holding a function, so the mistake
+// surfaces with the offending field instead of as a marshal error deep in
+// the request. Types implementing json.Marshaler or encoding.TextMarshaler
+// are trusted.
+func validateBodyType(root reflect.Type) error {
+	seen := make(map[reflect.Type]bool)
+	stack := []bodyTypeCheck{{t: root, path: "body"}}
+	for checks := 0; len(stack) > 0 && checks < maxBodyTypeChecks; checks++ {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[cur.t] || marshalsItself(cur.t) {
+			continue
+		}
+		seen[cur.t] = true
+
+		switch cur.t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+			return fmt.Errorf("request body %s has unsupported type %s: use a JSON-encodable type or RawBody", cur.path, cur.t)
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			stack = append(stack, bodyTypeCheck{t: cur.t.Elem(), path: cur.path + "[]"})
+		case reflect.Map:
+			if err := checkMapKey(cur.t.Key(), cur.path); err != nil {
+				return err
+			}
+			stack = append(stack, bodyTypeCheck{t: cur.t.Elem(), path: cur.path + "[]"})
+		case reflect.Struct:
+			stack = appendFields(stack, cur)
+		}
+	}
+	return nil
+}
+
+// appendFields queues the fields encoding/json would encode.
+func appendFields(stack []bodyTypeCheck, cur bodyTypeCheck) []bodyTypeCheck {
+	for i := range cur.t.NumField() {
+		field := cur.t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		stack = append(stack, bodyTypeCheck{t: field.Type, path: cur.path + "." + field.Name})
+	}
+	return stack
+}
+
+func checkMapKey(key reflect.Type, path string) error {
+	switch key.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return nil
+	}
+	if key.Implements(reflect.TypeFor[encoding.TextMarshaler]()) {
+		return nil
+	}
+	return fmt.Errorf("request body %s has unsupported map key type %s", path, key)
+}
+
+func marshalsItself(t reflect.Type) bool {
+	jsonMarshaler := reflect.TypeFor[json.Marshaler]()
+	textMarshaler := reflect.TypeFor[encoding.TextMarshaler]()
+	return t.Implements(jsonMarshaler) || t.Implements(textMarshaler) ||
+		reflect.PointerTo(t).Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(textMarshaler)
+}
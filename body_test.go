@@ -0,0 +1,220 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gridKey is a struct map key that encoding/json accepts via MarshalText.
+type gridKey struct{ X, Y int }
+
+func (k gridKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", k.X, k.Y)), nil
+}
+
+func TestValidateBodyType(t *testing.T) {
+	type node struct {
+		Name     string
+		Children []*node
+	}
+	type withFunc struct {
+		Name     string
+		Callback func()
+	}
+	type withIgnored struct {
+		Name     string
+		Callback func() `json:"-"`
+		hidden   chan int
+	}
+	type nested struct {
+		Inner struct {
+			Updates chan string
+		}
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"map", map[string]any{"a": 1}, ""},
+		{"recursive struct", node{}, ""},
+		{"ignored fields", withIgnored{}, ""},
+		{"marshaler", time.Time{}, ""},
+		{"text marshaler map key", map[gridKey]int{}, ""},
+		{"channel", make(chan int), "unsupported type chan int"},
+		{"function field", withFunc{}, "body.Callback"},
+		{"nested channel", &nested{}, "body[].Inner.Updates"},
+		{"complex slice", []complex128{}, "complex128"},
+		{"struct map key", map[struct{ A int }]int{}, "map key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBodyType(reflect.TypeOf(tt.value))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRequestBodyEncoding(t *testing.T) {
+	var gotType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotType, gotBody = r.Header.Get("Content-Type"), string(body)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("rejects unsupported types before sending", func(t *testing.T) {
+		gotBody = "untouched"
+		_, err := client.Post(ctx, "/", struct{ Done chan bool }{}, nil)
+		assert.ErrorContains(t, err, "body.Done")
+		assert.Equal(t, "untouched", gotBody)
+	})
+
+	t.Run("sends raw bodies unchanged", func(t *testing.T) {
+		_, err := client.Post(ctx, "/", RawBody{Data: []byte(`<signed/>`), ContentType: "application/xml"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/xml", gotType)
+		assert.Equal(t, `<signed/>`, gotBody)
+
+		_, err = client.Post(ctx, "/", &RawBody{Data: []byte("abc")}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+		assert.Equal(t, "abc", gotBody)
+	})
+
+	t.Run("uses json.Marshaler", func(t *testing.T) {
+		at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		_, err := client.Post(ctx, "/", at, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+		assert.Equal(t, `"2024-05-01T12:00:00Z"`, gotBody)
+	})
+
+	t.Run("sends text marshalers as JSON strings", func(t *testing.T) {
+		_, err := client.Post(ctx, "/", net.ParseIP("10.0.0.1"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+		assert.Equal(t, `"10.0.0.1"`, gotBody)
+	})
+
+	t.Run("sends text marshalers as plain text when opted in", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithBodyEncoder(TextBodyEncoder()))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", net.ParseIP("10.0.0.1"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", gotType)
+		assert.Equal(t, "10.0.0.1", gotBody)
+
+		at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		_, err = client.Post(ctx, "/", at, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+	})
+}
+
+type csvRows [][]string
+
+func TestWithBodyEncoder(t *testing.T) {
+	var gotType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotType, gotBody = r.Header.Get("Content-Type"), string(body)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	csvEncoder := BodyEncoderFunc(func(body any) (*EncodedBody, error) {
+		rows, ok := body.(csvRows)
+		if !ok {
+			return nil, nil
+		}
+		var b strings.Builder
+		for _, row := range rows {
+			b.WriteString(strings.Join(row, ",") + "\n")
+		}
+		return &EncodedBody{Body: strings.NewReader(b.String()), ContentType: "text/csv", Headers: map[string]string{"X-Rows": fmt.Sprint(len(rows))}}, nil
+	})
+
+	t.Run("rejects nil encoder", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithBodyEncoder(nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("custom encoder handles its types", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithBodyEncoder(csvEncoder))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", csvRows{{"a", "1"}, {"b", "2"}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", gotType)
+		assert.Equal(t, "a,1\nb,2\n", gotBody)
+
+		_, err = client.Post(ctx, "/", map[string]int{"n": 1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+	})
+
+	t.Run("custom encoder may override built-ins", func(t *testing.T) {
+		upper := BodyEncoderFunc(func(body any) (*EncodedBody, error) {
+			s, ok := body.(string)
+			if !ok {
+				return nil, nil
+			}
+			return &EncodedBody{Body: strings.NewReader(strings.ToUpper(s)), ContentType: "text/plain"}, nil
+		})
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithBodyEncoder(upper))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", "hello", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", gotBody)
+	})
+
+	t.Run("encodes multipart bodies", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", MultipartBody(
+			Part{FormName: "title", Body: strings.NewReader("Q3 report")},
+			Part{FormName: "file", FileName: "q3.csv", Headers: http.Header{"Content-Type": {"text/csv"}}, Body: strings.NewReader("a,b")},
+		), nil)
+		require.NoError(t, err)
+
+		mediaType, params, err := mime.ParseMediaType(gotType)
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/form-data", mediaType)
+
+		form, err := multipart.NewReader(strings.NewReader(gotBody), params["boundary"]).ReadForm(1 << 20)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Q3 report"}, form.Value["title"])
+		require.Len(t, form.File["file"], 1)
+		assert.Equal(t, "q3.csv", form.File["file"][0].Filename)
+		assert.Equal(t, "text/csv", form.File["file"][0].Header.Get("Content-Type"))
+
+		_, err = client.Post(ctx, "/", MultipartBody(Part{FormName: "empty"}), nil)
+		assert.ErrorContains(t, err, "no body")
+	})
+}
@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrBufferLimit is returned when WithMaxBufferedBytes has no room for a
+// request or response body.
+var ErrBufferLimit = errors.New("buffered bytes limit exceeded")
+
+// BufferLimitMode selects what happens to a request that does not fit
+// under WithMaxBufferedBytes.
+type BufferLimitMode int
+
+const (
+	// BufferReject fails the request with ErrBufferLimit.
+	BufferReject BufferLimitMode = iota
+	// BufferQueue waits, until the request's context ends, for in-flight
+	// requests to release enough room.
+	BufferQueue
+)
+
+// bufferBudget caps the bytes buffered by in-flight requests.
+// It is safe for concurrent use across goroutines.
+type bufferBudget struct {
+	max   int64
+	queue bool
+
+	mu    sync.Mutex
+	used  int64
+	freed chan struct{} // closed and replaced whenever bytes are released
+}
+
+// WithMaxBufferedBytes caps the request and response body bytes the client
+// holds for in-flight requests, e.g. so a ballooning upstream response
+// cannot exhaust memory. A request body that does not fit, or any request
+// while the cap is exhausted, is rejected or queued according to mode
+// before it is sent; a response that would exceed the cap fails with
+// ErrBufferLimit as soon as it does, without being read further. Response
+// bytes are reserved as they are read, and compressed responses count at
+// their decoded size too. Streamed responses (DoIntoStream) are not
+// buffered and not counted. Current usage is reported by
+// Stats.BufferedBytes.
+func WithMaxBufferedBytes(max int64, mode BufferLimitMode) ClientOption {
+	return func(c *Client) error {
+		if max <= 0 {
+			return fmt.Errorf("max buffered bytes %d must be positive", max)
+		}
+		if mode != BufferReject && mode != BufferQueue {
+			return fmt.Errorf("unknown buffer limit mode %d", mode)
+		}
+		c.buffers = &bufferBudget{max: max, queue: mode == BufferQueue, freed: make(chan struct{})}
+		return nil
+	}
+}
+
+// acquire reserves n bytes, waiting for room in queue mode. While the
+// budget is exhausted even a request without a body does not fit, as its
+// response would have no room.
+func (b *bufferBudget) acquire(ctx context.Context, n int64) error {
+	if n > b.max {
+		return fmt.Errorf("%w: body of %d bytes exceeds the %d byte limit", ErrBufferLimit, n, b.max)
+	}
+	for {
+		b.mu.Lock()
+		if b.used < b.max && b.used+n <= b.max {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		wait := b.freed
+		b.mu.Unlock()
+
+		if !b.queue {
+			return fmt.Errorf("%w: %d bytes do not fit under the %d byte limit", ErrBufferLimit, n, b.max)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// tryAcquire reserves n bytes if they fit, without waiting.
+func (b *bufferBudget) tryAcquire(n int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+n > b.max {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// release returns n reserved bytes and wakes queued requests.
+func (b *bufferBudget) release(n int64) {
+	if n == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+func (b *bufferBudget) inUse() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// reserveRequestBody reserves the call's request body.
+func (c *Client) reserveRequestBody(ctx context.Context, cl *call) error {
+	if c.buffers == nil {
+		return nil
+	}
+	n := int64(len(cl.body))
+	if err := c.buffers.acquire(ctx, n); err != nil {
+		return c.bufferError(cl, err)
+	}
+	cl.bufferedBytes += n
+	return nil
+}
+
+// readResponseBody reads a response body, reserving it under the buffer
+// limit in place of the body of the call's previous attempt.
+func (c *Client) readResponseBody(cl *call, body io.Reader) ([]byte, error) {
+	if c.buffers == nil {
+		return io.ReadAll(body)
+	}
+
+	c.buffers.release(cl.responseBytes)
+	cl.bufferedBytes -= cl.responseBytes
+	cl.responseBytes = 0
+
+	data, err := c.readReserved(cl, body)
+	if errors.Is(err, ErrBufferLimit) {
+		return nil, c.bufferError(cl, err)
+	}
+	return data, err
+}
+
+// decompressResponse decodes a response body read by readResponseBody. The
+// decoded bytes are reserved as they are read, so a small compressed body
+// cannot expand past the limit; the compressed bytes are released after.
+func (c *Client) decompressResponse(cl *call, header http.Header, body []byte) ([]byte, int, error) {
+	if c.buffers == nil {
+		return c.decompressBody(header, body)
+	}
+	decoded, compressedSize, err := c.decodeBody(header, body, func(r io.Reader) ([]byte, error) {
+		return c.readReserved(cl, r)
+	})
+	if errors.Is(err, ErrBufferLimit) {
+		return nil, 0, c.bufferError(cl, err)
+	}
+	if err != nil || compressedSize == 0 {
+		return decoded, compressedSize, err
+	}
+	c.buffers.release(int64(compressedSize))
+	cl.bufferedBytes -= int64(compressedSize)
+	cl.responseBytes -= int64(compressedSize)
+	return decoded, compressedSize, nil
+}
+
+// readReserved reads r to the end, reserving each chunk for the call as it
+// arrives, so concurrent responses together stay under the limit.
+func (c *Client) readReserved(cl *call, r io.Reader) ([]byte, error) {
+	reserved := &reservingReader{r: r, budget: c.buffers}
+	data, err := io.ReadAll(reserved)
+	cl.responseBytes += reserved.n
+	cl.bufferedBytes += reserved.n
+	return data, err
+}
+
+// reservingReader reserves the bytes read through it under a buffer budget
+// and fails with ErrBufferLimit once they no longer fit.
+type reservingReader struct {
+	r      io.Reader
+	budget *bufferBudget
+	n      int64 // bytes reserved
+}
+
+func (r *reservingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 && !r.budget.tryAcquire(int64(n)) {
+		return 0, fmt.Errorf("%w: response body exceeds the limit after %d bytes", ErrBufferLimit, r.n)
+	}
+	r.n += int64(n)
+	return n, err
+}
+
+// bufferError wraps a buffer limit failure for the call.
+func (c *Client) bufferError(cl *call, err error) *Error {
+	return &Error{Kind: ErrKindUnknown, Method: cl.method, URL: cl.url, Err: err}
+}
+
+// releaseBuffers returns everything the call reserved.
+func (c *Client) releaseBuffers(cl *call) {
+	if c.buffers == nil {
+		return
+	}
+	c.buffers.release(cl.bufferedBytes)
+	cl.bufferedBytes, cl.responseBytes = 0, 0
+}
@@ -0,0 +1,233 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxBufferedBytes(t *testing.T) {
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := New(WithMaxBufferedBytes(0, BufferReject))
+		assert.Error(t, err)
+
+		_, err = New(WithMaxBufferedBytes(10, BufferLimitMode(9)))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects responses larger than the limit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, strings.Repeat("x", 2048))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxBufferedBytes(1024, BufferReject))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBufferLimit)
+
+		var clientErr *Error
+		require.True(t, errors.As(err, &clientErr))
+		assert.Equal(t, int64(0), client.Stats().BufferedBytes)
+	})
+
+	t.Run("counts decompressed bytes", func(t *testing.T) {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, err := gz.Write(make([]byte, 1<<20))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(compressed.Bytes())
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithCompression(), WithMaxBufferedBytes(64<<10, BufferReject))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		assert.ErrorIs(t, err, ErrBufferLimit)
+		assert.Equal(t, int64(0), client.Stats().BufferedBytes)
+	})
+
+	t.Run("reserves response bytes while reading", func(t *testing.T) {
+		budget := &bufferBudget{max: 10, freed: make(chan struct{})}
+		first := &reservingReader{r: strings.NewReader("12345678"), budget: budget}
+		second := &reservingReader{r: strings.NewReader("12345678"), budget: budget}
+
+		_, err := io.ReadAll(first)
+		require.NoError(t, err)
+		_, err = io.ReadAll(second)
+		assert.ErrorIs(t, err, ErrBufferLimit)
+		assert.Equal(t, int64(8), budget.inUse())
+	})
+
+	t.Run("rejects request bodies larger than the limit", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://127.0.0.1:1"), WithLoggerDisabled(), WithMaxBufferedBytes(8, BufferQueue))
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/", map[string]string{"name": "too long for the limit"}, nil)
+		assert.ErrorIs(t, err, ErrBufferLimit)
+	})
+
+	t.Run("releases bytes after each request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, `{"ok":true}`)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxBufferedBytes(64, BufferReject))
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			resp, err := client.Post(context.Background(), "/", map[string]int{"n": i}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, `{"ok":true}`, string(resp.Body))
+		}
+		assert.Equal(t, int64(0), client.Stats().BufferedBytes)
+	})
+
+	t.Run("reports in-flight bytes", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxBufferedBytes(1024, BufferReject))
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Post(context.Background(), "/", "payload", nil)
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return client.Stats().BufferedBytes > 0 }, time.Second, 5*time.Millisecond)
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, int64(0), client.Stats().BufferedBytes)
+	})
+
+	t.Run("rejects or queues when full", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				<-release
+			}
+		}))
+		defer server.Close()
+
+		for _, mode := range []BufferLimitMode{BufferReject, BufferQueue} {
+			client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxBufferedBytes(20, mode))
+			require.NoError(t, err)
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := client.Post(context.Background(), "/slow", "fifteen bytes", nil)
+				done <- err
+			}()
+			require.Eventually(t, func() bool { return client.Stats().BufferedBytes > 0 }, time.Second, 5*time.Millisecond)
+
+			if mode == BufferReject {
+				_, err = client.Post(context.Background(), "/fast", "fifteen bytes", nil)
+				assert.ErrorIs(t, err, ErrBufferLimit)
+				release <- struct{}{}
+				require.NoError(t, <-done)
+				continue
+			}
+
+			queued := make(chan error, 1)
+			go func() {
+				_, err := client.Post(context.Background(), "/fast", "fifteen bytes", nil)
+				queued <- err
+			}()
+			select {
+			case err := <-queued:
+				t.Fatalf("queued request finished early: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			release <- struct{}{}
+			require.NoError(t, <-done)
+			require.NoError(t, <-queued)
+		}
+	})
+
+	t.Run("gates requests without a body at the cap", func(t *testing.T) {
+		release := make(chan struct{})
+		var fastHits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				<-release
+				return
+			}
+			fastHits.Add(1)
+		}))
+		defer server.Close()
+
+		for _, mode := range []BufferLimitMode{BufferReject, BufferQueue} {
+			fastHits.Store(0)
+			client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxBufferedBytes(13, mode))
+			require.NoError(t, err)
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := client.Post(context.Background(), "/slow", "fifteen bytes", nil)
+				done <- err
+			}()
+			require.Eventually(t, func() bool { return client.Stats().BufferedBytes == 13 }, time.Second, 5*time.Millisecond)
+
+			if mode == BufferReject {
+				_, err = client.Get(context.Background(), "/fast", nil)
+				assert.ErrorIs(t, err, ErrBufferLimit)
+				assert.Zero(t, fastHits.Load(), "rejected before reaching the upstream")
+				release <- struct{}{}
+				require.NoError(t, <-done)
+				continue
+			}
+
+			queued := make(chan error, 1)
+			go func() {
+				_, err := client.Delete(context.Background(), "/fast", nil)
+				queued <- err
+			}()
+			select {
+			case err := <-queued:
+				t.Fatalf("queued request finished early: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			assert.Zero(t, fastHits.Load())
+			release <- struct{}{}
+			require.NoError(t, <-done)
+			require.NoError(t, <-queued)
+			assert.Equal(t, int32(1), fastHits.Load())
+		}
+	})
+
+	t.Run("queued request honors context", func(t *testing.T) {
+		budget := &bufferBudget{max: 10, queue: true, freed: make(chan struct{})}
+		require.NoError(t, budget.acquire(context.Background(), 10))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, budget.acquire(ctx, 1), context.DeadlineExceeded)
+
+		budget.release(10)
+		assert.NoError(t, budget.acquire(context.Background(), 1))
+	})
+}
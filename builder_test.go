@@ -174,3 +174,108 @@ func TestRequestOption(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestRequestBuilderThen(t *testing.T) {
+	newJobServer := func(t *testing.T, requestIDs *[]string) *httptest.Server {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requestIDs = append(*requestIDs, r.Header.Get("X-Request-ID"))
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/jobs":
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"job-7"}`))
+			case r.Method == http.MethodGet && r.URL.Path == "/jobs/job-7":
+				_, _ = w.Write([]byte(`{"id":"job-7","status":"done"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	pollJob := func(client *Client) func(*Response) *RequestBuilder {
+		return func(resp *Response) *RequestBuilder {
+			var created struct{ ID string }
+			if err := resp.JSON(&created); err != nil {
+				return nil
+			}
+			return client.Request().Path("/jobs/" + created.ID)
+		}
+	}
+
+	t.Run("runs follow-up with values from the response", func(t *testing.T) {
+		var requestIDs []string
+		server := newJobServer(t, &requestIDs)
+		client, err := New(WithBaseURL(server.URL), WithMiddleware(RequestIDMiddleware("X-Request-ID")))
+		require.NoError(t, err)
+
+		var job struct{ ID, Status string }
+		err = client.Request().
+			Method(http.MethodPost).
+			Path("/jobs").
+			Then(pollJob(client)).
+			DoInto(context.Background(), &job)
+
+		require.NoError(t, err)
+		assert.Equal(t, "done", job.Status)
+		require.Len(t, requestIDs, 2)
+		assert.NotEmpty(t, requestIDs[0])
+		assert.Equal(t, requestIDs[0], requestIDs[1])
+	})
+
+	t.Run("nil builder ends the chain", func(t *testing.T) {
+		var requestIDs []string
+		server := newJobServer(t, &requestIDs)
+		client, err := New(WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		resp, err := client.Request().
+			Method(http.MethodPost).
+			Path("/jobs").
+			Then(func(*Response) *RequestBuilder { return nil }).
+			Do(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Len(t, requestIDs, 1)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		var requestIDs []string
+		server := newJobServer(t, &requestIDs)
+		client, err := New(WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		called := false
+		_, err = client.Request().
+			Path("/missing").
+			Then(func(*Response) *RequestBuilder {
+				called = true
+				return nil
+			}).
+			Do(context.Background())
+
+		require.Error(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("bounds the chain length", func(t *testing.T) {
+		var requestIDs []string
+		server := newJobServer(t, &requestIDs)
+		client, err := New(WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		var again func(*Response) *RequestBuilder
+		again = func(*Response) *RequestBuilder {
+			return client.Request().Path("/jobs/job-7").Then(again)
+		}
+
+		_, err = client.Request().Path("/jobs/job-7").Then(again).Do(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "request chain exceeds")
+		assert.Len(t, requestIDs, maxChainedRequests)
+	})
+}
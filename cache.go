@@ -0,0 +1,289 @@
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response held in a CacheStore.
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// CacheStore persists cached responses by key. Implementations must be safe
+// for concurrent use across goroutines.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	Set(ctx context.Context, key string, entry *CachedResponse) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCacheStore is an in-memory CacheStore that evicts the least recently
+// used entry when full. It is safe for concurrent use across goroutines.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+// NewMemoryCacheStore creates an LRU store holding at most maxEntries responses.
+func NewMemoryCacheStore(maxEntries int) (*MemoryCacheStore, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("cache max entries %d must be positive", maxEntries)
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element, maxEntries),
+	}, nil
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true, nil
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, entry *CachedResponse) error {
+	if entry == nil {
+		return errors.New("cache entry cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Delete implements CacheStore.
+func (s *MemoryCacheStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// responseCache is the client's caching layer.
+type responseCache struct {
+	store        CacheStore
+	staleIfError time.Duration
+}
+
+// WithStaleIfError caches successful GET responses in store and, when the
+// upstream later fails with a 5xx, timeout or network error, serves the
+// cached copy if it has been stale for no longer than maxStale, counted
+// from the end of its freshness lifetime. A stale-if-error Cache-Control
+// directive on the cached response overrides maxStale. Served copies are
+// marked with Response.Stale. Responses marked no-store or private, or
+// that Vary on anything but Accept-Encoding, are not cached, and entries
+// are keyed by the request's credentials, so a shared store never serves
+// one caller's response to another.
+func WithStaleIfError(store CacheStore, maxStale time.Duration) ClientOption {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("cache store cannot be nil")
+		}
+		if maxStale <= 0 {
+			return errors.New("stale-if-error window must be positive")
+		}
+		c.cache = &responseCache{store: store, staleIfError: maxStale}
+		return nil
+	}
+}
+
+// cacheKey identifies a cacheable request by its method, its URL and a
+// digest of the credentials in headers, the headers it is sent with.
+func cacheKey(method, url string, headers http.Header) string {
+	key := method + " " + url
+	if identity := credentialDigest(headers); identity != "" {
+		key += " " + identity
+	}
+	return key
+}
+
+// credentialDigest returns a digest of the credential headers in h, those
+// redacted from logs, or "" if there are none.
+func credentialDigest(h http.Header) string {
+	var names []string
+	for name := range h {
+		if isSensitiveHeader(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	slices.Sort(names)
+	digest := sha256.New()
+	for _, name := range names {
+		for _, value := range h[name] {
+			fmt.Fprintf(digest, "%s: %s\n", strings.ToLower(name), value)
+		}
+	}
+	return hex.EncodeToString(digest.Sum(nil)[:16])
+}
+
+// storeResponse saves a successful GET response.
+func (c *Client) storeResponse(ctx context.Context, cl *call, res attemptResult) {
+	response := res.response
+	if c.cache == nil || cl.method != http.MethodGet || cl.stream != nil || cl.classification.sensitive() || !response.IsSuccess() || response.FromFallback || response.Stale || response.FromCache {
+		return
+	}
+	if !isShareable(response.Headers) {
+		return
+	}
+
+	entry := &CachedResponse{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Headers:    response.Headers.Clone(),
+		Body:       bytes.Clone(response.Body),
+		StoredAt:   time.Now(),
+	}
+	if err := c.cache.store.Set(ctx, cacheKey(cl.method, cl.url, res.reqHeaders), entry); err != nil {
+		c.logCacheError(ctx, cl, err)
+	}
+}
+
+// serveStale replaces a transient failure with a cached copy when allowed.
+func (c *Client) serveStale(ctx context.Context, cl *call, res attemptResult) attemptResult {
+	if c.cache == nil || res.err == nil || cl.method != http.MethodGet {
+		return res
+	}
+
+	var clientErr *Error
+	if !errors.As(res.err, &clientErr) || !(clientErr.IsServerError() || clientErr.IsTimeout() || clientErr.IsNetwork()) {
+		return res
+	}
+
+	if res.reqHeaders == nil {
+		return res // failed before it was sent, so its credentials are unknown
+	}
+	entry, ok, err := c.cache.store.Get(ctx, cacheKey(cl.method, cl.url, res.reqHeaders))
+	if err != nil {
+		c.logCacheError(ctx, cl, err)
+		return res
+	}
+	if !ok || currentAge(entry, time.Now())-freshnessLifetime(entry) > c.cache.staleWindow(entry) {
+		return res
+	}
+
+	headers := entry.Headers.Clone()
+	headers.Set("Warning", `111 - "Revalidation Failed"`)
+	return attemptResult{
+		response: &Response{
+			StatusCode: entry.StatusCode,
+			Status:     entry.Status,
+			Headers:    headers,
+			Body:       bytes.Clone(entry.Body),
+			Stale:      true,
+		},
+		reqHeaders: res.reqHeaders,
+	}
+}
+
+// isShareable reports whether a response with headers h may be stored for
+// later requests with the same credentials: it is not marked no-store or
+// private and varies on nothing but Accept-Encoding.
+func isShareable(h http.Header) bool {
+	cc := parseCacheControl(h)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+	return variesOnlyByEncoding(h)
+}
+
+// variesOnlyByEncoding reports whether the Vary header in h names nothing
+// but Accept-Encoding, which the client sends the same way every time.
+func variesOnlyByEncoding(h http.Header) bool {
+	for _, vary := range h.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// staleWindow returns how long past its freshness lifetime entry may be
+// served after an error.
+func (rc *responseCache) staleWindow(entry *CachedResponse) time.Duration {
+	for _, directive := range strings.Split(entry.Headers.Get("Cache-Control"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "stale-if-error") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return rc.staleIfError
+}
+
+func (c *Client) logCacheError(ctx context.Context, cl *call, err error) {
+	if !c.logEnabled(ctx, slog.LevelWarn) {
+		return
+	}
+	c.logger.Log(ctx, slog.LevelWarn, "http_cache_error",
+		slog.String("method", cl.method),
+		slog.String("url", cl.url),
+		slog.String("error", err.Error()),
+	)
+}
@@ -0,0 +1,329 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("evicts least recently used entry", func(t *testing.T) {
+		store, err := NewMemoryCacheStore(2)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200}))
+		require.NoError(t, store.Set(ctx, "b", &CachedResponse{StatusCode: 200}))
+		_, ok, _ := store.Get(ctx, "a")
+		require.True(t, ok)
+		require.NoError(t, store.Set(ctx, "c", &CachedResponse{StatusCode: 200}))
+
+		_, ok, _ = store.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok, _ = store.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 2, store.Len())
+	})
+
+	t.Run("deletes entries", func(t *testing.T) {
+		store, err := NewMemoryCacheStore(2)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200}))
+		require.NoError(t, store.Delete(ctx, "a"))
+
+		_, ok, _ := store.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("validates max entries", func(t *testing.T) {
+		_, err := NewMemoryCacheStore(0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be positive")
+	})
+}
+
+// newFlakyServer serves body until failing is set, then responds with 503.
+func newFlakyServer(body string, failing *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestWithStaleIfError(t *testing.T) {
+	t.Run("serves stale copy on server error", func(t *testing.T) {
+		var failing atomic.Bool
+		server := newFlakyServer(`{"id":1}`, &failing)
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, time.Minute),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.Stale)
+
+		failing.Store(true)
+		var result map[string]int
+		resp, err = client.Get(context.Background(), "/item", &result)
+
+		require.NoError(t, err)
+		assert.True(t, resp.Stale)
+		assert.Equal(t, 1, result["id"])
+		assert.Contains(t, resp.Headers.Get("Warning"), "111")
+	})
+
+	t.Run("does not share body slices with callers", func(t *testing.T) {
+		var failing atomic.Bool
+		server := newFlakyServer(`{"id":1}`, &failing)
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStaleIfError(store, time.Minute))
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+		copy(resp.Body, "XXXXXXXX")
+
+		failing.Store(true)
+		first, err := client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+		copy(first.Body, "XXXXXXXX")
+		second, err := client.Get(context.Background(), "/item", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":1}`, string(second.Body))
+	})
+
+	t.Run("serves stale copy on timeout", func(t *testing.T) {
+		var slow atomic.Bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slow.Load() {
+				time.Sleep(100 * time.Millisecond)
+			}
+			_, _ = w.Write([]byte(`ok`))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, time.Minute),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+
+		slow.Store(true)
+		resp, err := client.Get(context.Background(), "/item", nil, WithRequestTimeout(10*time.Millisecond))
+
+		require.NoError(t, err)
+		assert.True(t, resp.Stale)
+		assert.Equal(t, "ok", string(resp.Body))
+	})
+
+	t.Run("returns error when copy is older than window", func(t *testing.T) {
+		var failing atomic.Bool
+		server := newFlakyServer(`{"id":1}`, &failing)
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, 10*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		failing.Store(true)
+		_, err = client.Get(context.Background(), "/item", nil)
+
+		require.Error(t, err)
+	})
+
+	t.Run("honors stale-if-error directive", func(t *testing.T) {
+		var failing atomic.Bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Cache-Control", "max-age=0, stale-if-error=0")
+			_, _ = w.Write([]byte(`ok`))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, time.Hour),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+
+		failing.Store(true)
+		_, err = client.Get(context.Background(), "/item", nil)
+
+		require.Error(t, err)
+	})
+
+	t.Run("counts the window from expiry", func(t *testing.T) {
+		var failing atomic.Bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Cache-Control", "max-age=60")
+			_, _ = w.Write([]byte(`ok`))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, 10*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		failing.Store(true)
+		resp, err := client.Get(context.Background(), "/item", nil)
+
+		require.NoError(t, err)
+		assert.True(t, resp.Stale)
+	})
+
+	t.Run("does not store private or varying responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/no-store":
+				w.Header().Set("Cache-Control", "no-store")
+			case "/private":
+				w.Header().Set("Cache-Control", "private, max-age=60")
+			case "/vary":
+				w.Header().Set("Vary", "Accept-Language")
+			}
+			_, _ = w.Write([]byte(`ok`))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStaleIfError(store, time.Minute))
+		require.NoError(t, err)
+
+		for _, path := range []string{"/no-store", "/private", "/vary"} {
+			_, err = client.Get(context.Background(), path, nil)
+			require.NoError(t, err)
+		}
+		assert.Zero(t, store.Len())
+	})
+
+	t.Run("keys copies by credentials", func(t *testing.T) {
+		var failing atomic.Bool
+		server := newFlakyServer(`{"owner":"alice"}`, &failing)
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		newClient := func(token string) *Client {
+			client, err := New(
+				WithBaseURL(server.URL),
+				WithLoggerDisabled(),
+				WithAuth(BearerAuth(token)),
+				WithStaleIfError(store, time.Minute),
+			)
+			require.NoError(t, err)
+			return client
+		}
+		alice, bob := newClient("alice"), newClient("bob")
+
+		_, err = alice.Get(context.Background(), "/me", nil)
+		require.NoError(t, err)
+
+		failing.Store(true)
+		_, err = bob.Get(context.Background(), "/me", nil)
+		require.Error(t, err)
+		resp, err := alice.Get(context.Background(), "/me", nil)
+		require.NoError(t, err)
+		assert.True(t, resp.Stale)
+	})
+
+	t.Run("does not serve stale copy on client errors", func(t *testing.T) {
+		var status atomic.Int32
+		status.Store(http.StatusOK)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(int(status.Load()))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, time.Minute),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil)
+		require.NoError(t, err)
+
+		status.Store(http.StatusNotFound)
+		_, err = client.Get(context.Background(), "/item", nil)
+
+		require.Error(t, err)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithStaleIfError(nil, time.Minute))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cache store cannot be nil")
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithStaleIfError(store, 0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stale-if-error window must be positive")
+	})
+}
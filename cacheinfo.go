@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// CacheInfo is the caching metadata of a response, parsed from its
+// Cache-Control, Age and Expires headers.
+type CacheInfo struct {
+	// Directives holds every Cache-Control directive by lowercase name,
+	// with unquoted values, including ones without a field below.
+	Directives map[string]string
+
+	// MaxAge is the max-age directive; HasMaxAge reports whether it was
+	// present and valid, as max-age=0 is meaningful.
+	MaxAge    time.Duration
+	HasMaxAge bool
+
+	NoStore        bool
+	NoCache        bool
+	Private        bool
+	Public         bool
+	MustRevalidate bool
+	Immutable      bool
+
+	// StaleWhileRevalidate and StaleIfError are the RFC 5861 extensions,
+	// or 0 when absent.
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+
+	// Age is the Age header, or 0 when absent or invalid.
+	Age time.Duration
+
+	// Expires is the Expires header, or the zero time when absent.
+	// HasExpires is true, with a zero Expires, for an invalid date, which
+	// means the response is already expired (RFC 7234, section 5.3).
+	Expires    time.Time
+	HasExpires bool
+}
+
+// CacheInfo parses the response's caching headers, whether or not the
+// client caches responses itself.
+func (r *Response) CacheInfo() CacheInfo {
+	cc := parseCacheControl(r.Headers)
+	info := CacheInfo{Directives: cc}
+	info.MaxAge, info.HasMaxAge = cacheSeconds(cc, "max-age")
+	info.StaleWhileRevalidate, _ = cacheSeconds(cc, "stale-while-revalidate")
+	info.StaleIfError, _ = cacheSeconds(cc, "stale-if-error")
+	_, info.NoStore = cc["no-store"]
+	_, info.NoCache = cc["no-cache"]
+	_, info.Private = cc["private"]
+	_, info.Public = cc["public"]
+	_, info.MustRevalidate = cc["must-revalidate"]
+	_, info.Immutable = cc["immutable"]
+
+	info.Age, _ = deltaSeconds(r.Headers.Get("Age"))
+	if expires := r.Headers.Get("Expires"); expires != "" {
+		info.HasExpires = true
+		info.Expires, _ = http.ParseTime(expires)
+	}
+	return info
+}
@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse_CacheInfo(t *testing.T) {
+	t.Run("parses caching headers", func(t *testing.T) {
+		resp := &Response{Headers: http.Header{
+			"Cache-Control": {`private, max-age=60, stale-while-revalidate=30`, `must-revalidate, community="UCI"`},
+			"Age":           {"12"},
+			"Expires":       {"Thu, 01 Jan 2026 00:00:00 GMT"},
+		}}
+
+		info := resp.CacheInfo()
+		assert.True(t, info.HasMaxAge)
+		assert.Equal(t, time.Minute, info.MaxAge)
+		assert.Equal(t, 30*time.Second, info.StaleWhileRevalidate)
+		assert.True(t, info.Private)
+		assert.True(t, info.MustRevalidate)
+		assert.False(t, info.NoStore)
+		assert.Equal(t, "UCI", info.Directives["community"])
+		assert.Equal(t, 12*time.Second, info.Age)
+		assert.True(t, info.HasExpires)
+		assert.True(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Equal(info.Expires))
+	})
+
+	t.Run("distinguishes max-age=0 from absent", func(t *testing.T) {
+		info := (&Response{Headers: http.Header{"Cache-Control": {"no-cache, max-age=0"}}}).CacheInfo()
+		assert.True(t, info.HasMaxAge)
+		assert.Zero(t, info.MaxAge)
+		assert.True(t, info.NoCache)
+
+		info = (&Response{Headers: http.Header{}}).CacheInfo()
+		assert.False(t, info.HasMaxAge)
+		assert.False(t, info.HasExpires)
+		assert.Empty(t, info.Directives)
+	})
+
+	t.Run("treats invalid Expires as expired", func(t *testing.T) {
+		info := (&Response{Headers: http.Header{"Expires": {"0"}, "Age": {"-1"}}}).CacheInfo()
+		assert.True(t, info.HasExpires)
+		assert.True(t, info.Expires.IsZero())
+		assert.Zero(t, info.Age)
+	})
+
+	t.Run("caps huge ages instead of overflowing", func(t *testing.T) {
+		info := (&Response{Headers: http.Header{
+			"Cache-Control": {"max-age=99999999999"},
+			"Age":           {"99999999999"},
+		}}).CacheInfo()
+		assert.Equal(t, math.MaxInt32*time.Second, info.Age)
+		assert.Equal(t, info.MaxAge, info.Age)
+	})
+}
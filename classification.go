@@ -0,0 +1,122 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// DataClassification labels the sensitivity of the data a request carries.
+type DataClassification int
+
+const (
+	DataPublic DataClassification = iota
+	DataInternal
+	// DataPII marks personally identifiable information.
+	DataPII
+	// DataPCI marks payment card data.
+	DataPCI
+)
+
+// minClassifiedTLSVersion is the TLS floor for sensitive requests.
+const minClassifiedTLSVersion = tls.VersionTLS12
+
+// String returns the classification name.
+func (d DataClassification) String() string {
+	switch d {
+	case DataPublic:
+		return "public"
+	case DataInternal:
+		return "internal"
+	case DataPII:
+		return "pii"
+	case DataPCI:
+		return "pci"
+	default:
+		return fmt.Sprintf("classification(%d)", int(d))
+	}
+}
+
+// sensitive reports whether d triggers the stricter handling of WithDataClassification.
+func (d DataClassification) sensitive() bool {
+	return d >= DataPII
+}
+
+// WithDataClassification labels the request's data. PII and PCI requests
+// are handled strictly: they are refused over plain HTTP and over TLS older
+// than 1.2, their bodies are left out of logs and captured requests, their
+// responses are never cached, and their audit records carry the
+// classification next to the payload hashes.
+func WithDataClassification(level DataClassification) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.classification = level
+	}
+}
+
+// checkClassifiedURL refuses to send sensitive data over plain HTTP.
+func checkClassifiedURL(level DataClassification, scheme string) error {
+	if level.sensitive() && scheme != "https" {
+		return fmt.Errorf("%s request refused: scheme %q is not https", level, scheme)
+	}
+	return nil
+}
+
+// classifiedKey marks the context of a sensitive request, so the middleware
+// chain sends it through the client's classified http.Client.
+type classifiedKey struct{}
+
+func markClassified(ctx context.Context, level DataClassification) context.Context {
+	if !level.sensitive() {
+		return ctx
+	}
+	return context.WithValue(ctx, classifiedKey{}, true)
+}
+
+// configureClassifiedClient prepares a copy of the http.Client whose
+// transport refuses TLS below 1.2 in the handshake, so a sensitive body is
+// never written to a weaker connection. The copy keeps its own connection
+// pool and is built on first use. Other round trippers cannot be changed;
+// checkClassifiedTLS still checks their responses.
+func (c *Client) configureClassifiedClient() {
+	var base *http.Transport
+	switch rt := c.httpClient.Transport.(type) {
+	case nil:
+		base, _ = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		base = rt
+	}
+	if base == nil || (base.TLSClientConfig != nil && base.TLSClientConfig.MinVersion >= minClassifiedTLSVersion) {
+		return
+	}
+
+	httpClient := *c.httpClient
+	c.classifiedClient = sync.OnceValue(func() *http.Client {
+		transport := base.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.MinVersion = minClassifiedTLSVersion
+		if c.tlsServerNames != nil {
+			// The cloned dialer would still handshake with base's config.
+			transport.DialTLSContext = c.serverNameDialer(transport)
+		}
+		httpClient.Transport = transport
+		return &httpClient
+	})
+}
+
+// checkClassifiedTLS enforces the TLS floor for sensitive data on responses
+// from round trippers configureClassifiedClient could not configure.
+func checkClassifiedTLS(cl *call, resp *http.Response) error {
+	if !cl.classification.sensitive() || resp.TLS == nil || resp.TLS.Version >= minClassifiedTLSVersion {
+		return nil
+	}
+	return &Error{
+		Kind:   ErrKindUnknown,
+		Method: cl.method,
+		URL:    cl.url,
+		Err:    fmt.Errorf("%s request refused: negotiated %s is below TLS 1.2", cl.classification, tls.VersionName(resp.TLS.Version)),
+	}
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataClassification_String(t *testing.T) {
+	assert.Equal(t, "public", DataPublic.String())
+	assert.Equal(t, "internal", DataInternal.String())
+	assert.Equal(t, "pii", DataPII.String())
+	assert.Equal(t, "pci", DataPCI.String())
+	assert.Equal(t, "classification(9)", DataClassification(9).String())
+}
+
+func TestWithDataClassification(t *testing.T) {
+	t.Run("refuses plain HTTP", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/cards", map[string]string{"pan": "4111"}, nil, WithDataClassification(DataPCI))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pci request refused")
+	})
+
+	t.Run("allows plain HTTP for public data", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/status", nil, WithDataClassification(DataInternal))
+
+		require.NoError(t, err)
+	})
+
+	t.Run("omits bodies from logs and tags audit records", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ssn":"123-45-6789"}`))
+		}))
+		defer server.Close()
+
+		logger := &testLogger{}
+		var records []AuditRecord
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLogger(logger),
+			WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) error {
+				records = append(records, record)
+				return nil
+			}), testAuditKey),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/kyc", map[string]string{"name": "Ada"}, nil, WithDataClassification(DataPII))
+		require.NoError(t, err)
+
+		entry := logger.LastEntry()
+		assert.NotContains(t, entry.Attrs, "request_body")
+		assert.NotContains(t, entry.Attrs, "response_body")
+		assert.Equal(t, "pii", entry.Attrs["data_classification"])
+		require.Len(t, records, 1)
+		assert.Equal(t, "pii", records[0].Classification)
+		assert.NotEmpty(t, records[0].ResponseBodyHash)
+	})
+
+	t.Run("refuses TLS below 1.2 before sending", func(t *testing.T) {
+		var cards atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/cards" {
+				cards.Add(1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+		server.StartTLS()
+		defer server.Close()
+
+		httpClient := server.Client()
+		httpClient.Transport.(*http.Transport).TLSClientConfig.MinVersion = tls.VersionTLS10
+		client, err := New(WithBaseURL(server.URL), WithHTTPClient(httpClient), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/status", nil)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/cards", map[string]string{"card": "4111"}, nil, WithDataClassification(DataPCI))
+		require.Error(t, err)
+		assert.Zero(t, cards.Load())
+	})
+
+	t.Run("checks responses of custom round trippers", func(t *testing.T) {
+		transport := vendorTransport(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       http.NoBody,
+				TLS:        &tls.ConnectionState{Version: tls.VersionTLS11},
+				Request:    req,
+			}, nil
+		})
+		client, err := New(WithBaseURL("https://api.example.com"), WithHTTPClient(&http.Client{Transport: transport}), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/cards", nil, WithDataClassification(DataPCI))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "below TLS 1.2")
+	})
+
+	t.Run("leaves sensitive bodies out of captured requests", func(t *testing.T) {
+		cl := &call{method: http.MethodPost, url: "https://api.example.com/cards", body: []byte(`{"card":"4111"}`)}
+		assert.NotEmpty(t, captureRequest(cl, nil).Body)
+
+		cl.classification = DataPCI
+		assert.Empty(t, captureRequest(cl, nil).Body)
+	})
+
+	t.Run("does not cache classified responses", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("secret"))
+		}))
+		defer server.Close()
+
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLoggerDisabled(),
+			WithStaleIfError(store, time.Minute),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/profile", nil, WithDataClassification(DataPII))
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, store.Len())
+	})
+}
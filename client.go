@@ -3,7 +3,8 @@
 // # Thread Safety
 //
 // The following types are safe for concurrent use:
-//   - Client: Immutable after creation, all methods are goroutine-safe
+//   - Client: Immutable after creation apart from atomically swapped
+//     credentials (SetAuth, RotateAuth), all methods are goroutine-safe
 //   - RateLimiter: Uses internal mutex for thread-safe token bucket
 //   - MockTransport: Uses internal mutex for thread-safe request recording
 //
@@ -14,13 +15,12 @@ package httpclient
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,21 +28,76 @@ import (
 const Version = "0.1.0"
 
 // Client is an immutable HTTP client configured via functional options.
+// Only its credentials can change after creation, via SetAuth and RotateAuth.
 // It is safe for concurrent use across goroutines.
 type Client struct {
 	baseURL            *url.URL
 	httpClient         *http.Client
+	classifiedClient   func() *http.Client // TLS 1.2 floor for sensitive requests, nil if httpClient enforces it
 	timeout            time.Duration
+	timeoutPerRequest  bool // timeout also bounds each request without its own
 	headers            http.Header
 	defaultContentType string
 	retryPolicy        *RetryPolicy
 	rateLimiter        *RateLimiter
+	rateLimitKeys      *rateLimitKeys
 	middlewares        []Middleware
-	authProvider       AuthProvider
+	auth               atomic.Pointer[authState]
+	authRetry          bool
 	logger             Logger
 	thirdPartyCode     string
 	logBodyConfig      LogBodyConfig
 	loggingDisabled    bool
+	events             chan Event
+	eventsDropped      atomic.Uint64
+	discovery          *discoveryCache
+	compression        bool
+	contentDecoders    map[string]ContentDecoder // set by WithContentDecoder
+	acceptEncoding     string                    // codings sent with WithCompression
+	transportHooks     []func(*http.Transport) error
+	tlsServerNames     map[string]string
+	deadlineHeader     string
+	slowThreshold      time.Duration
+	slowCallback       SlowRequestFunc
+	latency            *latencyStats
+	adaptiveTimeout    *adaptiveTimeout
+	adaptiveLimiter    *AdaptiveLimiter
+	bulkhead           *bulkhead
+	fallback           FallbackFunc
+	errorEnrichers     []ErrorEnricher
+	cache              *responseCache
+	auditSink          AuditSink
+	auditHashKey       []byte
+	httpsOnly          *httpsOnly
+	allowedHosts       *allowedHosts
+	failover           *failover
+	balancing          *balancing
+	queryEncoding      *QueryEncoding
+	queryMergePolicy   QueryMergePolicy
+	pathJoinMode       PathJoinMode
+	dnsCache           *dnsCache
+	hostMapping        map[string]string
+	addressFamily      AddressFamily
+	redirects          *redirectPolicy
+	proxy              *proxyConfig
+	shadow             *shadowConfig
+	flags              FeatureFlagProvider
+	sharedRateLimiter  *RateLimiter
+	strictJSON         bool
+	metrics            MetricsCollector
+	buffers            *bufferBudget
+	chain              RoundTripFunc // middlewares around httpClient
+	bodyEncoders       []BodyEncoder
+	decoders           map[string]Decoder // by media type
+	soapDefaults       *soapDefaults
+	httpCache          *httpCache
+	requestIDGenerator RequestIDGenerator
+	adaptiveRateLimit  bool
+	successPredicate   SuccessPredicate
+	statusPolicy       *StatusPolicy
+	allowEmptyResponse bool
+	noErrorOnStatus    bool
+	idempotency        *idempotency
 }
 
 // ClientOption configures a Client.
@@ -51,27 +106,52 @@ type ClientOption func(*Client) error
 // New creates a new Client with the given options.
 // Returns an error if required options are missing or invalid.
 func New(opts ...ClientOption) (*Client, error) {
+	c, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.baseURL == nil {
+		return nil, errors.New("base URL is required: use WithBaseURL option")
+	}
+
+	return c, nil
+}
+
+// newClient applies defaults and options without requiring a base URL.
+func newClient(opts []ClientOption) (*Client, error) {
 	c := &Client{
 		httpClient:         &http.Client{},
 		timeout:            30 * time.Second,
 		headers:            make(http.Header),
 		defaultContentType: "application/json",
 		logBodyConfig:      DefaultLogBodyConfig(),
+		discovery:          newDiscoveryCache(DefaultDiscoveryTTL),
+		acceptEncoding:     defaultAcceptEncoding,
 	}
 
 	c.headers.Set("User-Agent", "httpclient/"+Version)
 	c.headers.Set("Accept", "application/json")
 
+	ownHTTPClient := c.httpClient
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
 			return nil, err
 		}
 	}
+	if c.redirects == nil && c.httpClient == ownHTTPClient {
+		c.redirects = newRedirectPolicy()
+	}
 
-	if c.baseURL == nil {
-		return nil, errors.New("base URL is required: use WithBaseURL option")
+	if err := c.configurePolicies(); err != nil {
+		return nil, err
+	}
+	if err := c.configureHTTPClient(); err != nil {
+		return nil, err
 	}
 
+	c.chain = c.middlewareChain()
+
 	// Enable logging by default unless explicitly disabled
 	if !c.loggingDisabled && c.logger == nil {
 		c.logger = newDefaultLogger()
@@ -80,6 +160,45 @@ func New(opts ...ClientOption) (*Client, error) {
 	return c, nil
 }
 
+// configurePolicies sets up and cross-checks the timeout, rate limiting,
+// shadowing, failover and caching options.
+func (c *Client) configurePolicies() error {
+	c.configureAdaptiveTimeout()
+	c.configureSharedRateLimit()
+	c.configureAdaptiveRateLimit()
+	if err := c.configureRateLimitKeys(); err != nil {
+		return err
+	}
+	if err := c.configureShadow(); err != nil {
+		return err
+	}
+	if err := c.configureFailover(); err != nil {
+		return err
+	}
+	return c.configureHTTPCache()
+}
+
+// configureHTTPClient builds the transport and redirect handling of the
+// HTTP client. It runs after configurePolicies, which validates the shadow
+// base URL it checks.
+func (c *Client) configureHTTPClient() error {
+	if c.usesCustomDialer() {
+		c.transportHooks = append(c.transportHooks, c.configureDialer)
+	}
+	if err := c.configureTransport(); err != nil {
+		return err
+	}
+	c.configureRedirects()
+	if err := c.configureHTTPSOnly(); err != nil {
+		return err
+	}
+	if err := c.configureAllowedHosts(); err != nil {
+		return err
+	}
+	c.configureClassifiedClient()
+	return nil
+}
+
 // WithBaseURL sets the base URL for all requests.
 func WithBaseURL(baseURL string) ClientOption {
 	return func(c *Client) error {
@@ -90,6 +209,9 @@ func WithBaseURL(baseURL string) ClientOption {
 		if err != nil {
 			return err
 		}
+		if err := normalizeURLHost(u); err != nil {
+			return err
+		}
 		c.baseURL = u
 		return nil
 	}
@@ -163,6 +285,9 @@ func WithDefaultContentType(contentType string) ClientOption {
 // WithRetry sets the retry policy.
 func WithRetry(policy *RetryPolicy) ClientOption {
 	return func(c *Client) error {
+		if policy != nil && policy.MaxAttempts < 1 {
+			return errors.New("retry policy MaxAttempts must be at least 1")
+		}
 		c.retryPolicy = policy
 		return nil
 	}
@@ -193,7 +318,7 @@ func WithAuth(auth AuthProvider) ClientOption {
 		if auth == nil {
 			return errors.New("auth provider cannot be nil")
 		}
-		c.authProvider = auth
+		c.auth.Store(&authState{primary: auth})
 		return nil
 	}
 }
@@ -206,7 +331,9 @@ func WithLogger(logger Logger) ClientOption {
 	}
 }
 
-// WithThirdPartyCode sets the third party code used in logging.
+// WithThirdPartyCode names the integration, e.g. "stripe". The code tags
+// logs, events, statistics, audit records and errors (Error.ThirdParty)
+// and namespaces feature flags.
 func WithThirdPartyCode(code string) ClientOption {
 	return func(c *Client) error {
 		c.thirdPartyCode = code
@@ -225,6 +352,9 @@ func WithLoggerDisabled() ClientOption {
 // WithLogBodyConfig sets the body logging configuration.
 func WithLogBodyConfig(config LogBodyConfig) ClientOption {
 	return func(c *Client) error {
+		if err := validateXMLRedactions(config.RedactXML); err != nil {
+			return err
+		}
 		c.logBodyConfig = config
 		return nil
 	}
@@ -255,226 +385,603 @@ func (c *Client) Delete(ctx context.Context, path string, result any, opts ...Re
 	return c.doWithOptions(ctx, http.MethodDelete, path, nil, result, opts)
 }
 
+// call is a fully prepared logical request. It is encoded once and may be
+// sent several times by the retry loop.
+type call struct {
+	method          string
+	url             string
+	header          http.Header
+	body            []byte
+	contentType     string
+	timeout         time.Duration
+	decompress      bool
+	endpoint        string // "METHOD template" key for statistics
+	priority        Priority
+	tee             io.Writer
+	classification  DataClassification
+	host            string
+	hostOverride    string
+	stream          streamFunc // set by DoIntoStream and DoIntoMultipart
+	noErrorOnStatus bool
+	errorResult     any // set by WithErrorResult
+	operation       string
+	retryStateKey   string
+	rateLimiter     *RateLimiter // set by waitRateLimit
+	targetURLs      []string     // the URL under each base URL, see baseURLs
+	attempts        int          // made so far, including resumed ones
+	timing          *callTiming  // set by executeWithRetry if it can time out
+
+	// Bytes reserved under WithMaxBufferedBytes, of which responseBytes
+	// belong to the latest attempt's response body.
+	bufferedBytes int64
+	responseBytes int64
+
+	// Body bytes sent and received over all attempts, for traffic stats,
+	// and the received bytes the client decoded, before and after decoding.
+	sentBytes         uint64
+	receivedBytes     uint64
+	compressedBytes   uint64
+	decompressedBytes uint64
+}
+
+// attemptResult is the outcome of sending a call once.
+type attemptResult struct {
+	response   *Response
+	reqHeaders http.Header
+	err        error
+	retryable  bool
+}
+
 func (c *Client) doWithOptions(ctx context.Context, method, path string, body any, result any, opts []RequestOption) (*Response, error) {
 	cfg := newRequestConfig()
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	reqURL := c.baseURL.JoinPath(path)
+	path, err := c.resolvePath(ctx, path, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(cfg.query) > 0 {
-		q := reqURL.Query()
-		for key, values := range cfg.query {
-			for _, value := range values {
-				q.Add(key, value)
-			}
-		}
-		reqURL.RawQuery = q.Encode()
+	cl, err := c.newCall(ctx, method, path, body, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	// Encode body once for potential replay
-	var bodyReader io.Reader
-	var contentType string
-	var extraHeaders map[string]string
-	var err error
+	return c.execute(ctx, cl, result)
+}
 
-	if IsXMLBody(body) {
-		bodyReader, contentType, err = EncodeXMLBody(body)
-	} else if IsSOAPBody(body) {
-		bodyReader, contentType, extraHeaders, err = EncodeSOAPBody(body)
-	} else {
-		bodyReader, contentType, err = encodeBody(body)
+// resolvePath applies WithFlaggedPath and expands path parameters. An
+// expanded template becomes the endpoint for statistics unless one was set.
+func (c *Client) resolvePath(ctx context.Context, path string, cfg *requestConfig) (string, error) {
+	if cfg.flaggedPath != "" && c.flagEnabled(ctx, cfg.pathFlag) {
+		path = cfg.flaggedPath
 	}
+	if len(cfg.pathParams) == 0 {
+		return path, nil
+	}
+	if cfg.endpoint == "" {
+		cfg.endpoint = path
+	}
+	return expandPath(path, cfg.pathParams)
+}
+
+// newCall builds the URL, encodes the body and merges headers for a request.
+func (c *Client) newCall(ctx context.Context, method, path string, body any, cfg *requestConfig) (*call, error) {
+	reqURL, err := c.requestURL(path, cfg)
 	if err != nil {
 		return nil, err
 	}
+	targetURLs, err := c.targetURLs(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Absolute URLs are counted in statistics under their path alone.
+	if _, ok := absoluteURL(path); ok {
+		path = reqURL.Path
+	}
 
-	var bodyBytes []byte
-	if bodyReader != nil {
-		bodyBytes, err = io.ReadAll(bodyReader)
-		if err != nil {
-			return nil, err
-		}
+	bodyBytes, contentType, extraHeaders, err := c.encodeRequestBody(body)
+	if err != nil {
+		return nil, err
 	}
 
-	// Apply rate limiting
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, &Error{
-				Kind:   ErrKindRateLimit,
-				Method: method,
-				URL:    reqURL.String(),
-				Err:    err,
-			}
+	header := c.requestHeader(cfg, body, contentType, extraHeaders)
+
+	operation := GetOperation(ctx)
+	return &call{
+		method:          method,
+		url:             reqURL.String(),
+		header:          header,
+		body:            bodyBytes,
+		contentType:     contentType,
+		timeout:         cfg.timeout,
+		decompress:      c.compression && !cfg.rawBody,
+		endpoint:        callEndpoint(operation, method, path, cfg.endpoint),
+		operation:       operation,
+		retryStateKey:   cfg.retryStateKey,
+		priority:        callPriority(ctx, cfg),
+		tee:             cfg.tee,
+		classification:  cfg.classification,
+		host:            reqURL.Host,
+		hostOverride:    cfg.hostOverride,
+		noErrorOnStatus: c.noErrorOnStatus || cfg.noErrorOnStatus,
+		errorResult:     cfg.errorResult,
+		targetURLs:      targetURLs,
+	}, nil
+}
+
+// requestHeader merges the client's headers with the request's and those
+// implied by the body.
+func (c *Client) requestHeader(cfg *requestConfig, body any, contentType string, extraHeaders map[string]string) http.Header {
+	header := c.headers.Clone()
+	for key, values := range cfg.headers {
+		for _, value := range values {
+			header.Set(key, value)
 		}
 	}
 
-	if cfg.timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
-		defer cancel()
+	if cfg.contentType != "" {
+		header.Set("Content-Type", cfg.contentType)
+	} else if contentType != "" {
+		header.Set("Content-Type", contentType)
+	} else if body != nil {
+		header.Set("Content-Type", c.defaultContentType)
+	}
+
+	// Apply extra headers from body encoding (e.g., SOAPAction)
+	for key, value := range extraHeaders {
+		header.Set(key, value)
+	}
+
+	// Setting Accept-Encoding explicitly turns off net/http's transparent
+	// gzip handling, so the client either decodes the body or passes it through.
+	if (c.compression || cfg.rawBody) && header.Get("Accept-Encoding") == "" {
+		header.Set("Accept-Encoding", c.acceptEncoding)
+	}
+	return header
+}
+
+// encodeRequestBody encodes body once so it can be replayed on retries.
+func (c *Client) encodeRequestBody(body any) ([]byte, string, map[string]string, error) {
+	if body == nil {
+		return nil, "", nil, nil
+	}
+
+	encoded, err := c.encodeWithChain(body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if encoded.Body == nil {
+		return nil, encoded.ContentType, encoded.Headers, nil
+	}
+
+	bodyBytes, err := io.ReadAll(encoded.Body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return bodyBytes, encoded.ContentType, encoded.Headers, nil
+}
+
+// completeError fills in the context of a client error the call ended
+// with: what was sent, how often, the time budget if it ran out, and what
+// WithErrorEnricher adds. A deadline passing outside the transport, e.g.
+// while the body is read, becomes a client error too.
+func (c *Client) completeError(ctx context.Context, cl *call, res attemptResult, err error, start time.Time) error {
+	var clientErr *Error
+	if !errors.As(err, &clientErr) && errors.Is(err, context.DeadlineExceeded) {
+		clientErr = c.wrapError(err, cl.method, cl.url)
+		err = clientErr
+	}
+	if clientErr == nil {
+		return err
+	}
+	if clientErr.Request == nil {
+		clientErr.Request = captureRequest(cl, res.reqHeaders)
+	}
+	if clientErr.ThirdParty == "" {
+		clientErr.ThirdParty = c.thirdPartyCode
+	}
+	if clientErr.Attempts == 0 {
+		clientErr.Attempts = cl.attempts
+	}
+	if clientErr.Timeout == nil && errors.Is(err, context.DeadlineExceeded) {
+		clientErr.Timeout = c.timeoutInfo(ctx, cl, start)
+	}
+	c.enrichError(ctx, clientErr)
+	return err
+}
+
+// execute sends the call through the retry loop, decodes the result and
+// records the outcome.
+func (c *Client) execute(ctx context.Context, cl *call, result any) (*Response, error) {
+	startTime := time.Now()
+	ctx = c.withRequestIDGenerator(ctx)
+	defer c.releaseBuffers(cl)
+	c.emit(Event{Kind: EventRequestStarted, Method: cl.method, URL: cl.url})
+
+	stopSlowWatch := c.watchSlow(ctx, cl, startTime)
+	res := c.dedupedFetch(ctx, cl)
+	stopSlowWatch()
+	if res.err == nil {
+		c.storeResponse(ctx, cl, res)
+	}
+	res = c.serveStale(ctx, cl, res)
+	res = c.applyFallback(ctx, cl, res)
+	response, err := c.settle(cl, res, result)
+
+	err = c.completeError(ctx, cl, res, err, startTime)
+
+	duration := time.Since(startTime)
+	if c.latency != nil {
+		c.latency.observe(cl.endpoint, cl.host, startTime.Add(duration), sample{
+			duration:          duration,
+			failed:            err != nil,
+			requestBytes:      cl.sentBytes,
+			responseBytes:     cl.receivedBytes,
+			compressedBytes:   cl.compressedBytes,
+			decompressedBytes: cl.decompressedBytes,
+		})
+	}
+	c.logRequest(ctx, cl, res.reqHeaders, response, duration, err)
+	c.audit(ctx, cl, response, startTime, duration, err)
+
+	finished := Event{
+		Kind:          EventRequestFinished,
+		Method:        cl.method,
+		URL:           cl.url,
+		Duration:      duration,
+		RequestBytes:  cl.sentBytes,
+		ResponseBytes: cl.receivedBytes,
+		Err:           err,
+	}
+	if response != nil {
+		finished.StatusCode = response.StatusCode
+	}
+	c.emit(finished)
+	c.mirror(ctx, cl, response)
+
+	return response, err
+}
+
+func (c *Client) executeWithRetry(ctx context.Context, cl *call) attemptResult {
+	ctx = withCallPriority(ctx, cl)
+	if err := c.waitRateLimit(ctx, cl); err != nil {
+		return attemptResult{err: err}
+	}
+	if err := c.reserveRequestBody(ctx, cl); err != nil {
+		return attemptResult{err: err}
 	}
+	defer c.clearRetryState(ctx, cl)
 
+	ctx, cancel := c.startTiming(ctx, cl)
+	defer cancel()
+
+	ctx = withCallIdempotencyKey(ctx, cl)
+	if c.retryPolicy != nil && c.retryPolicy.Budget != nil {
+		c.retryPolicy.Budget.deposit()
+	}
+	ctx, deadline, cancelRetries := c.retryDeadline(ctx)
+	defer cancelRetries()
 	maxAttempts := 1
 	if c.retryPolicy != nil {
 		maxAttempts = c.retryPolicy.MaxAttempts
 	}
 
-	var response *Response
-	var lastErr error
-	startTime := time.Now()
-	var finalReqHeaders http.Header
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Create fresh body reader for each attempt
-		var reqBody io.Reader
-		if bodyBytes != nil {
-			reqBody = bytes.NewReader(bodyBytes)
+	first, timer, err := c.resumeRetries(ctx, cl, maxAttempts)
+	if err != nil {
+		return attemptResult{err: c.wrapError(err, cl.method, cl.url)}
+	}
+	var res attemptResult
+	for attempt := first; attempt <= maxAttempts; attempt++ {
+		beginAttempt(ctx, cl, attempt)
+		start := time.Now()
+		res = c.targetedAttempt(ctx, cl, attempt)
+		c.observeAttempt(cl, attempt, res, time.Since(start))
+		c.observeAttemptLatency(ctx, cl, res, start)
+		if res.err == nil {
+			return res
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), reqBody)
-		if err != nil {
-			return nil, err
+		failed := Event{Kind: EventAttemptFailed, Method: cl.method, URL: cl.url, Attempt: attempt, Err: res.err}
+		if res.response != nil {
+			failed.StatusCode = res.response.StatusCode
 		}
+		c.emit(failed)
 
-		for key, values := range c.headers {
-			for _, value := range values {
-				req.Header.Add(key, value)
-			}
+		if !res.retryable || attempt >= maxAttempts {
+			return res
 		}
-
-		for key, values := range cfg.headers {
-			for _, value := range values {
-				req.Header.Set(key, value)
-			}
+		delay, ok := c.scheduleRetry(ctx, cl, attempt, res, deadline)
+		if !ok {
+			return res
 		}
+		timer = c.waitForRetry(ctx, timer, delay)
+	}
 
-		if cfg.contentType != "" {
-			req.Header.Set("Content-Type", cfg.contentType)
-		} else if contentType != "" {
-			req.Header.Set("Content-Type", contentType)
-		} else if body != nil {
-			req.Header.Set("Content-Type", c.defaultContentType)
-		}
+	return res
+}
 
-		// Apply extra headers from body encoding (e.g., SOAPAction)
-		for key, value := range extraHeaders {
-			req.Header.Set(key, value)
-		}
+// retryDeadline bounds ctx by the retry policy's MaxElapsedTime and returns
+// the deadline, or the zero time without one.
+func (c *Client) retryDeadline(ctx context.Context) (context.Context, time.Time, context.CancelFunc) {
+	if c.retryPolicy == nil || c.retryPolicy.MaxElapsedTime <= 0 {
+		return ctx, time.Time{}, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.retryPolicy.MaxElapsedTime)
+	deadline, _ := ctx.Deadline()
+	return ctx, deadline, cancel
+}
 
-		// Apply authentication
-		if c.authProvider != nil {
-			if err := c.authProvider.Apply(req); err != nil {
-				return nil, &Error{
-					Kind:   ErrKindUnknown,
-					Method: method,
-					URL:    reqURL.String(),
-					Err:    err,
-				}
-			}
-		}
+// requestTimeout returns the per-request timeout for the call, or 0.
+func (c *Client) requestTimeout(cl *call) time.Duration {
+	timeout := cl.timeout
+	if timeout <= 0 && c.adaptiveTimeout != nil {
+		timeout = c.adaptiveTimeout.timeoutFor(c.adaptiveTimeout.latency, cl.endpoint, time.Now())
+	}
+	if timeout <= 0 && c.timeoutPerRequest {
+		timeout = c.timeout
+	}
+	return timeout
+}
 
-		// Capture headers for logging (after auth, will be redacted)
-		finalReqHeaders = req.Header.Clone()
+// scheduleRetry returns the delay before the next attempt, or false when
+// the retry budget or the MaxElapsedTime deadline rules out another one.
+func (c *Client) scheduleRetry(ctx context.Context, cl *call, attempt int, res attemptResult, deadline time.Time) (time.Duration, bool) {
+	delay := c.retryDelay(res.err, attempt)
+	if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	if c.retryPolicy.Budget != nil && !c.retryPolicy.Budget.withdraw(cl.priority) {
+		return 0, false
+	}
 
-		// Build middleware chain
-		transport := func(r *http.Request) (*http.Response, error) {
-			return c.httpClient.Do(r)
-		}
+	c.saveRetryState(ctx, cl, attempt, delay)
+	cl.timing.enter(TimeoutPhaseBackoff)
+	c.emit(Event{Kind: EventRetryScheduled, Method: cl.method, URL: cl.url, Attempt: attempt + 1, Delay: delay})
+	if c.retryPolicy.OnRetry != nil {
+		_ = c.runHook(ctx, "retry hook", func() error {
+			c.retryPolicy.OnRetry(attempt+1, delay, res.response, res.err)
+			return nil
+		})
+	}
+	return delay, true
+}
 
-		// Wrap transport with middlewares (in reverse order so first added executes first)
-		for i := len(c.middlewares) - 1; i >= 0; i-- {
-			mw := c.middlewares[i]
-			next := transport
-			transport = func(r *http.Request) (*http.Response, error) {
-				return mw(r, next)
-			}
+// retryDelay returns the backoff before the next attempt, honoring Retry-After.
+func (c *Client) retryDelay(err error, attempt int) time.Duration {
+	var clientErr *Error
+	if errors.As(err, &clientErr) && clientErr.RetryAfter > 0 {
+		return clientErr.RetryAfter
+	}
+	return c.retryPolicy.Backoff(attempt)
+}
+
+// limitedAttempt runs an attempt while holding a concurrency slot, if a
+// concurrency limiter is configured.
+func (c *Client) limitedAttempt(ctx context.Context, cl *call, attempt int) attemptResult {
+	if c.bulkhead != nil {
+		if err := c.bulkhead.acquire(ctx); err != nil {
+			return attemptResult{err: c.bulkheadError(cl, err)}
 		}
+		defer c.bulkhead.release()
+	}
+	if c.adaptiveLimiter == nil {
+		return c.attempt(ctx, cl, attempt)
+	}
 
-		resp, err := transport(req)
+	release, err := c.adaptiveLimiter.Acquire(ctx)
+	if err != nil {
+		return attemptResult{err: c.wrapError(err, cl.method, cl.url)}
+	}
+
+	start := time.Now()
+	res := c.attempt(ctx, cl, attempt)
+	release(time.Since(start), isOverloadSignal(ctx, res))
+	return res
+}
+
+// attempt sends the call once through auth and the middleware chain.
+func (c *Client) attempt(ctx context.Context, cl *call, attempt int) attemptResult {
+	resp, res := c.sendAuthenticated(ctx, cl)
+	if res.err != nil {
+		return res
+	}
+	cl.timing.enter(TimeoutPhaseRead)
+	reqHeaders := res.reqHeaders
+	c.observeQuota(cl, resp)
+
+	if cl.stream != nil && !c.isErrorStatus(resp.StatusCode) {
+		return c.streamBody(cl, resp, reqHeaders)
+	}
+
+	respBody, err := c.readResponseBody(cl, resp.Body)
+	resp.Body.Close()
+	cl.receivedBytes += uint64(len(respBody))
+	if err != nil {
+		return attemptResult{reqHeaders: reqHeaders, err: err}
+	}
+
+	var compressedSize int
+	if cl.decompress {
+		respBody, compressedSize, err = c.decompressResponse(cl, resp.Header, respBody)
 		if err != nil {
-			lastErr = c.wrapError(err, method, reqURL.String())
-			// Network errors are retryable
-			if c.retryPolicy != nil && attempt < maxAttempts {
-				c.waitForRetry(ctx, c.retryPolicy.Backoff(attempt))
-				continue
-			}
-			return nil, lastErr
+			return attemptResult{reqHeaders: reqHeaders, err: err}
 		}
+		if compressedSize > 0 {
+			cl.compressedBytes += uint64(compressedSize)
+			cl.decompressedBytes += uint64(len(respBody))
+		}
+	}
 
-		respBody, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return nil, err
+	response := &Response{
+		StatusCode:     resp.StatusCode,
+		Status:         resp.Status,
+		Headers:        resp.Header,
+		Body:           respBody,
+		CompressedSize: compressedSize,
+		TLSResumed:     resp.TLS != nil && resp.TLS.DidResume,
+	}
+
+	if !c.isErrorStatus(resp.StatusCode) {
+		return c.acceptResponse(ctx, cl, resp, response, reqHeaders, attempt)
+	}
+	return c.rejectResponse(ctx, cl, resp, response, reqHeaders, attempt)
+}
+
+// sendAuthenticated sends the call with the client's credentials. A 401 is
+// sent again once: with the new credentials during RotateAuth's overlap,
+// or with fresh ones under WithAuthRetry.
+func (c *Client) sendAuthenticated(ctx context.Context, cl *call) (*http.Response, attemptResult) {
+	state := c.auth.Load()
+	primary, fallback := state.providers(time.Now())
+
+	resp, res := c.send(ctx, cl, primary)
+	if res.err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, res
+	}
+	switch inv, ok := primary.(AuthInvalidator); {
+	case fallback != nil:
+		// The rotated-out credential was rejected; try the new one.
+		drainAndClose(resp.Body)
+		resp, res = c.send(ctx, cl, fallback)
+		if res.err == nil && resp.StatusCode != http.StatusUnauthorized {
+			c.auth.CompareAndSwap(state, &authState{primary: fallback})
 		}
+	case c.authRetry && ok:
+		drainAndClose(resp.Body)
+		inv.InvalidateAuth(resp.Request)
+		resp, res = c.send(ctx, cl, primary)
+	}
+	return resp, res
+}
 
-		response = &Response{
-			StatusCode: resp.StatusCode,
-			Status:     resp.Status,
-			Headers:    resp.Header,
-			Body:       respBody,
+// send builds a fresh request for the call, applies auth and sends it.
+// On success the returned result carries only the logged request headers.
+func (c *Client) send(ctx context.Context, cl *call, auth AuthProvider) (*http.Response, attemptResult) {
+	ctx, written := c.traceWrites(ctx, cl)
+	ctx, reused := c.traceConns(ctx)
+	ctx = cl.timing.trace(ctx)
+	ctx = markClassified(ctx, cl.classification)
+	req, err := c.attemptRequest(ctx, cl)
+	if err != nil {
+		return nil, attemptResult{err: err}
+	}
+
+	// Apply authentication
+	if auth != nil {
+		if err := c.applyAuth(auth, req); err != nil {
+			return nil, attemptResult{err: &Error{
+				Kind:   internalKind(err, ErrKindUnknown),
+				Method: cl.method,
+				URL:    cl.url,
+				Err:    err,
+			}}
 		}
+	}
 
-		if resp.StatusCode >= 400 {
-			lastErr = &Error{
-				Kind:       ErrKindHTTP,
-				StatusCode: resp.StatusCode,
-				Status:     resp.Status,
-				Body:       respBody,
-				Headers:    resp.Header,
-				Method:     method,
-				URL:        reqURL.String(),
-				Attempts:   attempt,
-			}
+	// Capture headers for logging (after auth, will be redacted) before
+	// middlewares can add their own, such as signatures.
+	reqHeaders := req.Header
+	if len(c.middlewares) > 0 {
+		reqHeaders = req.Header.Clone()
+	}
 
-			// Check if we should retry
-			if c.retryPolicy != nil && attempt < maxAttempts && c.retryPolicy.ShouldRetry(resp.StatusCode) {
-				delay := c.retryPolicy.Backoff(attempt)
+	cl.sentBytes += uint64(len(cl.body))
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		return nil, attemptResult{
+			reqHeaders: reqHeaders,
+			err:        c.wrapError(err, cl.method, cl.url),
+			retryable:  c.retryNetworkError(req, written, err),
+		}
+	}
 
-				// Check for Retry-After header
-				if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-					if parsed := ParseRetryAfter(retryAfter); parsed > 0 {
-						delay = parsed
-					}
-				}
+	c.observeTLSHandshake(cl, resp, reused)
+	if err := checkClassifiedTLS(cl, resp); err != nil {
+		drainAndClose(resp.Body)
+		return nil, attemptResult{reqHeaders: reqHeaders, err: err}
+	}
 
-				c.waitForRetry(ctx, delay)
-				continue
-			}
+	return resp, attemptResult{reqHeaders: reqHeaders}
+}
 
-			c.logRequest(ctx, method, reqURL.String(), contentType, bodyBytes, finalReqHeaders, response, time.Since(startTime), lastErr)
-			return response, lastErr
-		}
+// attemptRequest builds the http.Request for one attempt of the call, with
+// a fresh body reader and its own copy of the headers.
+func (c *Client) attemptRequest(ctx context.Context, cl *call) (*http.Request, error) {
+	var reqBody io.Reader
+	if cl.body != nil {
+		reqBody = bytes.NewReader(cl.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, cl.url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = cl.header.Clone()
+	if cl.hostOverride != "" {
+		req.Host = cl.hostOverride
+	}
+	if c.deadlineHeader != "" {
+		setDeadlineHeader(req, c.deadlineHeader)
+	}
+	return req, nil
+}
 
-		// Success
-		if result != nil && len(respBody) > 0 {
-			if err := response.JSON(result); err != nil {
-				c.logRequest(ctx, method, reqURL.String(), contentType, bodyBytes, finalReqHeaders, response, time.Since(startTime), err)
-				return response, err
-			}
+// drainAndClose discards the rest of body so the connection can be reused.
+// Errors are irrelevant because the response is being thrown away.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(io.Discard, body)
+	body.Close()
+}
+
+// roundTrip sends req through the middleware chain to the underlying
+// http.Client, recovering a panic in a middleware or the transport.
+func (c *Client) roundTrip(req *http.Request) (resp *http.Response, err error) {
+	defer c.recoverPanic(req.Context(), "middleware", &err)
+	return c.chain(req)
+}
+
+// middlewareChain wraps the HTTP client in the middlewares. newClient builds
+// it once, so attempts do not allocate a closure per middleware.
+func (c *Client) middlewareChain() RoundTripFunc {
+	transport := func(r *http.Request) (*http.Response, error) {
+		if c.classifiedClient != nil && r.Context().Value(classifiedKey{}) != nil {
+			return c.classifiedClient().Do(r)
 		}
+		return c.httpClient.Do(r)
+	}
 
-		c.logRequest(ctx, method, reqURL.String(), contentType, bodyBytes, finalReqHeaders, response, time.Since(startTime), nil)
-		return response, nil
+	// Wrap transport with middlewares (in reverse order so first added executes first)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		mw := c.middlewares[i]
+		next := transport
+		transport = func(r *http.Request) (*http.Response, error) {
+			return mw(r, next)
+		}
 	}
 
-	c.logRequest(ctx, method, reqURL.String(), contentType, bodyBytes, finalReqHeaders, response, time.Since(startTime), lastErr)
-	return response, lastErr
+	return transport
 }
 
-func (c *Client) waitForRetry(ctx context.Context, delay time.Duration) {
-	timer := time.NewTimer(delay)
-	defer timer.Stop()
+// waitForRetry sleeps for delay or until ctx ends. It reuses timer, when
+// non-nil, so a request allocates one timer however often it retries.
+func (c *Client) waitForRetry(ctx context.Context, timer *time.Timer, delay time.Duration) *time.Timer {
+	if timer == nil {
+		timer = time.NewTimer(delay)
+	} else {
+		timer.Reset(delay)
+	}
 
 	select {
 	case <-ctx.Done():
+		timer.Stop()
 	case <-timer.C:
 	}
+	return timer
 }
 
-func (c *Client) wrapError(err error, method, url string) error {
-	kind := ErrKindUnknown
+func (c *Client) wrapError(err error, method, url string) *Error {
+	kind := internalKind(err, ErrKindUnknown)
 	if errors.Is(err, context.DeadlineExceeded) {
 		kind = ErrKindTimeout
 	} else if errors.Is(err, context.Canceled) {
@@ -490,40 +997,44 @@ func (c *Client) wrapError(err error, method, url string) error {
 }
 
 // logRequest logs a completed HTTP request.
-func (c *Client) logRequest(ctx context.Context, method, url string, reqContentType string, reqBody []byte, reqHeaders http.Header, resp *Response, duration time.Duration, err error) {
-	if c.logger == nil {
-		return
-	}
-
+func (c *Client) logRequest(ctx context.Context, cl *call, reqHeaders http.Header, resp *Response, duration time.Duration, err error) {
 	level := slog.LevelInfo
-	if err != nil || (resp != nil && resp.StatusCode >= 400) {
+	if err != nil || (resp != nil && c.isErrorStatus(resp.StatusCode)) {
 		level = slog.LevelError
 	}
+	if !c.logEnabled(ctx, level) {
+		return
+	}
 
 	attrs := []slog.Attr{
-		slog.String("method", method),
-		slog.String("url", url),
+		slog.String("method", cl.method),
+		slog.String("url", cl.url),
 		slog.Int64("duration_ms", duration.Milliseconds()),
 	}
 
 	if c.thirdPartyCode != "" {
 		attrs = append(attrs, slog.String("third_party_code", c.thirdPartyCode))
 	}
+	if cl.operation != "" {
+		attrs = append(attrs, slog.String("operation", cl.operation))
+	}
+
+	// Classified bodies are never logged.
+	logBodies := !cl.classification.sensitive() && !c.logBodyConfig.Omit
+	if cl.classification.sensitive() {
+		attrs = append(attrs, slog.String("data_classification", cl.classification.String()))
+	}
 
 	// Add request headers (redacted)
 	attrs = append(attrs, slog.Any("request_headers", redactHeadersForLog(reqHeaders)))
 
 	// Add request body
-	if len(reqBody) > 0 {
-		attrs = append(attrs, slog.Any("request_body", formatBodyForLog(reqBody, reqContentType, c.logBodyConfig)))
+	if logBodies && len(cl.body) > 0 {
+		attrs = append(attrs, slog.Any("request_body", formatBodyForLog(cl.body, cl.contentType, c.logBodyConfig)))
 	}
 
 	if resp != nil {
-		attrs = append(attrs, slog.Int("status", resp.StatusCode))
-
-		// Add response body
-		respContentType := resp.Headers.Get("Content-Type")
-		attrs = append(attrs, slog.Any("response_body", formatBodyForLog(resp.Body, respContentType, c.logBodyConfig)))
+		attrs = c.appendResponseLogAttrs(attrs, resp, logBodies)
 	}
 
 	if err != nil {
@@ -533,26 +1044,30 @@ func (c *Client) logRequest(ctx context.Context, method, url string, reqContentT
 	c.logger.Log(ctx, level, "http_request", attrs...)
 }
 
-// encodeBody encodes the given body into an io.Reader and returns the content type.
-func encodeBody(body any) (io.Reader, string, error) {
-	if body == nil {
-		return nil, "", nil
-	}
-
-	switch v := body.(type) {
-	case []byte:
-		return bytes.NewReader(v), "", nil
-	case string:
-		return strings.NewReader(v), "", nil
-	case io.Reader:
-		return v, "", nil
-	case url.Values:
-		return strings.NewReader(v.Encode()), "application/x-www-form-urlencoded", nil
-	default:
-		data, err := json.Marshal(v)
-		if err != nil {
-			return nil, "", err
-		}
-		return bytes.NewReader(data), "application/json", nil
+// appendResponseLogAttrs appends the attributes describing resp.
+func (c *Client) appendResponseLogAttrs(attrs []slog.Attr, resp *Response, logBodies bool) []slog.Attr {
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	if resp.FromFallback {
+		attrs = append(attrs, slog.Bool("from_fallback", true))
+	}
+	if resp.Stale {
+		attrs = append(attrs, slog.Bool("stale", true))
+	}
+	if resp.FromCache {
+		attrs = append(attrs, slog.Bool("from_cache", true))
+	}
+
+	// Add response body
+	if logBodies {
+		respContentType := resp.Headers.Get("Content-Type")
+		attrs = append(attrs, slog.Any("response_body", formatBodyForLog(resp.Body, respContentType, c.logBodyConfig)))
+	}
+
+	if resp.CompressedSize > 0 {
+		attrs = append(attrs,
+			slog.Int("response_bytes", len(resp.Body)),
+			slog.Int("response_compressed_bytes", resp.CompressedSize),
+		)
 	}
+	return attrs
 }
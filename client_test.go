@@ -1,7 +1,11 @@
 package httpclient
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -231,3 +235,92 @@ func TestWithDefaultContentType(t *testing.T) {
 		assert.Contains(t, err.Error(), "content type cannot be empty")
 	})
 }
+
+func TestThirdPartyCodeTagging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := New(
+		WithBaseURL(server.URL),
+		WithLoggerDisabled(),
+		WithThirdPartyCode("acme"),
+		WithEvents(8),
+		WithLatencyStats(time.Minute),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/orders", nil)
+
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, "acme", clientErr.ThirdParty)
+	assert.Equal(t, "acme", client.Stats().ThirdParty)
+	events := drainEvents(client.Events())
+	require.NotEmpty(t, events)
+	for _, e := range events {
+		assert.Equal(t, "acme", e.ThirdParty, e.Kind.String())
+	}
+}
+
+// staticTransport answers every request in memory, so benchmarks and
+// allocation tests measure the client rather than the network.
+type staticTransport struct{}
+
+func (staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+		Request:    req,
+	}, nil
+}
+
+func newStaticClient(tb testing.TB, opts ...ClientOption) *Client {
+	tb.Helper()
+	opts = append([]ClientOption{
+		WithBaseURL("http://api.example.com"),
+		WithLoggerDisabled(),
+		WithHTTPClient(&http.Client{Transport: staticTransport{}}),
+	}, opts...)
+	client, err := New(opts...)
+	require.NoError(tb, err)
+	return client
+}
+
+func BenchmarkClientGet(b *testing.B) {
+	client := newStaticClient(b,
+		WithHeader("Authorization", "Bearer token"),
+		WithMiddleware(func(req *http.Request, next RoundTripFunc) (*http.Response, error) { return next(req) }),
+	)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = client.Get(ctx, "/users/42", nil, WithQuery("expand", "orders"))
+	}
+}
+
+func TestRequestAllocations(t *testing.T) {
+	client := newStaticClient(t, WithMiddleware(func(req *http.Request, next RoundTripFunc) (*http.Response, error) { return next(req) }))
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(200, func() {
+		_, _ = client.Get(ctx, "/users/42", nil, WithQuery("expand", "orders"))
+	})
+	// The bare request through the same transport is the baseline, so the
+	// bound follows what the toolchain allocates in net/http. It leaves room
+	// for the allocation the race detector adds.
+	httpClient := &http.Client{Transport: staticTransport{}}
+	baseline := testing.AllocsPerRun(200, func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.example.com/users/42?expand=orders", nil)
+		resp, _ := httpClient.Do(req)
+		_, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	})
+	// A regression here usually means per-attempt work started allocating again.
+	t.Logf("%.0f allocations, %.0f for the bare request", allocs, baseline)
+	assert.LessOrEqual(t, allocs-baseline, float64(32))
+}
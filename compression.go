@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultAcceptEncoding lists the content codings the client can decode
+// without a WithContentDecoder.
+const defaultAcceptEncoding = "gzip, deflate"
+
+// ContentDecoder returns a reader decoding r, a response body in one
+// content coding, e.g. a brotli reader for "br".
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// WithCompression negotiates gzip and deflate response compression
+// explicitly, plus the codings added with WithContentDecoder. Compressed
+// responses are decoded by the client, which records the size on the wire
+// in Response.CompressedSize, in the request log and, before and after
+// decoding, in Stats.
+func WithCompression() ClientOption {
+	return func(c *Client) error {
+		c.compression = true
+		return nil
+	}
+}
+
+// WithContentDecoder adds a content coding, such as "br", to those
+// negotiated by WithCompression, decoding it with decoder. This keeps the
+// client free of third-party codecs:
+//
+//	httpclient.WithContentDecoder("br", func(r io.Reader) (io.ReadCloser, error) {
+//		return io.NopCloser(brotli.NewReader(r)), nil
+//	})
+func WithContentDecoder(coding string, decoder ContentDecoder) ClientOption {
+	return func(c *Client) error {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			return errors.New("content coding cannot be empty")
+		}
+		if decoder == nil {
+			return errors.New("content decoder cannot be nil")
+		}
+		if _, ok := c.contentDecoder(coding); ok {
+			return fmt.Errorf("content coding %q is already decoded", coding)
+		}
+		if c.contentDecoders == nil {
+			c.contentDecoders = make(map[string]ContentDecoder)
+		}
+		c.contentDecoders[coding] = decoder
+		c.acceptEncoding += ", " + coding
+		return nil
+	}
+}
+
+// WithoutDecompression returns the response body exactly as received, still
+// encoded and with its Content-Encoding header, for pass-through proxies.
+func WithoutDecompression() RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.rawBody = true
+	}
+}
+
+// contentDecoder returns the decoder for a lowercase content coding.
+func (c *Client) contentDecoder(coding string) (ContentDecoder, bool) {
+	switch coding {
+	case "gzip", "x-gzip":
+		return func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }, true
+	case "deflate":
+		return zlib.NewReader, true
+	}
+	decoder, ok := c.contentDecoders[coding]
+	return decoder, ok
+}
+
+// decompressBody decodes body according to the Content-Encoding header.
+// It returns the decoded body and the encoded size, or the body unchanged and
+// 0 when the response was not compressed with a supported coding.
+func (c *Client) decompressBody(header http.Header, body []byte) ([]byte, int, error) {
+	return c.decodeBody(header, body, io.ReadAll)
+}
+
+// decodeBody is decompressBody reading the decoded body with readAll.
+func (c *Client) decodeBody(header http.Header, body []byte, readAll func(io.Reader) ([]byte, error)) ([]byte, int, error) {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	if len(body) == 0 {
+		return body, 0, nil
+	}
+	decoder, ok := c.contentDecoder(encoding)
+	if !ok {
+		return body, 0, nil
+	}
+
+	reader, err := decoder(bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+	defer reader.Close()
+
+	decoded, err := readAll(reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return decoded, len(body), nil
+}
+
+// decompressReader wraps body with a decoder for the Content-Encoding
+// header, for responses that are streamed rather than buffered.
+func (c *Client) decompressReader(header http.Header, body io.Reader) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	decoder, ok := c.contentDecoder(encoding)
+	if !ok {
+		return body, nil
+	}
+
+	reader, err := decoder(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return reader, nil
+}
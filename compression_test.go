@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func newGzipServer(t *testing.T, payload []byte) (*httptest.Server, *string) {
+	t.Helper()
+	var acceptEncoding string
+	compressed := gzipBytes(t, payload)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(compressed)
+	}))
+	return server, &acceptEncoding
+}
+
+func TestWithCompression(t *testing.T) {
+	payload := []byte(`{"data":"` + strings.Repeat("a", 2048) + `"}`)
+
+	t.Run("negotiates and decodes gzip", func(t *testing.T) {
+		server, acceptEncoding := newGzipServer(t, payload)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithCompression(),
+		)
+		require.NoError(t, err)
+
+		var result map[string]string
+		resp, err := client.Get(context.Background(), "/test", &result)
+
+		require.NoError(t, err)
+		assert.Equal(t, "gzip, deflate", *acceptEncoding)
+		assert.Equal(t, payload, resp.Body)
+		assert.Less(t, resp.CompressedSize, len(payload))
+		assert.Greater(t, resp.CompressedSize, 0)
+		assert.Empty(t, resp.Headers.Get("Content-Encoding"))
+		assert.Len(t, result["data"], 2048)
+	})
+
+	t.Run("logs compressed and uncompressed sizes", func(t *testing.T) {
+		server, _ := newGzipServer(t, payload)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithCompression(),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		entry := logger.LastEntry()
+		assert.Equal(t, int64(len(payload)), entry.Attrs["response_bytes"])
+		assert.Equal(t, int64(resp.CompressedSize), entry.Attrs["response_compressed_bytes"])
+	})
+
+	t.Run("passes body through when decompression is disabled per request", func(t *testing.T) {
+		server, acceptEncoding := newGzipServer(t, payload)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithCompression(),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/test", nil, WithoutDecompression())
+
+		require.NoError(t, err)
+		assert.Equal(t, "gzip, deflate", *acceptEncoding)
+		assert.Equal(t, "gzip", resp.Headers.Get("Content-Encoding"))
+		assert.Equal(t, gzipBytes(t, payload)[:2], resp.Body[:2])
+		assert.Zero(t, resp.CompressedSize)
+	})
+
+	t.Run("records compressed and decompressed bytes in stats", func(t *testing.T) {
+		server, _ := newGzipServer(t, payload)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithCompression(),
+			WithLatencyStats(time.Minute),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		stats := client.Stats().Endpoints["GET /test"]
+		assert.Equal(t, uint64(resp.CompressedSize), stats.CompressedBytes)
+		assert.Equal(t, uint64(len(payload)), stats.DecompressedBytes)
+	})
+
+	t.Run("returns error for corrupt gzip body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write([]byte("not gzip"))
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithCompression(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to decompress gzip response body")
+	})
+}
+
+func TestDecompressBody(t *testing.T) {
+	t.Run("decodes deflate", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, err := w.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		header := http.Header{"Content-Encoding": []string{"deflate"}}
+		body, size, err := (&Client{}).decompressBody(header, buf.Bytes())
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(body))
+		assert.Equal(t, buf.Len(), size)
+	})
+
+	t.Run("leaves unknown encodings untouched", func(t *testing.T) {
+		header := http.Header{"Content-Encoding": []string{"br"}}
+		body, size, err := (&Client{}).decompressBody(header, []byte("raw"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "raw", string(body))
+		assert.Zero(t, size)
+		assert.Equal(t, "br", header.Get("Content-Encoding"))
+	})
+}
+
+func TestWithContentDecoder(t *testing.T) {
+	upper := func(r io.Reader) (io.ReadCloser, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(bytes.ToUpper(data))), nil
+	}
+
+	t.Run("negotiates and decodes the added coding", func(t *testing.T) {
+		var acceptEncoding string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+			w.Header().Set("Content-Encoding", "x-upper")
+			_, _ = w.Write([]byte("hello"))
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithCompression(),
+			WithContentDecoder(" X-Upper ", upper),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "gzip, deflate, x-upper", acceptEncoding)
+		assert.Equal(t, "HELLO", string(resp.Body))
+		assert.Equal(t, 5, resp.CompressedSize)
+		assert.Empty(t, resp.Headers.Get("Content-Encoding"))
+	})
+
+	t.Run("rejects invalid decoders", func(t *testing.T) {
+		_, err := New(WithContentDecoder("", upper))
+		assert.ErrorContains(t, err, "content coding cannot be empty")
+
+		_, err = New(WithContentDecoder("br", nil))
+		assert.ErrorContains(t, err, "content decoder cannot be nil")
+
+		_, err = New(WithContentDecoder("gzip", upper))
+		assert.ErrorContains(t, err, `content coding "gzip" is already decoded`)
+	})
+}
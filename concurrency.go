@@ -0,0 +1,272 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveLimiterConfig configures an AIMD adaptive concurrency limiter.
+type AdaptiveLimiterConfig struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+
+	// LatencyThreshold treats slower requests as overload signals.
+	// Zero disables latency-based decreases.
+	LatencyThreshold time.Duration
+
+	// BackoffRatio multiplies the limit on overload (default 0.9).
+	BackoffRatio float64
+}
+
+// DefaultAdaptiveLimiterConfig returns a limiter configuration with sensible defaults.
+func DefaultAdaptiveLimiterConfig() AdaptiveLimiterConfig {
+	return AdaptiveLimiterConfig{
+		InitialLimit: 20,
+		MinLimit:     1,
+		MaxLimit:     200,
+		BackoffRatio: 0.9,
+	}
+}
+
+// AdaptiveLimiter caps in-flight requests with a limit that adapts to the
+// upstream using additive increase, multiplicative decrease (AIMD): each
+// successful request raises the limit by 1/limit, and each overload signal
+// (network error, timeout, 429, 502, 503, 504 or a slow response) multiplies
+// it by BackoffRatio. Waiting requests get freed slots in order of the
+// priority in their context (see WithContextPriority). It is safe for
+// concurrent use across goroutines.
+type AdaptiveLimiter struct {
+	mu       sync.Mutex
+	config   AdaptiveLimiterConfig
+	limit    float64
+	inFlight int
+	queue    waitQueue
+}
+
+// NewAdaptiveLimiter creates an adaptive concurrency limiter.
+func NewAdaptiveLimiter(config AdaptiveLimiterConfig) (*AdaptiveLimiter, error) {
+	if config.MinLimit <= 0 {
+		return nil, fmt.Errorf("minimum limit %d must be positive", config.MinLimit)
+	}
+	if config.MaxLimit < config.MinLimit {
+		return nil, fmt.Errorf("maximum limit %d is below minimum limit %d", config.MaxLimit, config.MinLimit)
+	}
+	if config.InitialLimit < config.MinLimit || config.InitialLimit > config.MaxLimit {
+		return nil, fmt.Errorf("initial limit %d must be between %d and %d", config.InitialLimit, config.MinLimit, config.MaxLimit)
+	}
+	if config.BackoffRatio == 0 {
+		config.BackoffRatio = 0.9
+	}
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		return nil, fmt.Errorf("backoff ratio %v must be between 0 and 1 exclusive", config.BackoffRatio)
+	}
+
+	return &AdaptiveLimiter{
+		config: config,
+		limit:  float64(config.InitialLimit),
+	}, nil
+}
+
+// Acquire blocks until a slot is free or ctx is done. The returned function
+// must be called once the request completes, reporting its latency and
+// whether it signalled overload.
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) (func(latency time.Duration, overloaded bool), error) {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) && l.queue.len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return l.release, nil
+	}
+	w := l.queue.push(GetPriority(ctx))
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.release, nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.queue.remove(w) {
+		// Granted while giving up; pass the slot on.
+		l.inFlight--
+		l.dispatch()
+	}
+	return nil, ctx.Err()
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, overloaded bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if overloaded || (l.config.LatencyThreshold > 0 && latency > l.config.LatencyThreshold) {
+		l.limit *= l.config.BackoffRatio
+	} else {
+		l.limit += 1 / l.limit
+	}
+
+	if l.limit < float64(l.config.MinLimit) {
+		l.limit = float64(l.config.MinLimit)
+	}
+	if l.limit > float64(l.config.MaxLimit) {
+		l.limit = float64(l.config.MaxLimit)
+	}
+	l.dispatch()
+}
+
+// dispatch grants free slots to queued waiters. l.mu must be held.
+func (l *AdaptiveLimiter) dispatch() {
+	for l.inFlight < int(l.limit) && l.queue.len() > 0 {
+		l.inFlight++
+		close(l.queue.pop().ready)
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of requests currently holding a slot.
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// WithAdaptiveConcurrency limits in-flight requests with an adaptive limiter.
+// Each attempt, including retries, holds one slot.
+func WithAdaptiveConcurrency(limiter *AdaptiveLimiter) ClientOption {
+	return func(c *Client) error {
+		if limiter == nil {
+			return errors.New("adaptive limiter cannot be nil")
+		}
+		c.adaptiveLimiter = limiter
+		return nil
+	}
+}
+
+// ErrOverload is returned when WithMaxConcurrentRequests has no free slot
+// for a request.
+var ErrOverload = errors.New("too many concurrent requests")
+
+// bulkhead caps the client's in-flight attempts with a fixed number of slots.
+type bulkhead struct {
+	mu       sync.Mutex
+	max      int
+	inFlight int
+	queue    waitQueue
+	maxWait  time.Duration
+}
+
+// WithMaxConcurrentRequests caps the client's in-flight requests at n, to
+// protect the upstream and the process's file descriptors. Each attempt,
+// including retries, holds one slot. A request finding every slot taken
+// waits up to maxWait for one, or fails at once when maxWait is 0, with an
+// ErrKindOverload error wrapping ErrOverload. Waiting requests get freed
+// slots in order of priority (see WithPriority). Overload errors are not
+// retried. It can be combined with WithAdaptiveConcurrency, whose limit then
+// applies within the cap.
+func WithMaxConcurrentRequests(n int, maxWait time.Duration) ClientOption {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("max concurrent requests %d must be positive", n)
+		}
+		if maxWait < 0 {
+			return errors.New("max concurrent requests wait cannot be negative")
+		}
+		c.bulkhead = &bulkhead{max: n, maxWait: maxWait}
+		return nil
+	}
+}
+
+// acquire takes a slot, waiting up to maxWait for one.
+func (b *bulkhead) acquire(ctx context.Context) error {
+	b.mu.Lock()
+	if b.inFlight < b.max && b.queue.len() == 0 {
+		b.inFlight++
+		b.mu.Unlock()
+		return nil
+	}
+	if b.maxWait == 0 {
+		b.mu.Unlock()
+		return ErrOverload
+	}
+	w := b.queue.push(GetPriority(ctx))
+	b.mu.Unlock()
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+		err = fmt.Errorf("%w: no slot freed within %v", ErrOverload, b.maxWait)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.queue.remove(w) {
+		// Granted while giving up; pass the slot on.
+		b.inFlight--
+		b.dispatch()
+	}
+	return err
+}
+
+func (b *bulkhead) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+	b.dispatch()
+}
+
+// dispatch grants free slots to queued waiters. b.mu must be held.
+func (b *bulkhead) dispatch() {
+	for b.inFlight < b.max && b.queue.len() > 0 {
+		b.inFlight++
+		close(b.queue.pop().ready)
+	}
+}
+
+// bulkheadError wraps a failure to get a slot for the call.
+func (c *Client) bulkheadError(cl *call, err error) error {
+	if !errors.Is(err, ErrOverload) {
+		return c.wrapError(err, cl.method, cl.url)
+	}
+	return &Error{Kind: ErrKindOverload, Method: cl.method, URL: cl.url, Err: err}
+}
+
+// isOverloadSignal reports whether an attempt indicates upstream overload:
+// an overload status, or a network failure or timeout of the transport.
+// Failures of the caller's own, e.g. a canceled ctx or a bad URL, are not.
+func isOverloadSignal(ctx context.Context, res attemptResult) bool {
+	if res.response == nil {
+		if res.err == nil || ctx.Err() != nil {
+			return false
+		}
+		var clientErr *Error
+		return errors.As(res.err, &clientErr) && (clientErr.Kind == ErrKindNetwork || clientErr.Kind == ErrKindTimeout)
+	}
+
+	switch res.response.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
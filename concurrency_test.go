@@ -0,0 +1,275 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdaptiveLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AdaptiveLimiterConfig
+		wantErr string
+	}{
+		{"zero minimum", AdaptiveLimiterConfig{InitialLimit: 1, MinLimit: 0, MaxLimit: 1}, "minimum limit 0 must be positive"},
+		{"max below min", AdaptiveLimiterConfig{InitialLimit: 2, MinLimit: 2, MaxLimit: 1}, "maximum limit 1 is below minimum limit 2"},
+		{"initial out of range", AdaptiveLimiterConfig{InitialLimit: 10, MinLimit: 1, MaxLimit: 5}, "initial limit 10 must be between 1 and 5"},
+		{"invalid backoff", AdaptiveLimiterConfig{InitialLimit: 1, MinLimit: 1, MaxLimit: 5, BackoffRatio: 1.5}, "backoff ratio 1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAdaptiveLimiter(tt.config)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("accepts defaults", func(t *testing.T) {
+		limiter, err := NewAdaptiveLimiter(DefaultAdaptiveLimiterConfig())
+
+		require.NoError(t, err)
+		assert.Equal(t, 20, limiter.Limit())
+	})
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	newLimiter := func(t *testing.T, initial int) *AdaptiveLimiter {
+		t.Helper()
+		limiter, err := NewAdaptiveLimiter(AdaptiveLimiterConfig{
+			InitialLimit:     initial,
+			MinLimit:         1,
+			MaxLimit:         10,
+			LatencyThreshold: 100 * time.Millisecond,
+			BackoffRatio:     0.5,
+		})
+		require.NoError(t, err)
+		return limiter
+	}
+
+	t.Run("increases additively on success", func(t *testing.T) {
+		limiter := newLimiter(t, 2)
+
+		for i := 0; i < 4; i++ {
+			release, err := limiter.Acquire(context.Background())
+			require.NoError(t, err)
+			release(time.Millisecond, false)
+		}
+
+		assert.Equal(t, 3, limiter.Limit())
+	})
+
+	t.Run("decreases multiplicatively on overload and slow responses", func(t *testing.T) {
+		limiter := newLimiter(t, 8)
+
+		release, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		release(time.Millisecond, true)
+		assert.Equal(t, 4, limiter.Limit())
+
+		release, err = limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		release(time.Second, false)
+		assert.Equal(t, 2, limiter.Limit())
+	})
+
+	t.Run("blocks at the limit until a slot is released", func(t *testing.T) {
+		limiter := newLimiter(t, 1)
+
+		release, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, limiter.InFlight())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Acquire(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			release(time.Millisecond, false)
+		}()
+		release2, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		release2(time.Millisecond, false)
+		assert.Equal(t, 0, limiter.InFlight())
+	})
+}
+
+func TestWithAdaptiveConcurrency(t *testing.T) {
+	t.Run("caps in-flight requests and backs off on 503", func(t *testing.T) {
+		var current, peak int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&current, 1)
+			defer atomic.AddInt32(&current, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		limiter, err := NewAdaptiveLimiter(AdaptiveLimiterConfig{InitialLimit: 4, MinLimit: 1, MaxLimit: 10})
+		require.NoError(t, err)
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAdaptiveConcurrency(limiter),
+		)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 12; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = client.Get(context.Background(), "/test", nil)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4))
+		assert.Equal(t, 1, limiter.Limit())
+	})
+
+	t.Run("returns error for nil limiter", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAdaptiveConcurrency(nil))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "adaptive limiter cannot be nil")
+	})
+}
+
+func TestIsOverloadSignal(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		res  attemptResult
+		want bool
+	}{
+		{name: "success", ctx: context.Background(), res: attemptResult{response: &Response{StatusCode: http.StatusOK}}},
+		{name: "503", ctx: context.Background(), res: attemptResult{response: &Response{StatusCode: http.StatusServiceUnavailable}}, want: true},
+		{name: "network error", ctx: context.Background(), res: attemptResult{err: &Error{Kind: ErrKindNetwork}}, want: true},
+		{name: "timeout", ctx: context.Background(), res: attemptResult{err: &Error{Kind: ErrKindTimeout}}, want: true},
+		{name: "caller canceled", ctx: canceled, res: attemptResult{err: &Error{Kind: ErrKindNetwork, Err: context.Canceled}}},
+		{name: "auth failure", ctx: context.Background(), res: attemptResult{err: &Error{Kind: ErrKindUnknown}}},
+		{name: "plain error", ctx: context.Background(), res: attemptResult{err: errors.New("bad URL")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isOverloadSignal(tt.ctx, tt.res))
+		})
+	}
+}
+
+func TestWithMaxConcurrentRequests(t *testing.T) {
+	// newBlockingServer holds requests until unblock is closed.
+	newBlockingServer := func(started chan<- struct{}, unblock <-chan struct{}) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-unblock
+		}))
+	}
+
+	t.Run("rejects requests over the cap", func(t *testing.T) {
+		started, unblock := make(chan struct{}, 2), make(chan struct{})
+		server := newBlockingServer(started, unblock)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithThirdPartyCode("bulkhead-test"), WithMaxConcurrentRequests(2, 0))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = client.Get(context.Background(), "/slow", nil)
+			}()
+			<-started
+		}
+
+		_, err = client.Get(context.Background(), "/slow", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindOverload, clientErr.Kind)
+		assert.ErrorIs(t, err, ErrOverload)
+		assert.False(t, clientErr.IsRetryable())
+		assert.Equal(t, "bulkhead-test", clientErr.ThirdParty)
+
+		close(unblock)
+		wg.Wait()
+		_, err = client.Get(context.Background(), "/fast", nil)
+		assert.NoError(t, err, "slots are released")
+	})
+
+	t.Run("queues up to max wait", func(t *testing.T) {
+		started, unblock := make(chan struct{}, 2), make(chan struct{})
+		server := newBlockingServer(started, unblock)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxConcurrentRequests(1, time.Second))
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Get(context.Background(), "/slow", nil)
+			done <- err
+		}()
+		<-started
+
+		queued := make(chan error, 1)
+		go func() {
+			_, err := client.Get(context.Background(), "/queued", nil)
+			queued <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+		close(unblock)
+
+		require.NoError(t, <-done)
+		require.NoError(t, <-queued)
+	})
+
+	t.Run("fails after max wait", func(t *testing.T) {
+		started, unblock := make(chan struct{}, 1), make(chan struct{})
+		server := newBlockingServer(started, unblock)
+		defer server.Close()
+		defer close(unblock)
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxConcurrentRequests(1, 20*time.Millisecond))
+		require.NoError(t, err)
+
+		go func() { _, _ = client.Get(context.Background(), "/slow", nil) }()
+		<-started
+
+		start := time.Now()
+		_, err = client.Get(context.Background(), "/queued", nil)
+		assert.ErrorIs(t, err, ErrOverload)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("validates options", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithMaxConcurrentRequests(0, 0))
+		assert.Error(t, err)
+		_, err = New(WithBaseURL("https://api.example.com"), WithMaxConcurrentRequests(1, -time.Second))
+		assert.Error(t, err)
+	})
+}
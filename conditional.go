@@ -0,0 +1,19 @@
+package httpclient
+
+import "errors"
+
+// WithConditionalGet remembers the ETag and Last-Modified of GET responses
+// in store and sends them as If-None-Match and If-Modified-Since on the
+// next request for the same URL. A 304 Not Modified answer is replaced by
+// the remembered response, marked with Response.FromCache, so callers see
+// the full body either way. Unlike WithHTTPCache, every request reaches
+// the server; freshness is never assumed. It replaces WithHTTPCache.
+func WithConditionalGet(store CacheStore) ClientOption {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("cache store cannot be nil")
+		}
+		c.httpCache = &httpCache{store: store, alwaysRevalidate: true}
+		return nil
+	}
+}
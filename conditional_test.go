@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConditionalGet(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(t *testing.T, server *httptest.Server) *Client {
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithConditionalGet(store))
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("returns cached body on 304", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"ETag": `"v1"`, "Cache-Control": "max-age=60"})
+		defer server.Close()
+		client := newClient(t, server)
+
+		first, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		assert.False(t, first.FromCache)
+
+		var result struct{ ID int }
+		second, err := client.Get(ctx, "/users/1", &result)
+		require.NoError(t, err)
+		assert.True(t, second.FromCache)
+		assert.Equal(t, http.StatusOK, second.StatusCode)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, int32(2), calls.Load(), "fresh copies are still revalidated")
+	})
+
+	t.Run("sends If-Modified-Since", func(t *testing.T) {
+		modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+		var got []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Header.Get("If-Modified-Since"))
+			w.Header().Set("Last-Modified", modified)
+			if r.Header.Get("If-Modified-Since") == modified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":1}`))
+		}))
+		defer server.Close()
+		client := newClient(t, server)
+
+		_, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"", modified}, got)
+		assert.JSONEq(t, `{"id":1}`, resp.String())
+	})
+
+	t.Run("does not remember responses without validators", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60"})
+		defer server.Close()
+		client := newClient(t, server)
+
+		for range 2 {
+			resp, err := client.Get(ctx, "/users/1", nil)
+			require.NoError(t, err)
+			assert.False(t, resp.FromCache)
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("rejects nil store", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithConditionalGet(nil))
+		assert.Error(t, err)
+	})
+}
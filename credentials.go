@@ -0,0 +1,234 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are secrets fetched from a CredentialsProvider.
+type Credentials struct {
+	// Secret is an API key or token, sent by APIKeyAuthFrom and
+	// BearerAuthFrom.
+	Secret string
+	// Username and Password are sent by BasicAuthFrom.
+	Username string
+	Password string
+	// ExpiresAt is when the credentials must be fetched again, e.g. the end
+	// of a lease, or zero if the store does not say.
+	ExpiresAt time.Time
+}
+
+// CredentialsProvider fetches credentials from a secrets store such as
+// Vault or AWS Secrets Manager. Auth providers built with APIKeyAuthFrom,
+// BearerAuthFrom and BasicAuthFrom consult it for every request, so keys
+// rotate without recreating the client; wrap it in CachedCredentials to
+// avoid a store round trip per request.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc is a function that implements CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials implements CredentialsProvider.
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// APIKeyAuthFrom returns an AuthProvider like APIKeyAuth that sends the
+// current Secret from provider in the named header.
+func APIKeyAuthFrom(headerName string, provider CredentialsProvider) AuthProvider {
+	return &credentialsAuth{provider: provider, apply: func(req *http.Request, creds Credentials) {
+		req.Header.Set(headerName, creds.Secret)
+	}}
+}
+
+// BearerAuthFrom returns an AuthProvider like BearerAuth that sends the
+// current Secret from provider as a Bearer token.
+func BearerAuthFrom(provider CredentialsProvider) AuthProvider {
+	return &credentialsAuth{provider: provider, apply: func(req *http.Request, creds Credentials) {
+		req.Header.Set("Authorization", "Bearer "+creds.Secret)
+	}}
+}
+
+// BasicAuthFrom returns an AuthProvider like BasicAuth that sends the
+// current Username and Password from provider.
+func BasicAuthFrom(provider CredentialsProvider) AuthProvider {
+	return &credentialsAuth{provider: provider, apply: func(req *http.Request, creds Credentials) {
+		encoded := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+		req.Header.Set("Authorization", "Basic "+encoded)
+	}}
+}
+
+type credentialsAuth struct {
+	provider CredentialsProvider
+	apply    func(req *http.Request, creds Credentials)
+}
+
+// Apply implements AuthProvider.
+func (a *credentialsAuth) Apply(req *http.Request) error {
+	creds, err := a.provider.Credentials(req.Context())
+	if err != nil {
+		return fmt.Errorf("fetching credentials: %w", err)
+	}
+	a.apply(req, creds)
+	return nil
+}
+
+// InvalidateAuth implements AuthInvalidator, so WithAuthRetry refetches
+// credentials from a CachedCredentials provider after a 401.
+func (a *credentialsAuth) InvalidateAuth(*http.Request) {
+	if cached, ok := a.provider.(*cachedCredentials); ok {
+		cached.invalidate()
+	}
+}
+
+// CachedCredentials returns a CredentialsProvider that reuses credentials
+// from provider for ttl, or until their ExpiresAt if that is sooner. If the
+// store fails once they are due, the old credentials are kept until they
+// expire. It is safe for concurrent use.
+func CachedCredentials(provider CredentialsProvider, ttl time.Duration) (CredentialsProvider, error) {
+	if provider == nil {
+		return nil, errors.New("credentials provider cannot be nil")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("credentials cache ttl must be positive")
+	}
+	return &cachedCredentials{provider: provider, ttl: ttl, now: time.Now}, nil
+}
+
+type cachedCredentials struct {
+	provider CredentialsProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	creds     Credentials
+	fetched   bool
+	refreshAt time.Time
+}
+
+// Credentials implements CredentialsProvider.
+func (c *cachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.fetched && now.Before(c.refreshAt) {
+		return c.creds, nil
+	}
+
+	creds, err := c.provider.Credentials(ctx)
+	if err != nil && c.fetched && (c.creds.ExpiresAt.IsZero() || now.Before(c.creds.ExpiresAt)) {
+		return c.creds, nil
+	}
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.creds, c.fetched = creds, true
+	c.refreshAt = now.Add(c.ttl)
+	if !creds.ExpiresAt.IsZero() && creds.ExpiresAt.Before(c.refreshAt) {
+		c.refreshAt = creds.ExpiresAt
+	}
+	return creds, nil
+}
+
+// invalidate makes the next call fetch fresh credentials.
+func (c *cachedCredentials) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshAt = time.Time{}
+}
+
+// VaultKV returns a CredentialsProvider reading the secret at path from
+// HashiCorp Vault's KV secrets engine, e.g. "secret/data/payments" for
+// version 2, through vault, a Client for the Vault address with the Vault
+// token as auth. Secret is taken from field; "username" and "password",
+// if present, fill Username and Password. Secrets are read as DataPII, so
+// vault must use https and never logs or caches the response bodies.
+func VaultKV(vault *Client, path, field string) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		var body struct {
+			Data          json.RawMessage `json:"data"`
+			LeaseDuration int             `json:"lease_duration"`
+		}
+		if _, err := vault.Get(ctx, "/v1/"+strings.TrimPrefix(path, "/"), &body, WithDataClassification(DataPII)); err != nil {
+			return Credentials{}, fmt.Errorf("reading vault secret %s: %w", path, err)
+		}
+
+		// Version 2 nests the secret in data.data next to data.metadata.
+		var values map[string]any
+		var v2 struct {
+			Data     map[string]any `json:"data"`
+			Metadata map[string]any `json:"metadata"`
+		}
+		if err := json.Unmarshal(body.Data, &v2); err == nil && v2.Metadata != nil {
+			values = v2.Data
+		} else if err := json.Unmarshal(body.Data, &values); err != nil {
+			return Credentials{}, fmt.Errorf("decoding vault secret %s: %w", path, err)
+		}
+
+		creds, err := credentialsFromFields(values, field)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("vault secret %s: %w", path, err)
+		}
+		if body.LeaseDuration > 0 {
+			creds.ExpiresAt = time.Now().Add(time.Duration(body.LeaseDuration) * time.Second)
+		}
+		return creds, nil
+	})
+}
+
+// SecretFetcher returns a secret's value by ID, e.g. a closure calling the
+// AWS SDK's GetSecretValue and returning its SecretString.
+type SecretFetcher func(ctx context.Context, secretID string) (string, error)
+
+// SecretsManager returns a CredentialsProvider reading the secret secretID
+// with fetch, for AWS Secrets Manager or a similar store, without this
+// package depending on its SDK. With an empty field the whole value is the
+// Secret; otherwise the value is a JSON object, Secret is taken from field,
+// and "username" and "password", if present, fill Username and Password.
+func SecretsManager(fetch SecretFetcher, secretID, field string) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		value, err := fetch(ctx, secretID)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("reading secret %s: %w", secretID, err)
+		}
+		if field == "" {
+			return Credentials{Secret: value}, nil
+		}
+
+		var values map[string]any
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return Credentials{}, fmt.Errorf("decoding secret %s: %w", secretID, err)
+		}
+		creds, err := credentialsFromFields(values, field)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("secret %s: %w", secretID, err)
+		}
+		return creds, nil
+	})
+}
+
+// credentialsFromFields maps the fields of a stored secret to Credentials.
+// Only string fields are used.
+func credentialsFromFields(values map[string]any, field string) (Credentials, error) {
+	var creds Credentials
+	creds.Username, _ = values["username"].(string)
+	creds.Password, _ = values["password"].(string)
+	if field == "" {
+		return creds, nil
+	}
+	secret, ok := values[field].(string)
+	if !ok {
+		return Credentials{}, fmt.Errorf("no string field %q", field)
+	}
+	creds.Secret = secret
+	return creds, nil
+}
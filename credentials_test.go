@@ -0,0 +1,257 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotatingCredentials returns a provider whose Secret is "key-<n>" for the
+// nth fetch.
+func rotatingCredentials(fetches *atomic.Int32) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		n := fetches.Add(1)
+		return Credentials{Secret: fmt.Sprintf("key-%d", n)}, nil
+	})
+}
+
+func TestCredentialsAuth(t *testing.T) {
+	t.Run("sends current API key", func(t *testing.T) {
+		var got []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Header.Get("X-API-Key"))
+		}))
+		defer server.Close()
+
+		var fetches atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(APIKeyAuthFrom("X-API-Key", rotatingCredentials(&fetches))),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/a", nil)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/b", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key-1", "key-2"}, got)
+	})
+
+	t.Run("sends bearer and basic credentials", func(t *testing.T) {
+		provider := CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			return Credentials{Secret: "token", Username: "user", Password: "pass"}, nil
+		})
+
+		token, err := applyAuth(t, BearerAuthFrom(provider))
+		require.NoError(t, err)
+		assert.Equal(t, "token", token)
+
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.invalid", nil)
+		require.NoError(t, BasicAuthFrom(provider).Apply(req))
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")), req.Header.Get("Authorization"))
+	})
+
+	t.Run("fails when the store fails", func(t *testing.T) {
+		provider := CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			return Credentials{}, errors.New("vault sealed")
+		})
+
+		_, err := applyAuth(t, BearerAuthFrom(provider))
+		require.ErrorContains(t, err, "vault sealed")
+	})
+
+	t.Run("refetches after 401 with auth retry", func(t *testing.T) {
+		var valid atomic.Value
+		valid.Store([]string{"key-2"})
+		server, seen := newKeyServer(&valid)
+		defer server.Close()
+
+		var fetches atomic.Int32
+		cache, err := CachedCredentials(rotatingCredentials(&fetches), time.Hour)
+		require.NoError(t, err)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuthFrom(cache)),
+			WithAuthRetry(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer key-1", "Bearer key-2"}, *seen)
+	})
+}
+
+func TestCachedCredentials(t *testing.T) {
+	newCache := func(provider CredentialsProvider, ttl time.Duration) (*cachedCredentials, *time.Time) {
+		t.Helper()
+		provider, err := CachedCredentials(provider, ttl)
+		require.NoError(t, err)
+		cache := provider.(*cachedCredentials)
+		now := time.Unix(1700000000, 0)
+		cache.now = func() time.Time { return now }
+		return cache, &now
+	}
+
+	t.Run("reuses credentials for ttl", func(t *testing.T) {
+		var fetches atomic.Int32
+		cache, now := newCache(rotatingCredentials(&fetches), time.Minute)
+
+		for range 3 {
+			creds, err := cache.Credentials(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "key-1", creds.Secret)
+		}
+
+		*now = now.Add(time.Minute)
+		creds, err := cache.Credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "key-2", creds.Secret)
+	})
+
+	t.Run("refetches at expiry before ttl", func(t *testing.T) {
+		var fetches atomic.Int32
+		start := time.Unix(1700000000, 0)
+		cache, now := newCache(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			fetches.Add(1)
+			return Credentials{Secret: "leased", ExpiresAt: start.Add(10 * time.Second)}, nil
+		}), time.Hour)
+
+		_, err := cache.Credentials(context.Background())
+		require.NoError(t, err)
+		*now = start.Add(10 * time.Second)
+		_, err = cache.Credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("keeps unexpired credentials while the store fails", func(t *testing.T) {
+		var fail atomic.Bool
+		start := time.Unix(1700000000, 0)
+		cache, now := newCache(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			if fail.Load() {
+				return Credentials{}, errors.New("unavailable")
+			}
+			return Credentials{Secret: "key", ExpiresAt: start.Add(time.Hour)}, nil
+		}), time.Minute)
+
+		_, err := cache.Credentials(context.Background())
+		require.NoError(t, err)
+		fail.Store(true)
+
+		*now = start.Add(time.Minute)
+		creds, err := cache.Credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "key", creds.Secret)
+
+		*now = start.Add(time.Hour)
+		_, err = cache.Credentials(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		_, err := CachedCredentials(nil, time.Minute)
+		require.Error(t, err)
+
+		var fetches atomic.Int32
+		_, err = CachedCredentials(rotatingCredentials(&fetches), 0)
+		require.ErrorContains(t, err, "ttl must be positive")
+	})
+}
+
+func TestVaultKV(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/secret/data/payments":
+			w.Write([]byte(`{"data":{"data":{"api_key":"sk-123","username":"svc","password":"pw"},"metadata":{"version":3}},"lease_duration":0}`))
+		case "/v1/kv/legacy":
+			w.Write([]byte(`{"data":{"api_key":"sk-v1"},"lease_duration":60}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	vault, err := New(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithLogger(logger),
+		WithAuth(APIKeyAuth("X-Vault-Token", "root")),
+	)
+	require.NoError(t, err)
+
+	t.Run("reads version 2 secret", func(t *testing.T) {
+		creds, err := VaultKV(vault, "secret/data/payments", "api_key").Credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, Credentials{Secret: "sk-123", Username: "svc", Password: "pw"}, creds)
+	})
+
+	t.Run("never logs secrets", func(t *testing.T) {
+		_, err := VaultKV(vault, "secret/data/payments", "api_key").Credentials(context.Background())
+		require.NoError(t, err)
+
+		entry := logger.LastEntry()
+		assert.Equal(t, DataPII.String(), entry.Attrs["data_classification"])
+		assert.NotContains(t, entry.Attrs, "response_body")
+	})
+
+	t.Run("reads version 1 secret with lease", func(t *testing.T) {
+		creds, err := VaultKV(vault, "/kv/legacy", "api_key").Credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "sk-v1", creds.Secret)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), creds.ExpiresAt, 5*time.Second)
+	})
+
+	t.Run("fails on missing field or secret", func(t *testing.T) {
+		_, err := VaultKV(vault, "secret/data/payments", "token").Credentials(context.Background())
+		require.ErrorContains(t, err, `no string field "token"`)
+
+		_, err = VaultKV(vault, "secret/data/missing", "api_key").Credentials(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestSecretsManager(t *testing.T) {
+	secrets := map[string]string{
+		"plain": "sk-plain",
+		"json":  `{"api_key":"sk-json","username":"admin","password":"hunter2"}`,
+	}
+	fetch := func(ctx context.Context, id string) (string, error) {
+		value, ok := secrets[id]
+		if !ok {
+			return "", errors.New("ResourceNotFoundException")
+		}
+		return value, nil
+	}
+
+	creds, err := SecretsManager(fetch, "plain", "").Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{Secret: "sk-plain"}, creds)
+
+	creds, err = SecretsManager(fetch, "json", "api_key").Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{Secret: "sk-json", Username: "admin", Password: "hunter2"}, creds)
+
+	_, err = SecretsManager(fetch, "plain", "api_key").Credentials(context.Background())
+	require.ErrorContains(t, err, "decoding secret plain")
+
+	_, err = SecretsManager(fetch, "missing", "").Credentials(context.Background())
+	require.ErrorContains(t, err, "ResourceNotFoundException")
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultDeadlineHeader is the header used by WithDeadlinePropagation when
+// no name is given.
+const DefaultDeadlineHeader = "X-Request-Deadline"
+
+// WithDeadlinePropagation sends the time remaining until the request
+// context's deadline, in whole milliseconds, in the named header on every
+// attempt, so cooperating upstreams can shed work that cannot finish in time.
+// An empty name uses DefaultDeadlineHeader. Requests without a deadline are
+// sent without the header.
+func WithDeadlinePropagation(header string) ClientOption {
+	return func(c *Client) error {
+		if header == "" {
+			header = DefaultDeadlineHeader
+		}
+		c.deadlineHeader = header
+		return nil
+	}
+}
+
+// setDeadlineHeader writes the remaining budget of req's context into header.
+func setDeadlineHeader(req *http.Request, header string) {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return
+	}
+
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return
+	}
+	req.Header.Set(header, strconv.FormatInt(remaining, 10))
+}
+
+// ParseDeadlineHeader parses a propagated deadline header value into the
+// remaining duration.
+func ParseDeadlineHeader(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, errors.New("deadline header value cannot be empty")
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("deadline header value %q is not an integer number of milliseconds: %w", value, err)
+	}
+	if ms <= 0 {
+		return 0, fmt.Errorf("deadline header value %d must be positive", ms)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// RequestDeadline returns the absolute deadline propagated to a server in
+// header, measured from now. Servers can pass it to context.WithDeadline.
+func RequestDeadline(r *http.Request, header string) (time.Time, bool) {
+	if r == nil {
+		return time.Time{}, false
+	}
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+
+	remaining, err := ParseDeadlineHeader(r.Header.Get(header))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Now().Add(remaining), true
+}
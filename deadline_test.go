@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeadlinePropagation(t *testing.T) {
+	t.Run("sends remaining budget in header", func(t *testing.T) {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get(DefaultDeadlineHeader)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithDeadlinePropagation(""),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err = client.Get(ctx, "/test", nil)
+		require.NoError(t, err)
+
+		ms, err := strconv.Atoi(received)
+		require.NoError(t, err)
+		assert.Greater(t, ms, 1500)
+		assert.LessOrEqual(t, ms, 2000)
+	})
+
+	t.Run("uses request timeout and custom header", func(t *testing.T) {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get("X-Budget-Ms")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithDeadlinePropagation("X-Budget-Ms"),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil, WithRequestTimeout(500*time.Millisecond))
+		require.NoError(t, err)
+
+		ms, err := strconv.Atoi(received)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, ms, 500)
+	})
+
+	t.Run("omits header without deadline", func(t *testing.T) {
+		var present bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, present = r.Header[DefaultDeadlineHeader]
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithDeadlinePropagation(DefaultDeadlineHeader),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		assert.False(t, present)
+	})
+}
+
+func TestParseDeadlineHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		wantErr  string
+	}{
+		{"valid", "1500", 1500 * time.Millisecond, ""},
+		{"empty", "", 0, "cannot be empty"},
+		{"not a number", "soon", 0, "is not an integer number of milliseconds"},
+		{"zero", "0", 0, "must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDeadlineHeader(tt.value)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	t.Run("returns absolute deadline", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(DefaultDeadlineHeader, "1000")
+
+		deadline, ok := RequestDeadline(req, "")
+
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("returns false without header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		_, ok := RequestDeadline(req, DefaultDeadlineHeader)
+
+		assert.False(t, ok)
+	})
+}
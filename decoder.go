@@ -0,0 +1,128 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Decoder decodes a response body into v.
+type Decoder interface {
+	Decode(body []byte, v any) error
+}
+
+// DecoderFunc adapts a function to a Decoder.
+type DecoderFunc func(body []byte, v any) error
+
+// Decode implements Decoder.
+func (f DecoderFunc) Decode(body []byte, v any) error {
+	return f(body, v)
+}
+
+// JSONDecoder returns a Decoder for JSON bodies using encoding/json.
+func JSONDecoder() Decoder {
+	return DecoderFunc(json.Unmarshal)
+}
+
+// XMLDecoder returns a Decoder for XML bodies using encoding/xml.
+func XMLDecoder() Decoder {
+	return DecoderFunc(xml.Unmarshal)
+}
+
+// WithDecoder decodes results of responses with the given media type, e.g.
+// "application/xml", with dec. A decoder registered for "application/xml"
+// also handles structured suffixes such as "application/soap+xml". Responses
+// without a matching decoder are decoded as JSON.
+func WithDecoder(mediaType string, dec Decoder) ClientOption {
+	return func(c *Client) error {
+		if dec == nil {
+			return errors.New("decoder cannot be nil")
+		}
+		parsed, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return fmt.Errorf("invalid decoder media type %q: %w", mediaType, err)
+		}
+		if c.decoders == nil {
+			c.decoders = make(map[string]Decoder)
+		}
+		c.decoders[parsed] = dec
+		return nil
+	}
+}
+
+// WithAllowEmptyResponse also skips decoding bodies that hold only
+// whitespace, for endpoints that sometimes answer 200 with a bare newline
+// instead of a document. The result is left unchanged.
+func WithAllowEmptyResponse() ClientOption {
+	return func(c *Client) error {
+		c.allowEmptyResponse = true
+		return nil
+	}
+}
+
+// hasResultBody reports whether response carries a body to decode. Empty
+// bodies and 204, 205 and 304 responses are never decoded.
+func (c *Client) hasResultBody(response *Response) bool {
+	switch response.StatusCode {
+	case http.StatusNoContent, http.StatusResetContent, http.StatusNotModified:
+		return false
+	}
+	if c.allowEmptyResponse {
+		return len(bytes.TrimSpace(response.Body)) > 0
+	}
+	return len(response.Body) > 0
+}
+
+// decodeResult decodes a response into result with the decoder for its
+// Content-Type, falling back to JSON.
+func (c *Client) decodeResult(response *Response, result any) error {
+	if result == nil {
+		return errors.New("target cannot be nil")
+	}
+	if dec := c.decoderFor(response.Headers.Get("Content-Type")); dec != nil {
+		return dec.Decode(response.Body, result)
+	}
+	return c.decodeJSON(response, result)
+}
+
+// decoderFor returns the decoder for contentType, or nil to decode as JSON.
+func (c *Client) decoderFor(contentType string) Decoder {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{mediaType}
+	if _, suffix, ok := strings.Cut(mediaType, "+"); ok {
+		candidates = append(candidates, "application/"+suffix)
+	}
+	for _, candidate := range candidates {
+		if dec, ok := c.decoders[candidate]; ok {
+			return dec
+		}
+	}
+	for _, candidate := range candidates {
+		if dec := c.defaultDecoder(candidate); dec != nil {
+			return dec
+		}
+	}
+	return nil
+}
+
+// defaultDecoder returns the decoder for results of mediaType when no
+// WithDecoder decoder matches, or nil to decode as JSON.
+func (c *Client) defaultDecoder(mediaType string) Decoder {
+	switch mediaType {
+	case "application/xml", "text/xml":
+		if c.soapDefaults != nil {
+			return SOAPDecoder()
+		}
+		return XMLDecoder()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(`<user><name>Ada</name></user>`))
+		case "/soap":
+			w.Header().Set("Content-Type", "application/soap+xml")
+			w.Write([]byte(`<user><name>Grace</name></user>`))
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("name\nLin"))
+		default:
+			w.Write([]byte(`{"name":"Json"}`))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithDecoder("text/csv", nil))
+		assert.Error(t, err)
+
+		_, err = New(WithBaseURL(server.URL), WithDecoder("", JSONDecoder()))
+		assert.Error(t, err)
+	})
+
+	t.Run("decodes by content type", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		for path, want := range map[string]string{"/xml": "Ada", "/soap": "Grace", "/json": "Json"} {
+			var got user
+			_, err := client.Get(ctx, path, &got)
+			require.NoError(t, err, path)
+			assert.Equal(t, want, got.Name, path)
+		}
+	})
+
+	t.Run("uses registered decoders", func(t *testing.T) {
+		csvDecoder := DecoderFunc(func(body []byte, v any) error {
+			lines := strings.Split(string(body), "\n")
+			v.(*user).Name = lines[len(lines)-1]
+			return nil
+		})
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithDecoder("text/csv", csvDecoder))
+		require.NoError(t, err)
+
+		var got user
+		_, err = client.Get(ctx, "/csv", &got)
+		require.NoError(t, err)
+		assert.Equal(t, "Lin", got.Name)
+
+		got = user{}
+		err = client.Request().Path("/xml").Then(func(*Response) *RequestBuilder {
+			return client.Request().Path("/csv")
+		}).DoInto(ctx, &got)
+		require.NoError(t, err)
+		assert.Equal(t, "Lin", got.Name)
+	})
+
+	t.Run("registered decoders override defaults", func(t *testing.T) {
+		called := false
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithDecoder("application/xml", DecoderFunc(func(body []byte, v any) error {
+			called = true
+			return json.Unmarshal([]byte(`{"name":"override"}`), v)
+		})))
+		require.NoError(t, err)
+
+		var got user
+		_, err = client.Get(ctx, "/soap", &got)
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Equal(t, "override", got.Name)
+	})
+}
+
+func TestEmptyResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/blank":
+			w.Write([]byte("\n"))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	var result struct{ Name string }
+
+	t.Run("skips decoding bodiless statuses", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		for _, path := range []string{"/no-content", "/not-modified", "/empty"} {
+			resp, err := client.Get(ctx, path, &result)
+			require.NoError(t, err, path)
+			assert.Empty(t, resp.Body, path)
+		}
+	})
+
+	t.Run("whitespace body fails by default", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/blank", &result)
+		assert.Error(t, err)
+	})
+
+	t.Run("WithAllowEmptyResponse skips whitespace body", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAllowEmptyResponse())
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/blank", &result)
+		require.NoError(t, err)
+		assert.Empty(t, result.Name)
+
+		err = client.Request().Path("/blank").DoInto(ctx, &result)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHasResultBody(t *testing.T) {
+	client := &Client{}
+	assert.False(t, client.hasResultBody(&Response{StatusCode: http.StatusResetContent, Body: []byte(`{}`)}))
+	assert.True(t, client.hasResultBody(&Response{StatusCode: http.StatusOK, Body: []byte(`{}`)}))
+	assert.True(t, client.hasResultBody(&Response{StatusCode: http.StatusOK, Body: []byte(" ")}))
+
+	client.allowEmptyResponse = true
+	assert.False(t, client.hasResultBody(&Response{StatusCode: http.StatusOK, Body: []byte(" \r\n")}))
+}
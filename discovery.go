@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultDiscoveryTTL is how long discovery documents are cached by default.
+const DefaultDiscoveryTTL = time.Hour
+
+// maxDiscoveryEntries bounds the paths whose documents one client caches.
+const maxDiscoveryEntries = 64
+
+// DiscoveryDocument holds OpenID Connect / OAuth 2.0 authorization server
+// metadata as published at a .well-known endpoint.
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	RegistrationEndpoint              string   `json:"registration_endpoint,omitempty"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported,omitempty"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported,omitempty"`
+}
+
+// discoveryCache caches copies of discovery documents by path, dropping
+// expired documents and then the one expiring first when it is full.
+// It is safe for concurrent use across goroutines.
+type discoveryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]discoveryEntry
+}
+
+type discoveryEntry struct {
+	doc       *DiscoveryDocument
+	expiresAt time.Time
+}
+
+func newDiscoveryCache(ttl time.Duration) *discoveryCache {
+	return &discoveryCache{
+		ttl:     ttl,
+		entries: make(map[string]discoveryEntry),
+	}
+}
+
+func (d *discoveryCache) get(path string) (*DiscoveryDocument, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[path]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.doc.clone(), true
+}
+
+func (d *discoveryCache) set(path string, doc *DiscoveryDocument) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := d.entries[path]; !ok && len(d.entries) >= maxDiscoveryEntries {
+		maps.DeleteFunc(d.entries, func(_ string, entry discoveryEntry) bool {
+			return now.After(entry.expiresAt)
+		})
+	}
+	if _, ok := d.entries[path]; !ok && len(d.entries) >= maxDiscoveryEntries {
+		d.evictFirstExpiring()
+	}
+	d.entries[path] = discoveryEntry{doc: doc.clone(), expiresAt: now.Add(d.ttl)}
+}
+
+// evictFirstExpiring drops the entry that expires first. Callers hold d.mu.
+func (d *discoveryCache) evictFirstExpiring() {
+	var first string
+	var firstExpiry time.Time
+	for path, entry := range d.entries {
+		if firstExpiry.IsZero() || entry.expiresAt.Before(firstExpiry) {
+			first, firstExpiry = path, entry.expiresAt
+		}
+	}
+	delete(d.entries, first)
+}
+
+// clone returns a copy of doc that shares no slices with it.
+func (doc *DiscoveryDocument) clone() *DiscoveryDocument {
+	c := *doc
+	c.ScopesSupported = slices.Clone(doc.ScopesSupported)
+	c.ResponseTypesSupported = slices.Clone(doc.ResponseTypesSupported)
+	c.GrantTypesSupported = slices.Clone(doc.GrantTypesSupported)
+	c.TokenEndpointAuthMethodsSupported = slices.Clone(doc.TokenEndpointAuthMethodsSupported)
+	c.IDTokenSigningAlgValuesSupported = slices.Clone(doc.IDTokenSigningAlgValuesSupported)
+	return &c
+}
+
+// WithDiscoveryTTL sets how long documents fetched by Discover are cached.
+func WithDiscoveryTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("discovery TTL must be positive")
+		}
+		c.discovery = newDiscoveryCache(ttl)
+		return nil
+	}
+}
+
+// Discover fetches and caches the discovery document at path, for example
+// "/.well-known/openid-configuration". Cached documents are returned without
+// a network call until the discovery TTL expires. Each call returns its own
+// copy of the document, which the caller may modify.
+func (c *Client) Discover(ctx context.Context, path string) (*DiscoveryDocument, error) {
+	if path == "" {
+		return nil, errors.New("discovery path cannot be empty")
+	}
+
+	if doc, ok := c.discovery.get(path); ok {
+		return doc, nil
+	}
+
+	var doc DiscoveryDocument
+	if _, err := c.Get(ctx, path, &doc); err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document from %s: %w", path, err)
+	}
+	if doc.Issuer == "" {
+		return nil, fmt.Errorf("discovery document at %s is missing issuer", path)
+	}
+
+	c.discovery.set(path, &doc)
+	return &doc, nil
+}
@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDiscoveryJSON = `{
+	"issuer": "https://auth.example.com",
+	"token_endpoint": "https://auth.example.com/oauth/token",
+	"jwks_uri": "https://auth.example.com/.well-known/jwks.json",
+	"grant_types_supported": ["client_credentials", "authorization_code"]
+}`
+
+func TestClient_Discover(t *testing.T) {
+	t.Run("decodes discovery metadata", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(testDiscoveryJSON))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		doc, err := client.Discover(context.Background(), "/.well-known/openid-configuration")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example.com", doc.Issuer)
+		assert.Equal(t, "https://auth.example.com/oauth/token", doc.TokenEndpoint)
+		assert.Equal(t, []string{"client_credentials", "authorization_code"}, doc.GrantTypesSupported)
+	})
+
+	t.Run("caches documents until TTL expires", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			_, _ = w.Write([]byte(testDiscoveryJSON))
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithDiscoveryTTL(50*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err = client.Discover(context.Background(), "/.well-known/openid-configuration")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = client.Discover(context.Background(), "/.well-known/openid-configuration")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("returns error for document without issuer", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"token_endpoint":"https://auth.example.com/token"}`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Discover(context.Background(), "/.well-known/openid-configuration")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing issuer")
+	})
+
+	t.Run("returns a copy of the cached document", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testDiscoveryJSON))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		first, err := client.Discover(context.Background(), "/.well-known/openid-configuration")
+		require.NoError(t, err)
+		first.Issuer = "https://evil.example.com"
+		first.GrantTypesSupported[0] = "password"
+
+		second, err := client.Discover(context.Background(), "/.well-known/openid-configuration")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example.com", second.Issuer)
+		assert.Equal(t, []string{"client_credentials", "authorization_code"}, second.GrantTypesSupported)
+	})
+
+	t.Run("bounds the cached paths", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			_, _ = w.Write([]byte(testDiscoveryJSON))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		for i := 0; i <= maxDiscoveryEntries; i++ {
+			_, err = client.Discover(context.Background(), fmt.Sprintf("/tenants/%d/.well-known/openid-configuration", i))
+			require.NoError(t, err)
+		}
+		assert.Len(t, client.discovery.entries, maxDiscoveryEntries)
+
+		_, err = client.Discover(context.Background(), fmt.Sprintf("/tenants/%d/.well-known/openid-configuration", maxDiscoveryEntries))
+		require.NoError(t, err)
+		assert.Equal(t, int32(maxDiscoveryEntries+1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("returns error for empty path", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		_, err = client.Discover(context.Background(), "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "discovery path cannot be empty")
+	})
+}
+
+func TestWithDiscoveryTTL(t *testing.T) {
+	_, err := New(
+		WithBaseURL("https://api.example.com"),
+		WithDiscoveryTTL(0),
+	)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "discovery TTL must be positive")
+}
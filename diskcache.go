@@ -0,0 +1,294 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const diskCacheIndexFile = "index.json"
+
+// DiskCacheStore is a CacheStore that keeps response bodies in
+// content-addressed files under a directory, with a JSON index mapping cache
+// keys to them, so entries survive restarts. When the total size of stored
+// bodies exceeds the configured limit, the least recently used entries are
+// evicted. It is safe for concurrent use within one process.
+type DiskCacheStore struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	size     int64
+	index    map[string]*diskCacheEntry
+	refs     map[string]int // keys per blob digest
+}
+
+// diskCacheEntry is the index record for one cache key.
+type diskCacheEntry struct {
+	Digest     string      `json:"digest"`
+	Size       int64       `json:"size"`
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Headers    http.Header `json:"headers"`
+	StoredAt   time.Time   `json:"stored_at"`
+	LastUsed   time.Time   `json:"last_used"`
+}
+
+// NewDiskCacheStore opens or creates a store in dir holding at most maxBytes
+// of response bodies.
+func NewDiskCacheStore(dir string, maxBytes int64) (*DiskCacheStore, error) {
+	if dir == "" {
+		return nil, errors.New("cache directory cannot be empty")
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("cache max bytes %d must be positive", maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	s := &DiskCacheStore{dir: dir, maxBytes: maxBytes, index: make(map[string]*diskCacheEntry), refs: make(map[string]int)}
+	data, err := os.ReadFile(filepath.Join(dir, diskCacheIndexFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("reading cache index: %w", err)
+	default:
+		if err := json.Unmarshal(data, &s.index); err != nil {
+			return nil, fmt.Errorf("parsing cache index: %w", err)
+		}
+	}
+
+	// The index is read from disk and may have been tampered with: only
+	// entries naming a digest-shaped blob that exists are kept.
+	for key, entry := range s.index {
+		if entry == nil || !validDigest(entry.Digest) {
+			delete(s.index, key)
+			continue
+		}
+		if _, err := os.Stat(s.blobPath(entry.Digest)); err != nil {
+			delete(s.index, key)
+			continue
+		}
+		s.addRef(entry)
+	}
+	return s, nil
+}
+
+// Get implements CacheStore.
+func (s *DiskCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+	body, err := os.ReadFile(s.blobPath(entry.Digest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, errors.Join(s.remove(key), s.writeIndex())
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading cached body: %w", err)
+	}
+
+	entry.LastUsed = time.Now()
+	return &CachedResponse{
+		StatusCode: entry.StatusCode,
+		Status:     entry.Status,
+		Headers:    entry.Headers.Clone(),
+		Body:       body,
+		StoredAt:   entry.StoredAt,
+	}, true, nil
+}
+
+// Set implements CacheStore. Bodies larger than the store limit are not cached.
+func (s *DiskCacheStore) Set(ctx context.Context, key string, resp *CachedResponse) error {
+	if resp == nil {
+		return errors.New("cache entry cannot be nil")
+	}
+	size := int64(len(resp.Body))
+	if size > s.maxBytes {
+		return nil
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	digest := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop the old entry first: removing it after writing would delete the
+	// blob just written when the body is unchanged.
+	if err := s.remove(key); err != nil {
+		return err
+	}
+	if err := s.writeBlob(digest, resp.Body); err != nil {
+		return err
+	}
+	now := time.Now()
+	entry := &diskCacheEntry{
+		Digest:     digest,
+		Size:       size,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Headers.Clone(),
+		StoredAt:   resp.StoredAt,
+		LastUsed:   now,
+	}
+	s.index[key] = entry
+	s.addRef(entry)
+	return errors.Join(s.evict(key), s.writeIndex())
+}
+
+// Delete implements CacheStore.
+func (s *DiskCacheStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.index[key]; !ok {
+		return nil
+	}
+	return errors.Join(s.remove(key), s.writeIndex())
+}
+
+// Size returns the total size in bytes of the cached bodies, counting a
+// body shared by several keys once.
+func (s *DiskCacheStore) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// blobPath returns the file holding the blob digest. A digest that is not
+// a hex SHA-256, e.g. from a tampered index, would escape the directory and
+// is an internal error.
+func (s *DiskCacheStore) blobPath(digest string) string {
+	if !validDigest(digest) {
+		panic(fmt.Sprintf("disk cache: invalid blob digest %q", digest))
+	}
+	return filepath.Join(s.dir, digest[:2], digest)
+}
+
+// validDigest reports whether digest is 64 lowercase hex characters, the
+// form of the SHA-256 digests naming blobs.
+func validDigest(digest string) bool {
+	if len(digest) != sha256.Size*2 {
+		return false
+	}
+	for _, r := range digest {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBlob stores body under its digest unless an identical blob exists.
+func (s *DiskCacheStore) writeBlob(digest string, body []byte) error {
+	path := s.blobPath(digest)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := writeFileAtomic(path, body); err != nil {
+		return fmt.Errorf("writing cached body: %w", err)
+	}
+	return nil
+}
+
+// addRef counts entry as a user of its blob, adding the blob to the store
+// size when it is the first. The caller must hold s.mu.
+func (s *DiskCacheStore) addRef(entry *diskCacheEntry) {
+	if s.refs[entry.Digest] == 0 {
+		s.size += entry.Size
+	}
+	s.refs[entry.Digest]++
+}
+
+// remove drops key from the index and deletes its blob if no other key
+// references it. The caller must hold s.mu.
+func (s *DiskCacheStore) remove(key string) error {
+	entry, ok := s.index[key]
+	if !ok {
+		return nil
+	}
+	delete(s.index, key)
+	if s.refs[entry.Digest]--; s.refs[entry.Digest] > 0 {
+		return nil
+	}
+	delete(s.refs, entry.Digest)
+	s.size -= entry.Size
+	if err := os.Remove(s.blobPath(entry.Digest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing cached body: %w", err)
+	}
+	return nil
+}
+
+// evict removes least recently used entries other than keep until the store
+// fits its limit. The caller must hold s.mu.
+func (s *DiskCacheStore) evict(keep string) error {
+	if s.size <= s.maxBytes {
+		return nil
+	}
+	keys := make([]string, 0, len(s.index))
+	for key := range s.index {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return s.index[a].LastUsed.Compare(s.index[b].LastUsed)
+	})
+
+	var errs []error
+	for _, key := range keys {
+		if s.size <= s.maxBytes {
+			break
+		}
+		if err := s.remove(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeIndex persists the index. The caller must hold s.mu.
+func (s *DiskCacheStore) writeIndex() error {
+	data, err := json.Marshal(s.index)
+	if err != nil {
+		return fmt.Errorf("encoding cache index: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, diskCacheIndexFile), data); err != nil {
+		return fmt.Errorf("writing cache index: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
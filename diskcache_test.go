@@ -0,0 +1,170 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("survives reopening", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+
+		storedAt := time.Now().Truncate(time.Second)
+		require.NoError(t, store.Set(ctx, "GET /rates", &CachedResponse{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Headers:    http.Header{"Content-Type": {"application/json"}},
+			Body:       []byte(`{"rates":[1,2]}`),
+			StoredAt:   storedAt,
+		}))
+
+		reopened, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+		entry, ok, err := reopened.Get(ctx, "GET /rates")
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, `{"rates":[1,2]}`, string(entry.Body))
+		assert.Equal(t, "application/json", entry.Headers.Get("Content-Type"))
+		assert.True(t, storedAt.Equal(entry.StoredAt))
+		assert.Equal(t, int64(15), reopened.Size())
+	})
+
+	t.Run("shares identical bodies", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+
+		body := []byte("same")
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200, Body: body}))
+		require.NoError(t, store.Set(ctx, "b", &CachedResponse{StatusCode: 200, Body: body}))
+		assert.Equal(t, int64(4), store.Size())
+		require.NoError(t, store.Delete(ctx, "a"))
+		assert.Equal(t, int64(4), store.Size())
+
+		entry, ok, err := store.Get(ctx, "b")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "same", string(entry.Body))
+	})
+
+	t.Run("keeps an unchanged body when stored again", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+
+		for range 2 {
+			require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200, Body: []byte("same")}))
+		}
+
+		entry, ok, err := store.Get(ctx, "a")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "same", string(entry.Body))
+		assert.Equal(t, int64(4), store.Size())
+
+		reopened, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), reopened.Size())
+	})
+
+	t.Run("evicts least recently used entries by size", func(t *testing.T) {
+		store, err := NewDiskCacheStore(t.TempDir(), 10)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200, Body: []byte("aaaa")}))
+		time.Sleep(time.Millisecond)
+		require.NoError(t, store.Set(ctx, "b", &CachedResponse{StatusCode: 200, Body: []byte("bbbb")}))
+		time.Sleep(time.Millisecond)
+		_, _, err = store.Get(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, store.Set(ctx, "c", &CachedResponse{StatusCode: 200, Body: []byte("cccc")}))
+
+		_, ok, _ := store.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok, _ = store.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, int64(8), store.Size())
+	})
+
+	t.Run("skips bodies larger than limit", func(t *testing.T) {
+		store, err := NewDiskCacheStore(t.TempDir(), 2)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200, Body: []byte("large")}))
+
+		_, ok, _ := store.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("drops entries whose body file is missing", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewDiskCacheStore(dir, 1024)
+		require.NoError(t, err)
+		require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200, Body: []byte("x")}))
+
+		for _, entry := range store.index {
+			require.NoError(t, os.Remove(store.blobPath(entry.Digest)))
+		}
+		_, ok, err := store.Get(ctx, "a")
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), store.Size())
+	})
+
+	t.Run("ignores tampered index entries", func(t *testing.T) {
+		secret := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(secret, []byte("secret"), 0o600))
+		escape, err := filepath.Rel(t.TempDir(), secret)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name  string
+			index string
+		}{
+			{name: "short digest", index: `{"k":{"digest":"a"}}`},
+			{name: "null entry", index: `{"k":null}`},
+			{name: "path traversal", index: `{"k":{"digest":"` + filepath.ToSlash(escape) + `","size":6}}`},
+			{name: "uppercase digest", index: `{"k":{"digest":"` + strings.Repeat("A", 64) + `"}}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, diskCacheIndexFile), []byte(tt.index), 0o600))
+
+				store, err := NewDiskCacheStore(dir, 1024)
+				require.NoError(t, err)
+				_, ok, err := store.Get(ctx, "k")
+
+				require.NoError(t, err)
+				assert.False(t, ok)
+				assert.Zero(t, store.Size())
+				require.NoError(t, store.Delete(ctx, "k"))
+				assert.FileExists(t, secret)
+			})
+		}
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := NewDiskCacheStore("", 1024)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cache directory cannot be empty")
+
+		_, err = NewDiskCacheStore(filepath.Join(t.TempDir(), "cache"), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be positive")
+	})
+}
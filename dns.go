@@ -0,0 +1,302 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsNegativeTTL caps how long failed lookups are cached.
+const dnsNegativeTTL = 5 * time.Second
+
+// DNSCacheStats reports the effectiveness of the DNS cache.
+type DNSCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// HitRate returns the fraction of lookups served from the cache.
+func (s DNSCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// dnsCache caches host lookups in process, including failures.
+// It is safe for concurrent use across goroutines.
+type dnsCache struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	lookup      func(ctx context.Context, host string) ([]string, error)
+
+	mu       sync.Mutex
+	entries  map[string]*dnsEntry
+	inflight map[string]*dnsLookup
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type dnsEntry struct {
+	addrs     []string
+	err       error
+	expiresAt time.Time
+}
+
+// dnsLookup is a resolution in progress that concurrent dials wait on.
+type dnsLookup struct {
+	done  chan struct{}
+	addrs []string
+	err   error
+}
+
+func newDNSCache(ttl time.Duration, maxEntries int) *dnsCache {
+	return &dnsCache{
+		ttl:         ttl,
+		negativeTTL: min(ttl, dnsNegativeTTL),
+		maxEntries:  maxEntries,
+		lookup:      net.DefaultResolver.LookupHost,
+		entries:     make(map[string]*dnsEntry),
+		inflight:    make(map[string]*dnsLookup),
+	}
+}
+
+// WithDNSCache caches DNS lookups made by the client's dialer for ttl,
+// holding at most maxEntries hosts. Failed lookups are cached for at most
+// five seconds. Hit rates are reported in Stats.
+func WithDNSCache(ttl time.Duration, maxEntries int) ClientOption {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("DNS cache TTL must be positive")
+		}
+		if maxEntries <= 0 {
+			return fmt.Errorf("DNS cache max entries %d must be positive", maxEntries)
+		}
+
+		c.dnsCache = newDNSCache(ttl, maxEntries)
+		return nil
+	}
+}
+
+// resolve returns the addresses of host, from the cache when possible.
+func (d *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	if entry, ok := d.entries[host]; ok && now.Before(entry.expiresAt) {
+		d.mu.Unlock()
+		d.hits.Add(1)
+		return entry.addrs, entry.err
+	}
+	d.misses.Add(1)
+	if pending, ok := d.inflight[host]; ok {
+		d.mu.Unlock()
+		select {
+		case <-pending.done:
+			return pending.addrs, pending.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	pending := &dnsLookup{done: make(chan struct{})}
+	d.inflight[host] = pending
+	d.mu.Unlock()
+
+	pending.addrs, pending.err = d.lookup(ctx, host)
+
+	d.mu.Lock()
+	delete(d.inflight, host)
+	// Context errors say nothing about the host and are not cached.
+	if ctx.Err() == nil {
+		ttl := d.ttl
+		if pending.err != nil {
+			ttl = d.negativeTTL
+		}
+		d.store(host, &dnsEntry{addrs: pending.addrs, err: pending.err, expiresAt: time.Now().Add(ttl)})
+	}
+	d.mu.Unlock()
+	close(pending.done)
+
+	return pending.addrs, pending.err
+}
+
+// store adds an entry, evicting expired entries and then the entry closest
+// to expiry when full. The caller must hold d.mu.
+func (d *dnsCache) store(host string, entry *dnsEntry) {
+	if _, ok := d.entries[host]; !ok && len(d.entries) >= d.maxEntries {
+		now := time.Now()
+		var soonest string
+		for h, e := range d.entries {
+			if !now.Before(e.expiresAt) {
+				delete(d.entries, h)
+				continue
+			}
+			if soonest == "" || e.expiresAt.Before(d.entries[soonest].expiresAt) {
+				soonest = h
+			}
+		}
+		if len(d.entries) >= d.maxEntries {
+			delete(d.entries, soonest)
+		}
+	}
+	d.entries[host] = entry
+}
+
+func (d *dnsCache) stats() *DNSCacheStats {
+	d.mu.Lock()
+	entries := len(d.entries)
+	d.mu.Unlock()
+	return &DNSCacheStats{Hits: d.hits.Load(), Misses: d.misses.Load(), Entries: entries}
+}
+
+// usesCustomDialer reports whether name resolution options need configureDialer.
+func (c *Client) usesCustomDialer() bool {
+	return c.dnsCache != nil || len(c.hostMapping) > 0 || c.addressFamily != AnyAddressFamily
+}
+
+// configureDialer wraps the transport's dialer with host mapping, the DNS
+// cache and the address family preference, in that order, regardless of the
+// order in which their options were given.
+func (c *Client) configureDialer(t *http.Transport) error {
+	dial := resolvingDialer(t.DialContext, c.resolver())
+	if len(c.hostMapping) > 0 {
+		dial = mappingDialer(dial, c.hostMapping)
+	}
+	t.DialContext = dial
+	return nil
+}
+
+// resolver returns the lookup configureDialer resolves host names with: the
+// DNS cache, if any, with the address family preference applied.
+func (c *Client) resolver() func(ctx context.Context, host string) ([]string, error) {
+	resolve := net.DefaultResolver.LookupHost
+	if c.dnsCache != nil {
+		resolve = c.dnsCache.resolve
+	}
+	if c.addressFamily != AnyAddressFamily {
+		resolve = preferAddressFamily(resolve, c.addressFamily)
+	}
+	return resolve
+}
+
+// resolveHost resolves host as the client's dialer would, after host
+// mapping, so a lookup lands in the same DNS cache entry.
+func (c *Client) resolveHost(ctx context.Context, host string) ([]string, error) {
+	if target, ok := c.hostMapping[strings.ToLower(host)]; ok {
+		host = target
+	}
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	return c.resolver()(ctx, host)
+}
+
+// dialFunc dials a network address, as http.Transport.DialContext does.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// defaultDialer matches the dialer of http.DefaultTransport.
+var defaultDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+// resolvingDialer wraps dial so host names are resolved with resolve and the
+// returned addresses are raced in order, as dialParallel does.
+func resolvingDialer(dial dialFunc, resolve func(ctx context.Context, host string) ([]string, error)) dialFunc {
+	if dial == nil {
+		dial = defaultDialer.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for %s", host)
+		}
+
+		targets := make([]string, len(addrs))
+		for i, ip := range addrs {
+			targets[i] = net.JoinHostPort(ip, port)
+		}
+		return dialParallel(ctx, dial, network, targets)
+	}
+}
+
+// fallbackDelay is how long a dial runs before the next address is tried
+// alongside it, as with net.Dialer's default (RFC 8305, section 5).
+const fallbackDelay = 300 * time.Millisecond
+
+// dialResult is the outcome of one dial of dialParallel.
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// dialParallel dials addrs in order, starting the next dial as soon as the
+// previous one fails or has not connected within fallbackDelay, so one
+// unreachable address does not stall the connection for the whole dial
+// timeout. It returns the first connection and closes any later ones.
+func dialParallel(ctx context.Context, dial dialFunc, network string, addrs []string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(addrs))
+	next, pending := 0, 0
+	start := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			conn, err := dial(ctx, network, addr)
+			results <- dialResult{conn: conn, err: err}
+		}()
+	}
+
+	start()
+	timer := time.NewTimer(fallbackDelay)
+	defer timer.Stop()
+	var errs []error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+				timer.Reset(fallbackDelay)
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				go closeDials(results, pending)
+				return res.conn, nil
+			}
+			errs = append(errs, res.err)
+			if next < len(addrs) && ctx.Err() == nil {
+				start()
+				timer.Reset(fallbackDelay)
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// closeDials closes the connections of the n dials still to report.
+func closeDials(results <-chan dialResult, n int) {
+	for range n {
+		if res := <-results; res.conn != nil {
+			res.conn.Close()
+		}
+	}
+}
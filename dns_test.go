@@ -0,0 +1,183 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLookup resolves every host to addrs, or fails with err.
+func countingLookup(calls *atomic.Int32, addrs []string, err error) func(context.Context, string) ([]string, error) {
+	return func(ctx context.Context, host string) ([]string, error) {
+		calls.Add(1)
+		return addrs, err
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves repeat lookups from cache", func(t *testing.T) {
+		var calls atomic.Int32
+		cache := newDNSCache(time.Minute, 10)
+		cache.lookup = countingLookup(&calls, []string{"10.0.0.1"}, nil)
+
+		for range 3 {
+			addrs, err := cache.resolve(ctx, "api.example.com")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"10.0.0.1"}, addrs)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+		stats := cache.stats()
+		assert.Equal(t, uint64(2), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.InDelta(t, 2.0/3, stats.HitRate(), 0.001)
+	})
+
+	t.Run("caches failures briefly", func(t *testing.T) {
+		var calls atomic.Int32
+		cache := newDNSCache(time.Minute, 10)
+		cache.negativeTTL = 10 * time.Millisecond
+		cache.lookup = countingLookup(&calls, nil, errors.New("no such host"))
+
+		_, err := cache.resolve(ctx, "missing.example.com")
+		require.Error(t, err)
+		_, err = cache.resolve(ctx, "missing.example.com")
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+
+		time.Sleep(20 * time.Millisecond)
+		_, _ = cache.resolve(ctx, "missing.example.com")
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("expires entries after ttl", func(t *testing.T) {
+		var calls atomic.Int32
+		cache := newDNSCache(10*time.Millisecond, 10)
+		cache.lookup = countingLookup(&calls, []string{"10.0.0.1"}, nil)
+
+		_, _ = cache.resolve(ctx, "api.example.com")
+		time.Sleep(20 * time.Millisecond)
+		_, _ = cache.resolve(ctx, "api.example.com")
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("bounds entries", func(t *testing.T) {
+		var calls atomic.Int32
+		cache := newDNSCache(time.Minute, 2)
+		cache.lookup = countingLookup(&calls, []string{"10.0.0.1"}, nil)
+
+		for _, host := range []string{"a", "b", "c"} {
+			_, _ = cache.resolve(ctx, host)
+		}
+
+		assert.Equal(t, 2, cache.stats().Entries)
+	})
+}
+
+func TestWithDNSCache(t *testing.T) {
+	t.Run("dials resolved addresses", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		client, err := New(
+			WithBaseURL("http://api.internal:"+serverURL.Port()),
+			WithLoggerDisabled(),
+			WithDNSCache(time.Minute, 10),
+		)
+		require.NoError(t, err)
+		var calls atomic.Int32
+		client.dnsCache.lookup = countingLookup(&calls, []string{"127.0.0.1"}, nil)
+		client.httpClient.Transport.(*http.Transport).DisableKeepAlives = true
+
+		for range 2 {
+			_, err = client.Get(context.Background(), "/test", nil, WithRequestTimeout(2*time.Second))
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+		stats := client.Stats().DNS
+		require.NotNil(t, stats)
+		assert.Equal(t, uint64(1), stats.Hits)
+	})
+
+	t.Run("tries each address in order", func(t *testing.T) {
+		var dialed []string
+		dial := resolvingDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return nil, errors.New("refused")
+		}, func(ctx context.Context, host string) ([]string, error) {
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		})
+
+		_, err := dial(context.Background(), "tcp", "api.internal:443")
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"10.0.0.1:443", "10.0.0.2:443"}, dialed)
+	})
+
+	t.Run("races a stalled address", func(t *testing.T) {
+		server, client := net.Pipe()
+		defer server.Close()
+		dial := resolvingDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr == "10.0.0.1:443" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return client, nil
+		}, func(ctx context.Context, host string) ([]string, error) {
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		})
+
+		start := time.Now()
+		conn, err := dial(context.Background(), "tcp", "api.internal:443")
+
+		require.NoError(t, err)
+		assert.Same(t, client, conn)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("leaves IP addresses alone", func(t *testing.T) {
+		var dialed string
+		dial := resolvingDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = addr
+			return nil, errors.New("stop")
+		}, func(ctx context.Context, host string) ([]string, error) {
+			t.Fatal("unexpected lookup")
+			return nil, nil
+		})
+
+		_, _ = dial(context.Background(), "tcp", "127.0.0.1:80")
+
+		assert.Equal(t, "127.0.0.1:80", dialed)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithDNSCache(0, 10))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "DNS cache TTL must be positive")
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithDNSCache(time.Minute, 0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be positive")
+	})
+
+	t.Run("stats are nil without cache", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		assert.Nil(t, client.Stats().DNS)
+	})
+}
@@ -0,0 +1,44 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorEnricher adds application context to an error before the client
+// returns it, e.g. a trace ID, tenant ID or vendor support hint, usually
+// with Error.Annotate. It runs on the calling goroutine with the request's
+// context.
+type ErrorEnricher func(ctx context.Context, err *Error)
+
+// WithErrorEnricher calls fn with every *Error the client returns from a
+// request, so context is attached in one place instead of at each call
+// site. Enrichers run in the order given, after the client has filled in
+// the error and before the request is logged.
+func WithErrorEnricher(fn ErrorEnricher) ClientOption {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("error enricher cannot be nil")
+		}
+		c.errorEnrichers = append(c.errorEnrichers, fn)
+		return nil
+	}
+}
+
+// Annotate attaches a key/value pair to the error, replacing any value
+// already under key. Annotations are not part of the error message.
+func (e *Error) Annotate(key, value string) {
+	if e.Annotations == nil {
+		e.Annotations = make(map[string]string)
+	}
+	e.Annotations[key] = value
+}
+
+func (c *Client) enrichError(ctx context.Context, err *Error) {
+	for _, enrich := range c.errorEnrichers {
+		_ = c.runHook(ctx, "error enricher", func() error {
+			enrich(ctx, err)
+			return nil
+		})
+	}
+}
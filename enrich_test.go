@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestWithErrorEnricher(t *testing.T) {
+	t.Run("annotates returned errors", func(t *testing.T) {
+		server := newStatusServer(http.StatusBadGateway)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithThirdPartyCode("acme"),
+			WithErrorEnricher(func(ctx context.Context, err *Error) {
+				if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+					err.Annotate("tenant", tenant)
+				}
+			}),
+			WithErrorEnricher(func(ctx context.Context, err *Error) {
+				if err.IsServerError() {
+					err.Annotate("hint", err.ThirdParty+" status page")
+				}
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "t-42")
+		_, err = client.Get(ctx, "/orders", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, map[string]string{"tenant": "t-42", "hint": "acme status page"}, clientErr.Annotations)
+		assert.NotContains(t, err.Error(), "t-42")
+	})
+
+	t.Run("sees filled in error", func(t *testing.T) {
+		server := newStatusServer(http.StatusNotFound)
+		defer server.Close()
+
+		var seen *Error
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithErrorEnricher(func(ctx context.Context, err *Error) { seen = err }),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/missing", nil)
+		require.Error(t, err)
+		require.NotNil(t, seen)
+		assert.Equal(t, http.StatusNotFound, seen.StatusCode)
+		assert.Equal(t, 1, seen.Attempts)
+		assert.NotNil(t, seen.Request)
+	})
+
+	t.Run("is not called on success", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		called := false
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithErrorEnricher(func(ctx context.Context, err *Error) { called = true }),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/ok", nil)
+		require.NoError(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("replaces annotation", func(t *testing.T) {
+		err := &Error{}
+		err.Annotate("trace_id", "a")
+		err.Annotate("trace_id", "b")
+		assert.Equal(t, map[string]string{"trace_id": "b"}, err.Annotations)
+	})
+
+	t.Run("rejects nil enricher", func(t *testing.T) {
+		_, err := New(WithBaseURL("http://api.example.com"), WithErrorEnricher(nil))
+		require.Error(t, err)
+	})
+}
@@ -1,8 +1,10 @@
 package httpclient
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ErrorKind classifies the type of error.
@@ -15,6 +17,8 @@ const (
 	ErrKindHTTP
 	ErrKindParse
 	ErrKindRateLimit
+	ErrKindOverload // shed by the client, see WithMaxConcurrentRequests
+	ErrKindInternal // a recovered panic, see PanicError
 )
 
 // Error represents an HTTP client error with classification and context.
@@ -28,14 +32,35 @@ type Error struct {
 	URL        string
 	Attempts   int
 	Err        error
+
+	// RetryAfter is the server-requested delay from the Retry-After header,
+	// or 0 if none was sent. Schedulers can use it to requeue work.
+	RetryAfter time.Duration
+
+	// Request records what was sent, for debugging and Replay.
+	Request *CapturedRequest
+
+	// ThirdParty is the client's WithThirdPartyCode, so errors can be
+	// attributed to an integration after they leave the client.
+	ThirdParty string
+
+	// Timeout describes the call's time budget when it failed because a
+	// deadline passed, or is nil.
+	Timeout *TimeoutInfo
+
+	// Annotations hold application context added by WithErrorEnricher.
+	Annotations map[string]string
 }
 
 // Error implements the error interface.
 func (e *Error) Error() string {
-	if e.Err != nil {
-		return fmt.Sprintf("%s %s: %v", e.Method, e.URL, e.Err)
+	if e.Err == nil {
+		return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	}
+	if e.Timeout != nil {
+		return fmt.Sprintf("%s %s: %v (%s)", e.Method, e.URL, e.Err, e.Timeout)
 	}
-	return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	return fmt.Sprintf("%s %s: %v", e.Method, e.URL, e.Err)
 }
 
 // Unwrap returns the underlying error.
@@ -59,6 +84,10 @@ func (e *Error) IsRetryable() bool {
 	case ErrKindTimeout, ErrKindNetwork:
 		return true
 	case ErrKindHTTP:
+		var retryable *retryableError
+		if errors.As(e.Err, &retryable) {
+			return true
+		}
 		switch e.StatusCode {
 		case http.StatusRequestTimeout,
 			http.StatusTooManyRequests,
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"errors"
+	"time"
+)
+
+// EventKind identifies a stage in the request lifecycle.
+type EventKind int
+
+const (
+	EventRequestStarted EventKind = iota
+	EventAttemptFailed
+	EventRetryScheduled
+	EventRequestFinished
+)
+
+// String returns the name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventRequestStarted:
+		return "request_started"
+	case EventAttemptFailed:
+		return "attempt_failed"
+	case EventRetryScheduled:
+		return "retry_scheduled"
+	case EventRequestFinished:
+		return "request_finished"
+	}
+	return "unknown"
+}
+
+// Event describes a single request lifecycle transition.
+type Event struct {
+	Kind          EventKind
+	Time          time.Time
+	Method        string
+	URL           string
+	Attempt       int           // set for AttemptFailed and RetryScheduled
+	StatusCode    int           // set when a response was received
+	Delay         time.Duration // set for RetryScheduled
+	Duration      time.Duration // set for RequestFinished
+	RequestBytes  uint64        // set for RequestFinished; body bytes sent over all attempts
+	ResponseBytes uint64        // set for RequestFinished; body bytes received over all attempts
+	ThirdParty    string        // the client's WithThirdPartyCode
+	Err           error
+}
+
+// WithEvents enables the lifecycle event stream returned by Client.Events.
+// The channel holds up to buffer events; when it is full new events are
+// dropped so a slow consumer never blocks requests.
+func WithEvents(buffer int) ClientOption {
+	return func(c *Client) error {
+		if buffer <= 0 {
+			return errors.New("event buffer size must be positive")
+		}
+		c.events = make(chan Event, buffer)
+		return nil
+	}
+}
+
+// Events returns the lifecycle event stream, or nil if WithEvents was not used.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// DroppedEvents returns the number of events discarded because the buffer was full.
+func (c *Client) DroppedEvents() uint64 {
+	return c.eventsDropped.Load()
+}
+
+// emit publishes an event without blocking.
+func (c *Client) emit(e Event) {
+	if c.events == nil {
+		return
+	}
+
+	e.Time = time.Now()
+	e.ThirdParty = c.thirdPartyCode
+	select {
+	case c.events <- e:
+	default:
+		c.eventsDropped.Add(1)
+	}
+}
@@ -0,0 +1,162 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drainEvents(ch <-chan Event) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestWithEvents(t *testing.T) {
+	t.Run("returns error for non-positive buffer", func(t *testing.T) {
+		_, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithEvents(0),
+		)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "event buffer size must be positive")
+	})
+
+	t.Run("events are nil when not enabled", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+
+		require.NoError(t, err)
+		assert.Nil(t, client.Events())
+	})
+}
+
+func TestClient_Events(t *testing.T) {
+	t.Run("emits started and finished for successful request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithEvents(10),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		events := drainEvents(client.Events())
+		require.Len(t, events, 2)
+		assert.Equal(t, EventRequestStarted, events[0].Kind)
+		assert.Equal(t, EventRequestFinished, events[1].Kind)
+		assert.Equal(t, http.MethodGet, events[1].Method)
+		assert.Equal(t, http.StatusOK, events[1].StatusCode)
+		assert.NoError(t, events[1].Err)
+	})
+
+	t.Run("reports traffic on finished event", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("pong"))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithEvents(10))
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/ping", "ping!", nil)
+		require.NoError(t, err)
+
+		events := drainEvents(client.Events())
+		require.Len(t, events, 2)
+		assert.Equal(t, uint64(5), events[1].RequestBytes)
+		assert.Equal(t, uint64(4), events[1].ResponseBytes)
+	})
+
+	t.Run("emits attempt and retry events", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithEvents(10),
+			WithRetry(&RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		events := drainEvents(client.Events())
+		kinds := make([]EventKind, 0, len(events))
+		for _, e := range events {
+			kinds = append(kinds, e.Kind)
+		}
+		assert.Equal(t, []EventKind{EventRequestStarted, EventAttemptFailed, EventRetryScheduled, EventRequestFinished}, kinds)
+		assert.Equal(t, 1, events[1].Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, events[1].StatusCode)
+		assert.Equal(t, 2, events[2].Attempt)
+		assert.Equal(t, time.Millisecond, events[2].Delay)
+	})
+
+	t.Run("drops events when buffer is full", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithEvents(1),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+
+		assert.Len(t, drainEvents(client.Events()), 1)
+		assert.Equal(t, uint64(1), client.DroppedEvents())
+	})
+}
+
+func TestEventKind_String(t *testing.T) {
+	tests := []struct {
+		kind     EventKind
+		expected string
+	}{
+		{EventRequestStarted, "request_started"},
+		{EventAttemptFailed, "attempt_failed"},
+		{EventRetryScheduled, "retry_scheduled"},
+		{EventRequestFinished, "request_finished"},
+		{EventKind(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.kind.String())
+		})
+	}
+}
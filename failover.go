@@ -0,0 +1,161 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultFailoverCooldown is how long a failed base URL is skipped by
+// default before requests try it again.
+const DefaultFailoverCooldown = 30 * time.Second
+
+// failover tracks the health of the base URLs given to WithBaseURLs.
+// It is safe for concurrent use across goroutines.
+type failover struct {
+	bases    []*url.URL // primary first
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	downUntil []time.Time
+}
+
+// WithBaseURLs sets the base URL for all requests, like WithBaseURL, with
+// fallbacks to fail over to. A base URL that fails to connect or answers
+// 5xx is skipped for the failover cooldown, so retries and later requests
+// go to the next healthy one in order; once its cooldown ends, the primary
+// is preferred again. Requests to absolute URLs or with WithRequestBaseURL
+// do not fail over.
+func WithBaseURLs(primary string, fallbacks ...string) ClientOption {
+	return func(c *Client) error {
+		if err := WithBaseURL(primary)(c); err != nil {
+			return err
+		}
+		bases := []*url.URL{c.baseURL}
+		for _, fallback := range fallbacks {
+			if fallback == "" {
+				return errors.New("fallback base URL cannot be empty")
+			}
+			u, err := url.Parse(fallback)
+			if err != nil {
+				return err
+			}
+			if err := normalizeURLHost(u); err != nil {
+				return err
+			}
+			bases = append(bases, u)
+		}
+		c.failoverConfig().bases = bases
+		c.failover.downUntil = make([]time.Time, len(bases))
+		return nil
+	}
+}
+
+// WithFailoverCooldown sets how long a failed base URL given to
+// WithBaseURLs is skipped. The default is DefaultFailoverCooldown.
+func WithFailoverCooldown(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		if d <= 0 {
+			return errors.New("failover cooldown must be positive")
+		}
+		c.failoverConfig().cooldown = d
+		return nil
+	}
+}
+
+// failoverConfig returns the client's failover, creating it on first use.
+func (c *Client) failoverConfig() *failover {
+	if c.failover == nil {
+		c.failover = &failover{cooldown: DefaultFailoverCooldown}
+	}
+	return c.failover
+}
+
+// configureFailover checks that WithFailoverCooldown came with fallbacks
+// and that the base URLs are not also balanced.
+func (c *Client) configureFailover() error {
+	if c.failover == nil {
+		return nil
+	}
+	if len(c.failover.bases) == 0 {
+		return errors.New("failover cooldown requires WithBaseURLs")
+	}
+	if c.balancing != nil {
+		return errors.New("WithBaseURLs and WithBalancer cannot be combined")
+	}
+	return nil
+}
+
+// failoverAttempt sends one attempt of the call to the healthiest base URL
+// and records how that base URL fared.
+func (c *Client) failoverAttempt(ctx context.Context, cl *call, attempt int) attemptResult {
+	i := c.failover.pick(time.Now())
+	cl.url = cl.targetURLs[i]
+	cl.host = c.failover.bases[i].Host
+	res := c.limitedAttempt(ctx, cl, attempt)
+	if isFailoverSignal(ctx, res) {
+		c.failover.markDown(i, time.Now())
+		c.logFailover(ctx, cl, res)
+	}
+	return res
+}
+
+// pick returns the first base URL that is not cooling down, or the one
+// whose cooldown ends first when all are.
+func (f *failover) pick(now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	best := 0
+	for i, until := range f.downUntil {
+		if !now.Before(until) {
+			return i
+		}
+		if until.Before(f.downUntil[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// markDown skips base URL i until the cooldown has passed.
+func (f *failover) markDown(i int, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downUntil[i] = now.Add(f.cooldown)
+}
+
+// isFailoverSignal reports whether an attempt failed to connect or got a
+// 5xx response. Attempts cut short by ctx say nothing about the server.
+func isFailoverSignal(ctx context.Context, res attemptResult) bool {
+	if res.response != nil {
+		return res.response.StatusCode >= http.StatusInternalServerError
+	}
+	if res.err == nil || ctx.Err() != nil {
+		return false
+	}
+	var opErr *net.OpError
+	return errors.As(res.err, &opErr) && opErr.Op == "dial"
+}
+
+func (c *Client) logFailover(ctx context.Context, cl *call, res attemptResult) {
+	if !c.logEnabled(ctx, slog.LevelWarn) {
+		return
+	}
+	reason := fmt.Sprint(res.err)
+	if res.response != nil {
+		reason = res.response.Status
+	}
+	c.logger.Log(ctx, slog.LevelWarn, "http_failover",
+		slog.String("method", cl.method),
+		slog.String("url", cl.url),
+		slog.String("reason", reason),
+		slog.Duration("cooldown", c.failover.cooldown),
+	)
+}
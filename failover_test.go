@@ -0,0 +1,167 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer answers status and counts the requests it receives.
+func newCountingServer(status *atomic.Int32, hits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+}
+
+func TestWithBaseURLs(t *testing.T) {
+	fastRetry := &RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+	t.Run("fails over on connect errors", func(t *testing.T) {
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+		var status, hits atomic.Int32
+		status.Store(http.StatusOK)
+		fallback := newCountingServer(&status, &hits)
+		defer fallback.Close()
+
+		client, err := New(
+			WithBaseURLs(down.URL+"/v1", fallback.URL+"/v1"),
+			WithLoggerDisabled(),
+			WithRetry(fastRetry),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/orders", nil, WithQuery("page", "2"))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("fails over on server errors", func(t *testing.T) {
+		var primaryStatus, primaryHits, fallbackStatus, fallbackHits atomic.Int32
+		primaryStatus.Store(http.StatusServiceUnavailable)
+		fallbackStatus.Store(http.StatusOK)
+		primary := newCountingServer(&primaryStatus, &primaryHits)
+		defer primary.Close()
+		fallback := newCountingServer(&fallbackStatus, &fallbackHits)
+		defer fallback.Close()
+
+		client, err := New(
+			WithBaseURLs(primary.URL, fallback.URL),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.Error(t, err)
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), primaryHits.Load())
+		assert.Equal(t, int32(1), fallbackHits.Load())
+	})
+
+	t.Run("restores primary after cooldown", func(t *testing.T) {
+		var primaryStatus, primaryHits, fallbackStatus, fallbackHits atomic.Int32
+		primaryStatus.Store(http.StatusBadGateway)
+		fallbackStatus.Store(http.StatusOK)
+		primary := newCountingServer(&primaryStatus, &primaryHits)
+		defer primary.Close()
+		fallback := newCountingServer(&fallbackStatus, &fallbackHits)
+		defer fallback.Close()
+
+		client, err := New(
+			WithBaseURLs(primary.URL, fallback.URL),
+			WithFailoverCooldown(50*time.Millisecond),
+			WithLoggerDisabled(),
+			WithRetry(fastRetry),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), fallbackHits.Load())
+
+		primaryStatus.Store(http.StatusOK)
+		time.Sleep(60 * time.Millisecond)
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), primaryHits.Load())
+		assert.Equal(t, int32(1), fallbackHits.Load())
+	})
+
+	t.Run("does not fail over client errors", func(t *testing.T) {
+		var primaryStatus, primaryHits, fallbackStatus, fallbackHits atomic.Int32
+		primaryStatus.Store(http.StatusNotFound)
+		fallbackStatus.Store(http.StatusOK)
+		primary := newCountingServer(&primaryStatus, &primaryHits)
+		defer primary.Close()
+		fallback := newCountingServer(&fallbackStatus, &fallbackHits)
+		defer fallback.Close()
+
+		client, err := New(WithBaseURLs(primary.URL, fallback.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, _ = client.Get(context.Background(), "/orders", nil)
+		_, _ = client.Get(context.Background(), "/orders", nil)
+		assert.Equal(t, int32(2), primaryHits.Load())
+		assert.Zero(t, fallbackHits.Load())
+	})
+
+	t.Run("refuses classified requests to plain http fallbacks", func(t *testing.T) {
+		down := httptest.NewTLSServer(http.NotFoundHandler())
+		down.Close()
+		var status, hits atomic.Int32
+		status.Store(http.StatusOK)
+		fallback := newCountingServer(&status, &hits)
+		defer fallback.Close()
+
+		client, err := New(
+			WithBaseURLs(down.URL, fallback.URL),
+			WithLoggerDisabled(),
+			WithRetry(fastRetry),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/charges", map[string]string{"card": "4111"}, nil,
+			WithDataClassification(DataPCI))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not https")
+		assert.Zero(t, hits.Load())
+	})
+
+	t.Run("picks the base URL cooling down shortest when all are down", func(t *testing.T) {
+		f := &failover{cooldown: time.Minute, downUntil: make([]time.Time, 3)}
+		now := time.Now()
+		f.markDown(1, now)
+		f.markDown(0, now.Add(time.Second))
+		assert.Equal(t, 2, f.pick(now))
+
+		f.markDown(2, now.Add(2*time.Second))
+		assert.Equal(t, 1, f.pick(now))
+	})
+
+	t.Run("rejects empty fallback", func(t *testing.T) {
+		_, err := New(WithBaseURLs("https://api.example.com", ""))
+		require.Error(t, err)
+	})
+
+	t.Run("requires base URLs for cooldown", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithFailoverCooldown(time.Second))
+		require.Error(t, err)
+	})
+
+	t.Run("checks fallbacks under https only", func(t *testing.T) {
+		_, err := New(WithBaseURLs("https://api.example.com", "http://backup.example.com"), WithHTTPSOnly())
+		require.ErrorIs(t, err, ErrInsecureURL)
+	})
+}
@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// FallbackFunc produces a substitute response when a request fails with a
+// transient error after all retries, for example from a cache or a static
+// default. Returning a nil response declines to substitute.
+type FallbackFunc func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error)
+
+// WithFallback enables graceful degradation: when a request ends in a
+// retryable or server error, fn is asked for a substitute response, which is
+// returned marked with Response.FromFallback. Client errors (4xx) never fall back.
+func WithFallback(fn FallbackFunc) ClientOption {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("fallback function cannot be nil")
+		}
+		c.fallback = fn
+		return nil
+	}
+}
+
+// applyFallback substitutes a fallback response for a transient failure.
+// If the fallback itself fails, both errors are returned.
+func (c *Client) applyFallback(ctx context.Context, cl *call, res attemptResult) attemptResult {
+	if c.fallback == nil || res.err == nil {
+		return res
+	}
+
+	var clientErr *Error
+	if !errors.As(res.err, &clientErr) || !(clientErr.IsRetryable() || clientErr.IsServerError()) {
+		return res
+	}
+
+	var response *Response
+	err := c.runHook(ctx, "fallback", func() (err error) {
+		response, err = c.fallback(ctx, captureRequest(cl, res.reqHeaders), res.err)
+		return err
+	})
+	if err != nil {
+		res.err = errors.Join(res.err, fmt.Errorf("fallback failed: %w", err))
+		return res
+	}
+	if response == nil {
+		return res
+	}
+
+	response.FromFallback = true
+	return attemptResult{response: response, reqHeaders: res.reqHeaders}
+}
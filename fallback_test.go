@@ -0,0 +1,117 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatusServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+}
+
+func TestWithFallback(t *testing.T) {
+	t.Run("substitutes response on server error", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		var gotReq *CapturedRequest
+		var gotCause error
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithFallback(func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error) {
+				gotReq, gotCause = req, cause
+				return &Response{StatusCode: http.StatusOK, Body: []byte(`{"rates":[]}`)}, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		var result map[string][]int
+		resp, err := client.Get(context.Background(), "/rates", &result)
+
+		require.NoError(t, err)
+		assert.True(t, resp.FromFallback)
+		assert.Contains(t, result, "rates")
+		assert.Equal(t, server.URL+"/rates", gotReq.URL)
+		var httpErr *Error
+		require.ErrorAs(t, gotCause, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	})
+
+	t.Run("does not fall back on client errors", func(t *testing.T) {
+		server := newStatusServer(http.StatusBadRequest)
+		defer server.Close()
+
+		called := false
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithFallback(func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error) {
+				called = true
+				return &Response{StatusCode: http.StatusOK}, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/rates", nil)
+
+		require.Error(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("returns both errors when fallback fails", func(t *testing.T) {
+		server := newStatusServer(http.StatusBadGateway)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithFallback(func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error) {
+				return nil, errors.New("cache miss")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/rates", nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fallback failed: cache miss")
+		var httpErr *Error
+		assert.ErrorAs(t, err, &httpErr)
+	})
+
+	t.Run("logs fallback responses", func(t *testing.T) {
+		server := newStatusServer(http.StatusInternalServerError)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithFallback(func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error) {
+				return &Response{StatusCode: http.StatusOK, Headers: http.Header{}}, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/rates", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, logger.LastEntry().Attrs["from_fallback"])
+	})
+
+	t.Run("returns error for nil function", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithFallback(nil))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fallback function cannot be nil")
+	})
+}
@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticFlags is a FeatureFlagProvider backed by a map; missing flags are unknown.
+type staticFlags struct {
+	mu    sync.Mutex
+	flags map[string]bool
+	asked []string
+}
+
+func (f *staticFlags) Flag(ctx context.Context, name string) (bool, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.asked = append(f.asked, name)
+	enabled, ok := f.flags[name]
+	return enabled, ok
+}
+
+func TestFeatureFlags(t *testing.T) {
+	fastRetry := &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+	t.Run("retry_on_500 makes 500 retryable", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		flags := &staticFlags{flags: map[string]bool{"billing.retry_on_500": true}}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(fastRetry),
+			WithThirdPartyCode("billing"),
+			WithFeatureFlagProvider(flags),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/invoices", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Contains(t, flags.asked, "billing.retry_on_500")
+	})
+
+	t.Run("unknown flags keep default behavior", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(fastRetry),
+			WithFeatureFlagProvider(&staticFlags{}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/invoices", nil)
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("flagged path switches endpoints", func(t *testing.T) {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+		}))
+		defer server.Close()
+
+		flags := &staticFlags{flags: map[string]bool{"use_v2_orders": true}}
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithFeatureFlagProvider(flags))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/v1/orders", nil, WithFlaggedPath("use_v2_orders", "/v2/orders"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v2/orders", path)
+
+		_, err = client.Get(context.Background(), "/v1/orders", nil, WithFlaggedPath("use_v3_orders", "/v3/orders"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/orders", path)
+	})
+
+	t.Run("shadow flag turns mirroring off", func(t *testing.T) {
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer primary.Close()
+		shadowed := make(chan struct{}, 1)
+		shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			shadowed <- struct{}{}
+		}))
+		defer shadow.Close()
+
+		client, err := New(
+			WithBaseURL(primary.URL),
+			WithLoggerDisabled(),
+			WithShadow(shadow.URL),
+			WithFeatureFlagProvider(FeatureFlagFunc(func(ctx context.Context, name string) (bool, bool) {
+				return false, name == FlagShadow
+			})),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/items", nil)
+		require.NoError(t, err)
+
+		select {
+		case <-shadowed:
+			t.Fatal("request was mirrored with shadowing flagged off")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("rejects nil provider", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithFeatureFlagProvider(nil))
+		assert.Error(t, err)
+	})
+}
@@ -1,7 +1,19 @@
 package httpclient
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
 	"testing"
+	"unicode"
+	"unicode/utf8"
 )
 
 // FuzzParseRetryAfter tests ParseRetryAfter with random inputs.
@@ -121,3 +133,236 @@ func FuzzResponseXML(f *testing.F) {
 		_ = resp.XML(&result)
 	})
 }
+
+// FuzzEncodeBody tests the built-in body encoders with random inputs.
+func FuzzEncodeBody(f *testing.F) {
+	f.Add("name", "value", []byte(`{"a":1}`))
+	f.Add("", "", []byte{})
+	f.Add("a&b=c", "d=e&f", []byte("a=b&c=d"))
+	f.Add("quote\"", "line\nbreak", []byte("\x00\xff"))
+	f.Add("\xff\xfe", " ", []byte("--boundary\r\n"))
+	f.Add("<x>", "]]>", []byte("<![CDATA[x]]>"))
+
+	f.Fuzz(func(t *testing.T, key, value string, raw []byte) {
+		c := &Client{}
+
+		data := fuzzEncode(t, c, map[string]string{key: value})
+		if !json.Valid(data) {
+			t.Fatalf("invalid JSON for %q=%q: %s", key, value, data)
+		}
+		if utf8.ValidString(key) && utf8.ValidString(value) {
+			var decoded map[string]string
+			if err := json.Unmarshal(data, &decoded); err != nil || decoded[key] != value {
+				t.Errorf("JSON did not round-trip %q=%q: %s", key, value, data)
+			}
+		}
+
+		data = fuzzEncode(t, c, url.Values{key: {value}})
+		decoded, err := url.ParseQuery(string(data))
+		if err != nil || decoded.Get(key) != value {
+			t.Errorf("form did not round-trip %q=%q: %s", key, value, data)
+		}
+
+		if data := fuzzEncode(t, c, raw); !bytes.Equal(data, raw) {
+			t.Errorf("raw body changed: %q became %q", raw, data)
+		}
+
+		data = fuzzEncode(t, c, XMLBody(struct {
+			XMLName xml.Name `xml:"item"`
+			Key     string   `xml:"key,attr"`
+			Value   string   `xml:"value"`
+		}{Key: key, Value: value}))
+		if err := checkWellFormedXML(data); err != nil {
+			t.Errorf("XML body is malformed: %v: %s", err, data)
+		}
+
+		encoded, err := c.encodeWithChain(MultipartBody(Part{FormName: key, FileName: value, Body: bytes.NewReader(raw)}))
+		if err != nil {
+			return
+		}
+		_, params, err := mime.ParseMediaType(encoded.ContentType)
+		if err != nil {
+			t.Fatalf("invalid multipart content type %q: %v", encoded.ContentType, err)
+		}
+		part, err := multipart.NewReader(encoded.Body, params["boundary"]).NextPart()
+		if err != nil {
+			t.Fatalf("reading multipart part: %v", err)
+		}
+		if got, _ := io.ReadAll(part); !bytes.Equal(got, raw) {
+			t.Errorf("multipart part body changed: %q became %q", raw, got)
+		}
+	})
+}
+
+// FuzzEncodeSOAPBody tests SOAP envelopes built from random options and
+// payloads.
+func FuzzEncodeSOAPBody(f *testing.F) {
+	f.Add("urn:Action", "soap", "ns", "urn:example", "", "text", false)
+	f.Add("", "soapenv", "", "", "http://schemas.xmlsoap.org/soap/encoding/", "", true)
+	f.Add(`"quoted\"`, "s", "x", `"><evil/>`, `'&<>`, "</soap:Body>", true)
+	f.Add("a\r\nX-Injected: 1", "xmlns", "xml", "urn:x", "", "\x00\xff", false)
+	f.Add("", "a:b", "b c", "urn:y", "", "]]>", false)
+
+	f.Fuzz(func(t *testing.T, action, prefix, nsPrefix, namespace, encodingStyle, text string, soap12 bool) {
+		opts := SOAPOptions{Action: action, SOAP12: soap12, Prefix: prefix, EncodingStyle: encodingStyle}
+		if nsPrefix != "" {
+			opts.Namespaces = map[string]string{nsPrefix: namespace}
+		}
+		payload := struct {
+			XMLName xml.Name `xml:"Request"`
+			Text    string   `xml:"Text"`
+		}{Text: text}
+
+		reader, contentType, _, err := EncodeSOAPBody(SOAPBodyWithOptions(payload, opts))
+		if err != nil {
+			return
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var env struct {
+			XMLName xml.Name
+			Body    struct {
+				Request struct {
+					Text string `xml:"Text"`
+				} `xml:"Request"`
+			} `xml:"Body"`
+		}
+		if err := checkWellFormedXML(data); err != nil {
+			t.Fatalf("SOAP envelope is malformed: %v: %s", err, data)
+		}
+		if err := xml.Unmarshal(data, &env); err != nil {
+			t.Fatalf("decoding SOAP envelope: %v: %s", err, data)
+		}
+		if env.XMLName.Local != "Envelope" {
+			t.Errorf("root element is %q, want Envelope", env.XMLName.Local)
+		}
+		if isXMLText(text) && env.Body.Request.Text != text {
+			t.Errorf("payload text %q became %q", text, env.Body.Request.Text)
+		}
+		if _, _, err := mime.ParseMediaType(contentType); err != nil && !strings.ContainsFunc(action, unicode.IsControl) {
+			t.Errorf("invalid content type %q: %v", contentType, err)
+		}
+	})
+}
+
+// FuzzXMLBodyWithRoot tests XML bodies wrapped in a random root element.
+func FuzzXMLBodyWithRoot(f *testing.F) {
+	f.Add("Request", "value", false)
+	f.Add("ns.Item-2", "", true)
+	f.Add("ns:Request", "value", false)
+	f.Add("a:b:c", "value", false)
+	f.Add("_root", "<b>&amp;</b>", false)
+	f.Add("a><evil/><a", "value", false)
+	f.Add("a x='1'", "value", true)
+	f.Add("a/><b", "value", false)
+	f.Add("", "value", false)
+
+	f.Fuzz(func(t *testing.T, root, text string, declaration bool) {
+		payload := struct {
+			XMLName xml.Name `xml:"Item"`
+			Text    string   `xml:"Text"`
+		}{Text: text}
+
+		reader, _, err := EncodeXMLBody(XMLBodyFull(payload, root, declaration))
+		if err != nil {
+			return
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := checkWellFormedXML(data); err != nil {
+			t.Fatalf("XML body is malformed: %v: %s", err, data)
+		}
+		want := root
+		if want == "" {
+			want = "Item"
+		}
+		start, err := rootElement(data)
+		if err != nil || start != want {
+			t.Errorf("root element is %q, want %q: %s", start, want, data)
+		}
+	})
+}
+
+// checkWellFormedXML reports whether data is one well-formed XML element,
+// optionally preceded by a declaration.
+func checkWellFormedXML(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth, roots := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(tok)) > 0 {
+				return errors.New("text outside the root element")
+			}
+		}
+	}
+	if roots != 1 {
+		return fmt.Errorf("%d root elements", roots)
+	}
+	return nil
+}
+
+// rootElement returns the name of the first element in data as written,
+// with its prefix.
+func rootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Space != "" {
+				return start.Name.Space + ":" + start.Name.Local, nil
+			}
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// isXMLText reports whether s survives encoding as XML text unchanged.
+func isXMLText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r == '\r' || (r < 0x20 && r != '\t' && r != '\n') || r == 0xFFFE || r == 0xFFFF {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzEncode encodes body with the client's encoders, failing on errors.
+func fuzzEncode(t *testing.T, c *Client, body any) []byte {
+	t.Helper()
+	encoded, err := c.encodeWithChain(body)
+	if err != nil {
+		t.Fatalf("encoding %T: %v", body, err)
+	}
+	data, err := io.ReadAll(encoded.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// GroupOption configures a Group.
+type GroupOption func(*groupConfig)
+
+type groupConfig struct {
+	limit           int
+	continueOnError bool
+}
+
+// GroupLimit runs at most n of the group's functions at once. A limit of
+// zero or less means no limit.
+func GroupLimit(n int) GroupOption {
+	return func(cfg *groupConfig) {
+		cfg.limit = n
+	}
+}
+
+// GroupContinueOnError keeps running the other functions after one fails.
+// By default the first failure cancels the group's context.
+func GroupContinueOnError() GroupOption {
+	return func(cfg *groupConfig) {
+		cfg.continueOnError = true
+	}
+}
+
+// Group runs related calls made through one client concurrently, like
+// errgroup: the calls share a context that is cancelled on the first
+// failure, an optional concurrency limit, and one aggregated error.
+type Group struct {
+	client          *Client
+	ctx             context.Context
+	cancel          context.CancelCauseFunc
+	sem             chan struct{}
+	continueOnError bool
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	errs    []error
+	skipped bool
+}
+
+// Group creates a Group whose functions receive a context derived from ctx.
+func (c *Client) Group(ctx context.Context, opts ...GroupOption) *Group {
+	var cfg groupConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	g := &Group{client: c, continueOnError: cfg.continueOnError}
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+	if cfg.limit > 0 {
+		g.sem = make(chan struct{}, cfg.limit)
+	}
+	return g
+}
+
+// Go runs fn in a new goroutine, blocking first while the group is at its
+// concurrency limit. Functions started after the group's context was
+// cancelled are skipped.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.skip()
+			return
+		}
+	}
+	if g.ctx.Err() != nil {
+		g.release()
+		g.skip()
+		return
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.release()
+
+		if err := fn(g.ctx); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+			if !g.continueOnError {
+				g.cancel(err)
+			}
+		}
+	}()
+}
+
+// Get fetches path into result as part of the group.
+func (g *Group) Get(path string, result any, opts ...RequestOption) {
+	g.Go(func(ctx context.Context) error {
+		_, err := g.client.Get(ctx, path, result, opts...)
+		return err
+	})
+}
+
+// Wait blocks until every started function returns, then returns all of
+// their errors joined, or nil if they all succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	defer g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	errs := g.errs
+	if g.skipped && len(errs) == 0 {
+		errs = append(errs, context.Cause(g.ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Group) release() {
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+func (g *Group) skip() {
+	g.mu.Lock()
+	g.skipped = true
+	g.mu.Unlock()
+}
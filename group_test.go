@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Group(t *testing.T) {
+	t.Run("fetches concurrently and combines results", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name":"` + r.URL.Path[1:] + `"}`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		var a, b struct{ Name string }
+		g := client.Group(context.Background())
+		g.Get("/a", &a)
+		g.Get("/b", &b)
+
+		require.NoError(t, g.Wait())
+		assert.Equal(t, "a", a.Name)
+		assert.Equal(t, "b", b.Name)
+	})
+
+	t.Run("cancels siblings on first failure", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		g := client.Group(context.Background())
+		g.Go(func(ctx context.Context) error {
+			return errors.New("a failed")
+		})
+		g.Go(func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return errors.New("not cancelled")
+			}
+		})
+
+		err = g.Wait()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a failed")
+		assert.NotContains(t, err.Error(), "not cancelled")
+	})
+
+	t.Run("aggregates errors when continuing on error", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		errA, errB := errors.New("a failed"), errors.New("b failed")
+		g := client.Group(context.Background(), GroupContinueOnError())
+		g.Go(func(ctx context.Context) error { return errA })
+		g.Go(func(ctx context.Context) error { return errB })
+
+		err = g.Wait()
+		assert.ErrorIs(t, err, errA)
+		assert.ErrorIs(t, err, errB)
+	})
+
+	t.Run("limits concurrency", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		var running, peak atomic.Int32
+		g := client.Group(context.Background(), GroupLimit(2))
+		for range 6 {
+			g.Go(func(ctx context.Context) error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}
+
+		require.NoError(t, g.Wait())
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("skips functions after parent cancellation", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ran := false
+		g := client.Group(ctx)
+		g.Go(func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+
+		err = g.Wait()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, ran)
+	})
+}
@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// DecodeHeaders sets the fields of the struct pointed to by v from response
+// headers named in `header:"X-Total-Count"` tags. Supported field types are
+// strings, integers, floats, bools, time.Duration (Go syntax or whole
+// seconds), time.Time (HTTP date or RFC 3339), []string (every value), and
+// pointers to these. Fields whose header is absent are left unchanged.
+func (r *Response) DecodeHeaders(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("header target must be a non-nil pointer to a struct")
+	}
+
+	target := rv.Elem()
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		name, ok := field.Tag.Lookup("header")
+		if !ok || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values := r.Headers.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setHeaderField(target.Field(i), values); err != nil {
+			return fmt.Errorf("decoding header %s into %s: %w", http.CanonicalHeaderKey(name), field.Name, err)
+		}
+	}
+	return nil
+}
+
+// setHeaderField converts header values to the field's type.
+func setHeaderField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setHeaderValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	return setHeaderValue(field, values)
+}
+
+// setHeaderValue converts header values to a non-pointer field's type.
+func setHeaderValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+		field.Set(reflect.ValueOf(append([]string(nil), values...)).Convert(field.Type()))
+		return nil
+	}
+
+	value := strings.TrimSpace(values[0])
+	switch {
+	case field.Type() == durationType:
+		d, err := parseHeaderDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Type() == timeType:
+		t, err := parseHeaderTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+	default:
+		return setHeaderScalar(field, value)
+	}
+	return nil
+}
+
+// setHeaderScalar sets a string, numeric or bool field.
+func setHeaderScalar(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// parseHeaderDuration accepts whole seconds, as in Retry-After, or Go
+// duration syntax.
+func parseHeaderDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseHeaderTime accepts HTTP dates and RFC 3339 timestamps.
+func parseHeaderTime(value string) (time.Time, error) {
+	if t, err := http.ParseTime(value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
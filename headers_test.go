@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseDecodeHeaders(t *testing.T) {
+	newResponse := func(pairs ...string) *Response {
+		headers := http.Header{}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			headers.Add(pairs[i], pairs[i+1])
+		}
+		return &Response{Headers: headers}
+	}
+
+	t.Run("converts typed fields", func(t *testing.T) {
+		resp := newResponse(
+			"X-Total-Count", "1234",
+			"X-RateLimit-Remaining", "17",
+			"X-Ratio", "0.75",
+			"X-Preview", "true",
+			"Retry-After", "30",
+			"Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT",
+			"X-Request-Id", "abc",
+			"Vary", "Accept",
+			"Vary", "Accept-Encoding",
+		)
+
+		var meta struct {
+			Total      int           `header:"x-total-count"`
+			Remaining  *uint16       `header:"X-RateLimit-Remaining"`
+			Ratio      float64       `header:"X-Ratio"`
+			Preview    bool          `header:"X-Preview"`
+			RetryAfter time.Duration `header:"Retry-After"`
+			Modified   time.Time     `header:"Last-Modified"`
+			RequestID  string        `header:"X-Request-Id"`
+			Vary       []string      `header:"Vary"`
+			Untagged   string
+		}
+		require.NoError(t, resp.DecodeHeaders(&meta))
+
+		assert.Equal(t, 1234, meta.Total)
+		require.NotNil(t, meta.Remaining)
+		assert.Equal(t, uint16(17), *meta.Remaining)
+		assert.InDelta(t, 0.75, meta.Ratio, 1e-9)
+		assert.True(t, meta.Preview)
+		assert.Equal(t, 30*time.Second, meta.RetryAfter)
+		assert.Equal(t, time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), meta.Modified)
+		assert.Equal(t, "abc", meta.RequestID)
+		assert.Equal(t, []string{"Accept", "Accept-Encoding"}, meta.Vary)
+		assert.Empty(t, meta.Untagged)
+	})
+
+	t.Run("leaves absent headers unchanged", func(t *testing.T) {
+		meta := struct {
+			Total int `header:"X-Total-Count"`
+		}{Total: -1}
+
+		require.NoError(t, newResponse().DecodeHeaders(&meta))
+		assert.Equal(t, -1, meta.Total)
+	})
+
+	t.Run("reports conversion errors", func(t *testing.T) {
+		var meta struct {
+			Total int8 `header:"X-Total-Count"`
+		}
+
+		err := newResponse("X-Total-Count", "1000").DecodeHeaders(&meta)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "X-Total-Count into Total")
+	})
+
+	t.Run("rejects non-struct targets", func(t *testing.T) {
+		var n int
+		assert.Error(t, newResponse().DecodeHeaders(&n))
+		assert.Error(t, newResponse().DecodeHeaders(nil))
+	})
+}
@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// AddressFamily selects which IP addresses are dialed first.
+type AddressFamily int
+
+const (
+	// AnyAddressFamily dials addresses in resolver order.
+	AnyAddressFamily AddressFamily = iota
+	// PreferIPv4 dials IPv4 addresses before IPv6 addresses.
+	PreferIPv4
+	// PreferIPv6 dials IPv6 addresses before IPv4 addresses.
+	PreferIPv6
+)
+
+// WithHostMapping dials mapped hosts at a fixed IP or alternate host name,
+// like an /etc/hosts entry, e.g. for split-horizon DNS or to test against a
+// specific vendor edge node. TLS still verifies the original host name.
+// Keys and values are host names or IPs without ports.
+func WithHostMapping(mapping map[string]string) ClientOption {
+	return func(c *Client) error {
+		if len(mapping) == 0 {
+			return errors.New("host mapping cannot be empty")
+		}
+		if c.hostMapping == nil {
+			c.hostMapping = make(map[string]string, len(mapping))
+		}
+		for host, target := range mapping {
+			if host == "" || target == "" {
+				return fmt.Errorf("host mapping %q -> %q must name both hosts", host, target)
+			}
+			c.hostMapping[strings.ToLower(host)] = target
+		}
+		return nil
+	}
+}
+
+// WithAddressFamily sets which address family is dialed first when a host
+// resolves to both IPv4 and IPv6 addresses.
+func WithAddressFamily(family AddressFamily) ClientOption {
+	return func(c *Client) error {
+		if family < AnyAddressFamily || family > PreferIPv6 {
+			return fmt.Errorf("unknown address family %d", family)
+		}
+		c.addressFamily = family
+		return nil
+	}
+}
+
+// mappingDialer rewrites mapped hosts before dialing.
+func mappingDialer(dial dialFunc, mapping map[string]string) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			if target, ok := mapping[strings.ToLower(host)]; ok {
+				addr = net.JoinHostPort(target, port)
+			}
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// preferAddressFamily orders resolved addresses so family comes first.
+func preferAddressFamily(resolve func(ctx context.Context, host string) ([]string, error), family AddressFamily) func(ctx context.Context, host string) ([]string, error) {
+	return func(ctx context.Context, host string) ([]string, error) {
+		addrs, err := resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		sorted := slices.Clone(addrs)
+		slices.SortStableFunc(sorted, func(a, b string) int {
+			return familyRank(a, family) - familyRank(b, family)
+		})
+		return sorted, nil
+	}
+}
+
+// familyRank is 0 for addresses of the preferred family and 1 otherwise.
+func familyRank(addr string, family AddressFamily) int {
+	ip := net.ParseIP(addr)
+	isV4 := ip != nil && ip.To4() != nil
+	if isV4 == (family == PreferIPv4) {
+		return 0
+	}
+	return 1
+}
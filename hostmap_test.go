@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHostMapping(t *testing.T) {
+	t.Run("dials mapped IP", func(t *testing.T) {
+		var host string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+		}))
+		defer server.Close()
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		client, err := New(
+			WithBaseURL("http://edge-1.vendor.example:"+serverURL.Port()),
+			WithLoggerDisabled(),
+			WithHostMapping(map[string]string{"EDGE-1.vendor.example": "127.0.0.1"}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "edge-1.vendor.example:"+serverURL.Port(), host)
+	})
+
+	t.Run("resolves alternate hosts through the DNS cache", func(t *testing.T) {
+		var resolved []string
+		client, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithHostMapping(map[string]string{"api.example.com": "api-eu.example.com"}),
+			WithDNSCache(time.Minute, 10),
+		)
+		require.NoError(t, err)
+		client.dnsCache.lookup = func(ctx context.Context, host string) ([]string, error) {
+			resolved = append(resolved, host)
+			return nil, errors.New("stop")
+		}
+
+		_, err = client.httpClient.Transport.(*http.Transport).DialContext(context.Background(), "tcp", "api.example.com:443")
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"api-eu.example.com"}, resolved)
+	})
+
+	t.Run("validates mapping", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithHostMapping(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "host mapping cannot be empty")
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithHostMapping(map[string]string{"a": ""}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must name both hosts")
+	})
+}
+
+func TestWithAddressFamily(t *testing.T) {
+	addrs := []string{"2001:db8::1", "10.0.0.1", "2001:db8::2", "10.0.0.2"}
+	resolve := func(ctx context.Context, host string) ([]string, error) {
+		return addrs, nil
+	}
+
+	t.Run("orders preferred family first", func(t *testing.T) {
+		v4, err := preferAddressFamily(resolve, PreferIPv4)(context.Background(), "h")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "2001:db8::1", "2001:db8::2"}, v4)
+
+		v6, err := preferAddressFamily(resolve, PreferIPv6)(context.Background(), "h")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2001:db8::1", "2001:db8::2", "10.0.0.1", "10.0.0.2"}, v6)
+	})
+
+	t.Run("rejects unknown family", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAddressFamily(AddressFamily(7)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown address family")
+	})
+}
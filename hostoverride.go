@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// WithHostOverride sends host as the request's Host header instead of the
+// URL's host, e.g. when the base URL is a vendor IP on an allowlist or a
+// private link endpoint. TLS still verifies the URL's host unless
+// WithTLSServerName sets a server name for it.
+func WithHostOverride(host string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.hostOverride = host
+	}
+}
+
+// WithTLSServerName sets the TLS server name (SNI) sent and verified on
+// connections to host, a host name or IP address with an optional port,
+// independent of the dialed address. Pair it with WithHostOverride when
+// dialing a vendor by IP. Connections to other hosts, such as redirects,
+// failover targets or the shadow, still verify their own names, and
+// connections through a proxy are not affected.
+func WithTLSServerName(host, name string) ClientOption {
+	return func(c *Client) error {
+		if host == "" || name == "" {
+			return errors.New("TLS server name and host cannot be empty")
+		}
+		host, err := NormalizeHost(host)
+		if err != nil {
+			return err
+		}
+		if c.tlsServerNames == nil {
+			c.tlsServerNames = make(map[string]string)
+			c.transportHooks = append(c.transportHooks, func(t *http.Transport) error {
+				t.DialTLSContext = c.serverNameDialer(t)
+				return nil
+			})
+		}
+		c.tlsServerNames[host] = name
+		return nil
+	}
+}
+
+// serverNameDialer returns a DialTLSContext for t that performs the TLS
+// handshake t would, with the server name configured for the dialed host.
+func (c *Client) serverNameDialer(t *http.Transport) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = defaultDialer.DialContext
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := t.TLSClientConfig.Clone()
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		cfg.ServerName = c.tlsServerName(addr, cfg.ServerName)
+		if t.TLSHandshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.TLSHandshakeTimeout)
+			defer cancel()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// tlsServerName returns the server name configured for addr, by address or
+// by host, or else def, or else addr's host.
+func (c *Client) tlsServerName(addr, def string) string {
+	if name, ok := c.tlsServerNames[addr]; ok {
+		return name
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if name, ok := c.tlsServerNames[host]; ok {
+		return name
+	}
+	if def != "" {
+		return def
+	}
+	return host
+}
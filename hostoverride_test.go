@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHostOverride(t *testing.T) {
+	t.Run("sets the Host header", func(t *testing.T) {
+		var host string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil, WithHostOverride("api.vendor.example"))
+		require.NoError(t, err)
+		assert.Equal(t, "api.vendor.example", host)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		require.NoError(t, err)
+		assert.Equal(t, server.Listener.Addr().String(), host)
+	})
+
+	t.Run("dials by IP with TLS server name", func(t *testing.T) {
+		var host, serverName string
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+			serverName = r.TLS.ServerName
+		}))
+		defer server.Close()
+
+		// The test certificate is valid for example.com as well as 127.0.0.1.
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLoggerDisabled(),
+			WithTLSServerName(server.Listener.Addr().String(), "example.com"),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil, WithHostOverride("example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", host)
+		assert.Equal(t, "example.com", serverName)
+	})
+
+	t.Run("TLS server name is verified", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLoggerDisabled(),
+			WithTLSServerName("127.0.0.1", "vendor.invalid"),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("TLS server name applies only to its host", func(t *testing.T) {
+		vendor := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer vendor.Close()
+		var serverName string
+		other := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverName = r.TLS.ServerName
+		}))
+		defer other.Close()
+
+		client, err := New(
+			WithBaseURL(vendor.URL),
+			WithHTTPClient(vendor.Client()),
+			WithLoggerDisabled(),
+			WithTLSServerName(vendor.Listener.Addr().String(), "vendor.invalid"),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		assert.Error(t, err)
+		_, err = client.Get(context.Background(), other.URL+"/", nil)
+		require.NoError(t, err)
+		assert.Empty(t, serverName)
+	})
+
+	t.Run("rejects empty server name", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithTLSServerName("10.0.0.1", ""))
+		assert.Error(t, err)
+	})
+}
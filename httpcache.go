@@ -0,0 +1,354 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHeuristicFreshness caps the freshness derived from Last-Modified for
+// responses without explicit expiry.
+const maxHeuristicFreshness = 24 * time.Hour
+
+// httpCache is the client's RFC 7234 private cache.
+type httpCache struct {
+	store        CacheStore
+	revalidating sync.Map // cache key -> struct{}, one revalidation at a time
+
+	// alwaysRevalidate sends every request, conditional on the cached
+	// validators, instead of serving fresh copies (see WithConditionalGet).
+	alwaysRevalidate bool
+}
+
+// WithHTTPCache caches GET responses in store following RFC 7234, as a
+// private cache. Fresh responses, per Cache-Control max-age or Expires, or
+// by default a tenth of the time since Last-Modified up to a day, are
+// served without contacting the server and are marked with
+// Response.FromCache. Stale responses carrying an ETag or Last-Modified are
+// revalidated with a conditional request and served from the cache on 304.
+// Within a stale-while-revalidate window the stale copy is served, marked
+// Stale, while it is revalidated in the background. no-store and no-cache
+// are honored on requests and responses. Responses that Vary on anything
+// but Accept-Encoding are not stored, and successful POST, PUT, PATCH and
+// DELETE requests evict the cached copy of their URL. Entries are keyed by
+// the credentials requests are sent with, so a store shared between
+// clients or processes never serves one caller's response to another, but
+// the store cannot also be given to WithStaleIfError. Background
+// revalidations are bounded by the client timeout. It replaces
+// WithConditionalGet.
+func WithHTTPCache(store CacheStore) ClientOption {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("cache store cannot be nil")
+		}
+		c.httpCache = &httpCache{store: store}
+		return nil
+	}
+}
+
+// fetch sends the call through the HTTP cache, if enabled.
+func (c *Client) fetch(ctx context.Context, cl *call) attemptResult {
+	if c.httpCache == nil || cl.stream != nil || cl.classification.sensitive() {
+		return c.executeWithRetry(ctx, cl)
+	}
+	if cl.method != http.MethodGet {
+		res := c.executeWithRetry(ctx, cl)
+		c.invalidateCache(ctx, cl, res)
+		return res
+	}
+
+	reqCC := parseCacheControl(cl.header)
+	key, ok := c.httpCacheKey(ctx, cl)
+	if _, noStore := reqCC["no-store"]; noStore || !ok {
+		return c.executeWithRetry(ctx, cl)
+	}
+
+	entry := c.cachedEntry(ctx, cl, key)
+	if entry != nil {
+		_, noCache := reqCC["no-cache"]
+		noCache = noCache || c.httpCache.alwaysRevalidate
+		now := time.Now()
+		lifetime, age := freshnessLifetime(entry), currentAge(entry, now)
+		switch {
+		case !noCache && age < lifetime:
+			return attemptResult{response: cachedResponse(entry, age, false)}
+		case !noCache && age < lifetime+staleWhileRevalidate(entry):
+			c.revalidateInBackground(ctx, cl, key, entry)
+			return attemptResult{response: cachedResponse(entry, age, true)}
+		}
+		setValidators(cl.header, entry)
+	}
+	return c.updateCache(ctx, cl, key, entry, c.executeWithRetry(ctx, cl))
+}
+
+// configureHTTPCache refuses to share one store between WithHTTPCache and
+// WithStaleIfError, whose entries would overwrite each other.
+func (c *Client) configureHTTPCache() error {
+	if c.httpCache == nil || c.cache == nil {
+		return nil
+	}
+	a, b := c.httpCache.store, c.cache.store
+	if t := reflect.TypeOf(a); t == reflect.TypeOf(b) && t.Comparable() && a == b {
+		return errors.New("WithHTTPCache and WithStaleIfError cannot share a cache store")
+	}
+	return nil
+}
+
+// httpCacheKey returns the cache key of the call under the credentials it
+// will be sent with. ok is false if they cannot be told, because the auth
+// provider failed; the call then bypasses the cache.
+func (c *Client) httpCacheKey(ctx context.Context, cl *call) (key string, ok bool) {
+	auth, _ := c.auth.Load().providers(time.Now())
+	if auth == nil {
+		return cacheKey(cl.method, cl.url, cl.header), true
+	}
+	req, err := c.attemptRequest(ctx, cl)
+	if err != nil {
+		return "", false
+	}
+	if err := c.applyAuth(auth, req); err != nil {
+		return "", false
+	}
+	return cacheKey(cl.method, cl.url, req.Header), true
+}
+
+// cachedEntry returns the stored response under key, or nil.
+func (c *Client) cachedEntry(ctx context.Context, cl *call, key string) *CachedResponse {
+	entry, ok, err := c.httpCache.store.Get(ctx, key)
+	if err != nil {
+		c.logCacheError(ctx, cl, err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return entry
+}
+
+// updateCache stores a cacheable response, or answers a 304 to a
+// conditional request with the refreshed cached copy.
+func (c *Client) updateCache(ctx context.Context, cl *call, key string, entry *CachedResponse, res attemptResult) attemptResult {
+	if res.err != nil {
+		return res
+	}
+	resp := res.response
+	now := time.Now()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		refreshed := *entry
+		refreshed.Headers = entry.Headers.Clone()
+		refreshed.Headers.Del("Age")
+		for key, values := range resp.Headers {
+			refreshed.Headers[key] = values
+		}
+		refreshed.StoredAt = now
+		c.setCacheEntry(ctx, cl, key, &refreshed)
+		return attemptResult{response: cachedResponse(&refreshed, 0, false), reqHeaders: res.reqHeaders}
+	}
+
+	if isStorable(resp) && (!c.httpCache.alwaysRevalidate || hasValidators(resp.Headers)) {
+		c.setCacheEntry(ctx, cl, key, &CachedResponse{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    resp.Headers.Clone(),
+			Body:       bytes.Clone(resp.Body),
+			StoredAt:   now,
+		})
+	}
+	return res
+}
+
+func (c *Client) setCacheEntry(ctx context.Context, cl *call, key string, entry *CachedResponse) {
+	if err := c.httpCache.store.Set(ctx, key, entry); err != nil {
+		c.logCacheError(ctx, cl, err)
+	}
+}
+
+// invalidateCache evicts the cached GET of a URL written by an unsafe
+// method (RFC 7234, section 4.4), as cached for the same credentials.
+func (c *Client) invalidateCache(ctx context.Context, cl *call, res attemptResult) {
+	switch cl.method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return
+	}
+	if res.err != nil {
+		return
+	}
+	if err := c.httpCache.store.Delete(ctx, cacheKey(http.MethodGet, cl.url, res.reqHeaders)); err != nil {
+		c.logCacheError(ctx, cl, err)
+	}
+}
+
+// revalidateInBackground refreshes entry without delaying the caller. Only
+// one revalidation per key runs at a time, for at most the client timeout.
+func (c *Client) revalidateInBackground(ctx context.Context, cl *call, key string, entry *CachedResponse) {
+	if _, busy := c.httpCache.revalidating.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	bg := *cl
+	bg.header = cl.header.Clone()
+	bg.tee, bg.errorResult = nil, nil
+	setValidators(bg.header, entry)
+	// The revalidation outlives the caller's request, so it keeps only
+	// ctx's values.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	go func() {
+		defer cancel()
+		defer c.httpCache.revalidating.Delete(key)
+		defer c.releaseBuffers(&bg)
+		res := c.updateCache(ctx, &bg, key, entry, c.executeWithRetry(ctx, &bg))
+		if res.err != nil && c.logEnabled(ctx, slog.LevelWarn) {
+			c.logger.Log(ctx, slog.LevelWarn, "http_cache_revalidation_failed",
+				slog.String("url", bg.url),
+				slog.String("error", res.err.Error()),
+			)
+		}
+	}()
+}
+
+// cachedResponse builds the response served from entry.
+func cachedResponse(entry *CachedResponse, age time.Duration, stale bool) *Response {
+	headers := entry.Headers.Clone()
+	headers.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return &Response{
+		StatusCode: entry.StatusCode,
+		Status:     entry.Status,
+		Headers:    headers,
+		Body:       bytes.Clone(entry.Body),
+		FromCache:  true,
+		Stale:      stale,
+	}
+}
+
+// setValidators makes the request conditional on the cached copy.
+func setValidators(header http.Header, entry *CachedResponse) {
+	if etag := entry.Headers.Get("ETag"); etag != "" && header.Get("If-None-Match") == "" {
+		header.Set("If-None-Match", etag)
+	}
+	if modified := entry.Headers.Get("Last-Modified"); modified != "" && header.Get("If-Modified-Since") == "" {
+		header.Set("If-Modified-Since", modified)
+	}
+}
+
+// isStorable reports whether a GET response may be cached.
+func isStorable(resp *Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusPermanentRedirect,
+		http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+	if resp.FromFallback || resp.Stale || resp.FromCache {
+		return false
+	}
+	if _, ok := parseCacheControl(resp.Headers)["no-store"]; ok {
+		return false
+	}
+	if !variesOnlyByEncoding(resp.Headers) {
+		return false
+	}
+	h := resp.Headers
+	return h.Get("Cache-Control") != "" || h.Get("Expires") != "" || hasValidators(h)
+}
+
+// hasValidators reports whether h allows a conditional request.
+func hasValidators(h http.Header) bool {
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// freshnessLifetime returns how long entry is fresh (RFC 7234, section 4.2.1).
+func freshnessLifetime(entry *CachedResponse) time.Duration {
+	cc := parseCacheControl(entry.Headers)
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+	if seconds, ok := cacheSeconds(cc, "max-age"); ok {
+		return seconds
+	}
+
+	date, err := http.ParseTime(entry.Headers.Get("Date"))
+	if err != nil {
+		date = entry.StoredAt
+	}
+	if expires := entry.Headers.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0 // invalid dates mean already expired
+		}
+		return max(t.Sub(date), 0)
+	}
+	if modified, err := http.ParseTime(entry.Headers.Get("Last-Modified")); err == nil {
+		return min(max(date.Sub(modified)/10, 0), maxHeuristicFreshness)
+	}
+	return 0
+}
+
+// currentAge returns the age of entry at now (RFC 7234, section 4.2.3).
+func currentAge(entry *CachedResponse, now time.Time) time.Duration {
+	age := time.Duration(0)
+	if date, err := http.ParseTime(entry.Headers.Get("Date")); err == nil {
+		age = max(entry.StoredAt.Sub(date), 0)
+	}
+	if header, ok := deltaSeconds(entry.Headers.Get("Age")); ok {
+		age = max(age, header)
+	}
+	return age + max(now.Sub(entry.StoredAt), 0)
+}
+
+// staleWhileRevalidate returns how long past its lifetime entry may be
+// served while it is revalidated (RFC 5861).
+func staleWhileRevalidate(entry *CachedResponse) time.Duration {
+	cc := parseCacheControl(entry.Headers)
+	if _, ok := cc["must-revalidate"]; ok {
+		return 0
+	}
+	seconds, _ := cacheSeconds(cc, "stale-while-revalidate")
+	return seconds
+}
+
+// parseCacheControl returns the Cache-Control directives in h by lowercase
+// name, with unquoted values.
+func parseCacheControl(h http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return directives
+}
+
+// cacheSeconds returns a delta-seconds directive as a duration.
+func cacheSeconds(cc map[string]string, name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	return deltaSeconds(value)
+}
+
+// deltaSeconds parses a delta-seconds value, such as an Age header, as a
+// duration.
+func deltaSeconds(value string) (time.Duration, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	// Larger values mean "forever" and are capped (RFC 7234, section 1.2.1).
+	return time.Duration(min(seconds, math.MaxInt32)) * time.Second, true
+}
@@ -0,0 +1,220 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCachingServer serves body with the given headers, answering 304 to
+// matching If-None-Match requests.
+func newCachingServer(body string, headers map[string]string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if etag := headers["ETag"]; etag != "" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	return server, &calls
+}
+
+func newHTTPCacheClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+	store, err := NewMemoryCacheStore(10)
+	require.NoError(t, err)
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithHTTPCache(store))
+	require.NoError(t, err)
+	return client
+}
+
+func TestWithHTTPCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves fresh responses from cache", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60"})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		first, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		assert.False(t, first.FromCache)
+
+		var result struct{ ID int }
+		second, err := client.Get(ctx, "/users/1", &result)
+		require.NoError(t, err)
+		assert.True(t, second.FromCache)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, "0", second.Headers.Get("Age"))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("revalidates stale responses", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "no-cache", "ETag": `"v1"`})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		_, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+
+		assert.True(t, resp.FromCache)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"id":1}`, resp.String())
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("serves stale while revalidating", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{
+			"Cache-Control": "max-age=0, stale-while-revalidate=60",
+			"ETag":          `"v1"`,
+		})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		_, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+
+		assert.True(t, resp.FromCache)
+		assert.True(t, resp.Stale)
+		assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("honors no-store", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60, no-store"})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		for range 2 {
+			resp, err := client.Get(ctx, "/users/1", nil)
+			require.NoError(t, err)
+			assert.False(t, resp.FromCache)
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("request no-cache forces revalidation", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60"})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		_, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/users/1", nil, WithRequestHeader("Cache-Control", "no-cache"))
+		require.NoError(t, err)
+		assert.False(t, resp.FromCache)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("writes evict the cached copy", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60"})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		_, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		_, err = client.Put(ctx, "/users/1", map[string]int{"id": 1}, nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.FromCache)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("skips responses that vary", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60", "Vary": "Authorization"})
+		defer server.Close()
+		client := newHTTPCacheClient(t, server)
+
+		_, _ = client.Get(ctx, "/users/1", nil)
+		_, _ = client.Get(ctx, "/users/1", nil)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("keys entries by credentials", func(t *testing.T) {
+		server, calls := newCachingServer(`{"id":1}`, map[string]string{"Cache-Control": "max-age=60"})
+		defer server.Close()
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		alice, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithHTTPCache(store), WithAuth(BearerAuth("alice")))
+		require.NoError(t, err)
+		bob, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithHTTPCache(store), WithAuth(BearerAuth("bob")))
+		require.NoError(t, err)
+
+		_, err = alice.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		resp, err := bob.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.FromCache)
+		resp, err = alice.Get(ctx, "/users/1", nil)
+		require.NoError(t, err)
+		assert.True(t, resp.FromCache)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("rejects nil store", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithHTTPCache(nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects sharing a store with WithStaleIfError", func(t *testing.T) {
+		store, err := NewMemoryCacheStore(10)
+		require.NoError(t, err)
+		_, err = New(WithBaseURL("https://api.example.com"), WithHTTPCache(store), WithStaleIfError(store, time.Minute))
+		assert.ErrorContains(t, err, "cannot share a cache store")
+	})
+}
+
+func TestFreshnessLifetime(t *testing.T) {
+	stored := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(headers map[string]string) *CachedResponse {
+		h := make(http.Header)
+		for key, value := range headers {
+			h.Set(key, value)
+		}
+		return &CachedResponse{Headers: h, StoredAt: stored}
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{"max-age", map[string]string{"Cache-Control": "public, max-age=120"}, 2 * time.Minute},
+		{"max-age wins over expires", map[string]string{"Cache-Control": "max-age=5", "Expires": stored.Add(time.Hour).Format(http.TimeFormat)}, 5 * time.Second},
+		{"expires", map[string]string{"Date": stored.Format(http.TimeFormat), "Expires": stored.Add(time.Hour).Format(http.TimeFormat)}, time.Hour},
+		{"invalid expires", map[string]string{"Expires": "0"}, 0},
+		{"heuristic", map[string]string{"Date": stored.Format(http.TimeFormat), "Last-Modified": stored.Add(-10 * time.Hour).Format(http.TimeFormat)}, time.Hour},
+		{"no-cache", map[string]string{"Cache-Control": "no-cache, max-age=60"}, 0},
+		{"none", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, freshnessLifetime(entry(tt.headers)))
+		})
+	}
+
+	t.Run("current age counts Age and residence", func(t *testing.T) {
+		e := entry(map[string]string{"Age": "30"})
+		assert.Equal(t, 40*time.Second, currentAge(e, stored.Add(10*time.Second)))
+	})
+}
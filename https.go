@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInsecureURL is returned when WithHTTPSOnly refuses a plain HTTP URL.
+var ErrInsecureURL = errors.New("insecure URL refused")
+
+// httpsOnly holds the WithHTTPSOnly policy.
+type httpsOnly struct {
+	insecureHosts map[string]bool
+}
+
+// HTTPSOnlyOption configures WithHTTPSOnly.
+type HTTPSOnlyOption func(*httpsOnly) error
+
+// AllowInsecureHosts exempts the named hosts, e.g. "localhost", from
+// WithHTTPSOnly. Hosts are matched without their port.
+func AllowInsecureHosts(hosts ...string) HTTPSOnlyOption {
+	return func(p *httpsOnly) error {
+		for _, host := range hosts {
+			if host == "" {
+				return errors.New("insecure host cannot be empty")
+			}
+			p.insecureHosts[strings.ToLower(host)] = true
+		}
+		return nil
+	}
+}
+
+// WithHTTPSOnly refuses to talk plain HTTP: http:// base URLs are rejected
+// when the client is built, and requests or redirects to http:// URLs fail,
+// so an https:// redirect chain cannot be downgraded.
+func WithHTTPSOnly(opts ...HTTPSOnlyOption) ClientOption {
+	return func(c *Client) error {
+		policy := &httpsOnly{insecureHosts: make(map[string]bool)}
+		for _, opt := range opts {
+			if err := opt(policy); err != nil {
+				return err
+			}
+		}
+		c.httpsOnly = policy
+		return nil
+	}
+}
+
+// check returns an error if u may not be requested under the policy.
+func (p *httpsOnly) check(u *url.URL) error {
+	if p == nil || strings.EqualFold(u.Scheme, "https") || p.insecureHosts[strings.ToLower(u.Hostname())] {
+		return nil
+	}
+	return fmt.Errorf("%w: https-only client cannot request %s", ErrInsecureURL, u.Redacted())
+}
+
+// configureHTTPSOnly validates the base URLs and guards redirects.
+func (c *Client) configureHTTPSOnly() error {
+	if c.httpsOnly == nil {
+		return nil
+	}
+	if c.baseURL != nil {
+		if err := c.httpsOnly.check(c.baseURL); err != nil {
+			return err
+		}
+	}
+	for _, base := range c.baseURLs() {
+		if err := c.httpsOnly.check(base); err != nil {
+			return err
+		}
+	}
+	if c.shadow != nil {
+		if err := c.httpsOnly.check(c.shadow.baseURL); err != nil {
+			return fmt.Errorf("shadow base URL: %w", err)
+		}
+	}
+
+	policy := c.httpsOnly
+	next := c.httpClient.CheckRedirect
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := policy.check(req.URL); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return defaultCheckRedirect(via)
+	}
+	c.httpClient = &httpClient
+	return nil
+}
+
+// defaultCheckRedirect mirrors net/http's limit of 10 redirects.
+func defaultCheckRedirect(via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
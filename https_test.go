@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHTTPSOnly(t *testing.T) {
+	t.Run("rejects http base URL", func(t *testing.T) {
+		_, err := New(WithBaseURL("http://api.example.com"), WithHTTPSOnly())
+
+		require.ErrorIs(t, err, ErrInsecureURL)
+	})
+
+	t.Run("allows exempt hosts", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithHTTPSOnly(AllowInsecureHosts("127.0.0.1")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/health", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("refuses redirect downgrade without retrying", func(t *testing.T) {
+		var plainHits atomic.Int32
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plainHits.Add(1)
+		}))
+		defer plain.Close()
+
+		var secureHits atomic.Int32
+		secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secureHits.Add(1)
+			http.Redirect(w, r, plain.URL+"/landing", http.StatusFound)
+		}))
+		defer secure.Close()
+
+		client, err := New(
+			WithBaseURL(secure.URL),
+			WithHTTPClient(secure.Client()),
+			WithLoggerDisabled(),
+			WithHTTPSOnly(),
+			WithRetry(&RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/start", nil)
+
+		require.ErrorIs(t, err, ErrInsecureURL)
+		assert.Equal(t, int32(0), plainHits.Load())
+		assert.Equal(t, int32(1), secureHits.Load())
+	})
+
+	t.Run("keeps existing redirect policy", func(t *testing.T) {
+		secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/start" {
+				http.Redirect(w, r, "/next", http.StatusFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer secure.Close()
+
+		httpClient := secure.Client()
+		httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		client, err := New(WithBaseURL(secure.URL), WithHTTPClient(httpClient), WithLoggerDisabled(), WithHTTPSOnly())
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/start", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+	})
+
+	t.Run("returns error for empty exempt host", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithHTTPSOnly(AllowInsecureHosts("")))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insecure host cannot be empty")
+	})
+}
@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IdempotencyStore records claimed idempotency keys so that a write carrying
+// the same key is performed at most once, even across client replicas when
+// the store is shared. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Reserve claims key for ttl. It returns false if the key is already claimed.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release frees key so the write may be attempted again.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	sweepAt int // size at which expired keys are next removed
+}
+
+// NewMemoryIdempotencyStore creates an empty in-process store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{expires: make(map[string]time.Time)}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, errors.New("idempotency key ttl must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if exp, ok := s.expires[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+	if len(s.expires) >= s.sweepAt {
+		maps.DeleteFunc(s.expires, func(_ string, exp time.Time) bool { return !now.Before(exp) })
+		s.sweepAt = 2*len(s.expires) + 64
+	}
+	s.expires[key] = now.Add(ttl)
+	return true, nil
+}
+
+// ErrDuplicateWrite is returned for a write whose idempotency key another
+// request, possibly from another replica, has already claimed.
+var ErrDuplicateWrite = errors.New("idempotency key already used")
+
+// idempotency is the configuration of WithIdempotencyStore.
+type idempotency struct {
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+// WithIdempotencyStore claims the idempotency key of each POST or PATCH
+// made with WithIdempotencyKey in store for ttl before sending it, so that
+// replicas sharing the store perform the write at most once. A write whose
+// key is already claimed fails with ErrDuplicateWrite without being sent;
+// one that fails releases its key so it may be tried again. Keys the
+// client generates itself are unique to a call and are not claimed.
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) ClientOption {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("idempotency store cannot be nil")
+		}
+		if ttl <= 0 {
+			return errors.New("idempotency key ttl must be positive")
+		}
+		c.idempotency = &idempotency{store: store, ttl: ttl}
+		return nil
+	}
+}
+
+// dedupedFetch fetches the call once its idempotency key is claimed in the
+// store of WithIdempotencyStore.
+func (c *Client) dedupedFetch(ctx context.Context, cl *call) attemptResult {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	if c.idempotency == nil || key == "" || isIdempotentMethod(cl.method) {
+		return c.fetch(ctx, cl)
+	}
+
+	claimed, err := c.idempotency.store.Reserve(ctx, key, c.idempotency.ttl)
+	if err == nil && !claimed {
+		err = ErrDuplicateWrite
+	}
+	if err != nil {
+		return attemptResult{err: c.wrapError(err, cl.method, cl.url)}
+	}
+
+	res := c.fetch(ctx, cl)
+	if res.err != nil {
+		// The call may have failed because ctx ended.
+		if err := c.idempotency.store.Release(context.WithoutCancel(ctx), key); err != nil && c.logEnabled(ctx, slog.LevelWarn) {
+			c.logger.Log(ctx, slog.LevelWarn, "http_idempotency_error",
+				slog.String("method", cl.method),
+				slog.String("url", cl.url),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return res
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, key)
+	return nil
+}
+
+// IdempotencyKeyHeader is the header IdempotencyKeyMiddleware sets.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKeyKey is the context key for idempotency keys.
+type idempotencyKeyKey struct{}
+
+// callIdempotencyKey is the key shared by the attempts of one call. It is
+// generated when an attempt first needs it.
+type callIdempotencyKey struct {
+	once sync.Once
+	key  string
+}
+
+// WithIdempotencyKey sets the idempotency key IdempotencyKeyMiddleware
+// sends for requests made with ctx, e.g. to reuse a key derived from the
+// caller's own operation ID.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// GetIdempotencyKey returns the idempotency key for ctx, or "" if none is
+// set. Within a POST or PATCH sent by the client, it returns the key shared
+// by all attempts, generating it on first use.
+func GetIdempotencyKey(ctx context.Context) string {
+	switch key := ctx.Value(idempotencyKeyKey{}).(type) {
+	case string:
+		return key
+	case *callIdempotencyKey:
+		key.once.Do(func() { key.key = randomUUID() })
+		return key.key
+	}
+	return ""
+}
+
+// IdempotencyKeyMiddleware sends an Idempotency-Key header with POST and
+// PATCH requests that do not set one. Every attempt of a request carries
+// the same key, so a server that honors the header performs the write at
+// most once and the client may retry it after a network failure.
+func IdempotencyKeyMiddleware() Middleware {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		if isIdempotentMethod(req.Method) || req.Header.Get(IdempotencyKeyHeader) != "" {
+			return next(req)
+		}
+		if key := GetIdempotencyKey(req.Context()); key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		return next(req)
+	}
+}
+
+// isIdempotentMethod reports whether repeating a request with method has
+// the same effect as sending it once (RFC 9110, section 9.2.2).
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// withCallIdempotencyKey gives the attempts of a non-idempotent call a
+// shared idempotency key unless the caller set one.
+func withCallIdempotencyKey(ctx context.Context, cl *call) context.Context {
+	if isIdempotentMethod(cl.method) || ctx.Value(idempotencyKeyKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyKey{}, &callIdempotencyKey{})
+}
+
+// traceWrites records in the returned flag whether the request was
+// written, when a network failure of the call is only retried if it was
+// not. It returns ctx unchanged and a nil flag otherwise.
+func (c *Client) traceWrites(ctx context.Context, cl *call) (context.Context, *atomic.Bool) {
+	if c.retryPolicy == nil || c.retryPolicy.RetryNonIdempotent || isIdempotentMethod(cl.method) {
+		return ctx, nil
+	}
+	written := new(atomic.Bool)
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { written.Store(true) },
+	}
+	return httptrace.WithClientTrace(ctx, trace), written
+}
+
+// retryNetworkError reports whether a failed send of req may be retried.
+// A non-idempotent request that was written without an idempotency key is
+// not, since the server may have acted on it, and neither is a recovered
+// panic, which would only panic again.
+func (c *Client) retryNetworkError(req *http.Request, written *atomic.Bool, err error) bool {
+	if c.retryPolicy == nil || errors.Is(err, ErrInsecureURL) || internalKind(err, ErrKindUnknown) == ErrKindInternal {
+		return false
+	}
+	if written != nil && written.Load() && req.Header.Get(IdempotencyKeyHeader) == "" {
+		return false
+	}
+	return c.shouldRetryResponse(req.Context(), nil, err)
+}
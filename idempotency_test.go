@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reserves a key once", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore()
+
+		ok, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("allows reuse after release or expiry", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore()
+
+		_, _ = store.Reserve(ctx, "released", time.Minute)
+		require.NoError(t, store.Release(ctx, "released"))
+		ok, _ := store.Reserve(ctx, "released", time.Minute)
+		assert.True(t, ok)
+
+		_, _ = store.Reserve(ctx, "expired", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		ok, _ = store.Reserve(ctx, "expired", time.Minute)
+		assert.True(t, ok)
+	})
+
+	t.Run("validates ttl", func(t *testing.T) {
+		_, err := NewMemoryIdempotencyStore().Reserve(ctx, "key", 0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ttl must be positive")
+	})
+}
+
+func TestWithIdempotencyStore(t *testing.T) {
+	var status, hits atomic.Int32
+	status.Store(http.StatusCreated)
+	server := newCountingServer(&status, &hits)
+	defer server.Close()
+
+	store := NewMemoryIdempotencyStore()
+	newClient := func() *Client {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithIdempotencyStore(store, time.Minute))
+		require.NoError(t, err)
+		return client
+	}
+	replicaA, replicaB := newClient(), newClient()
+
+	t.Run("performs a keyed write once across clients", func(t *testing.T) {
+		hits.Store(0)
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+		_, err := replicaA.Post(ctx, "/orders", map[string]int{"id": 1}, nil)
+		require.NoError(t, err)
+
+		_, err = replicaB.Post(ctx, "/orders", map[string]int{"id": 1}, nil)
+		assert.ErrorIs(t, err, ErrDuplicateWrite)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("releases the key of a failed write", func(t *testing.T) {
+		hits.Store(0)
+		ctx := WithIdempotencyKey(context.Background(), "order-2")
+		status.Store(http.StatusInternalServerError)
+		_, err := replicaA.Post(ctx, "/orders", nil, nil)
+		require.Error(t, err)
+
+		status.Store(http.StatusCreated)
+		_, err = replicaB.Post(ctx, "/orders", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("does not claim generated keys or idempotent methods", func(t *testing.T) {
+		hits.Store(0)
+		for range 2 {
+			_, err := replicaA.Post(context.Background(), "/orders", nil, nil)
+			require.NoError(t, err)
+			_, err = replicaA.Put(WithIdempotencyKey(context.Background(), "order-3"), "/orders/3", nil, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(4), hits.Load())
+	})
+
+	t.Run("validates options", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithIdempotencyStore(nil, time.Minute))
+		assert.Error(t, err)
+		_, err = New(WithBaseURL(server.URL), WithIdempotencyStore(store, 0))
+		assert.Error(t, err)
+	})
+}
+
+// newDroppingServer reads each request and then drops the connection
+// without answering until failures requests have been dropped. It records
+// the Idempotency-Key of every request.
+func newDroppingServer(t *testing.T, failures int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		drop := len(keys) <= failures
+		mu.Unlock()
+		if !drop {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestNonIdempotentRetries(t *testing.T) {
+	policy := func() *RetryPolicy {
+		return &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	}
+	ctx := context.Background()
+
+	t.Run("does not retry written POST by default", func(t *testing.T) {
+		server, keys := newDroppingServer(t, 1)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy()))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/orders", map[string]int{"qty": 1}, nil)
+		require.Error(t, err)
+		assert.Equal(t, []string{""}, keys())
+	})
+
+	t.Run("retries idempotent methods", func(t *testing.T) {
+		server, keys := newDroppingServer(t, 1)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy()))
+		require.NoError(t, err)
+
+		_, err = client.Put(ctx, "/orders/1", map[string]int{"qty": 1}, nil)
+		require.NoError(t, err)
+		assert.Len(t, keys(), 2)
+	})
+
+	t.Run("RetryNonIdempotent allows retry", func(t *testing.T) {
+		server, keys := newDroppingServer(t, 1)
+		defer server.Close()
+
+		retry := policy()
+		retry.RetryNonIdempotent = true
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(retry))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/orders", map[string]int{"qty": 1}, nil)
+		require.NoError(t, err)
+		assert.Len(t, keys(), 2)
+	})
+
+	t.Run("retries with one idempotency key", func(t *testing.T) {
+		server, keys := newDroppingServer(t, 2)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy()),
+			WithMiddleware(IdempotencyKeyMiddleware()))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/orders", map[string]int{"qty": 1}, nil)
+		require.NoError(t, err)
+		sent := keys()
+		require.Len(t, sent, 3)
+		assert.NotEmpty(t, sent[0])
+		assert.Equal(t, []string{sent[0], sent[0], sent[0]}, sent)
+	})
+}
+
+func TestIdempotencyKeyMiddleware(t *testing.T) {
+	var got []string
+	next := func(req *http.Request) (*http.Response, error) {
+		got = append(got, req.Header.Get(IdempotencyKeyHeader))
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}
+	middleware := IdempotencyKeyMiddleware()
+	send := func(ctx context.Context, method string, header http.Header) {
+		req, err := http.NewRequestWithContext(ctx, method, "http://api.example.com", nil)
+		require.NoError(t, err)
+		if header != nil {
+			req.Header = header
+		}
+		_, err = middleware(req, next)
+		require.NoError(t, err)
+	}
+
+	ctx := WithIdempotencyKey(context.Background(), "op-1")
+	send(ctx, http.MethodPost, nil)
+	send(ctx, http.MethodPatch, http.Header{IdempotencyKeyHeader: {"caller"}})
+	send(ctx, http.MethodGet, nil)
+	send(context.Background(), http.MethodPost, nil)
+
+	assert.Equal(t, []string{"op-1", "caller", "", ""}, got)
+}
@@ -0,0 +1,128 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidHost is returned when a URL's host is neither a valid domain
+// name nor an IP address.
+var ErrInvalidHost = errors.New("invalid host")
+
+// NormalizeHost returns host, with an optional port, in the ASCII form sent
+// on the wire: mapped as UTS #46 does for lookup, so it is lowercased,
+// fullwidth and decomposed forms are folded, and internationalized labels
+// are converted to punycode. "Bücher.example:8443" becomes
+// "xn--bcher-kva.example:8443".
+// IP addresses are returned as they are. It fails with ErrInvalidHost,
+// naming the offending label, for hosts the transport could not dial, and
+// is applied to every base URL and request URL; call it to validate
+// customer-supplied endpoints up front.
+func NormalizeHost(host string) (string, error) {
+	u := url.URL{Host: host}
+	hostname, port := u.Hostname(), u.Port()
+	if port == "" && strings.HasSuffix(host, ":") {
+		return "", fmt.Errorf("%w %q: invalid port", ErrInvalidHost, host)
+	}
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return host, nil
+	}
+
+	ascii, err := asciiDomain(hostname)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", ErrInvalidHost, host, err)
+	}
+	if port != "" {
+		return net.JoinHostPort(ascii, port), nil
+	}
+	return ascii, nil
+}
+
+// normalizeURLHost replaces u's host with its NormalizeHost form.
+func normalizeURLHost(u *url.URL) error {
+	if u.Host == "" {
+		return nil
+	}
+	host, err := NormalizeHost(u.Host)
+	if err != nil {
+		return err
+	}
+	u.Host = host
+	return nil
+}
+
+// idnaMapping applies the UTS #46 lookup mapping without its label checks,
+// which asciiLabel makes instead so that underscores stay allowed.
+var idnaMapping = idna.New(
+	idna.MapForLookup(),
+	idna.Transitional(false),
+	idna.StrictDomainName(false),
+	idna.ValidateLabels(false),
+)
+
+// asciiDomain converts a domain name to lowercase ASCII, label by label.
+func asciiDomain(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", errors.New("not valid UTF-8")
+	}
+	fqdn := strings.HasSuffix(name, ".")
+	mapped, err := idnaMapping.ToUnicode(strings.TrimSuffix(name, "."))
+	if err != nil {
+		return "", err
+	}
+	labels := strings.Split(mapped, ".")
+	for i, label := range labels {
+		ascii, err := asciiLabel(label)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = ascii
+	}
+
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > 253 {
+		return "", errors.New("longer than 253 bytes")
+	}
+	if fqdn {
+		ascii += "."
+	}
+	return ascii, nil
+}
+
+// asciiLabel validates one label, converting it to punycode if it is not
+// ASCII. Underscores are allowed, as in many internal host names.
+func asciiLabel(label string) (string, error) {
+	if label == "" {
+		return "", errors.New("empty label")
+	}
+	if len(label) > 253 {
+		return "", fmt.Errorf("label %q is too long", label)
+	}
+	for _, r := range label {
+		valid := r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') ||
+			(r >= utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)))
+		if !valid {
+			return "", fmt.Errorf("label %q contains %q", label, r)
+		}
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return "", fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+
+	ascii, err := idna.Punycode.ToASCII(label)
+	if err != nil {
+		return "", err
+	}
+	if len(ascii) > 63 {
+		return "", fmt.Errorf("label %q is longer than 63 bytes", ascii)
+	}
+	return ascii, nil
+}
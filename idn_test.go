@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	valid := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "api.example.com"},
+		{"API.Example.COM", "api.example.com"},
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"Bücher.example:8443", "xn--bcher-kva.example:8443"},
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"mu\u0308nchen.de", "xn--mnchen-3ya.de"},
+		{"ＥＸＡＭＰＬＥ.com", "example.com"},
+		{"他们为什么不说中文.example", "xn--ihqwcrb4cv8a8dqg056pqjye.example"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example"},
+		{"my_service.internal", "my_service.internal"},
+		{"example.com.", "example.com."},
+		{"127.0.0.1:8080", "127.0.0.1:8080"},
+		{"[::1]:443", "[::1]:443"},
+		{"[fe80::1%25en0]", "[fe80::1%25en0]"},
+	}
+	for _, tt := range valid {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := NormalizeHost(tt.host)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	invalid := []struct {
+		host string
+		want string
+	}{
+		{"bad host.example", `contains ' '`},
+		{"api..example.com", "empty label"},
+		{"-api.example.com", "hyphen"},
+		{"api.example.com:https", `contains ':'`},
+		{"api.example.com:", "invalid port"},
+		{strings.Repeat("a", 64) + ".example", "longer than 63 bytes"},
+		{strings.Repeat("ü", 60) + ".example", "longer than 63 bytes"},
+		{strings.Repeat("a.", 127) + "example", "longer than 253 bytes"},
+		{"api\x00.example", `contains '\x00'`},
+		{"shop☃.example", `contains '☃'`},
+		{"xn--zz.example", `invalid label "zz"`},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects "+tt.host, func(t *testing.T) {
+			_, err := NormalizeHost(tt.host)
+			require.ErrorIs(t, err, ErrInvalidHost)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestInternationalizedURLs(t *testing.T) {
+	t.Run("normalizes base URL", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://Bücher.example/api"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://xn--bcher-kva.example/api", client.baseURL.String())
+	})
+
+	t.Run("rejects invalid base URL host", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://bad_host!.example"))
+		require.ErrorIs(t, err, ErrInvalidHost)
+
+		_, err = New(WithBaseURLs("https://api.example.com", "https://backup..example.com"))
+		require.ErrorIs(t, err, ErrInvalidHost)
+
+		_, err = New(WithBalancer(RoundRobin(), "https://api.example.com", "https://-eu.example.com"))
+		require.ErrorIs(t, err, ErrInvalidHost)
+	})
+
+	t.Run("normalizes request URLs", func(t *testing.T) {
+		var host string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithHostMapping(map[string]string{
+			"xn--bcher-kva.example": "127.0.0.1",
+		}))
+		require.NoError(t, err)
+
+		port := server.URL[strings.LastIndex(server.URL, ":"):]
+		_, err = client.Get(context.Background(), "http://Bücher.example"+port+"/items", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "xn--bcher-kva.example"+port, host)
+
+		_, err = client.Get(context.Background(), "/items", nil, WithRequestBaseURL("http://b ad.example"))
+		require.Error(t, err)
+	})
+
+	t.Run("matches allowed hosts in either form", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithAllowedHosts("bücher.example", "*.xn--mnchen-3ya.de"),
+		)
+		require.NoError(t, err)
+
+		for _, path := range []string{"https://xn--bcher-kva.example/a", "https://shop.münchen.de/a"} {
+			_, err := client.requestURL(path, newRequestConfig())
+			require.NoError(t, err, path)
+		}
+		_, err = client.requestURL("https://bucher.example/a", newRequestConfig())
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+	})
+
+	t.Run("rejects invalid allowed host", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAllowedHosts("*.bad host"))
+		require.ErrorIs(t, err, ErrInvalidHost)
+	})
+}
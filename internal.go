@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Defaults applied by NewInternal.
+const (
+	DefaultInternalTimeout = 5 * time.Second
+	DefaultInternalScheme  = "http"
+)
+
+// NewInternal creates a client for service-to-service calls to serviceName
+// with internal defaults: a 5s deadline per request, quick retries of
+// network errors, 502 and 503, request ID and W3C traceparent propagation,
+// strict JSON decoding, and logs without bodies. The base URL defaults to
+// http://<serviceName>, the service's cluster DNS name. opts are applied
+// after the defaults and override them, so WithTimeout changes the
+// deadline; WithRequestTimeout overrides it for a single request.
+func NewInternal(serviceName string, opts ...ClientOption) (*Client, error) {
+	if serviceName == "" {
+		return nil, errors.New("service name cannot be empty")
+	}
+
+	logBodyConfig := DefaultLogBodyConfig()
+	logBodyConfig.Omit = true
+	defaults := []ClientOption{
+		WithBaseURL(DefaultInternalScheme + "://" + serviceName),
+		WithTimeout(DefaultInternalTimeout),
+		withTimeoutPerRequest(),
+		WithRetry(&RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: 50 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2.0,
+			Jitter:       0.2,
+			RetryableStatusCodes: []int{
+				http.StatusBadGateway,
+				http.StatusServiceUnavailable,
+			},
+		}),
+		WithMiddleware(RequestIDMiddleware("X-Request-ID")),
+		WithMiddleware(TracePropagationMiddleware()),
+		WithStrictJSON(),
+		WithLogBodyConfig(logBodyConfig),
+		WithThirdPartyCode(serviceName),
+	}
+	return New(append(defaults, opts...)...)
+}
+
+// withTimeoutPerRequest makes the client timeout bound every request that
+// does not set its own with WithRequestTimeout.
+func withTimeoutPerRequest() ClientOption {
+	return func(c *Client) error {
+		c.timeoutPerRequest = true
+		return nil
+	}
+}
+
+// WithStrictJSON makes decoding into a result fail on fields the result
+// type does not declare and on trailing data, surfacing contract drift
+// between services instead of silently dropping data.
+func WithStrictJSON() ClientOption {
+	return func(c *Client) error {
+		c.strictJSON = true
+		return nil
+	}
+}
+
+// decodeJSON decodes a response body into result.
+func (c *Client) decodeJSON(response *Response, result any) error {
+	if !c.strictJSON {
+		return response.JSON(result)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(response.Body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(result); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
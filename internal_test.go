@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInternal(t *testing.T) {
+	t.Run("applies internal defaults", func(t *testing.T) {
+		client, err := NewInternal("billing")
+		require.NoError(t, err)
+
+		assert.Equal(t, "http://billing", client.baseURL.String())
+		assert.Equal(t, DefaultInternalTimeout, client.timeout)
+		assert.Equal(t, "billing", client.thirdPartyCode)
+		assert.True(t, client.strictJSON)
+		assert.True(t, client.logBodyConfig.Omit)
+		require.NotNil(t, client.retryPolicy)
+		assert.Equal(t, 3, client.retryPolicy.MaxAttempts)
+		assert.True(t, client.retryPolicy.ShouldRetry(http.StatusBadGateway))
+		assert.False(t, client.retryPolicy.ShouldRetry(http.StatusTooManyRequests))
+	})
+
+	t.Run("propagates trace context, retries and omits bodies", func(t *testing.T) {
+		var calls atomic.Int32
+		var traceparent, requestID string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			traceparent = r.Header.Get("traceparent")
+			requestID = r.Header.Get("X-Request-ID")
+			_, _ = w.Write([]byte(`{"id":7}`))
+		}))
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := NewInternal("billing", WithBaseURL(server.URL), WithLogger(logger))
+		require.NoError(t, err)
+
+		tp := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		ctx := WithRequestID(WithTraceParent(context.Background(), tp), "req-1")
+		var result struct{ ID int }
+		_, err = client.Get(ctx, "/invoices/7", &result)
+
+		require.NoError(t, err)
+		assert.Equal(t, 7, result.ID)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, tp, traceparent)
+		assert.Equal(t, "req-1", requestID)
+		require.NotEmpty(t, logger.entries)
+		for _, entry := range logger.entries {
+			assert.NotContains(t, entry.Attrs, "response_body")
+		}
+	})
+
+	t.Run("bounds each request by the timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		client, err := NewInternal("billing", WithBaseURL(server.URL), WithLoggerDisabled(),
+			WithTimeout(20*time.Millisecond), WithRetry(NoRetry()))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/slow", nil)
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.True(t, clientErr.IsTimeout())
+	})
+
+	t.Run("options override defaults", func(t *testing.T) {
+		client, err := NewInternal("billing", WithTimeout(time.Second), WithRetry(NoRetry()))
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Second, client.timeout)
+		assert.Equal(t, 1, client.retryPolicy.MaxAttempts)
+	})
+
+	t.Run("rejects empty service name", func(t *testing.T) {
+		_, err := NewInternal("")
+		assert.Error(t, err)
+	})
+}
+
+func TestWithStrictJSON(t *testing.T) {
+	newClient := func(t *testing.T, body string) *Client {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStrictJSON())
+		require.NoError(t, err)
+		return client
+	}
+
+	var result struct{ ID int }
+
+	_, err := newClient(t, `{"ID":1}`).Get(context.Background(), "/", &result)
+	assert.NoError(t, err)
+
+	_, err = newClient(t, `{"ID":1,"name":"x"}`).Get(context.Background(), "/", &result)
+	assert.ErrorContains(t, err, "unknown field")
+
+	_, err = newClient(t, `{"ID":1} {"ID":2}`).Get(context.Background(), "/", &result)
+	assert.ErrorContains(t, err, "unexpected data")
+}
@@ -0,0 +1,298 @@
+package httpclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWTRefreshWindow is how long before its expiry a StaticJWTAuth token is
+// refreshed.
+const JWTRefreshWindow = time.Minute
+
+// JWTSigner signs the JWTs minted by JWTAuth.
+type JWTSigner interface {
+	// Algorithm returns the JWS "alg" header value, e.g. "RS256".
+	Algorithm() string
+	// Sign returns the signature of the JWT signing input.
+	Sign(input []byte) ([]byte, error)
+}
+
+// HS256 returns a JWTSigner using HMAC-SHA256 with secret.
+func HS256(secret []byte) JWTSigner {
+	return hs256Signer(secret)
+}
+
+type hs256Signer []byte
+
+func (s hs256Signer) Algorithm() string { return "HS256" }
+
+func (s hs256Signer) Sign(input []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
+
+// RS256 returns a JWTSigner using RSASSA-PKCS1-v1_5 with SHA-256 and key.
+func RS256(key *rsa.PrivateKey) JWTSigner {
+	return rs256Signer{key: key}
+}
+
+type rs256Signer struct {
+	key *rsa.PrivateKey
+}
+
+func (s rs256Signer) Algorithm() string { return "RS256" }
+
+func (s rs256Signer) Sign(input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+}
+
+// JWTClaims are the claims of a JWT, e.g. "iss", "sub" and "aud".
+type JWTClaims map[string]any
+
+// jwtAuth mints and caches JWTs for JWTAuth.
+// It is safe for concurrent use across goroutines.
+type jwtAuth struct {
+	signer JWTSigner
+	claims JWTClaims
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// JWTAuth returns an AuthProvider that sends a Bearer JWT signed by signer,
+// carrying claims plus "iat" and an "exp" ttl later, as service accounts
+// authenticate to many APIs. The token is cached and re-signed once less
+// than a fifth of ttl remains, so no request carries one about to expire.
+// It returns an error if signer is nil or ttl is not positive.
+func JWTAuth(signer JWTSigner, claims JWTClaims, ttl time.Duration) (AuthProvider, error) {
+	if signer == nil {
+		return nil, errors.New("JWT signer cannot be nil")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("JWT ttl must be positive, got %v", ttl)
+	}
+	return &jwtAuth{signer: signer, claims: maps.Clone(claims), ttl: ttl, now: time.Now}, nil
+}
+
+// Apply implements AuthProvider.
+func (a *jwtAuth) Apply(req *http.Request) error {
+	token, err := a.current()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// InvalidateAuth implements AuthInvalidator.
+func (a *jwtAuth) InvalidateAuth(req *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if token := bearerToken(req); token == "" || token == a.token {
+		a.token = ""
+	}
+}
+
+// current returns the cached token, minting a new one when it is due.
+func (a *jwtAuth) current() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if a.token != "" && now.Before(a.expires.Add(-a.ttl/5)) {
+		return a.token, nil
+	}
+
+	claims := maps.Clone(a.claims)
+	if claims == nil {
+		claims = JWTClaims{}
+	}
+	expires := now.Add(a.ttl)
+	claims["iat"] = now.Unix()
+	claims["exp"] = expires.Unix()
+	token, err := signJWT(a.signer, claims)
+	if err != nil {
+		return "", err
+	}
+	a.token, a.expires = token, expires
+	return token, nil
+}
+
+// signJWT encodes and signs a JWT in compact serialization.
+func signJWT(signer JWTSigner, claims JWTClaims) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": signer.Algorithm(), "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("encoding JWT claims: %w", err)
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := signer.Sign([]byte(input))
+	if err != nil {
+		return "", fmt.Errorf("signing JWT: %w", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// staticJWTAuth sends a JWT obtained elsewhere, refreshing it before expiry.
+// It is safe for concurrent use across goroutines.
+type staticJWTAuth struct {
+	refresh TokenSource
+	now     func() time.Time
+
+	mu         sync.Mutex
+	token      string
+	expires    time.Time     // zero if the token does not expire
+	stale      bool          // rejected by the server, see InvalidateAuth
+	refreshing chan struct{} // closed when the running refresh ends, nil without one
+}
+
+// StaticJWTAuth returns an AuthProvider that sends token as a Bearer JWT
+// and, once it is within JWTRefreshWindow of its "exp" claim, takes a new
+// one from refresh, as it does under WithAuthRetry once the server rejects
+// the token. If refresh fails before the token has expired, the token is
+// sent anyway and refresh is asked again on the next request. A token
+// without "exp" is otherwise never refreshed. Tokens are decoded but not
+// verified; that is the server's job.
+func StaticJWTAuth(token string, refresh TokenSource) (AuthProvider, error) {
+	if refresh == nil {
+		return nil, errors.New("JWT refresh cannot be nil")
+	}
+	expires, err := jwtExpiry(token)
+	if err != nil {
+		return nil, err
+	}
+	return &staticJWTAuth{refresh: refresh, now: time.Now, token: token, expires: expires}, nil
+}
+
+// Apply implements AuthProvider.
+func (a *staticJWTAuth) Apply(req *http.Request) error {
+	token, err := a.current(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// InvalidateAuth implements AuthInvalidator.
+func (a *staticJWTAuth) InvalidateAuth(req *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if token := bearerToken(req); token == "" || token == a.token {
+		a.stale = true
+	}
+}
+
+// current returns the token, refreshing it when it is about to expire or
+// was rejected. One refresh runs at a time, outside a.mu: meanwhile other
+// requests keep sending a token that has not expired, or wait for the
+// refresh when it has expired or was rejected.
+func (a *staticJWTAuth) current(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	now := a.now()
+	for !a.fresh(now) && a.refreshing != nil {
+		done, token, usable := a.refreshing, a.token, !a.stale && now.Before(a.expires)
+		a.mu.Unlock()
+		if usable {
+			return token, nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		a.mu.Lock()
+		now = a.now()
+	}
+	if a.fresh(now) {
+		defer a.mu.Unlock()
+		return a.token, nil
+	}
+
+	done := make(chan struct{})
+	a.refreshing = done
+	a.mu.Unlock()
+	defer a.endRefresh(done)
+
+	token, err := a.refresh.Token(ctx)
+	return a.storeRefreshed(now, token, err)
+}
+
+// fresh reports whether the token can be sent at now without refreshing it.
+// Callers hold a.mu.
+func (a *staticJWTAuth) fresh(now time.Time) bool {
+	return !a.stale && (a.expires.IsZero() || now.Before(a.expires.Add(-JWTRefreshWindow)))
+}
+
+// endRefresh marks the refresh signalled by done as over, even when the
+// TokenSource panicked.
+func (a *staticJWTAuth) endRefresh(done chan struct{}) {
+	a.mu.Lock()
+	if a.refreshing == done {
+		a.refreshing = nil
+	}
+	a.mu.Unlock()
+	close(done)
+}
+
+// storeRefreshed keeps a refreshed token, or the current one if refreshing
+// failed before it expired.
+func (a *staticJWTAuth) storeRefreshed(now time.Time, token string, err error) (string, error) {
+	var expires time.Time
+	if err == nil {
+		expires, err = jwtExpiry(token)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil && (a.expires.IsZero() || now.Before(a.expires)) {
+		return a.token, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("refreshing expired JWT: %w", err)
+	}
+	a.token, a.expires, a.stale = token, expires, false
+	return token, nil
+}
+
+// jwtExpiry returns the time of a JWT's "exp" claim, or zero if it has none.
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("malformed JWT: want three dot-separated parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed JWT payload: %w", err)
+	}
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(*claims.Exp), 0), nil
+}
@@ -0,0 +1,296 @@
+package httpclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyAuth returns the bearer token auth puts on a request.
+func applyAuth(t *testing.T, auth AuthProvider) (string, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.invalid", nil)
+	if err := auth.Apply(req); err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), nil
+}
+
+// decodeJWT returns a JWT's header, claims and signing input.
+func decodeJWT(t *testing.T, token string) (map[string]any, map[string]any, string) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	var header, claims map[string]any
+	for i, v := range []*map[string]any{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, v))
+	}
+	return header, claims, parts[0] + "." + parts[1]
+}
+
+// testJWT builds a JWT for sub expiring at exp, for StaticJWTAuth tests.
+func testJWT(t *testing.T, sub string, exp time.Time) string {
+	t.Helper()
+	token, err := signJWT(HS256([]byte("test")), JWTClaims{"sub": sub, "exp": exp.Unix()})
+	require.NoError(t, err)
+	return token
+}
+
+// newJWTAuth returns a JWTAuth provider, failing the test on bad arguments.
+func newJWTAuth(t *testing.T, signer JWTSigner, claims JWTClaims, ttl time.Duration) AuthProvider {
+	t.Helper()
+	auth, err := JWTAuth(signer, claims, ttl)
+	require.NoError(t, err)
+	return auth
+}
+
+func TestJWTAuth(t *testing.T) {
+	t.Run("mints HS256 tokens with claims", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		auth := newJWTAuth(t, HS256([]byte("secret")), JWTClaims{"iss": "svc", "aud": "api"}, 10*time.Minute)
+		auth.(*jwtAuth).now = func() time.Time { return now }
+
+		token, err := applyAuth(t, auth)
+		require.NoError(t, err)
+
+		header, claims, input := decodeJWT(t, token)
+		assert.Equal(t, map[string]any{"alg": "HS256", "typ": "JWT"}, header)
+		assert.Equal(t, map[string]any{"iss": "svc", "aud": "api", "iat": 1700000000.0, "exp": 1700000600.0}, claims)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(input))
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), token[len(input)+1:])
+	})
+
+	t.Run("mints RS256 tokens", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		token, err := applyAuth(t, newJWTAuth(t, RS256(key), JWTClaims{"sub": "svc"}, time.Minute))
+		require.NoError(t, err)
+
+		header, _, input := decodeJWT(t, token)
+		assert.Equal(t, "RS256", header["alg"])
+		signature, err := base64.RawURLEncoding.DecodeString(token[len(input)+1:])
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(input))
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+	})
+
+	t.Run("caches until renewal is due", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		auth := newJWTAuth(t, HS256([]byte("secret")), nil, 10*time.Minute)
+		auth.(*jwtAuth).now = func() time.Time { return now }
+
+		first, err := applyAuth(t, auth)
+		require.NoError(t, err)
+
+		now = now.Add(7 * time.Minute)
+		second, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		now = now.Add(2 * time.Minute)
+		third, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, third)
+		_, claims, _ := decodeJWT(t, third)
+		assert.Equal(t, float64(now.Add(10*time.Minute).Unix()), claims["exp"])
+	})
+
+	t.Run("sends token with requests", func(t *testing.T) {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("Authorization")
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(newJWTAuth(t, HS256([]byte("secret")), JWTClaims{"sub": "svc"}, time.Minute)),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(got, "Bearer "))
+		_, claims, _ := decodeJWT(t, strings.TrimPrefix(got, "Bearer "))
+		assert.Equal(t, "svc", claims["sub"])
+	})
+
+	t.Run("rejects invalid configuration", func(t *testing.T) {
+		_, err := JWTAuth(nil, nil, time.Minute)
+		require.ErrorContains(t, err, "signer cannot be nil")
+
+		_, err = JWTAuth(HS256([]byte("secret")), nil, 0)
+		require.ErrorContains(t, err, "ttl must be positive")
+	})
+}
+
+func TestStaticJWTAuth(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	newAuth := func(t *testing.T, token string, refresh TokenSourceFunc) (AuthProvider, *time.Time) {
+		t.Helper()
+		auth, err := StaticJWTAuth(token, refresh)
+		require.NoError(t, err)
+		now := start
+		auth.(*staticJWTAuth).now = func() time.Time { return now }
+		return auth, &now
+	}
+
+	t.Run("refreshes near expiry", func(t *testing.T) {
+		initial := testJWT(t, "initial", start.Add(5*time.Minute))
+		refreshed := testJWT(t, "refreshed", start.Add(time.Hour))
+		calls := 0
+		auth, now := newAuth(t, initial, func(ctx context.Context) (string, error) {
+			calls++
+			return refreshed, nil
+		})
+
+		token, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, initial, token)
+		assert.Zero(t, calls)
+
+		*now = start.Add(4*time.Minute + 30*time.Second)
+		token, err = applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed, token)
+
+		token, err = applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed, token)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("keeps token while refresh fails before expiry", func(t *testing.T) {
+		initial := testJWT(t, "initial", start.Add(5*time.Minute))
+		auth, now := newAuth(t, initial, func(ctx context.Context) (string, error) {
+			return "", errors.New("identity provider down")
+		})
+
+		*now = start.Add(4*time.Minute + 30*time.Second)
+		token, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, initial, token)
+
+		*now = start.Add(5 * time.Minute)
+		_, err = applyAuth(t, auth)
+		require.ErrorContains(t, err, "identity provider down")
+	})
+
+	t.Run("keeps sending the token during a slow refresh", func(t *testing.T) {
+		initial := testJWT(t, "initial", start.Add(5*time.Minute))
+		refreshed := testJWT(t, "refreshed", start.Add(time.Hour))
+		started, release := make(chan struct{}), make(chan struct{})
+		auth, now := newAuth(t, initial, func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return refreshed, nil
+		})
+		*now = start.Add(4*time.Minute + 30*time.Second)
+
+		result := make(chan string)
+		go func() {
+			token, _ := applyAuth(t, auth)
+			result <- token
+		}()
+		<-started
+
+		token, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, initial, token, "not blocked behind the refresh")
+		close(release)
+		assert.Equal(t, refreshed, <-result)
+	})
+
+	t.Run("rejects malformed refreshed token", func(t *testing.T) {
+		auth, now := newAuth(t, testJWT(t, "initial", start), func(ctx context.Context) (string, error) {
+			return "not-a-jwt", nil
+		})
+
+		*now = start.Add(time.Second)
+		_, err := applyAuth(t, auth)
+		require.ErrorContains(t, err, "malformed JWT")
+	})
+
+	t.Run("never refreshes token without expiry", func(t *testing.T) {
+		token, err := signJWT(HS256([]byte("test")), JWTClaims{"sub": "forever"})
+		require.NoError(t, err)
+		auth, now := newAuth(t, token, func(ctx context.Context) (string, error) {
+			t.Fatal("refresh called")
+			return "", nil
+		})
+
+		*now = start.Add(24 * 365 * time.Hour)
+		got, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, token, got)
+	})
+
+	t.Run("rejects malformed token and nil refresh", func(t *testing.T) {
+		refresh := TokenSourceFunc(func(ctx context.Context) (string, error) { return "", nil })
+		_, err := StaticJWTAuth("a.b", refresh)
+		require.Error(t, err)
+
+		_, err = StaticJWTAuth("a.!!.c", refresh)
+		require.Error(t, err)
+
+		_, err = StaticJWTAuth(testJWT(t, "x", start), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestJWTAuthInvalidation(t *testing.T) {
+	t.Run("re-signs rejected token", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		auth := newJWTAuth(t, HS256([]byte("secret")), nil, time.Hour)
+		auth.(*jwtAuth).now = func() time.Time { return now }
+		first, err := applyAuth(t, auth)
+		require.NoError(t, err)
+
+		now = now.Add(time.Second)
+		auth.(AuthInvalidator).InvalidateAuth(&http.Request{Header: http.Header{"Authorization": {"Bearer " + first}}})
+		second, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+
+		auth.(AuthInvalidator).InvalidateAuth(&http.Request{Header: http.Header{"Authorization": {"Bearer " + first}}})
+		third, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, second, third)
+	})
+
+	t.Run("refreshes rejected static token", func(t *testing.T) {
+		start := time.Unix(1700000000, 0)
+		initial := testJWT(t, "initial", start.Add(time.Hour))
+		refreshed := testJWT(t, "refreshed", start.Add(2*time.Hour))
+		auth, err := StaticJWTAuth(initial, TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return refreshed, nil
+		}))
+		require.NoError(t, err)
+		auth.(*staticJWTAuth).now = func() time.Time { return start }
+
+		auth.(AuthInvalidator).InvalidateAuth(nil)
+		token, err := applyAuth(t, auth)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed, token)
+	})
+}
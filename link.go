@@ -0,0 +1,162 @@
+package httpclient
+
+import "strings"
+
+// Link is one entry of an RFC 8288 Link header.
+type Link struct {
+	URL string
+	// Rel is the relation type, e.g. "next"; it may hold several
+	// space-separated types.
+	Rel string
+	// Params holds the remaining parameters keyed by lowercase name.
+	Params map[string]string
+}
+
+// HasRel reports whether the link has relation type rel, ignoring case.
+func (l Link) HasRel(rel string) bool {
+	for _, r := range strings.Fields(l.Rel) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLinkHeader parses a Link header value such as
+// `<https://api.example.com/items?page=2>; rel="next"`. Malformed entries
+// are skipped.
+func ParseLinkHeader(h string) []Link {
+	var links []Link
+	for i := 0; i < len(h); {
+		i = skipLinkSpace(h, i, ",")
+		if i >= len(h) {
+			break
+		}
+		if h[i] != '<' {
+			i = skipLinkEntry(h, i)
+			continue
+		}
+		end := strings.IndexByte(h[i:], '>')
+		if end < 0 {
+			break
+		}
+		link := Link{URL: strings.TrimSpace(h[i+1 : i+end])}
+		i = parseLinkParams(h, i+end+1, &link)
+		links = append(links, link)
+	}
+	return links
+}
+
+// Links returns the links from all Link headers of the response.
+func (r *Response) Links() []Link {
+	var links []Link
+	for _, h := range r.Headers.Values("Link") {
+		links = append(links, ParseLinkHeader(h)...)
+	}
+	return links
+}
+
+// FindLink returns the first link with relation type rel.
+func FindLink(links []Link, rel string) (Link, bool) {
+	for _, link := range links {
+		if link.HasRel(rel) {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// parseLinkParams parses the ";name=value" parameters following a link's
+// URL and returns the index after the entry.
+func parseLinkParams(h string, i int, link *Link) int {
+	for i < len(h) {
+		i = skipLinkSpace(h, i, "")
+		if i >= len(h) || h[i] == ',' {
+			return i
+		}
+		if h[i] != ';' {
+			return skipLinkEntry(h, i)
+		}
+
+		i = skipLinkSpace(h, i+1, "")
+		start := i
+		for i < len(h) && !strings.ContainsRune("=;, \t", rune(h[i])) {
+			i++
+		}
+		name := strings.ToLower(h[start:i])
+		i = skipLinkSpace(h, i, "")
+
+		var value string
+		if i < len(h) && h[i] == '=' {
+			value, i = parseLinkValue(h, skipLinkSpace(h, i+1, ""))
+		}
+		if name == "" {
+			continue
+		}
+		if name == "rel" {
+			if link.Rel == "" {
+				link.Rel = value
+			}
+			continue
+		}
+		if link.Params == nil {
+			link.Params = make(map[string]string)
+		}
+		if _, ok := link.Params[name]; !ok {
+			link.Params[name] = value
+		}
+	}
+	return i
+}
+
+// parseLinkValue parses a token or quoted-string parameter value.
+func parseLinkValue(h string, i int) (string, int) {
+	if i >= len(h) || h[i] != '"' {
+		start := i
+		for i < len(h) && h[i] != ';' && h[i] != ',' {
+			i++
+		}
+		return strings.TrimSpace(h[start:i]), i
+	}
+
+	var b strings.Builder
+	for i++; i < len(h); i++ {
+		switch h[i] {
+		case '\\':
+			if i+1 < len(h) {
+				i++
+				b.WriteByte(h[i])
+			}
+		case '"':
+			return b.String(), i + 1
+		default:
+			b.WriteByte(h[i])
+		}
+	}
+	return b.String(), i
+}
+
+// skipLinkSpace skips whitespace and any of the extra characters.
+func skipLinkSpace(h string, i int, extra string) int {
+	for i < len(h) && (h[i] == ' ' || h[i] == '\t' || strings.IndexByte(extra, h[i]) >= 0) {
+		i++
+	}
+	return i
+}
+
+// skipLinkEntry skips to the comma ending a malformed entry, honoring
+// quoted strings.
+func skipLinkEntry(h string, i int) int {
+	quoted := false
+	for ; i < len(h); i++ {
+		switch {
+		case h[i] == '\\' && quoted:
+			i++
+		case h[i] == '"':
+			quoted = !quoted
+		case h[i] == ',' && !quoted:
+			return i
+		}
+	}
+	return i
+}
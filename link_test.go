@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	t.Run("parses GitHub-style pagination links", func(t *testing.T) {
+		links := ParseLinkHeader(`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=9>; rel="last"`)
+
+		require.Len(t, links, 2)
+		assert.Equal(t, Link{URL: "https://api.example.com/items?page=2", Rel: "next"}, links[0])
+		assert.Equal(t, Link{URL: "https://api.example.com/items?page=9", Rel: "last"}, links[1])
+	})
+
+	t.Run("parses params and quoted commas", func(t *testing.T) {
+		links := ParseLinkHeader(`</docs>; REL=help; title="Guide, part \"1\""; type=text/html`)
+
+		require.Len(t, links, 1)
+		assert.Equal(t, "/docs", links[0].URL)
+		assert.Equal(t, "help", links[0].Rel)
+		assert.Equal(t, map[string]string{"title": `Guide, part "1"`, "type": "text/html"}, links[0].Params)
+	})
+
+	t.Run("keeps commas inside URLs", func(t *testing.T) {
+		links := ParseLinkHeader(`</items?ids=1,2>; rel=next`)
+
+		require.Len(t, links, 1)
+		assert.Equal(t, "/items?ids=1,2", links[0].URL)
+	})
+
+	t.Run("skips malformed entries", func(t *testing.T) {
+		links := ParseLinkHeader(`garbage; rel="x, y", </ok>; rel=next, <unterminated`)
+
+		require.Len(t, links, 1)
+		assert.Equal(t, "/ok", links[0].URL)
+	})
+
+	t.Run("empty header", func(t *testing.T) {
+		assert.Empty(t, ParseLinkHeader(""))
+	})
+}
+
+func TestLinkHasRel(t *testing.T) {
+	link := Link{URL: "/a", Rel: "next Prefetch"}
+
+	assert.True(t, link.HasRel("next"))
+	assert.True(t, link.HasRel("prefetch"))
+	assert.False(t, link.HasRel("prev"))
+}
+
+func TestResponseLinks(t *testing.T) {
+	headers := http.Header{}
+	headers.Add("Link", `</p/2>; rel="next"`)
+	headers.Add("Link", `</p/1>; rel="prev first"`)
+	resp := &Response{Headers: headers}
+
+	links := resp.Links()
+
+	require.Len(t, links, 2)
+	next, ok := FindLink(links, "next")
+	require.True(t, ok)
+	assert.Equal(t, "/p/2", next.URL)
+	first, ok := FindLink(links, "first")
+	require.True(t, ok)
+	assert.Equal(t, "/p/1", first.URL)
+	_, ok = FindLink(links, "last")
+	assert.False(t, ok)
+}
@@ -14,10 +14,25 @@ type Logger interface {
 	Log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
 }
 
+// LevelEnabler is implemented by loggers that can report whether they
+// would record an entry at a level. The client skips formatting bodies and
+// redacting headers for entries a LevelEnabler would discard.
+type LevelEnabler interface {
+	Enabled(ctx context.Context, level slog.Level) bool
+}
+
 // LogBodyConfig configures body logging behavior.
 type LogBodyConfig struct {
-	MaxBodySize    int // total body limit in bytes (default: 4096)
-	MaxStringValue int // max JSON string value in bytes (default: 1024)
+	MaxBodySize    int  // total body limit in bytes (default: 4096)
+	MaxStringValue int  // max JSON string value in bytes (default: 1024)
+	Omit           bool // never log request or response bodies
+
+	// RedactXML lists elements whose content is replaced in logged XML and
+	// SOAP bodies, as "//Name" for any depth, "//Parent/Name", or an
+	// absolute "/Envelope/Body/Login/Name". Names match local names, so
+	// prefixes are ignored. Password, BinarySecurityToken and CardNumber
+	// elements are always redacted.
+	RedactXML []string
 }
 
 // DefaultLogBodyConfig returns the default body logging configuration.
@@ -43,6 +58,22 @@ func (l *defaultLogger) Log(ctx context.Context, level slog.Level, msg string, a
 	l.logger.LogAttrs(ctx, level, msg, attrs...)
 }
 
+// Enabled implements LevelEnabler.
+func (l *defaultLogger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.logger.Enabled(ctx, level)
+}
+
+// logEnabled reports whether the client's logger would record an entry at level.
+func (c *Client) logEnabled(ctx context.Context, level slog.Level) bool {
+	if c.logger == nil {
+		return false
+	}
+	if enabler, ok := c.logger.(LevelEnabler); ok {
+		return enabler.Enabled(ctx, level)
+	}
+	return true
+}
+
 // sensitiveHeaders contains headers that should be redacted in logs.
 var sensitiveHeaders = []string{
 	"authorization",
@@ -106,7 +137,7 @@ func redactHeadersForLog(headers map[string][]string) map[string]string {
 	result := make(map[string]string)
 	for name, values := range headers {
 		if isSensitiveHeader(name) {
-			result[name] = "[REDACTED]"
+			result[name] = redactedValue
 		} else if len(values) > 0 {
 			result[name] = values[0]
 		}
@@ -125,13 +156,22 @@ func formatBodyForLog(body []byte, contentType string, config LogBodyConfig) any
 		return fmt.Sprintf("[binary: %s]", formatBytes(len(body)))
 	}
 
+	// XML is truncated at element boundaries, after redaction
+	if isXMLContentType(contentType) {
+		return formatXMLForLog(body, config)
+	}
+
 	// Handle large bodies
 	if len(body) > config.MaxBodySize {
 		return fmt.Sprintf("[body: %s truncated]", formatBytes(len(body)))
 	}
 
-	// Try to parse as JSON for string truncation
-	if strings.Contains(strings.ToLower(contentType), "json") {
+	// JSON bodies are logged as-is unless a string value needs truncating,
+	// which is the only case that requires decoding them.
+	if strings.Contains(strings.ToLower(contentType), "json") && json.Valid(body) {
+		if !hasLongJSONString(body, config.MaxStringValue) {
+			return json.RawMessage(body)
+		}
 		var data any
 		if err := json.Unmarshal(body, &data); err == nil {
 			return truncateJSONStrings(data, config.MaxStringValue)
@@ -147,6 +187,29 @@ func formatBodyForLog(body []byte, contentType string, config LogBodyConfig) any
 	return string(body)
 }
 
+// hasLongJSONString reports whether valid JSON data contains a string literal
+// longer than maxSize bytes. Literals are measured escaped, so a false result
+// guarantees no decoded string exceeds maxSize.
+func hasLongJSONString(data []byte, maxSize int) bool {
+	start := -1
+	for i := 0; i < len(data); i++ {
+		switch {
+		case start < 0:
+			if data[i] == '"' {
+				start = i + 1
+			}
+		case data[i] == '\\':
+			i++
+		case data[i] == '"':
+			if i-start > maxSize {
+				return true
+			}
+			start = -1
+		}
+	}
+	return false
+}
+
 // truncateJSONStrings recursively truncates large string values in JSON data.
 func truncateJSONStrings(data any, maxSize int) any {
 	switch v := data.(type) {
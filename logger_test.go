@@ -317,3 +317,128 @@ func TestLogger_LogsErrorResponses(t *testing.T) {
 	assert.Equal(t, slog.LevelError, entry.Level)
 	assert.EqualValues(t, 400, entry.Attrs["status"]) // EqualValues handles int/int64
 }
+
+func TestFormatBodyForLog_JSON(t *testing.T) {
+	config := DefaultLogBodyConfig()
+
+	t.Run("returns raw JSON when no string needs truncating", func(t *testing.T) {
+		body := []byte(`{"id":"123","tags":["a","b"]}`)
+
+		got := formatBodyForLog(body, "application/json", config)
+
+		assert.Equal(t, json.RawMessage(body), got)
+	})
+
+	t.Run("decodes only when truncating", func(t *testing.T) {
+		body := []byte(`{"id":"123","data":"` + strings.Repeat("x", 2000) + `"}`)
+
+		got, ok := formatBodyForLog(body, "application/json", config).(map[string]any)
+
+		require.True(t, ok)
+		assert.Contains(t, got["data"], "truncated]")
+	})
+
+	t.Run("logs invalid JSON as string", func(t *testing.T) {
+		got := formatBodyForLog([]byte(`{"id":`), "application/json", config)
+
+		assert.Equal(t, `{"id":`, got)
+	})
+}
+
+func TestHasLongJSONString(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{name: "short strings", data: `{"a":"bc","d":["ef"]}`, want: false},
+		{name: "long value", data: `{"a":"abcdef"}`, want: true},
+		{name: "long key", data: `{"abcdef":1}`, want: true},
+		{name: "escaped quote", data: `["a\"b"]`, want: false},
+		{name: "escaped backslash before quote", data: `["ab\\","x"]`, want: false},
+		{name: "numbers only", data: `[123456789]`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasLongJSONString([]byte(tt.data), 4))
+		})
+	}
+}
+
+func BenchmarkFormatBodyForLog(b *testing.B) {
+	config := DefaultLogBodyConfig()
+	small, _ := json.Marshal(map[string]any{
+		"id":     "cus_123",
+		"email":  "user@example.com",
+		"amount": 4200,
+		"items":  []string{"a", "b", "c"},
+	})
+	large, _ := json.Marshal(map[string]any{
+		"id":   "cus_123",
+		"data": strings.Repeat("x", 2000),
+	})
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = formatBodyForLog(small, "application/json", config)
+		}
+	})
+
+	// decode_json measures the previous behavior, which decoded every JSON body.
+	b.Run("decode_json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var data any
+			_ = json.Unmarshal(small, &data)
+			_ = truncateJSONStrings(data, config.MaxStringValue)
+		}
+	})
+
+	b.Run("json_truncated", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = formatBodyForLog(large, "application/json", config)
+		}
+	})
+}
+
+// leveledLogger is a testLogger that reports levels below min as disabled.
+type leveledLogger struct {
+	testLogger
+	min slog.Level
+}
+
+func (l *leveledLogger) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= l.min
+}
+
+func TestLogger_SkipsDisabledLevels(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	logger := &leveledLogger{min: slog.LevelError}
+	client, err := New(WithBaseURL(server.URL), WithLogger(logger))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/ok", nil)
+	require.NoError(t, err)
+	assert.Empty(t, logger.Entries())
+
+	status = http.StatusInternalServerError
+	_, err = client.Get(context.Background(), "/fail", nil)
+	require.Error(t, err)
+	require.Len(t, logger.Entries(), 1)
+	assert.Equal(t, slog.LevelError, logger.LastEntry().Level)
+}
+
+func TestDefaultLogger_Enabled(t *testing.T) {
+	logger := newDefaultLogger()
+
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelInfo))
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+}
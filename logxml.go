@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultXMLRedactions are always applied to logged XML bodies, on top of
+// LogBodyConfig.RedactXML, covering WS-Security credentials and card data.
+var defaultXMLRedactions = []string{"//Password", "//BinarySecurityToken", "//CardNumber"}
+
+// validateXMLRedactions checks LogBodyConfig.RedactXML rules.
+func validateXMLRedactions(rules []string) error {
+	for _, rule := range rules {
+		steps, _ := parseXMLRedaction(rule)
+		if len(steps) == 0 {
+			return fmt.Errorf("invalid XML redaction rule %q: use //Name or /Root/Child", rule)
+		}
+		for _, step := range steps {
+			if step == "" {
+				return fmt.Errorf("invalid XML redaction rule %q: empty element name", rule)
+			}
+		}
+	}
+	return nil
+}
+
+// parseXMLRedaction splits a rule into element names. anywhere is true for
+// rules starting with "//", which match the names at any depth.
+func parseXMLRedaction(rule string) (steps []string, anywhere bool) {
+	switch {
+	case strings.HasPrefix(rule, "//"):
+		return strings.Split(rule[2:], "/"), true
+	case strings.HasPrefix(rule, "/"):
+		return strings.Split(rule[1:], "/"), false
+	}
+	return nil, false
+}
+
+// matchesXMLRedaction reports whether the element at path, a list of local
+// names from the root, matches one of the rules.
+func matchesXMLRedaction(path []string, rules []string) bool {
+	for _, rule := range rules {
+		steps, anywhere := parseXMLRedaction(rule)
+		if len(steps) == 0 || len(steps) > len(path) || (!anywhere && len(steps) != len(path)) {
+			continue
+		}
+		tail := path[len(path)-len(steps):]
+		matched := true
+		for i, step := range steps {
+			if tail[i] != step {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// formatXMLForLog returns body with the content of redacted elements
+// replaced, long text nodes truncated and, beyond MaxBodySize, the rest cut
+// at an element boundary. Names are matched on local names, so
+// "//Password" also matches <wsse:Password>. The original bytes are kept
+// otherwise, so namespace prefixes appear as sent.
+func formatXMLForLog(body []byte, config LogBodyConfig) string {
+	rules := append(defaultXMLRedactions[:len(defaultXMLRedactions):len(defaultXMLRedactions)], config.RedactXML...)
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	var out strings.Builder
+	var path []string
+	written := 0 // bytes of body copied or replaced so far
+	for range len(body) + 1 {
+		before := int(dec.InputOffset())
+		if before > config.MaxBodySize {
+			out.Write(body[written:before])
+			fmt.Fprintf(&out, "[xml: %s truncated]", formatBytes(len(body)-before))
+			return out.String()
+		}
+
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			out.Write(body[written:before])
+			out.WriteString("[xml: unparseable remainder omitted]")
+			return out.String()
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			if !matchesXMLRedaction(path, rules) {
+				continue
+			}
+			contentStart := int(dec.InputOffset())
+			if err := dec.Skip(); err != nil {
+				out.Write(body[written:contentStart])
+				out.WriteString(redactedValue)
+				return out.String()
+			}
+			path = path[:len(path)-1]
+			end := int(dec.InputOffset())
+			if closeTag := bytes.LastIndex(body[contentStart:end], []byte("</")); closeTag >= 0 {
+				out.Write(body[written:contentStart])
+				out.WriteString(redactedValue)
+				written = contentStart + closeTag
+			}
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			if len(t) > config.MaxStringValue {
+				out.Write(body[written:before])
+				fmt.Fprintf(&out, "[text: %s truncated]", formatBytes(len(t)))
+				written = int(dec.InputOffset())
+			}
+		}
+	}
+	out.Write(body[written:])
+	return out.String()
+}
+
+// isXMLContentType reports whether contentType is an XML or SOAP type.
+func isXMLContentType(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	return strings.Contains(ct, "/xml") || strings.Contains(ct, "+xml")
+}
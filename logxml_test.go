@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const loginEnvelope = `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:wsse="urn:wsse">` +
+	`<soapenv:Header><wsse:Security><wsse:UsernameToken><wsse:Username>ada</wsse:Username>` +
+	`<wsse:Password Type="PasswordText">hunter2</wsse:Password></wsse:UsernameToken></wsse:Security></soapenv:Header>` +
+	`<soapenv:Body><Pay><Card><Number>4111111111111111</Number><Holder>Ada</Holder></Card><Memo/></Pay></soapenv:Body>` +
+	`</soapenv:Envelope>`
+
+func TestFormatXMLForLog(t *testing.T) {
+	t.Run("redacts default and configured elements", func(t *testing.T) {
+		config := DefaultLogBodyConfig()
+		config.RedactXML = []string{"//Card/Number", "/Envelope/Body/Pay/Memo"}
+
+		got := formatXMLForLog([]byte(loginEnvelope), config)
+
+		assert.NotContains(t, got, "hunter2")
+		assert.NotContains(t, got, "4111")
+		assert.Contains(t, got, `<wsse:Password Type="PasswordText">[REDACTED]</wsse:Password>`)
+		assert.Contains(t, got, `<Number>[REDACTED]</Number><Holder>Ada</Holder>`)
+		assert.Contains(t, got, `<wsse:Username>ada</wsse:Username>`)
+		assert.Contains(t, got, `<Memo/>`)
+	})
+
+	t.Run("absolute rules only match the full path", func(t *testing.T) {
+		config := DefaultLogBodyConfig()
+		config.RedactXML = []string{"/Holder"}
+
+		assert.Contains(t, formatXMLForLog([]byte(loginEnvelope), config), "<Holder>Ada</Holder>")
+	})
+
+	t.Run("truncates long text nodes", func(t *testing.T) {
+		config := DefaultLogBodyConfig()
+		body := `<Doc><Blob>` + strings.Repeat("x", 2000) + `</Blob><Id>7</Id></Doc>`
+
+		got := formatXMLForLog([]byte(body), config)
+
+		assert.Equal(t, `<Doc><Blob>[text: 2.0KB truncated]</Blob><Id>7</Id></Doc>`, got)
+	})
+
+	t.Run("cuts large bodies at an element boundary", func(t *testing.T) {
+		config := DefaultLogBodyConfig()
+		config.MaxBodySize = 40
+		body := `<Items>` + strings.Repeat(`<Item>abc</Item>`, 10) + `</Items>`
+
+		got := formatXMLForLog([]byte(body), config)
+
+		assert.True(t, strings.HasPrefix(got, `<Items><Item>abc</Item><Item>abc</Item>`), got)
+		assert.Contains(t, got, "truncated]")
+		assert.Less(t, len(got), len(body))
+	})
+
+	t.Run("does not leak secrets from broken documents", func(t *testing.T) {
+		got := formatXMLForLog([]byte(`<Login><Password>hunter2`), DefaultLogBodyConfig())
+
+		assert.NotContains(t, got, "hunter2")
+	})
+
+	t.Run("validates rules", func(t *testing.T) {
+		assert.NoError(t, validateXMLRedactions([]string{"//Password", "/Envelope/Body"}))
+		assert.Error(t, validateXMLRedactions([]string{"Password"}))
+		assert.Error(t, validateXMLRedactions([]string{"//"}))
+		assert.Error(t, validateXMLRedactions([]string{"/a//b"}))
+
+		_, err := New(WithBaseURL("http://example.com"), WithLogBodyConfig(LogBodyConfig{RedactXML: []string{"bad"}}))
+		assert.Error(t, err)
+	})
+}
+
+func TestSOAPBodyLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(`<Envelope><Body><LoginResponse><SessionToken>s3cret</SessionToken></LoginResponse></Body></Envelope>`))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	config := DefaultLogBodyConfig()
+	config.RedactXML = []string{"//SessionToken"}
+	client, err := New(WithBaseURL(server.URL), WithLogger(logger), WithLogBodyConfig(config))
+	require.NoError(t, err)
+
+	type login struct {
+		User     string
+		Password string
+	}
+	_, err = client.Post(context.Background(), "/", SOAPBody(login{User: "ada", Password: "hunter2"}), nil)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, logger.entries)
+	entry := logger.entries[len(logger.entries)-1]
+	assert.NotContains(t, entry.Attrs["request_body"], "hunter2")
+	assert.Contains(t, entry.Attrs["request_body"], "ada")
+	assert.NotContains(t, entry.Attrs["response_body"], "s3cret")
+}
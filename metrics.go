@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MetricsCollector receives request telemetry, e.g. to export Prometheus
+// metrics (see PrometheusCollector). ObserveRequest is called once per
+// attempt, so retries are attempts above 1; status is 0 when no response
+// was received. Implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	ObserveRequest(method, host string, status int, duration time.Duration, attempt int)
+}
+
+// RateLimitObserver is implemented by collectors that also track time spent
+// waiting for the client-side rate limiter.
+type RateLimitObserver interface {
+	ObserveRateLimitWait(host string, wait time.Duration, rejected bool)
+}
+
+// WithMetrics reports every request attempt to m.
+func WithMetrics(m MetricsCollector) ClientOption {
+	return func(c *Client) error {
+		if m == nil {
+			return errors.New("metrics collector cannot be nil")
+		}
+		c.metrics = m
+		return nil
+	}
+}
+
+// observeAttempt reports one attempt to the metrics collector.
+func (c *Client) observeAttempt(cl *call, attempt int, res attemptResult, elapsed time.Duration) {
+	if c.metrics == nil {
+		return
+	}
+	status := 0
+	if res.response != nil {
+		status = res.response.StatusCode
+	}
+	c.metrics.ObserveRequest(cl.method, cl.host, status, elapsed, attempt)
+	if obs, ok := c.metrics.(OperationObserver); ok && cl.operation != "" {
+		obs.ObserveOperation(cl.operation, status, elapsed, attempt)
+	}
+}
+
+// waitRateLimit waits for the rate limiter and reports the wait. It waits
+// no longer than the client timeout, so a quota that resets only in hours
+// fails the call at once instead of blocking it.
+func (c *Client) waitRateLimit(ctx context.Context, cl *call) error {
+	cl.rateLimiter = c.callRateLimiter(ctx, cl)
+	if cl.rateLimiter == nil {
+		return nil
+	}
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	err := cl.rateLimiter.Wait(waitCtx)
+	cancel()
+	if obs, ok := c.metrics.(RateLimitObserver); ok {
+		obs.ObserveRateLimitWait(cl.host, time.Since(start), err != nil)
+	}
+	if err != nil {
+		return &Error{
+			Kind:   ErrKindRateLimit,
+			Method: cl.method,
+			URL:    cl.url,
+			Err:    err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	method, host string
+	status       int
+	attempt      int
+}
+
+// recordingCollector records observations for assertions.
+type recordingCollector struct {
+	mu           sync.Mutex
+	observations []observation
+	waits        int
+	rejected     int
+}
+
+func (r *recordingCollector) ObserveRequest(method, host string, status int, duration time.Duration, attempt int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observations = append(r.observations, observation{method, host, status, attempt})
+}
+
+func (r *recordingCollector) ObserveRateLimitWait(host string, wait time.Duration, rejected bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits++
+	if rejected {
+		r.rejected++
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("observes every attempt", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		collector := &recordingCollector{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}),
+			WithRateLimit(10, time.Second),
+			WithMetrics(collector),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+
+		host := server.Listener.Addr().String()
+		assert.Equal(t, []observation{
+			{http.MethodGet, host, http.StatusServiceUnavailable, 1},
+			{http.MethodGet, host, http.StatusOK, 2},
+		}, collector.observations)
+		assert.Equal(t, 1, collector.waits)
+		assert.Zero(t, collector.rejected)
+	})
+
+	t.Run("rejects nil collector", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithMetrics(nil))
+		assert.Error(t, err)
+	})
+}
@@ -2,9 +2,10 @@ package httpclient
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"net/http"
-
-	"github.com/google/uuid"
 )
 
 // RoundTripFunc is the function signature for making HTTP requests.
@@ -29,14 +30,98 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
-// RequestIDMiddleware adds a unique request ID to each request.
+// RequestIDGenerator returns a new unique request ID.
+type RequestIDGenerator func() string
+
+// requestIDGeneratorKey is the context key for the client's generator.
+type requestIDGeneratorKey struct{}
+
+// WithRequestIDGenerator generates the request IDs the client creates, for
+// RequestIDMiddleware and chained requests, with gen instead of random
+// UUIDs, e.g. to use ULIDs or reuse the caller's trace ID.
+func WithRequestIDGenerator(gen RequestIDGenerator) ClientOption {
+	return func(c *Client) error {
+		if gen == nil {
+			return errors.New("request ID generator cannot be nil")
+		}
+		c.requestIDGenerator = gen
+		return nil
+	}
+}
+
+// withRequestIDGenerator passes the client's generator to middlewares.
+func (c *Client) withRequestIDGenerator(ctx context.Context) context.Context {
+	if c.requestIDGenerator == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDGeneratorKey{}, c.requestIDGenerator)
+}
+
+// newRequestID returns an ID from the generator in ctx, or a random UUID.
+func newRequestID(ctx context.Context) string {
+	if gen, ok := ctx.Value(requestIDGeneratorKey{}).(RequestIDGenerator); ok {
+		return gen()
+	}
+	return randomUUID()
+}
+
+// randomUUID returns a random RFC 4122 version 4 UUID.
+func randomUUID() string {
+	var b [16]byte
+	rand.Read(b[:]) // never fails
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// RequestIDMiddleware adds a unique request ID to each request: the one in
+// the request's context, or else a new one (see WithRequestIDGenerator).
 func RequestIDMiddleware(headerName string) Middleware {
 	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
 		id := GetRequestID(req.Context())
 		if id == "" {
-			id = uuid.New().String()
+			id = newRequestID(req.Context())
 		}
 		req.Header.Set(headerName, id)
 		return next(req)
 	}
 }
+
+// traceParentKey is the context key for W3C trace context.
+type traceParentKey struct{}
+
+// WithTraceParent adds a W3C traceparent value, e.g. from an incoming
+// request, to the context for TracePropagationMiddleware.
+func WithTraceParent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, traceparent)
+}
+
+// GetTraceParent retrieves the traceparent value from the context.
+func GetTraceParent(ctx context.Context) string {
+	if tp, ok := ctx.Value(traceParentKey{}).(string); ok {
+		return tp
+	}
+	return ""
+}
+
+// TracePropagationMiddleware sends the context's traceparent as the W3C
+// traceparent header, so downstream services join the caller's trace.
+func TracePropagationMiddleware() Middleware {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		if tp := GetTraceParent(req.Context()); tp != "" && req.Header.Get("traceparent") == "" {
+			req.Header.Set("traceparent", tp)
+		}
+		return next(req)
+	}
+}
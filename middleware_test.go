@@ -2,6 +2,7 @@ package httpclient
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -69,6 +70,30 @@ func TestMiddleware(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("keeps headers it adds out of logs and errors", func(t *testing.T) {
+		server := newStatusServer(http.StatusInternalServerError)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithMiddleware(func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+				req.Header.Set("X-Partner-Secret", "s3cr3t")
+				return next(req)
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		captured, ok := CaptureFromError(err)
+		require.True(t, ok)
+		assert.Empty(t, captured.Headers.Get("X-Partner-Secret"))
+		for _, entry := range logger.Entries() {
+			assert.NotContains(t, fmt.Sprint(entry.Attrs["request_headers"]), "s3cr3t")
+		}
+	})
+
 	t.Run("can short-circuit request", func(t *testing.T) {
 		var serverCalled int32
 
@@ -153,4 +178,64 @@ func TestRequestIDMiddleware(t *testing.T) {
 	t.Run("GetRequestID returns empty for missing ID", func(t *testing.T) {
 		assert.Equal(t, "", GetRequestID(context.Background()))
 	})
+
+	t.Run("defaults to random UUIDs", func(t *testing.T) {
+		id := randomUUID()
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+		assert.NotEqual(t, id, randomUUID())
+	})
+}
+
+func TestWithRequestIDGenerator(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var n atomic.Int32
+	client, err := New(
+		WithBaseURL(server.URL),
+		WithLoggerDisabled(),
+		WithMiddleware(RequestIDMiddleware("X-Request-ID")),
+		WithRequestIDGenerator(func() string { return fmt.Sprintf("id-%d", n.Add(1)) }),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/test", nil)
+	require.NoError(t, err)
+	_, err = client.Request().Path("/first").
+		Then(func(*Response) *RequestBuilder { return client.Request().Path("/second") }).
+		Do(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id-1", "id-2", "id-2"}, received)
+
+	_, err = New(WithBaseURL(server.URL), WithRequestIDGenerator(nil))
+	assert.Error(t, err)
+}
+
+func TestTracePropagationMiddleware(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("traceparent"))
+	}))
+	defer server.Close()
+
+	client, err := New(
+		WithBaseURL(server.URL),
+		WithLoggerDisabled(),
+		WithMiddleware(TracePropagationMiddleware()),
+	)
+	require.NoError(t, err)
+
+	tp := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	_, err = client.Get(WithTraceParent(context.Background(), tp), "/", nil)
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), "/", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{tp, ""}, received)
+	assert.Empty(t, GetTraceParent(context.Background()))
 }
@@ -2,6 +2,7 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -19,12 +20,60 @@ type MockTransport struct {
 	handlers     map[string]MockHandler
 	methodRoutes map[string]map[string]MockHandler
 	sequences    map[string]*responseSequence
+	sequenceKey  func(*http.Request) string
 	requests     []*http.Request
 }
 
+// responseSequence replays buffered responses, keeping a position per
+// sequence key so keyed callers each see the whole sequence.
 type responseSequence struct {
 	responses []*http.Response
-	index     int
+	bodies    [][]byte
+	positions map[string]int
+}
+
+// next returns a fresh copy of the next response for key, if any remain.
+func (s *responseSequence) next(key string) (*http.Response, bool) {
+	i := s.positions[key]
+	if i >= len(s.responses) {
+		return nil, false
+	}
+	s.positions[key] = i + 1
+
+	resp := s.responses[i]
+	if resp == nil {
+		return nil, true
+	}
+	clone := *resp
+	clone.Header = resp.Header.Clone()
+	clone.Body = io.NopCloser(bytes.NewReader(s.bodies[i]))
+	return &clone, true
+}
+
+// mockSequenceKey is the context key for mock sequence keys.
+type mockSequenceKey struct{}
+
+// WithMockSequenceKey keys the response sequences of requests made with
+// ctx, for a MockTransport using MockSequenceKey.
+func WithMockSequenceKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, mockSequenceKey{}, key)
+}
+
+// GetMockSequenceKey retrieves the mock sequence key from the context.
+func GetMockSequenceKey(ctx context.Context) string {
+	if key, ok := ctx.Value(mockSequenceKey{}).(string); ok {
+		return key
+	}
+	return ""
+}
+
+// MockSequenceKey keys a request by its WithMockSequenceKey key, falling
+// back to its WithRequestID request ID.
+func MockSequenceKey(req *http.Request) string {
+	if key := GetMockSequenceKey(req.Context()); key != "" {
+		return key
+	}
+	return GetRequestID(req.Context())
 }
 
 // NewMockTransport creates a new mock transport.
@@ -45,18 +94,13 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	path := req.URL.Path
 
+	if resp, ok := m.nextInSequence(req); ok {
+		return resp, nil
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	// Check for response sequence
-	if seq, ok := m.sequences[path]; ok {
-		if seq.index < len(seq.responses) {
-			resp := seq.responses[seq.index]
-			seq.index++
-			return resp, nil
-		}
-	}
-
 	// Check for method-specific handler
 	if methodHandlers, ok := m.methodRoutes[req.Method]; ok {
 		if handler, ok := methodHandlers[path]; ok {
@@ -104,15 +148,54 @@ func (m *MockTransport) AddHandler(path string, handler MockHandler) {
 	m.handlers[path] = handler
 }
 
-// AddResponseSequence adds a sequence of responses for a path.
+// AddResponseSequence adds a sequence of responses for a path. Once the
+// sequence is used up, the path's handlers answer. Bodies are buffered, so
+// a response may appear more than once.
 func (m *MockTransport) AddResponseSequence(path string, responses ...*http.Response) {
+	bodies := make([][]byte, len(responses))
+	for i, resp := range responses {
+		if resp == nil || resp.Body == nil {
+			continue
+		}
+		bodies[i], _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.sequences[path] = &responseSequence{
 		responses: responses,
-		index:     0,
+		bodies:    bodies,
+		positions: make(map[string]int),
+	}
+}
+
+// KeySequences replays each response sequence separately for every key
+// returned by fn, so parallel tests sharing the transport do not consume
+// each other's responses. Pass MockSequenceKey and give each test its own
+// key with WithMockSequenceKey or WithRequestID.
+func (m *MockTransport) KeySequences(fn func(req *http.Request) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sequenceKey = fn
+}
+
+// nextInSequence returns the next sequenced response for req, if any.
+func (m *MockTransport) nextInSequence(req *http.Request) (*http.Response, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seq, ok := m.sequences[req.URL.Path]
+	if !ok {
+		return nil, false
+	}
+	var key string
+	if m.sequenceKey != nil {
+		key = m.sequenceKey(req)
 	}
+	return seq.next(key)
 }
 
 // Requests returns all recorded requests.
@@ -151,7 +234,7 @@ func (m *MockTransport) Reset() {
 
 	m.requests = nil
 	for _, seq := range m.sequences {
-		seq.index = 0
+		clear(seq.positions)
 	}
 }
 
@@ -2,7 +2,10 @@ package httpclient
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -245,3 +248,95 @@ func TestMockResponseHelpers(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
 }
+
+func TestMockTransportKeyedSequences(t *testing.T) {
+	newFlaky := func() *MockTransport {
+		mock := NewMockTransport()
+		mock.AddResponseSequence("/flaky",
+			MockJSONResponse(http.StatusServiceUnavailable, nil),
+			MockJSONResponse(http.StatusOK, map[string]string{"status": "ok"}),
+		)
+		mock.AddResponse("/flaky", http.StatusNoContent, nil)
+		return mock
+	}
+
+	t.Run("replays sequence per key", func(t *testing.T) {
+		mock := newFlaky()
+		mock.KeySequences(MockSequenceKey)
+
+		client, err := New(
+			WithBaseURL("http://api.example.com"),
+			WithHTTPClient(&http.Client{Transport: mock}),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		statuses := make([][]int, 8)
+		for i := range statuses {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx := WithMockSequenceKey(context.Background(), fmt.Sprintf("test-%d", i))
+				for range 3 {
+					resp, _ := client.Get(ctx, "/flaky", nil)
+					statuses[i] = append(statuses[i], resp.StatusCode)
+				}
+			}()
+		}
+		wg.Wait()
+
+		for _, got := range statuses {
+			assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusNoContent}, got)
+		}
+	})
+
+	t.Run("keys by request ID without sequence key", func(t *testing.T) {
+		mock := newFlaky()
+		mock.KeySequences(MockSequenceKey)
+
+		client, err := New(
+			WithBaseURL("http://api.example.com"),
+			WithHTTPClient(&http.Client{Transport: mock}),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		resp, _ := client.Get(WithRequestID(context.Background(), "a"), "/flaky", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp, _ = client.Get(WithRequestID(context.Background(), "b"), "/flaky", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("shares sequence without key function", func(t *testing.T) {
+		mock := newFlaky()
+
+		client, err := New(
+			WithBaseURL("http://api.example.com"),
+			WithHTTPClient(&http.Client{Transport: mock}),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		resp, _ := client.Get(WithMockSequenceKey(context.Background(), "a"), "/flaky", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp, _ = client.Get(WithMockSequenceKey(context.Background(), "b"), "/flaky", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("reset restarts every key", func(t *testing.T) {
+		mock := newFlaky()
+		mock.KeySequences(MockSequenceKey)
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/flaky", nil)
+		req = req.WithContext(WithMockSequenceKey(req.Context(), "a"))
+
+		resp, err := mock.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		mock.Reset()
+		resp, err = mock.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+}
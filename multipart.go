@@ -0,0 +1,130 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// MaxMultipartParts bounds how many parts a MultipartReader returns.
+const MaxMultipartParts = 1000
+
+// Part is one part of a multipart response, or of a MultipartBody request.
+type Part struct {
+	Headers http.Header
+	// FormName and FileName come from a form-data Content-Disposition.
+	FormName string
+	FileName string
+	// Body reads the part's content. It is valid until the next call to
+	// MultipartReader.NextPart, or until a PartFunc returns.
+	Body io.Reader
+}
+
+// JSON unmarshals the rest of the part body as JSON into v.
+func (p *Part) JSON(v any) error {
+	if v == nil {
+		return errors.New("target cannot be nil")
+	}
+	return json.NewDecoder(p.Body).Decode(v)
+}
+
+// PartFunc receives one part of a streamed multipart response. Returning
+// an error stops the stream.
+type PartFunc func(part *Part) error
+
+// MultipartReader iterates over the parts of a multipart/mixed or
+// multipart/form-data response, e.g. a document alongside its JSON metadata.
+type MultipartReader struct {
+	reader *multipart.Reader
+	parts  int
+}
+
+// Multipart returns a reader over the parts of a buffered multipart
+// response. Use DoIntoMultipart to read large responses as they arrive.
+func (r *Response) Multipart() (*MultipartReader, error) {
+	return newMultipartReader(r.Headers, bytes.NewReader(r.Body))
+}
+
+// DoIntoMultipart performs a request and passes each part of a successful
+// multipart response to fn as it arrives, so documents of any size are
+// read without buffering the response. fn runs on the reading goroutine,
+// and a part's Body is only valid until fn returns. Failed attempts are
+// retried as usual. The returned Response has no Body.
+func (c *Client) DoIntoMultipart(ctx context.Context, method, path string, body any, fn PartFunc, opts ...RequestOption) (*Response, error) {
+	if fn == nil {
+		return nil, errors.New("part function cannot be nil")
+	}
+	return c.doStream(ctx, method, path, body, func(r io.Reader, headers http.Header) error {
+		return readMultipart(headers, r, fn)
+	}, opts)
+}
+
+// DoIntoMultipart executes the request, streaming a multipart response to
+// fn as Client.DoIntoMultipart does.
+func (b *RequestBuilder) DoIntoMultipart(ctx context.Context, fn PartFunc) (*Response, error) {
+	return b.client.DoIntoMultipart(ctx, b.method, b.path, b.body, fn, b.toRequestOptions()...)
+}
+
+// readMultipart passes each part of a multipart body to fn.
+func readMultipart(headers http.Header, body io.Reader, fn PartFunc) error {
+	mr, err := newMultipartReader(headers, body)
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(part); err != nil {
+			return err
+		}
+	}
+}
+
+// newMultipartReader returns a reader over body, split at the boundary
+// declared by the multipart Content-Type in headers.
+func newMultipartReader(headers http.Header, body io.Reader) (*MultipartReader, error) {
+	contentType := headers.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("parsing content type %q: %w", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("content type %q is not multipart", mediaType)
+	}
+	if params["boundary"] == "" {
+		return nil, fmt.Errorf("content type %q has no boundary", contentType)
+	}
+	return &MultipartReader{reader: multipart.NewReader(body, params["boundary"])}, nil
+}
+
+// NextPart returns the next part, or io.EOF after the last one.
+func (m *MultipartReader) NextPart() (*Part, error) {
+	if m.parts >= MaxMultipartParts {
+		return nil, fmt.Errorf("multipart response exceeds %d parts", MaxMultipartParts)
+	}
+
+	p, err := m.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	m.parts++
+
+	return &Part{
+		Headers:  http.Header(p.Header),
+		FormName: p.FormName(),
+		FileName: p.FileName(),
+		Body:     p,
+	}, nil
+}
@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseMultipart(t *testing.T) {
+	t.Run("reads mixed parts from a response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var buf bytes.Buffer
+			mw := multipart.NewWriter(&buf)
+			meta, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+			_, _ = meta.Write([]byte(`{"pages":3}`))
+			doc, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/pdf"}})
+			_, _ = doc.Write([]byte("%PDF-1.7"))
+			_ = mw.Close()
+
+			w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+			_, _ = w.Write(buf.Bytes())
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL))
+		require.NoError(t, err)
+		resp, err := client.Get(context.Background(), "/documents/1", nil)
+		require.NoError(t, err)
+
+		mr, err := resp.Multipart()
+		require.NoError(t, err)
+
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", part.Headers.Get("Content-Type"))
+		var meta struct{ Pages int }
+		require.NoError(t, part.JSON(&meta))
+		assert.Equal(t, 3, meta.Pages)
+
+		part, err = mr.NextPart()
+		require.NoError(t, err)
+		data, err := io.ReadAll(part.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "%PDF-1.7", string(data))
+
+		_, err = mr.NextPart()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("exposes form-data names", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("status", "ok")
+		file, _ := mw.CreateFormFile("report", "report.csv")
+		_, _ = file.Write([]byte("a,b\n"))
+		_ = mw.Close()
+		resp := &Response{
+			Headers: http.Header{"Content-Type": {mw.FormDataContentType()}},
+			Body:    buf.Bytes(),
+		}
+
+		mr, err := resp.Multipart()
+		require.NoError(t, err)
+
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "status", part.FormName)
+		part, err = mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "report", part.FormName)
+		assert.Equal(t, "report.csv", part.FileName)
+	})
+
+	t.Run("rejects non-multipart responses", func(t *testing.T) {
+		resp := &Response{Headers: http.Header{"Content-Type": {"application/json"}}}
+		_, err := resp.Multipart()
+		assert.Error(t, err)
+
+		resp = &Response{Headers: http.Header{"Content-Type": {"multipart/mixed"}}}
+		_, err = resp.Multipart()
+		assert.ErrorContains(t, err, "no boundary")
+	})
+}
+
+func TestDoIntoMultipart(t *testing.T) {
+	t.Run("delivers parts before the response ends", func(t *testing.T) {
+		firstSeen := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mw := multipart.NewWriter(w)
+			w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+			meta, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+			_, _ = meta.Write([]byte(`{"pages":3}`))
+			doc, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/pdf"}})
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-firstSeen:
+			case <-time.After(time.Second):
+				return
+			}
+			_, _ = doc.Write([]byte("%PDF-1.7"))
+			_ = mw.Close()
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		var pages int
+		var doc string
+		resp, err := client.DoIntoMultipart(context.Background(), http.MethodGet, "/documents/1", nil, func(part *Part) error {
+			if part.Headers.Get("Content-Type") == "application/json" {
+				var meta struct{ Pages int }
+				err := part.JSON(&meta)
+				pages = meta.Pages
+				close(firstSeen)
+				return err
+			}
+			data, err := io.ReadAll(part.Body)
+			doc = string(data)
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Body)
+		assert.Equal(t, 3, pages)
+		assert.Equal(t, "%PDF-1.7", doc)
+	})
+
+	t.Run("stops when fn returns an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mw := multipart.NewWriter(w)
+			w.Header().Set("Content-Type", mw.FormDataContentType())
+			_ = mw.WriteField("a", "1")
+			_ = mw.WriteField("b", "2")
+			_ = mw.Close()
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		stop := errors.New("stop")
+		var names []string
+		_, err = client.Request().Path("/form").DoIntoMultipart(context.Background(), func(part *Part) error {
+			names = append(names, part.FormName)
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, []string{"a"}, names)
+	})
+
+	t.Run("rejects a nil function", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://example.com"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.DoIntoMultipart(context.Background(), http.MethodGet, "/", nil, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"context"
+	"time"
+)
+
+// operationKey is the context key for operation names.
+type operationKey struct{}
+
+// WithOperation names the operation requests made with ctx perform, e.g.
+// "CreateShipment", so telemetry can be broken down by operation rather
+// than by URL template. Request logs and audit records carry the name,
+// collectors implementing OperationObserver receive it, and Stats and
+// endpoint policies such as WithAdaptiveTimeout key such requests by it
+// instead of by method and path template.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// GetOperation retrieves the operation name from the context.
+func GetOperation(ctx context.Context) string {
+	if name, ok := ctx.Value(operationKey{}).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// OperationObserver is implemented by collectors that also track attempts
+// by the operation name set with WithOperation. It is called after
+// ObserveRequest, only for requests with an operation.
+type OperationObserver interface {
+	ObserveOperation(operation string, status int, duration time.Duration, attempt int)
+}
+
+// callEndpoint returns the statistics key for a call: the operation name
+// when one is set, otherwise the method and path template.
+func callEndpoint(operation, method, path, template string) string {
+	if operation != "" {
+		return operation
+	}
+	return endpointKey(method, path, template)
+}
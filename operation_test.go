@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// operationCollector records operation observations.
+type operationCollector struct {
+	recordingCollector
+	operations []string
+}
+
+func (o *operationCollector) ObserveOperation(operation string, status int, duration time.Duration, attempt int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.operations = append(o.operations, operation)
+}
+
+func TestWithOperation(t *testing.T) {
+	assert.Empty(t, GetOperation(context.Background()))
+	assert.Equal(t, "CreateShipment", GetOperation(WithOperation(context.Background(), "CreateShipment")))
+
+	server := newStatusServer(http.StatusCreated)
+	defer server.Close()
+
+	logger := &testLogger{}
+	collector := &operationCollector{}
+	var records []AuditRecord
+	client, err := New(
+		WithBaseURL(server.URL),
+		WithLogger(logger),
+		WithMetrics(collector),
+		WithLatencyStats(time.Minute),
+		WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) error {
+			records = append(records, record)
+			return nil
+		}), testAuditKey),
+	)
+	require.NoError(t, err)
+
+	ctx := WithOperation(context.Background(), "CreateShipment")
+	_, err = client.Post(ctx, "/shipments", map[string]string{"to": "Berlin"}, nil, WithEndpointTemplate("/shipments"))
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), "/health", nil)
+	require.NoError(t, err)
+
+	t.Run("logs the operation", func(t *testing.T) {
+		entries := logger.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "CreateShipment", entries[0].Attrs["operation"])
+		assert.NotContains(t, entries[1].Attrs, "operation")
+	})
+
+	t.Run("observes the operation", func(t *testing.T) {
+		assert.Equal(t, []string{"CreateShipment"}, collector.operations)
+		assert.Len(t, collector.observations, 2)
+	})
+
+	t.Run("audits the operation", func(t *testing.T) {
+		require.Len(t, records, 2)
+		assert.Equal(t, "CreateShipment", records[0].Operation)
+		assert.Empty(t, records[1].Operation)
+	})
+
+	t.Run("keys statistics by operation", func(t *testing.T) {
+		stats := client.Stats()
+		assert.Equal(t, uint64(1), stats.Endpoints["CreateShipment"].Count)
+		assert.Equal(t, uint64(1), stats.Endpoints["GET /health"].Count)
+		assert.NotContains(t, stats.Endpoints, "POST /shipments")
+	})
+}
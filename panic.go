@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is the Err of an ErrKindInternal error: a panic in a
+// middleware, auth provider or hook that the client recovered, so a bug in
+// third-party code fails the request instead of crashing the goroutine.
+type PanicError struct {
+	// Source names what panicked, e.g. "middleware" or "auth".
+	Source string
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s: %v", e.Source, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverPanic, deferred, turns a panic in source into a *PanicError in
+// *err and logs it with its stack trace.
+func (c *Client) recoverPanic(ctx context.Context, source string, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	panicErr := &PanicError{Source: source, Value: v, Stack: debug.Stack()}
+	*err = panicErr
+	if c.logEnabled(ctx, slog.LevelError) {
+		c.logger.Log(ctx, slog.LevelError, "http_panic",
+			slog.String("source", source),
+			slog.String("panic", fmt.Sprint(v)),
+			slog.String("stack", string(panicErr.Stack)),
+		)
+	}
+}
+
+// runHook calls hook, returning its error or the panic it recovered from.
+func (c *Client) runHook(ctx context.Context, source string, hook func() error) (err error) {
+	defer c.recoverPanic(ctx, source, &err)
+	return hook()
+}
+
+// applyAuth applies auth to req, recovering a panic in the provider. It
+// takes no closure, as it runs on every attempt.
+func (c *Client) applyAuth(auth AuthProvider, req *http.Request) (err error) {
+	defer c.recoverPanic(req.Context(), "auth", &err)
+	return auth.Apply(req)
+}
+
+// internalKind returns ErrKindInternal if err is a recovered panic, and
+// kind otherwise.
+func internalKind(err error, kind ErrorKind) ErrorKind {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return ErrKindInternal
+	}
+	return kind
+}
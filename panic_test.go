@@ -0,0 +1,265 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingBalancer is a Balancer whose Pick panics.
+type panickingBalancer struct{}
+
+func (panickingBalancer) Pick(n int) int        { panic("bad balancer") }
+func (panickingBalancer) Done(i int, err error) {}
+
+// panicError asserts err is an ErrKindInternal error recovered from a panic
+// in source and returns the panic.
+func panicError(t *testing.T, err error, source string) *PanicError {
+	t.Helper()
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, ErrKindInternal, clientErr.Kind)
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, source, panicErr.Source)
+	assert.NotEmpty(t, panicErr.Stack)
+	return panicErr
+}
+
+func TestPanicRecovery(t *testing.T) {
+	t.Run("recovers middleware panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithMiddleware(func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+				panic("nil map")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		panicErr := panicError(t, err, "middleware")
+		assert.Equal(t, "nil map", panicErr.Value)
+		assert.Contains(t, err.Error(), "panic in middleware: nil map")
+
+		var logged bool
+		for _, entry := range logger.Entries() {
+			if entry.Msg == "http_panic" {
+				logged = true
+				assert.Equal(t, "middleware", entry.Attrs["source"])
+				assert.NotEmpty(t, entry.Attrs["stack"])
+			}
+		}
+		assert.True(t, logged)
+	})
+
+	t.Run("recovers auth panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(AuthFunc(func(req *http.Request) error {
+				var headers map[string]string
+				headers["Authorization"] = "Bearer x"
+				return nil
+			})),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		panicError(t, err, "auth")
+	})
+
+	t.Run("unwraps error panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		errBroken := errors.New("broken")
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithMiddleware(func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+				panic(errBroken)
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		assert.ErrorIs(t, err, errBroken)
+	})
+
+	t.Run("fails the request on success predicate panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithSuccessPredicate(func(resp *Response) error {
+				panic("bad predicate")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		panicError(t, err, "success predicate")
+	})
+
+	t.Run("survives hook panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{
+				MaxAttempts:  2,
+				InitialDelay: time.Millisecond,
+				MaxDelay:     time.Millisecond,
+				Multiplier:   1,
+				OnRetry: func(int, time.Duration, *Response, error) {
+					panic("bad retry hook")
+				},
+			}),
+			WithErrorEnricher(func(ctx context.Context, err *Error) {
+				panic("bad enricher")
+			}),
+			WithErrorEnricher(func(ctx context.Context, err *Error) {
+				err.Annotate("enriched", "yes")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindHTTP, clientErr.Kind)
+		assert.Equal(t, 2, clientErr.Attempts)
+		assert.Equal(t, "yes", clientErr.Annotations["enriched"])
+	})
+
+	t.Run("does not retry after RetryIf panics", func(t *testing.T) {
+		var status, hits atomic.Int32
+		status.Store(http.StatusServiceUnavailable)
+		server := newCountingServer(&status, &hits)
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithRetry(&RetryPolicy{
+				MaxAttempts:  3,
+				InitialDelay: time.Millisecond,
+				MaxDelay:     time.Millisecond,
+				Multiplier:   1,
+				RetryIf: func(*http.Response, error) bool {
+					panic("bad retry condition")
+				},
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindHTTP, clientErr.Kind)
+		assert.Equal(t, int32(1), hits.Load())
+
+		var sources []any
+		for _, entry := range logger.Entries() {
+			if entry.Msg == "http_panic" {
+				sources = append(sources, entry.Attrs["source"])
+			}
+		}
+		assert.Equal(t, []any{"retry condition"}, sources)
+	})
+
+	t.Run("does not retry recovered panics", func(t *testing.T) {
+		var status, hits atomic.Int32
+		status.Store(http.StatusOK)
+		server := newCountingServer(&status, &hits)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{
+				MaxAttempts:  3,
+				InitialDelay: time.Millisecond,
+				MaxDelay:     time.Millisecond,
+				Multiplier:   1,
+				RetryIf:      func(*http.Response, error) bool { return true },
+			}),
+			WithSuccessPredicate(func(resp *Response) error {
+				panic("bad predicate")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		panicError(t, err, "success predicate")
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("falls back to the client rate limit on key func panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRateLimit(10, time.Second),
+			WithRateLimitKeyFunc(func(*http.Request) string {
+				panic("bad key func")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("recovers balancer panics", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(WithLoggerDisabled(), WithBalancer(panickingBalancer{}, server.URL))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		panicError(t, err, "balancer")
+	})
+
+	t.Run("reports fallback panics as fallback failures", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithFallback(func(ctx context.Context, req *CapturedRequest, cause error) (*Response, error) {
+				panic("bad fallback")
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/rates", nil)
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "fallback", panicErr.Source)
+		assert.Contains(t, err.Error(), "fallback failed")
+	})
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PathJoinMode selects how request paths are joined to the base URL. In
+// every mode the request path is taken as already escaped, so an encoded
+// slash such as "a%2Fb" stays one segment.
+type PathJoinMode int
+
+const (
+	// PathJoinStrict joins like url.URL.JoinPath: "." and ".." segments are
+	// resolved and repeated slashes collapsed, while a trailing slash is
+	// kept. It is the default.
+	PathJoinStrict PathJoinMode = iota
+	// PathJoinPreserve puts exactly one slash between the base path and the
+	// request path and otherwise sends the request path as given, including
+	// repeated slashes and dot segments.
+	PathJoinPreserve
+	// PathJoinRaw appends the request path to the base path byte for byte,
+	// adding or removing no slash: "/v1" and "users" give "/v1users".
+	PathJoinRaw
+)
+
+// WithPathJoinMode sets how request paths are joined to the base URL, for
+// APIs that need exact path bytes on the wire. The default is
+// PathJoinStrict.
+func WithPathJoinMode(mode PathJoinMode) ClientOption {
+	return func(c *Client) error {
+		if mode < PathJoinStrict || mode > PathJoinRaw {
+			return fmt.Errorf("unknown path join mode %d", mode)
+		}
+		c.pathJoinMode = mode
+		return nil
+	}
+}
+
+// joinPath joins the escaped path to base under the client's mode. A path
+// with invalid escapes is rejected rather than dropped, as JoinPath would.
+func (c *Client) joinPath(base *url.URL, path string) (*url.URL, error) {
+	if _, err := url.PathUnescape(path); err != nil {
+		return nil, fmt.Errorf("invalid request path %q: %w", path, err)
+	}
+	switch {
+	case c.pathJoinMode == PathJoinRaw:
+		return withEscapedPath(base, base.EscapedPath()+path)
+	case c.pathJoinMode == PathJoinPreserve && path != "":
+		return withEscapedPath(base, strings.TrimSuffix(base.EscapedPath(), "/")+"/"+strings.TrimPrefix(path, "/"))
+	case c.pathJoinMode == PathJoinPreserve:
+		u := *base
+		return &u, nil
+	default:
+		return base.JoinPath(path), nil
+	}
+}
+
+// withEscapedPath returns a copy of base with the escaped path, failing if
+// the URL would not send it byte for byte.
+func withEscapedPath(base *url.URL, escaped string) (*url.URL, error) {
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request path %q: %w", escaped, err)
+	}
+	u := *base
+	u.Path = unescaped
+	u.RawPath = escaped
+	if u.EscapedPath() != escaped {
+		return nil, fmt.Errorf("invalid request path %q: characters must be escaped", escaped)
+	}
+	return &u, nil
+}
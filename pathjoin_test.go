@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPathJoinMode(t *testing.T) {
+	server := newURLServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		mode PathJoinMode
+		base string
+		path string
+		want string
+	}{
+		{"strict keeps encoded slash", PathJoinStrict, "/v1", "files/a%2Fb", "/v1/files/a%2Fb"},
+		{"strict keeps trailing slash", PathJoinStrict, "/v1/", "/users/", "/v1/users/"},
+		{"strict cleans path", PathJoinStrict, "/v1", "a//b/../c", "/v1/a/c"},
+		{"preserve keeps encoded slash", PathJoinPreserve, "/v1", "files/a%2Fb/", "/v1/files/a%2Fb/"},
+		{"preserve keeps repeated slashes", PathJoinPreserve, "/v1/", "/a//b/./c", "/v1/a//b/./c"},
+		{"preserve keeps base without path", PathJoinPreserve, "/v1/", "", "/v1/"},
+		{"raw concatenates", PathJoinRaw, "/v1/", "/users", "/v1//users"},
+		{"raw keeps encoded dots", PathJoinRaw, "/v1", "/a%2F%2E%2E%2Fb", "/v1/a%2F%2E%2E%2Fb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(WithBaseURL(server.URL+tt.base), WithPathJoinMode(tt.mode), WithLoggerDisabled())
+			require.NoError(t, err)
+
+			resp, err := client.Get(context.Background(), tt.path, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(resp.Body))
+		})
+	}
+
+	t.Run("applies to request base URL", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid"), WithPathJoinMode(PathJoinPreserve), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "a//b", nil, WithRequestBaseURL(server.URL+"/upload"))
+		require.NoError(t, err)
+		assert.Equal(t, "/upload/a//b", string(resp.Body))
+	})
+
+	t.Run("applies to balanced base URLs", func(t *testing.T) {
+		client, err := New(
+			WithBalancer(RoundRobin(), server.URL+"/a", server.URL+"/b"),
+			WithPathJoinMode(PathJoinPreserve),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		for _, want := range []string{"/a/x%2Fy//", "/b/x%2Fy//"} {
+			resp, err := client.Get(context.Background(), "x%2Fy//", nil)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(resp.Body))
+		}
+	})
+
+	t.Run("rejects invalid escapes", func(t *testing.T) {
+		for _, mode := range []PathJoinMode{PathJoinStrict, PathJoinPreserve, PathJoinRaw} {
+			client, err := New(WithBaseURL(server.URL), WithPathJoinMode(mode), WithLoggerDisabled())
+			require.NoError(t, err)
+
+			_, err = client.Get(context.Background(), "/discount/100%", nil)
+			require.ErrorContains(t, err, "invalid request path")
+		}
+	})
+
+	t.Run("rejects unescaped characters when preserving", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithPathJoinMode(PathJoinPreserve), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/a b", nil)
+		require.ErrorContains(t, err, "must be escaped")
+	})
+
+	t.Run("rejects unknown mode", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithPathJoinMode(PathJoinMode(9)))
+		require.Error(t, err)
+	})
+}
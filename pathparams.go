@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WithPathParam fills the {name} placeholder in the request path with value,
+// e.g. "/users/{id}" with WithPathParam("id", "a/b") requests "/users/a%2Fb".
+// Values are path-escaped, so slashes, spaces and query characters stay part
+// of the segment. Unless WithEndpointTemplate is given, statistics group
+// requests under the unexpanded template.
+func WithPathParam(name, value string) RequestOption {
+	return func(cfg *requestConfig) {
+		if cfg.pathParams == nil {
+			cfg.pathParams = make(map[string]string)
+		}
+		cfg.pathParams[name] = value
+	}
+}
+
+// expandPath substitutes params into the {name} placeholders of template.
+func expandPath(template string, params map[string]string) (string, error) {
+	var b strings.Builder
+	rest := template
+	for len(rest) > 0 {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("path %q has an unclosed placeholder", template)
+		}
+		name := rest[open+1 : open+end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("path %q has no value for {%s}; use WithPathParam", template, name)
+		}
+		if value == "" || value == "." || value == ".." {
+			return "", fmt.Errorf("path parameter %s value %q is not a valid segment", name, value)
+		}
+		b.WriteString(rest[:open])
+		b.WriteString(url.PathEscape(value))
+		rest = rest[open+end+1:]
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPathParam(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithLatencyStats(time.Minute))
+	require.NoError(t, err)
+
+	t.Run("expands and escapes placeholders", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/users/{id}/orders/{orderID}", nil,
+			WithPathParam("id", "a/b c"),
+			WithPathParam("orderID", "42?x=1"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "/users/a%2Fb%20c/orders/42%3Fx=1", gotPath)
+	})
+
+	t.Run("groups statistics under the template", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/items/{id}", nil, WithPathParam("id", "1"))
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/items/{id}", nil, WithPathParam("id", "2"))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(2), client.Stats().Endpoints["GET /items/{id}"].Count)
+	})
+
+	t.Run("rejects missing and unsafe values", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/users/{id}", nil, WithPathParam("other", "1"))
+		assert.ErrorContains(t, err, "{id}")
+
+		_, err = client.Get(context.Background(), "/users/{id}/x", nil, WithPathParam("id", ".."))
+		assert.Error(t, err)
+
+		_, err = client.Get(context.Background(), "/users/{id", nil, WithPathParam("id", "1"))
+		assert.Error(t, err)
+	})
+
+	t.Run("leaves paths alone without parameters", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/raw/{literal}", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/raw/%7Bliteral%7D", gotPath)
+	})
+
+	t.Run("builder", func(t *testing.T) {
+		_, err := client.Request().Path("/users/{id}").PathParam("id", "x y").Do(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "/users/x%20y", gotPath)
+	})
+}
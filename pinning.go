@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pinPrefix is the optional prefix of an SPKI pin, as used by HPKP and OkHttp.
+const pinPrefix = "sha256/"
+
+// SPKIPin returns the pin of a certificate's public key in "sha256/<base64>" form.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WithCertificatePinning rejects TLS connections unless a certificate in the
+// verified chain has a public key matching one of the SHA-256 SPKI pins.
+// Pins use "sha256/<base64>" form (the prefix is optional). Pass the current
+// and the next key's pin to rotate certificates without downtime.
+func WithCertificatePinning(pins ...string) ClientOption {
+	return func(c *Client) error {
+		if len(pins) == 0 {
+			return errors.New("at least one certificate pin is required")
+		}
+
+		decoded := make([][]byte, 0, len(pins))
+		for _, pin := range pins {
+			raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, pinPrefix))
+			if err != nil {
+				return fmt.Errorf("certificate pin %q is not valid base64: %w", pin, err)
+			}
+			if len(raw) != sha256.Size {
+				return fmt.Errorf("certificate pin %q must be a %d-byte SHA-256 digest, got %d bytes", pin, sha256.Size, len(raw))
+			}
+			decoded = append(decoded, raw)
+		}
+
+		c.transportHooks = append(c.transportHooks, func(t *http.Transport) error {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.VerifyConnection = chainVerifyConnection(t.TLSClientConfig.VerifyConnection, verifyPins(decoded))
+			return nil
+		})
+		return nil
+	}
+}
+
+// verifyPins returns a VerifyConnection callback that enforces pins. Only
+// the verified chains count: the certificates the peer sent may include a
+// pinned certificate it holds no key for.
+func verifyPins(pins [][]byte) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		for _, chain := range cs.VerifiedChains {
+			if chainMatchesPin(chain, pins) {
+				return nil
+			}
+		}
+		return fmt.Errorf("certificate pinning failed for %s: no verified certificate matches the configured pins", cs.ServerName)
+	}
+}
+
+// chainMatchesPin reports whether a certificate in chain has a pinned key.
+func chainMatchesPin(chain []*x509.Certificate, pins [][]byte) bool {
+	for _, cert := range chain {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if subtle.ConstantTimeCompare(sum[:], pin) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chainVerifyConnection runs existing before next so hooks compose.
+func chainVerifyConnection(existing, next func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	if existing == nil {
+		return next
+	}
+	return func(cs tls.ConnectionState) error {
+		if err := existing(cs); err != nil {
+			return err
+		}
+		return next(cs)
+	}
+}
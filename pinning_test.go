@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCertificatePinning(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	otherPin := pinPrefix + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	t.Run("accepts matching pin", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithHTTPClient(server.Client()),
+			WithCertificatePinning(SPKIPin(server.Certificate())),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts any of several pins during rotation", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithHTTPClient(server.Client()),
+			WithCertificatePinning(otherPin, strings.TrimPrefix(SPKIPin(server.Certificate()), pinPrefix)),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects non-matching pin", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithHTTPClient(server.Client()),
+			WithCertificatePinning(otherPin),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate pinning failed")
+	})
+
+	t.Run("ignores unverified certificates", func(t *testing.T) {
+		pin := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+		verify := verifyPins([][]byte{pin[:]})
+
+		err := verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.Certificate()}})
+		require.Error(t, err)
+
+		err = verify(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{server.Certificate()}}})
+		require.NoError(t, err)
+	})
+
+	t.Run("does not mutate the caller's http client", func(t *testing.T) {
+		httpClient := server.Client()
+		transport := httpClient.Transport
+
+		_, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(httpClient),
+			WithCertificatePinning(otherPin),
+		)
+		require.NoError(t, err)
+
+		assert.Same(t, transport, httpClient.Transport)
+		assert.Nil(t, httpClient.Transport.(*http.Transport).TLSClientConfig.VerifyConnection)
+	})
+}
+
+func TestWithCertificatePinning_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr string
+	}{
+		{"no pins", nil, "at least one certificate pin is required"},
+		{"invalid base64", []string{"sha256/not base64!"}, "is not valid base64"},
+		{"wrong length", []string{base64.StdEncoding.EncodeToString([]byte("short"))}, "must be a 32-byte SHA-256 digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(
+				WithBaseURL("https://api.example.com"),
+				WithCertificatePinning(tt.pins...),
+			)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("requires an http.Transport", func(t *testing.T) {
+		_, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithHTTPClient(&http.Client{Transport: NewMockTransport()}),
+			WithCertificatePinning(base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))),
+		)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "transport options require an *http.Transport")
+	})
+}
@@ -0,0 +1,125 @@
+package httpclient
+
+import "context"
+
+// Priority ranks requests when shared resources are scarce: the retry
+// budget, and the slots and tokens of WithMaxConcurrentRequests,
+// WithAdaptiveConcurrency and the rate limiters, which a saturated client
+// hands to waiting requests in order of priority. The zero value is
+// PriorityNormal.
+type Priority int
+
+const (
+	PriorityLow      Priority = -1
+	PriorityNormal   Priority = 0
+	PriorityHigh     Priority = 1
+	PriorityCritical Priority = 2
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// WithPriority sets the priority of this specific request, overriding one
+// set with WithContextPriority.
+func WithPriority(p Priority) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.priority = p
+		cfg.prioritySet = true
+	}
+}
+
+type priorityKey struct{}
+
+// WithContextPriority sets the priority of requests made with ctx, so a
+// background job can lower the priority of everything it calls.
+func WithContextPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// GetPriority retrieves the priority from the context, or PriorityNormal.
+func GetPriority(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// callPriority returns the priority of a request made with ctx and cfg.
+func callPriority(ctx context.Context, cfg *requestConfig) Priority {
+	if cfg.prioritySet {
+		return cfg.priority
+	}
+	return GetPriority(ctx)
+}
+
+// withCallPriority carries the call's priority in ctx to the limiters.
+func withCallPriority(ctx context.Context, cl *call) context.Context {
+	if GetPriority(ctx) == cl.priority {
+		return ctx
+	}
+	return WithContextPriority(ctx, cl.priority)
+}
+
+// priorityWaiter is a request queued for a slot or token.
+type priorityWaiter struct {
+	priority Priority
+	ready    chan struct{} // closed when the waiter is granted a slot
+}
+
+// waitQueue orders waiters by priority, then by arrival. It is not safe
+// for concurrent use; its owner's mutex guards it.
+type waitQueue struct {
+	waiters []*priorityWaiter
+}
+
+// push queues a waiter with priority p behind those of equal or higher
+// priority.
+func (q *waitQueue) push(p Priority) *priorityWaiter {
+	w := &priorityWaiter{priority: p, ready: make(chan struct{})}
+	i := len(q.waiters)
+	for i > 0 && q.waiters[i-1].priority < p {
+		i--
+	}
+	q.waiters = append(q.waiters, nil)
+	copy(q.waiters[i+1:], q.waiters[i:])
+	q.waiters[i] = w
+	return w
+}
+
+// pop removes and returns the first waiter, or nil.
+func (q *waitQueue) pop() *priorityWaiter {
+	if len(q.waiters) == 0 {
+		return nil
+	}
+	w := q.waiters[0]
+	q.waiters[0] = nil
+	q.waiters = q.waiters[1:]
+	return w
+}
+
+// remove takes w out of the queue and reports whether it was still queued.
+func (q *waitQueue) remove(w *priorityWaiter) bool {
+	for i, queued := range q.waiters {
+		if queued == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *waitQueue) len() int {
+	return len(q.waiters)
+}
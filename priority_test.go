@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriority_String(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		expected string
+	}{
+		{PriorityLow, "low"},
+		{PriorityNormal, "normal"},
+		{PriorityHigh, "high"},
+		{PriorityCritical, "critical"},
+		{Priority(42), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.priority.String())
+		})
+	}
+}
+
+func TestPriority_ZeroValueIsNormal(t *testing.T) {
+	var p Priority
+	assert.Equal(t, PriorityNormal, p)
+}
+
+func TestContextPriority(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, PriorityNormal, GetPriority(ctx))
+
+	low := WithContextPriority(ctx, PriorityLow)
+	assert.Equal(t, PriorityLow, GetPriority(low))
+
+	cfg := newRequestConfig()
+	assert.Equal(t, PriorityLow, callPriority(low, cfg))
+	WithPriority(PriorityNormal)(cfg)
+	assert.Equal(t, PriorityNormal, callPriority(low, cfg), "request option wins")
+}
+
+func TestWaitQueue(t *testing.T) {
+	var q waitQueue
+	low := q.push(PriorityLow)
+	normal1 := q.push(PriorityNormal)
+	critical := q.push(PriorityCritical)
+	normal2 := q.push(PriorityNormal)
+
+	assert.True(t, q.remove(low))
+	assert.False(t, q.remove(low))
+	assert.Same(t, critical, q.pop())
+	assert.Same(t, normal1, q.pop())
+	assert.Same(t, normal2, q.pop())
+	assert.Nil(t, q.pop())
+}
+
+// dispatchOrder starts a low and then a high priority waiter on a
+// saturated limiter, frees capacity with release and returns the order in
+// which the waiters got through.
+func dispatchOrder(t *testing.T, wait func(ctx context.Context) error, release func()) []Priority {
+	t.Helper()
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for _, p := range []Priority{PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(WithContextPriority(context.Background(), p), 2*time.Second)
+			defer cancel()
+			if assert.NoError(t, wait(ctx)) {
+				mu.Lock()
+				order = append(order, p)
+				mu.Unlock()
+				release()
+			}
+		}()
+		time.Sleep(20 * time.Millisecond) // queue in order
+	}
+	release()
+	wg.Wait()
+	return order
+}
+
+func TestPriorityDispatch(t *testing.T) {
+	want := []Priority{PriorityHigh, PriorityLow}
+
+	t.Run("bulkhead", func(t *testing.T) {
+		b := &bulkhead{max: 1, maxWait: time.Second}
+		require.NoError(t, b.acquire(context.Background()))
+		assert.Equal(t, want, dispatchOrder(t, b.acquire, b.release))
+	})
+
+	t.Run("adaptive limiter", func(t *testing.T) {
+		limiter, err := NewAdaptiveLimiter(AdaptiveLimiterConfig{InitialLimit: 1, MinLimit: 1, MaxLimit: 1})
+		require.NoError(t, err)
+		var mu sync.Mutex
+		var releases []func(time.Duration, bool)
+		acquire := func(ctx context.Context) error {
+			release, err := limiter.Acquire(ctx)
+			if err == nil {
+				mu.Lock()
+				releases = append(releases, release)
+				mu.Unlock()
+			}
+			return err
+		}
+		release := func() {
+			mu.Lock()
+			next := releases[0]
+			releases = releases[1:]
+			mu.Unlock()
+			next(0, false)
+		}
+		require.NoError(t, acquire(context.Background()))
+		assert.Equal(t, want, dispatchOrder(t, acquire, release))
+	})
+
+	t.Run("rate limiter", func(t *testing.T) {
+		limiter := NewRateLimiter(1, 100*time.Millisecond)
+		require.NoError(t, limiter.Wait(context.Background()))
+		assert.Equal(t, want, dispatchOrder(t, limiter.Wait, func() {}))
+	})
+
+	t.Run("client requests", func(t *testing.T) {
+		var mu sync.Mutex
+		var paths []string
+		unblock := make(chan struct{})
+		started := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/first" {
+				close(started)
+				<-unblock
+				return
+			}
+			mu.Lock()
+			paths = append(paths, r.URL.Path)
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithMaxConcurrentRequests(1, time.Second))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		get := func(ctx context.Context, path string, opts ...RequestOption) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.Get(ctx, path, nil, opts...)
+				assert.NoError(t, err)
+			}()
+		}
+		get(context.Background(), "/first")
+		<-started
+		get(WithContextPriority(context.Background(), PriorityLow), "/background")
+		time.Sleep(20 * time.Millisecond)
+		get(context.Background(), "/interactive", WithPriority(PriorityHigh))
+		time.Sleep(20 * time.Millisecond)
+		close(unblock)
+		wg.Wait()
+
+		assert.Equal(t, []string{"/interactive", "/background"}, paths)
+	})
+}
@@ -0,0 +1,281 @@
+package httpclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPrometheusSeries bounds the label combinations per metric; further
+// hosts are aggregated under OtherEndpoint.
+const maxPrometheusSeries = 1024
+
+// PrometheusCollector is a MetricsCollector, RateLimitObserver and
+// TLSObserver that serves its metrics in the Prometheus text exposition
+// format, without a dependency on the Prometheus client library. Mount it
+// on a metrics endpoint:
+//
+//	metrics := httpclient.NewPrometheusCollector("payments")
+//	client, err := httpclient.New(httpclient.WithMetrics(metrics), ...)
+//	http.Handle("/metrics", metrics)
+//
+// It exports httpclient_requests_total, httpclient_retries_total,
+// httpclient_request_duration_seconds, httpclient_rate_limit_waits_total,
+// httpclient_rate_limit_rejections_total,
+// httpclient_rate_limit_wait_seconds_total and
+// httpclient_tls_handshakes_total. It is safe for concurrent use.
+type PrometheusCollector struct {
+	mu        sync.Mutex
+	client    string
+	requests  map[requestSeries]uint64
+	retries   map[hostSeries]uint64
+	durations map[hostSeries]*promHistogram
+	waits     map[string]*rateLimitSeries
+	tls       map[tlsSeries]uint64
+}
+
+type requestSeries struct {
+	method, host, status string
+}
+
+type hostSeries struct {
+	method, host string
+}
+
+type promHistogram struct {
+	counts [len(latencyBuckets)]uint64 // per bucket, made cumulative on export
+	count  uint64
+	sum    float64
+}
+
+type tlsSeries struct {
+	host    string
+	resumed bool
+}
+
+type rateLimitSeries struct {
+	waits    uint64
+	rejected uint64
+	seconds  float64
+}
+
+// NewPrometheusCollector creates a collector whose series carry a client
+// label, e.g. the integration name, so several clients can share one.
+func NewPrometheusCollector(client string) *PrometheusCollector {
+	return &PrometheusCollector{
+		client:    client,
+		requests:  make(map[requestSeries]uint64),
+		retries:   make(map[hostSeries]uint64),
+		durations: make(map[hostSeries]*promHistogram),
+		waits:     make(map[string]*rateLimitSeries),
+		tls:       make(map[tlsSeries]uint64),
+	}
+}
+
+// ObserveRequest implements MetricsCollector.
+func (p *PrometheusCollector) ObserveRequest(method, host string, status int, duration time.Duration, attempt int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.durations) >= maxPrometheusSeries {
+		if _, ok := p.durations[hostSeries{method, host}]; !ok {
+			host = OtherEndpoint
+		}
+	}
+	statusLabel := "error"
+	if status > 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+
+	p.requests[requestSeries{method, host, statusLabel}]++
+	if attempt > 1 {
+		p.retries[hostSeries{method, host}]++
+	}
+
+	h, ok := p.durations[hostSeries{method, host}]
+	if !ok {
+		h = &promHistogram{}
+		p.durations[hostSeries{method, host}] = h
+	}
+	for i, bound := range latencyBuckets {
+		if duration <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += duration.Seconds()
+}
+
+// ObserveRateLimitWait implements RateLimitObserver.
+func (p *PrometheusCollector) ObserveRateLimitWait(host string, wait time.Duration, rejected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.waits[host]; !ok && len(p.waits) >= maxPrometheusSeries {
+		host = OtherEndpoint
+	}
+	s, ok := p.waits[host]
+	if !ok {
+		s = &rateLimitSeries{}
+		p.waits[host] = s
+	}
+	s.waits++
+	s.seconds += wait.Seconds()
+	if rejected {
+		s.rejected++
+	}
+}
+
+// ObserveTLSHandshake implements TLSObserver.
+func (p *PrometheusCollector) ObserveTLSHandshake(host string, resumed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.tls[tlsSeries{host, resumed}]; !ok && len(p.tls) >= maxPrometheusSeries {
+		host = OtherEndpoint
+	}
+	p.tls[tlsSeries{host, resumed}]++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (p *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.Export(w)
+}
+
+// Export writes the metrics in the Prometheus text exposition format.
+func (p *PrometheusCollector) Export(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	p.writeRequests(bw)
+	p.writeDurations(bw)
+	p.writeRateLimits(bw)
+	p.writeTLSHandshakes(bw)
+	return bw.Flush()
+}
+
+func (p *PrometheusCollector) writeRequests(w *bufio.Writer) {
+	writeMetricHeader(w, "httpclient_requests_total", "counter", "HTTP request attempts by status; status is \"error\" when no response was received.")
+	keys := make([]requestSeries, 0, len(p.requests))
+	for k := range p.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "httpclient_requests_total{%s,status=%s} %d\n", p.labels(k.method, k.host), quoteLabel(k.status), p.requests[k])
+	}
+
+	writeMetricHeader(w, "httpclient_retries_total", "counter", "HTTP request attempts after the first.")
+	for _, k := range sortedHostSeries(p.retries) {
+		fmt.Fprintf(w, "httpclient_retries_total{%s} %d\n", p.labels(k.method, k.host), p.retries[k])
+	}
+}
+
+func (p *PrometheusCollector) writeDurations(w *bufio.Writer) {
+	writeMetricHeader(w, "httpclient_request_duration_seconds", "histogram", "HTTP request attempt latency.")
+	for _, k := range sortedHostSeries(p.durations) {
+		h := p.durations[k]
+		labels := p.labels(k.method, k.host)
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "httpclient_request_duration_seconds_bucket{%s,le=%s} %d\n", labels, quoteLabel(formatFloat(bound.Seconds())), cumulative)
+		}
+		fmt.Fprintf(w, "httpclient_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "httpclient_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "httpclient_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+}
+
+func (p *PrometheusCollector) writeRateLimits(w *bufio.Writer) {
+	hosts := make([]string, 0, len(p.waits))
+	for host := range p.waits {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	writeMetricHeader(w, "httpclient_rate_limit_waits_total", "counter", "Requests that waited for the client-side rate limiter.")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "httpclient_rate_limit_waits_total{%s} %d\n", p.hostLabels(host), p.waits[host].waits)
+	}
+	writeMetricHeader(w, "httpclient_rate_limit_rejections_total", "counter", "Requests abandoned while waiting for the rate limiter.")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "httpclient_rate_limit_rejections_total{%s} %d\n", p.hostLabels(host), p.waits[host].rejected)
+	}
+	writeMetricHeader(w, "httpclient_rate_limit_wait_seconds_total", "counter", "Time spent waiting for the rate limiter.")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "httpclient_rate_limit_wait_seconds_total{%s} %s\n", p.hostLabels(host), formatFloat(p.waits[host].seconds))
+	}
+}
+
+func (p *PrometheusCollector) writeTLSHandshakes(w *bufio.Writer) {
+	keys := make([]tlsSeries, 0, len(p.tls))
+	for k := range p.tls {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return !keys[i].resumed && keys[j].resumed
+	})
+
+	writeMetricHeader(w, "httpclient_tls_handshakes_total", "counter", "TLS handshakes of new connections by whether they resumed a session.")
+	for _, k := range keys {
+		fmt.Fprintf(w, "httpclient_tls_handshakes_total{%s,resumed=%s} %d\n", p.hostLabels(k.host), quoteLabel(strconv.FormatBool(k.resumed)), p.tls[k])
+	}
+}
+
+func (p *PrometheusCollector) labels(method, host string) string {
+	return fmt.Sprintf("client=%s,method=%s,host=%s", quoteLabel(p.client), quoteLabel(method), quoteLabel(host))
+}
+
+func (p *PrometheusCollector) hostLabels(host string) string {
+	return fmt.Sprintf("client=%s,host=%s", quoteLabel(p.client), quoteLabel(host))
+}
+
+func sortedHostSeries[V any](m map[hostSeries]V) []hostSeries {
+	keys := make([]hostSeries, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return keys[i].method < keys[j].method
+	})
+	return keys
+}
+
+func writeMetricHeader(w *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// quoteLabel quotes a label value, escaping as the exposition format requires.
+func quoteLabel(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(v) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	p := NewPrometheusCollector("payments")
+	p.ObserveRequest(http.MethodGet, "api.example.com", 503, 3*time.Millisecond, 1)
+	p.ObserveRequest(http.MethodGet, "api.example.com", 200, 40*time.Millisecond, 2)
+	p.ObserveRequest(http.MethodPost, "api.example.com", 0, 2*time.Minute, 1)
+	p.ObserveRateLimitWait("api.example.com", 250*time.Millisecond, false)
+	p.ObserveRateLimitWait("api.example.com", 250*time.Millisecond, true)
+	p.ObserveTLSHandshake("api.example.com", false)
+	p.ObserveTLSHandshake("api.example.com", true)
+	p.ObserveTLSHandshake("api.example.com", true)
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, out, "# TYPE httpclient_requests_total counter\n")
+	assert.Contains(t, out, `httpclient_requests_total{client="payments",method="GET",host="api.example.com",status="200"} 1`)
+	assert.Contains(t, out, `httpclient_requests_total{client="payments",method="GET",host="api.example.com",status="503"} 1`)
+	assert.Contains(t, out, `httpclient_requests_total{client="payments",method="POST",host="api.example.com",status="error"} 1`)
+	assert.Contains(t, out, `httpclient_retries_total{client="payments",method="GET",host="api.example.com"} 1`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{client="payments",method="GET",host="api.example.com",le="0.005"} 1`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{client="payments",method="GET",host="api.example.com",le="0.05"} 2`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{client="payments",method="POST",host="api.example.com",le="60"} 0`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{client="payments",method="POST",host="api.example.com",le="+Inf"} 1`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_sum{client="payments",method="GET",host="api.example.com"} 0.043`)
+	assert.Contains(t, out, `httpclient_request_duration_seconds_count{client="payments",method="GET",host="api.example.com"} 2`)
+	assert.Contains(t, out, `httpclient_rate_limit_waits_total{client="payments",host="api.example.com"} 2`)
+	assert.Contains(t, out, `httpclient_rate_limit_rejections_total{client="payments",host="api.example.com"} 1`)
+	assert.Contains(t, out, `httpclient_rate_limit_wait_seconds_total{client="payments",host="api.example.com"} 0.5`)
+	assert.Contains(t, out, `httpclient_tls_handshakes_total{client="payments",host="api.example.com",resumed="false"} 1`)
+	assert.Contains(t, out, `httpclient_tls_handshakes_total{client="payments",host="api.example.com",resumed="true"} 2`)
+}
+
+func TestQuoteLabel(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
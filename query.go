@@ -0,0 +1,243 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryStyle selects how WithQuerySlice encodes an array, following the
+// OpenAPI parameter styles.
+type QueryStyle int
+
+const (
+	// QueryRepeat repeats the key per value: ids=1&ids=2 (form, explode).
+	QueryRepeat QueryStyle = iota
+	// QueryComma joins values with commas: ids=1,2 (form, no explode).
+	QueryComma
+	// QueryPipe joins values with pipes: ids=1|2 (pipeDelimited).
+	QueryPipe
+	// QuerySpace joins values with spaces: ids=1+2 (spaceDelimited).
+	QuerySpace
+)
+
+// WithQueryInt adds an integer query parameter.
+func WithQueryInt(key string, value int64) RequestOption {
+	return WithQuery(key, strconv.FormatInt(value, 10))
+}
+
+// WithQueryBool adds a boolean query parameter as "true" or "false".
+func WithQueryBool(key string, value bool) RequestOption {
+	return WithQuery(key, strconv.FormatBool(value))
+}
+
+// WithQueryTime adds a time query parameter formatted with layout, e.g.
+// time.RFC3339 or time.DateOnly.
+func WithQueryTime(key string, value time.Time, layout string) RequestOption {
+	return WithQuery(key, value.Format(layout))
+}
+
+// WithQuerySlice adds an array query parameter encoded in style. Values are
+// formatted with fmt.Sprint. An empty slice adds nothing.
+func WithQuerySlice[T any](key string, values []T, style QueryStyle) RequestOption {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = fmt.Sprint(v)
+	}
+
+	return func(cfg *requestConfig) {
+		if len(strs) == 0 {
+			return
+		}
+		switch style {
+		case QueryComma:
+			cfg.query.Add(key, strings.Join(strs, ","))
+		case QueryPipe:
+			cfg.query.Add(key, strings.Join(strs, "|"))
+		case QuerySpace:
+			cfg.query.Add(key, strings.Join(strs, " "))
+		default:
+			for _, s := range strs {
+				cfg.query.Add(key, s)
+			}
+		}
+	}
+}
+
+// QueryEncoding controls how query parameters are escaped, for legacy APIs
+// that reject the standard form encoding.
+type QueryEncoding struct {
+	// SpaceAsPercent20 encodes spaces as %20 instead of +.
+	SpaceAsPercent20 bool
+	// Unescaped lists characters sent literally instead of percent-encoded,
+	// e.g. ",:" for ids=1,2 or since=12:30. Only characters allowed in a
+	// query by RFC 3986 other than & = + and %, plus [ ] and |, may be given.
+	Unescaped string
+
+	unescape *strings.Replacer
+}
+
+// queryLiterals are the characters QueryEncoding.Unescaped may hold.
+const queryLiterals = "!$'()*,/:;?@[]|"
+
+// WithQueryEncoding encodes query parameters from WithQuery and its typed
+// variants with enc instead of the standard form encoding. Parameters are
+// still sorted by key. A URL's own query is sent as it is.
+func WithQueryEncoding(enc QueryEncoding) ClientOption {
+	return func(c *Client) error {
+		var pairs []string
+		for _, r := range enc.Unescaped {
+			if !strings.ContainsRune(queryLiterals, r) {
+				return fmt.Errorf("query character %q cannot be left unescaped", r)
+			}
+			pairs = append(pairs, fmt.Sprintf("%%%02X", r), string(r))
+		}
+		if len(pairs) > 0 {
+			enc.unescape = strings.NewReplacer(pairs...)
+		}
+		c.queryEncoding = &enc
+		return nil
+	}
+}
+
+// WithRawQuery appends query to this request's URL exactly as given, after
+// any encoded parameters, e.g. "filter=a:b,c" for an API that rejects
+// escaping. It must not contain spaces, control characters or '#'.
+func WithRawQuery(query string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.rawQuery = query
+	}
+}
+
+// ErrQueryConflict is returned under QueryMergeError when a request sets
+// a query parameter the URL already has.
+var ErrQueryConflict = errors.New("query parameter conflict")
+
+// QueryMergePolicy selects how query parameters from WithQuery combine with
+// parameters of the same key already in the base URL or an absolute URL.
+type QueryMergePolicy int
+
+const (
+	// QueryMergeAppend keeps both, the URL's parameters first. It is the
+	// default.
+	QueryMergeAppend QueryMergePolicy = iota
+	// QueryMergeReplace drops the URL's parameters with a key the request
+	// sets.
+	QueryMergeReplace
+	// QueryMergeError fails the request with ErrQueryConflict.
+	QueryMergeError
+)
+
+// WithQueryMergePolicy sets how request query parameters combine with the
+// base URL's or an absolute URL's own, e.g. a base URL carrying
+// "?api-version=2" or a pagination link carrying its cursor. The URL's
+// other parameters are always kept byte for byte. WithRawQuery is appended
+// regardless of the policy.
+func WithQueryMergePolicy(policy QueryMergePolicy) ClientOption {
+	return func(c *Client) error {
+		if policy < QueryMergeAppend || policy > QueryMergeError {
+			return fmt.Errorf("unknown query merge policy %d", policy)
+		}
+		c.queryMergePolicy = policy
+		return nil
+	}
+}
+
+// addQuery merges the request's query parameters into u's own query under
+// the merge policy, then appends WithRawQuery.
+func (c *Client) addQuery(u *url.URL, cfg *requestConfig) error {
+	query, err := c.mergeQuery(u.RawQuery, cfg.query)
+	if err != nil {
+		return err
+	}
+	if cfg.rawQuery != "" {
+		if query, err = appendRawQuery(query, cfg.rawQuery); err != nil {
+			return err
+		}
+	}
+	u.RawQuery = query
+	return nil
+}
+
+// mergeQuery appends the encoded values to the raw query own, leaving the
+// pairs of own it keeps untouched.
+func (c *Client) mergeQuery(own string, values url.Values) (string, error) {
+	if len(values) == 0 {
+		return own, nil
+	}
+	var pairs []string
+	for pair := range strings.SplitSeq(own, "&") {
+		if pair == "" {
+			continue
+		}
+		key := queryKey(pair)
+		if !values.Has(key) {
+			pairs = append(pairs, pair)
+			continue
+		}
+		switch c.queryMergePolicy {
+		case QueryMergeError:
+			return "", fmt.Errorf("%w: %q is already in the URL", ErrQueryConflict, key)
+		case QueryMergeAppend:
+			pairs = append(pairs, pair)
+		}
+	}
+	return strings.Join(append(pairs, c.queryEncoding.encode(values)), "&"), nil
+}
+
+// queryKey returns the unescaped key of a raw "key=value" pair.
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
+
+// encode encodes values like url.Values.Encode, with the encoding's escaping.
+func (e *QueryEncoding) encode(values url.Values) string {
+	if e == nil {
+		return values.Encode()
+	}
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(e.escape(key))
+			b.WriteByte('=')
+			b.WriteString(e.escape(value))
+		}
+	}
+	return b.String()
+}
+
+func (e *QueryEncoding) escape(s string) string {
+	escaped := url.QueryEscape(s)
+	if e.SpaceAsPercent20 {
+		// QueryEscape encodes a literal plus as %2B, so any + is a space.
+		escaped = strings.ReplaceAll(escaped, "+", "%20")
+	}
+	if e.unescape != nil {
+		escaped = e.unescape.Replace(escaped)
+	}
+	return escaped
+}
+
+// appendRawQuery adds raw to the encoded query, rejecting characters that
+// would end the query or break the request line.
+func appendRawQuery(query, raw string) (string, error) {
+	if strings.ContainsFunc(raw, func(r rune) bool { return r <= ' ' || r == 0x7f || r == '#' }) {
+		return "", errors.New("raw query cannot contain spaces, control characters or '#'")
+	}
+	if query == "" {
+		return raw, nil
+	}
+	return query + "&" + raw, nil
+}
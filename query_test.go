@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedQueryOptions(t *testing.T) {
+	encode := func(opts ...RequestOption) string {
+		cfg := newRequestConfig()
+		for _, opt := range opts {
+			opt(cfg)
+		}
+		return cfg.query.Encode()
+	}
+
+	t.Run("scalars", func(t *testing.T) {
+		at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+		assert.Equal(t, "limit=50", encode(WithQueryInt("limit", 50)))
+		assert.Equal(t, "archived=false", encode(WithQueryBool("archived", false)))
+		assert.Equal(t, "since=2024-03-01T12%3A30%3A00Z", encode(WithQueryTime("since", at, time.RFC3339)))
+		assert.Equal(t, "day=2024-03-01", encode(WithQueryTime("day", at, time.DateOnly)))
+	})
+
+	t.Run("slice styles", func(t *testing.T) {
+		ids := []int{1, 2, 3}
+
+		assert.Equal(t, "id=1&id=2&id=3", encode(WithQuerySlice("id", ids, QueryRepeat)))
+		assert.Equal(t, "id=1%2C2%2C3", encode(WithQuerySlice("id", ids, QueryComma)))
+		assert.Equal(t, "id=1%7C2%7C3", encode(WithQuerySlice("id", ids, QueryPipe)))
+		assert.Equal(t, "id=1+2+3", encode(WithQuerySlice("id", ids, QuerySpace)))
+		assert.Equal(t, "tag=a&tag=b", encode(WithQuerySlice("tag", []string{"a", "b"}, QueryRepeat)))
+	})
+
+	t.Run("empty slice adds nothing", func(t *testing.T) {
+		assert.Empty(t, encode(WithQuerySlice("id", []int{}, QueryComma)))
+	})
+}
+
+func TestWithQueryEncoding(t *testing.T) {
+	rawQuery := func(t *testing.T, clientOpts []ClientOption, path string, opts ...RequestOption) string {
+		t.Helper()
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.URL.RawQuery
+		}))
+		defer server.Close()
+
+		client, err := New(append([]ClientOption{WithBaseURL(server.URL), WithLoggerDisabled()}, clientOpts...)...)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), path, nil, opts...)
+		require.NoError(t, err)
+		return got
+	}
+
+	t.Run("uses form encoding by default", func(t *testing.T) {
+		got := rawQuery(t, nil, "/search", WithQuery("q", "a b+c"), WithQuerySlice("id", []int{1, 2}, QueryComma))
+		assert.Equal(t, "id=1%2C2&q=a+b%2Bc", got)
+	})
+
+	t.Run("encodes spaces as %20", func(t *testing.T) {
+		got := rawQuery(t, []ClientOption{WithQueryEncoding(QueryEncoding{SpaceAsPercent20: true})},
+			"/search", WithQuery("q", "a b+c"))
+		assert.Equal(t, "q=a%20b%2Bc", got)
+	})
+
+	t.Run("leaves listed characters unescaped", func(t *testing.T) {
+		at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+		got := rawQuery(t, []ClientOption{WithQueryEncoding(QueryEncoding{Unescaped: ",:[]"})},
+			"/search", WithQuerySlice("id", []int{1, 2}, QueryComma), WithQueryTime("since", at, time.RFC3339),
+			WithQuery("filter[name]", "x&y"))
+		assert.Equal(t, "filter[name]=x%26y&id=1,2&since=2024-03-01T12:30:00Z", got)
+	})
+
+	t.Run("keeps the URL's own query as is", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid"), WithQueryEncoding(QueryEncoding{SpaceAsPercent20: true}))
+		require.NoError(t, err)
+		cfg := newRequestConfig()
+		WithQuery("page", "2")(cfg)
+
+		u, err := client.requestURL("http://api.example.invalid/search?q=a+b", cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "q=a+b&page=2", u.RawQuery)
+	})
+
+	t.Run("appends raw query as is", func(t *testing.T) {
+		got := rawQuery(t, nil, "/search", WithQuery("page", "2"), WithRawQuery("filter=a:b,c&sort=-name"))
+		assert.Equal(t, "page=2&filter=a:b,c&sort=-name", got)
+	})
+
+	t.Run("rejects raw query that would break the URL", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		for _, raw := range []string{"a=b c", "a=b#frag", "a=b\r\nHost: evil"} {
+			_, err = client.Get(context.Background(), "/search", nil, WithRawQuery(raw))
+			assert.ErrorContains(t, err, "raw query cannot contain", raw)
+		}
+	})
+
+	t.Run("rejects characters that change the query's meaning", func(t *testing.T) {
+		for _, chars := range []string{"&", "=", "+", "%", "#", " "} {
+			_, err := New(WithBaseURL("http://api.example.invalid"), WithQueryEncoding(QueryEncoding{Unescaped: chars}))
+			assert.Error(t, err, chars)
+		}
+	})
+}
+
+func TestWithQueryMergePolicy(t *testing.T) {
+	server := newURLServer()
+	defer server.Close()
+
+	get := func(t *testing.T, policy QueryMergePolicy, baseQuery string, opts ...RequestOption) (string, error) {
+		t.Helper()
+		client, err := New(WithBaseURL(server.URL+"/v1?"+baseQuery), WithQueryMergePolicy(policy), WithLoggerDisabled())
+		require.NoError(t, err)
+		resp, err := client.Get(context.Background(), "/items", nil, opts...)
+		if err != nil {
+			return "", err
+		}
+		return string(resp.Body), nil
+	}
+
+	t.Run("keeps base query byte for byte", func(t *testing.T) {
+		got, err := get(t, QueryMergeAppend, "api-version=2024-01-01&sig=a%2Bb~c", WithQuery("page", "2"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?api-version=2024-01-01&sig=a%2Bb~c&page=2", got)
+	})
+
+	t.Run("keeps base query without request parameters", func(t *testing.T) {
+		got, err := get(t, QueryMergeError, "key=a%20b")
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?key=a%20b", got)
+	})
+
+	t.Run("appends duplicate keys", func(t *testing.T) {
+		got, err := get(t, QueryMergeAppend, "tag=a&limit=10", WithQuery("tag", "b"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?tag=a&limit=10&tag=b", got)
+	})
+
+	t.Run("replaces duplicate keys", func(t *testing.T) {
+		got, err := get(t, QueryMergeReplace, "tag=a&limit=10&tag=c", WithQuery("tag", "b"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?limit=10&tag=b", got)
+	})
+
+	t.Run("matches escaped keys", func(t *testing.T) {
+		got, err := get(t, QueryMergeReplace, "filter%5Bname%5D=x", WithQuery("filter[name]", "y"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?filter%5Bname%5D=y", got)
+	})
+
+	t.Run("fails on duplicate keys", func(t *testing.T) {
+		_, err := get(t, QueryMergeError, "tag=a", WithQuery("tag", "b"))
+		require.ErrorIs(t, err, ErrQueryConflict)
+
+		got, err := get(t, QueryMergeError, "tag=a", WithQuery("page", "1"), WithRawQuery("tag=b"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/items?tag=a&page=1&tag=b", got)
+	})
+
+	t.Run("applies to absolute URLs", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithQueryMergePolicy(QueryMergeReplace), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), server.URL+"/items?cursor=abc&limit=10", nil,
+			WithQuery("limit", "50"))
+		require.NoError(t, err)
+		assert.Equal(t, "/items?cursor=abc&limit=50", string(resp.Body))
+	})
+
+	t.Run("keeps each balanced base URL's query", func(t *testing.T) {
+		client, err := New(
+			WithBalancer(RoundRobin(), server.URL+"/a?region=eu", server.URL+"/b?region=us"),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		for _, want := range []string{"/a/items?region=eu&page=2", "/b/items?region=us&page=2"} {
+			resp, err := client.Get(context.Background(), "/items", nil, WithQuery("page", "2"))
+			require.NoError(t, err)
+			assert.Equal(t, want, string(resp.Body))
+		}
+	})
+
+	t.Run("rejects unknown policy", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithQueryMergePolicy(QueryMergePolicy(7)))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// minEpochReset distinguishes a rate limit reset given as a Unix time, as
+// GitHub and Twitter send it, from one given in seconds from now.
+const minEpochReset = 1_000_000_000
+
+// quotaHeaders are the remaining and reset header pairs the client reads,
+// in order of preference.
+var quotaHeaders = [][2]string{
+	{"RateLimit-Remaining", "RateLimit-Reset"},     // IETF draft
+	{"X-RateLimit-Remaining", "X-RateLimit-Reset"}, // GitHub and most APIs
+	{"X-Rate-Limit-Remaining", "X-Rate-Limit-Reset"},
+}
+
+// WithAdaptiveRateLimit throttles requests by the quota servers report in
+// RateLimit-*, X-RateLimit-* or X-Rate-Limit-* remaining and reset
+// headers, with resets in seconds or as a Unix time, and by the
+// Retry-After of a 429 response: once the quota is used up, requests wait
+// for the reset instead of being rejected, unless the reset is further
+// away than the request's deadline or the client timeout, in which case
+// they fail at once with ErrKindRateLimit. It works alongside WithRateLimit
+// or WithSharedRateLimit, which then also apply, or on its own.
+func WithAdaptiveRateLimit() ClientOption {
+	return func(c *Client) error {
+		c.adaptiveRateLimit = true
+		return nil
+	}
+}
+
+// configureAdaptiveRateLimit gives the client a limiter to feed quotas to.
+func (c *Client) configureAdaptiveRateLimit() {
+	if c.adaptiveRateLimit && c.rateLimiter == nil {
+		c.rateLimiter = &RateLimiter{unlimited: true}
+	}
+}
+
+// observeQuota passes the quota reported in resp to the call's rate limiter.
+func (c *Client) observeQuota(cl *call, resp *http.Response) {
+	if !c.adaptiveRateLimit {
+		return
+	}
+	limiter := cl.rateLimiter
+	if limiter == nil {
+		limiter = c.rateLimiter
+	}
+	if remaining, reset, ok := parseQuota(resp, time.Now()); ok {
+		limiter.Observe(remaining, reset)
+	}
+}
+
+// parseQuota returns the remaining requests and the reset time resp reports.
+func parseQuota(resp *http.Response, now time.Time) (int, time.Time, bool) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if wait := ParseRetryAfter(resp.Header.Get("Retry-After")); wait > 0 {
+			return 0, now.Add(wait), true
+		}
+	}
+
+	for _, names := range quotaHeaders {
+		remaining, err := strconv.Atoi(resp.Header.Get(names[0]))
+		if err != nil {
+			continue
+		}
+		reset, err := strconv.ParseFloat(resp.Header.Get(names[1]), 64)
+		if err != nil || reset < 0 || math.IsInf(reset, 0) {
+			continue
+		}
+		if reset >= minEpochReset {
+			return remaining, time.Unix(0, int64(reset*float64(time.Second))), true
+		}
+		return remaining, now.Add(time.Duration(reset * float64(time.Second))), true
+	}
+	return 0, time.Time{}, false
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuota(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		status    int
+		headers   map[string]string
+		remaining int
+		reset     time.Time
+		ok        bool
+	}{
+		{"IETF draft", 200, map[string]string{"RateLimit-Remaining": "5", "RateLimit-Reset": "30"}, 5, now.Add(30 * time.Second), true},
+		{"GitHub epoch reset", 200, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)}, 0, now.Add(time.Minute), true},
+		{"fractional seconds", 200, map[string]string{"X-Rate-Limit-Remaining": "1", "X-Rate-Limit-Reset": "1.5"}, 1, now.Add(1500 * time.Millisecond), true},
+		{"429 Retry-After", 429, map[string]string{"Retry-After": "7", "X-RateLimit-Remaining": "3", "X-RateLimit-Reset": "60"}, 0, now.Add(7 * time.Second), true},
+		{"missing reset", 200, map[string]string{"X-RateLimit-Remaining": "3"}, 0, time.Time{}, false},
+		{"invalid remaining", 200, map[string]string{"X-RateLimit-Remaining": "many", "X-RateLimit-Reset": "1"}, 0, time.Time{}, false},
+		{"none", 200, nil, 0, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: make(http.Header)}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+			remaining, reset, ok := parseQuota(resp, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.remaining, remaining)
+			assert.True(t, tt.reset.Equal(reset), "reset %v, want %v", reset, tt.reset)
+		})
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	limiter := NewRateLimiter(100, time.Second)
+	limiter.Observe(1, time.Now().Add(100*time.Millisecond))
+
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded, "quota used up until reset")
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.Greater(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestWithAdaptiveRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "0.1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAdaptiveRateLimit())
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/search", nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Get(context.Background(), "/search", nil)
+	require.NoError(t, err)
+	assert.Greater(t, time.Since(start), 50*time.Millisecond, "waits for the reported reset")
+}
+
+func TestAdaptiveRateLimitFailsFast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "86400")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAdaptiveRateLimit())
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/search", nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Get(context.Background(), "/search", nil)
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, ErrKindRateLimit, clientErr.Kind)
+	assert.ErrorIs(t, err, ErrRateLimitWait)
+	assert.Less(t, time.Since(start), time.Second, "does not wait for a reset past the client timeout")
+}
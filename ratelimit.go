@@ -2,6 +2,11 @@ package httpclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -13,7 +18,20 @@ type RateLimiter struct {
 	maxTokens  float64
 	refillRate float64 // tokens per nanosecond
 	lastRefill time.Time
+	unlimited  bool // no local bucket, only the server's quota
 	mu         sync.Mutex
+
+	// Quota reported by the server (see Observe), until serverReset.
+	serverRemaining int
+	serverReset     time.Time
+
+	// Requests blocked in Wait by priority, and a channel closed whenever
+	// one of them leaves, for lower priorities to wait on.
+	waiting map[Priority]int
+	left    chan struct{}
+
+	// Buckets of WithRateLimitKeyFunc at the same rate, by key.
+	keyed rateLimitBuckets
 }
 
 // NewRateLimiter creates a new rate limiter that allows `requests` per `duration`.
@@ -26,37 +44,193 @@ func NewRateLimiter(requests int, duration time.Duration) *RateLimiter {
 	}
 }
 
+// ErrRateLimitWait is returned by Wait when the token, or the server's
+// quota reset, would only become available after the context's deadline.
+// The error also matches context.DeadlineExceeded.
+var ErrRateLimitWait = errors.New("rate limit wait exceeds deadline")
+
+var errRateLimitDeadline = fmt.Errorf("%w: %w", ErrRateLimitWait, context.DeadlineExceeded)
+
 // Wait blocks until a token is available or the context is cancelled.
+// While requests of higher priority (see WithContextPriority) are waiting,
+// it lets them take the tokens first. It fails with ErrRateLimitWait at
+// once, rather than at the deadline, when the wait would outlast it.
 func (r *RateLimiter) Wait(ctx context.Context) error {
+	p := GetPriority(ctx)
+	r.mu.Lock()
+	queued := false
 	for {
-		r.mu.Lock()
-		r.refill()
-
-		if r.tokens >= 1 {
-			r.tokens--
+		var wait time.Duration
+		var left chan struct{}
+		if r.higherWaiting(p) {
+			left = r.leftChan()
+		} else if wait = r.reserve(time.Now()); wait == 0 {
+			if queued {
+				r.leave(p)
+			}
 			r.mu.Unlock()
 			return nil
+		} else if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			if queued {
+				r.leave(p)
+			}
+			r.mu.Unlock()
+			return errRateLimitDeadline
+		}
+		if !queued {
+			r.join(p)
+			queued = true
 		}
-
-		// Calculate time until next token
-		tokensNeeded := 1 - r.tokens
-		waitDuration := time.Duration(tokensNeeded / r.refillRate)
 		r.mu.Unlock()
 
-		// Wait for refill or context cancellation
-		timer := time.NewTimer(waitDuration)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			return ctx.Err()
-		case <-timer.C:
-			// Continue loop to try again
+		err := sleepOrLeft(ctx, wait, left)
+		r.mu.Lock()
+		if err != nil {
+			r.leave(p)
+			r.mu.Unlock()
+			return err
 		}
 	}
 }
 
-func (r *RateLimiter) refill() {
+// sleepOrLeft waits for left to close or, when left is nil, for d.
+func sleepOrLeft(ctx context.Context, d time.Duration, left <-chan struct{}) error {
+	var timeout <-chan time.Time
+	if left == nil {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+	case <-left:
+	}
+	return nil
+}
+
+// higherWaiting reports whether requests above priority p are waiting.
+func (r *RateLimiter) higherWaiting(p Priority) bool {
+	for priority, n := range r.waiting {
+		if priority > p && n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RateLimiter) join(p Priority) {
+	if r.waiting == nil {
+		r.waiting = make(map[Priority]int)
+	}
+	r.waiting[p]++
+}
+
+func (r *RateLimiter) leave(p Priority) {
+	r.waiting[p]--
+	if r.left != nil {
+		close(r.left)
+		r.left = nil
+	}
+}
+
+func (r *RateLimiter) leftChan() chan struct{} {
+	if r.left == nil {
+		r.left = make(chan struct{})
+	}
+	return r.left
+}
+
+// Allow takes a token if one is available now and reports whether it did.
+// Unlike Wait it never blocks, so callers can shed load instead.
+func (r *RateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reserve(time.Now()) == 0
+}
+
+// Reserve takes a token, borrowing against future refills if none is
+// available, and returns how long the caller must wait before using it, so
+// work can be scheduled rather than blocked on. Later calls to Wait, Allow
+// and Reserve queue behind the reservation. ok is false, and nothing is
+// reserved, when the limiter never refills.
+func (r *RateLimiter) Reserve() (delay time.Duration, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	now := time.Now()
+	if !r.unlimited {
+		r.refill(now)
+		if r.refillRate <= 0 && r.tokens < 1 {
+			return 0, false
+		}
+		r.tokens--
+		if r.tokens < 0 {
+			delay = time.Duration(-r.tokens / r.refillRate)
+		}
+	}
+
+	if !r.serverReset.IsZero() && !now.Before(r.serverReset) {
+		r.serverReset = time.Time{}
+	}
+	if !r.serverReset.IsZero() {
+		if r.serverRemaining <= 0 {
+			delay = max(delay, r.serverReset.Sub(now))
+		}
+		r.serverRemaining--
+	}
+	return delay, true
+}
+
+// Tokens returns the tokens currently available, which is negative while
+// reservations are outstanding, or +Inf for a limiter that only follows
+// server quotas (see WithAdaptiveRateLimit).
+func (r *RateLimiter) Tokens() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unlimited {
+		return math.Inf(1)
+	}
+	r.refill(time.Now())
+	return r.tokens
+}
+
+// Observe records a quota reported by the server: remaining requests until
+// reset. Until reset, Wait lets no more than remaining requests through, on
+// top of the limiter's own rate, and then blocks until reset.
+func (r *RateLimiter) Observe(remaining int, reset time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serverRemaining = max(remaining, 0)
+	r.serverReset = reset
+}
+
+// reserve takes a token and returns 0, or returns how long to wait for one.
+func (r *RateLimiter) reserve(now time.Time) time.Duration {
+	if !r.serverReset.IsZero() && !now.Before(r.serverReset) {
+		r.serverReset = time.Time{}
+	}
+	quota := !r.serverReset.IsZero()
+	if quota && r.serverRemaining <= 0 {
+		return r.serverReset.Sub(now)
+	}
+
+	if !r.unlimited {
+		r.refill(now)
+		if r.tokens < 1 {
+			// Calculate time until next token
+			return max(time.Duration((1-r.tokens)/r.refillRate), time.Nanosecond)
+		}
+		r.tokens--
+	}
+	if quota {
+		r.serverRemaining--
+	}
+	return 0
+}
+
+func (r *RateLimiter) refill(now time.Time) {
 	elapsed := now.Sub(r.lastRefill)
 	r.tokens += float64(elapsed) * r.refillRate
 	if r.tokens > r.maxTokens {
@@ -64,3 +238,149 @@ func (r *RateLimiter) refill() {
 	}
 	r.lastRefill = now
 }
+
+// WithSharedRateLimit rate limits the client with limiter, e.g. from
+// NewRateLimiter, together with every other client given the same
+// limiter, so an integration's vendor quota holds however many clients
+// call it. It replaces WithRateLimit.
+func WithSharedRateLimit(limiter *RateLimiter) ClientOption {
+	return func(c *Client) error {
+		if limiter == nil {
+			return errors.New("shared rate limiter cannot be nil")
+		}
+		c.sharedRateLimiter = limiter
+		return nil
+	}
+}
+
+// configureSharedRateLimit makes the shared limiter the client's.
+func (c *Client) configureSharedRateLimit() {
+	if c.sharedRateLimiter != nil {
+		c.rateLimiter = c.sharedRateLimiter
+	}
+}
+
+// rateLimitKeys splits the client's rate limit into buckets by key.
+type rateLimitKeys struct {
+	keyFunc func(*http.Request) string
+}
+
+// maxRateLimitKeys bounds the buckets of one limiter's keys.
+const maxRateLimitKeys = 1024
+
+// rateLimitBuckets holds token buckets by key. A bucket that has refilled
+// completely behaves as a new one would, so it is dropped to make room.
+type rateLimitBuckets struct {
+	mu      sync.Mutex
+	buckets map[string]*RateLimiter
+}
+
+// WithRateLimitKeyFunc gives each key that keyFunc returns its own token
+// bucket, at the rate of WithRateLimit or WithSharedRateLimit, for APIs
+// that publish per-endpoint quotas, e.g. keyed by endpoint so /search and
+// /users are limited independently. Quotas observed by
+// WithAdaptiveRateLimit apply to the bucket of the responding request.
+// Requests with an empty key use the client-wide bucket, as do new keys
+// while 1024 others have buckets that have not refilled, so keys should
+// name endpoints rather than resources: /users, not /users/123. Under
+// WithSharedRateLimit, buckets are shared per key too.
+func WithRateLimitKeyFunc(keyFunc func(*http.Request) string) ClientOption {
+	return func(c *Client) error {
+		if keyFunc == nil {
+			return errors.New("rate limit key func cannot be nil")
+		}
+		c.rateLimitKeys = &rateLimitKeys{keyFunc: keyFunc}
+		return nil
+	}
+}
+
+// configureRateLimitKeys checks that there is a rate to split into buckets.
+func (c *Client) configureRateLimitKeys() error {
+	if c.rateLimitKeys != nil && c.rateLimiter == nil {
+		return errors.New("rate limit key func requires WithRateLimit, WithSharedRateLimit or WithAdaptiveRateLimit")
+	}
+	return nil
+}
+
+// callRateLimiter returns the limiter for the call's key, or the client's.
+func (c *Client) callRateLimiter(ctx context.Context, cl *call) *RateLimiter {
+	if c.rateLimitKeys == nil {
+		return c.rateLimiter
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, cl.url, nil)
+	if err != nil {
+		return c.rateLimiter
+	}
+	req.Header = cl.header
+	if cl.hostOverride != "" {
+		req.Host = cl.hostOverride
+	}
+	var key string
+	err = c.runHook(ctx, "rate limit key", func() error {
+		key = c.rateLimitKeys.keyFunc(req)
+		return nil
+	})
+	if err != nil || key == "" {
+		return c.rateLimiter
+	}
+	if limiter := c.rateLimiter.bucket(key, time.Now()); limiter != nil {
+		return limiter
+	}
+	return c.rateLimiter
+}
+
+// bucket returns r's bucket for key, creating it if needed, or nil if r
+// has maxRateLimitKeys buckets in use.
+func (r *RateLimiter) bucket(key string, now time.Time) *RateLimiter {
+	b := &r.keyed
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limiter, ok := b.buckets[key]; ok {
+		return limiter
+	}
+	if len(b.buckets) >= maxRateLimitKeys {
+		maps.DeleteFunc(b.buckets, func(_ string, limiter *RateLimiter) bool {
+			return limiter.idle(now)
+		})
+		if len(b.buckets) >= maxRateLimitKeys {
+			return nil
+		}
+	}
+	if b.buckets == nil {
+		b.buckets = make(map[string]*RateLimiter)
+	}
+	limiter := r.newBucket()
+	b.buckets[key] = limiter
+	return limiter
+}
+
+// idle reports whether r is as a new bucket would be: full, with no
+// server quota in force and no requests waiting.
+func (r *RateLimiter) idle(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.waiting {
+		if n > 0 {
+			return false
+		}
+	}
+	if !r.serverReset.IsZero() && now.Before(r.serverReset) {
+		return false
+	}
+	if r.unlimited {
+		return true
+	}
+	r.refill(now)
+	return r.tokens >= r.maxTokens
+}
+
+// newBucket returns a full limiter with the same rate as r.
+func (r *RateLimiter) newBucket() *RateLimiter {
+	return &RateLimiter{
+		tokens:     r.maxTokens,
+		maxTokens:  r.maxTokens,
+		refillRate: r.refillRate,
+		lastRefill: time.Now(),
+		unlimited:  r.unlimited,
+	}
+}
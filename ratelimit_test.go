@@ -2,8 +2,10 @@ package httpclient
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -95,6 +97,53 @@ func TestRateLimiter(t *testing.T) {
 	})
 }
 
+func TestRateLimiterAllowReserve(t *testing.T) {
+	t.Run("Allow does not block", func(t *testing.T) {
+		limiter := NewRateLimiter(2, time.Hour)
+
+		assert.True(t, limiter.Allow())
+		assert.True(t, limiter.Allow())
+		assert.False(t, limiter.Allow())
+		assert.InDelta(t, 0, limiter.Tokens(), 0.01)
+	})
+
+	t.Run("Reserve borrows future tokens", func(t *testing.T) {
+		limiter := NewRateLimiter(10, time.Second)
+		for i := 0; i < 10; i++ {
+			delay, ok := limiter.Reserve()
+			require.True(t, ok)
+			require.Zero(t, delay)
+		}
+
+		delay, ok := limiter.Reserve()
+		require.True(t, ok)
+		assert.InDelta(t, float64(100*time.Millisecond), float64(delay), float64(5*time.Millisecond))
+		delay, _ = limiter.Reserve()
+		assert.InDelta(t, float64(200*time.Millisecond), float64(delay), float64(5*time.Millisecond))
+		assert.Less(t, limiter.Tokens(), -1.9)
+		assert.False(t, limiter.Allow(), "queues behind reservations")
+	})
+
+	t.Run("Reserve waits for the server reset", func(t *testing.T) {
+		limiter := &RateLimiter{unlimited: true}
+		limiter.Observe(0, time.Now().Add(time.Minute))
+
+		delay, ok := limiter.Reserve()
+		require.True(t, ok)
+		assert.Greater(t, delay, 59*time.Second)
+		assert.False(t, limiter.Allow())
+		assert.True(t, math.IsInf(limiter.Tokens(), 1))
+	})
+
+	t.Run("Reserve fails without refill", func(t *testing.T) {
+		limiter := NewRateLimiter(0, time.Second)
+
+		_, ok := limiter.Reserve()
+		assert.False(t, ok)
+		assert.Zero(t, limiter.Tokens())
+	})
+}
+
 func TestClient_RateLimit(t *testing.T) {
 	t.Run("limits request rate", func(t *testing.T) {
 		var requests int32
@@ -147,3 +196,151 @@ func TestClient_RateLimit(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestWithSharedRateLimit(t *testing.T) {
+	t.Run("clients given the same limiter share it", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		newClient := func(limiter *RateLimiter) *Client {
+			client, err := New(
+				WithBaseURL(server.URL),
+				WithLoggerDisabled(),
+				WithThirdPartyCode("shared-limit-test"),
+				WithRateLimit(100, time.Second),
+				WithSharedRateLimit(limiter),
+			)
+			require.NoError(t, err)
+			return client
+		}
+		shared := NewRateLimiter(1, time.Hour)
+		first := newClient(shared)
+		second := newClient(shared)
+		other := newClient(NewRateLimiter(1, time.Hour))
+
+		assert.Same(t, first.rateLimiter, second.rateLimiter)
+
+		_, err := first.Get(context.Background(), "/a", nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = second.Get(ctx, "/a", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindRateLimit, clientErr.Kind)
+		assert.Equal(t, "shared-limit-test", clientErr.ThirdParty)
+
+		_, err = other.Get(context.Background(), "/a", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects nil limiter", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithSharedRateLimit(nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestWithRateLimitKeyFunc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	byPath := func(r *http.Request) string { return r.URL.Path }
+
+	t.Run("limits keys independently", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRateLimit(1, time.Hour), WithRateLimitKeyFunc(byPath))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/search", nil)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/users", nil)
+		require.NoError(t, err, "/users has its own bucket")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.Get(ctx, "/search", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindRateLimit, clientErr.Kind)
+	})
+
+	t.Run("empty key uses the client bucket", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRateLimit(1, time.Hour),
+			WithRateLimitKeyFunc(func(*http.Request) string { return "" }))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/search", nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.Get(ctx, "/users", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("shares keyed buckets between shared clients", func(t *testing.T) {
+		shared := NewRateLimiter(1, time.Hour)
+		newClient := func() *Client {
+			client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(),
+				WithSharedRateLimit(shared), WithRateLimitKeyFunc(byPath))
+			require.NoError(t, err)
+			return client
+		}
+		first, second := newClient(), newClient()
+
+		_, err := first.Get(context.Background(), "/search", nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = second.Get(ctx, "/search", nil)
+		assert.Error(t, err)
+		_, err = second.Get(context.Background(), "/users", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("applies observed quotas to the key", func(t *testing.T) {
+		quotaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/search" {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", "3600")
+			}
+		}))
+		defer quotaServer.Close()
+
+		client, err := New(WithBaseURL(quotaServer.URL), WithLoggerDisabled(), WithAdaptiveRateLimit(), WithRateLimitKeyFunc(byPath))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/search", nil)
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/users", nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.Get(ctx, "/search", nil)
+		assert.Error(t, err, "/search quota used up")
+	})
+
+	t.Run("bounds the number of buckets", func(t *testing.T) {
+		limiter := NewRateLimiter(1, time.Minute)
+		now := time.Now()
+		for i := range maxRateLimitKeys {
+			bucket := limiter.bucket(strconv.Itoa(i), now)
+			require.NotNil(t, bucket)
+			require.True(t, bucket.Allow())
+		}
+		assert.Nil(t, limiter.bucket("/users/1025", now), "every bucket is in use")
+		assert.NotNil(t, limiter.bucket("0", now))
+
+		later := now.Add(2 * time.Minute)
+		assert.NotNil(t, limiter.bucket("/users/1025", later), "refilled buckets are dropped")
+		assert.Equal(t, 1, len(limiter.keyed.buckets))
+	})
+
+	t.Run("validates options", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithRateLimitKeyFunc(byPath))
+		assert.Error(t, err)
+		_, err = New(WithBaseURL(server.URL), WithRateLimit(1, time.Second), WithRateLimitKeyFunc(nil))
+		assert.Error(t, err)
+	})
+}
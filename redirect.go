@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// sensitiveQueryParams are query parameters stripped from cross-host
+// redirects in addition to any containing "token", "secret" or "password".
+var sensitiveQueryParams = []string{
+	"api_key",
+	"apikey",
+	"key",
+	"sig",
+	"signature",
+	"auth",
+}
+
+// redirectPolicy decides which credentials survive a redirect to another host.
+type redirectPolicy struct {
+	keep        bool
+	headers     map[string]bool
+	queryParams map[string]bool
+}
+
+// RedirectOption configures WithRedirectPolicy.
+type RedirectOption func(*redirectPolicy) error
+
+// StripRedirectHeaders strips the named headers, e.g. a tenant header, on
+// cross-host redirects in addition to the sensitive headers redacted from
+// logs (Authorization, Cookie, X-API-Key and names containing "token",
+// "secret", "password" or "key").
+func StripRedirectHeaders(names ...string) RedirectOption {
+	return func(p *redirectPolicy) error {
+		for _, name := range names {
+			if name == "" {
+				return errors.New("redirect header name cannot be empty")
+			}
+			p.headers[strings.ToLower(name)] = true
+		}
+		return nil
+	}
+}
+
+// StripRedirectQueryParams strips the named query parameters from
+// cross-host redirect URLs, e.g. the parameter used with APIKeyQueryAuth
+// when its name is not recognized as a credential.
+func StripRedirectQueryParams(names ...string) RedirectOption {
+	return func(p *redirectPolicy) error {
+		for _, name := range names {
+			if name == "" {
+				return errors.New("redirect query parameter cannot be empty")
+			}
+			p.queryParams[strings.ToLower(name)] = true
+		}
+		return nil
+	}
+}
+
+// KeepRedirectCredentials disables credential stripping, leaving redirects
+// to net/http's defaults.
+func KeepRedirectCredentials() RedirectOption {
+	return func(p *redirectPolicy) error {
+		p.keep = true
+		return nil
+	}
+}
+
+// WithRedirectPolicy configures which credentials are stripped when a
+// redirect leaves the host of the original request. By default sensitive
+// headers and credential-like query parameters are stripped, so a token
+// cannot leak to a third-party redirect target. A client passed to
+// WithHTTPClient keeps its own redirect handling unless WithRedirectPolicy
+// is also given.
+func WithRedirectPolicy(opts ...RedirectOption) ClientOption {
+	return func(c *Client) error {
+		policy := newRedirectPolicy()
+		for _, opt := range opts {
+			if err := opt(policy); err != nil {
+				return err
+			}
+		}
+		c.redirects = policy
+		return nil
+	}
+}
+
+func newRedirectPolicy() *redirectPolicy {
+	return &redirectPolicy{
+		headers:     make(map[string]bool),
+		queryParams: make(map[string]bool),
+	}
+}
+
+// configureRedirects installs credential stripping on the HTTP client.
+func (c *Client) configureRedirects() {
+	if c.redirects == nil || c.redirects.keep {
+		return
+	}
+
+	policy := c.redirects
+	next := c.httpClient.CheckRedirect
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// net/http copies the original headers onto every hop, so each hop
+		// is compared with the original host rather than the previous one.
+		if len(via) > 0 && !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			policy.strip(req)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return defaultCheckRedirect(via)
+	}
+	c.httpClient = &httpClient
+}
+
+// strip removes credentials from a cross-host redirect request.
+func (p *redirectPolicy) strip(req *http.Request) {
+	for name := range req.Header {
+		if isSensitiveHeader(name) || p.headers[strings.ToLower(name)] {
+			req.Header.Del(name)
+		}
+	}
+
+	if req.URL.RawQuery == "" {
+		return
+	}
+	query := req.URL.Query()
+	stripped := false
+	for name := range query {
+		if p.isSensitiveQueryParam(name) {
+			query.Del(name)
+			stripped = true
+		}
+	}
+	if stripped {
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+func (p *redirectPolicy) isSensitiveQueryParam(name string) bool {
+	lower := strings.ToLower(name)
+	if p.queryParams[lower] {
+		return true
+	}
+	for _, param := range sensitiveQueryParams {
+		if lower == param {
+			return true
+		}
+	}
+	for _, pattern := range []string{"token", "secret", "password"} {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectCredentialStripping(t *testing.T) {
+	// newRedirectServers returns an origin that redirects /away to a second
+	// host and /here to itself, and the requests the targets received.
+	newRedirectServers := func(t *testing.T) (*httptest.Server, *[]*http.Request) {
+		t.Helper()
+		var received []*http.Request
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r)
+		}))
+		t.Cleanup(target.Close)
+
+		origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/away":
+				http.Redirect(w, r, target.URL+"/download?access_token=t0k&page=2&sig=abc", http.StatusFound)
+			case "/here":
+				http.Redirect(w, r, "/landed?access_token=t0k", http.StatusFound)
+			default:
+				received = append(received, r)
+			}
+		}))
+		t.Cleanup(origin.Close)
+		return origin, &received
+	}
+
+	t.Run("strips credentials on cross-host redirects", func(t *testing.T) {
+		origin, received := newRedirectServers(t)
+		client, err := New(
+			WithBaseURL(origin.URL),
+			WithAuth(BearerAuth("secret-token")),
+			WithHeader("X-Vendor-Key", "k1"),
+			WithHeader("X-Tenant", "acme"),
+			WithRedirectPolicy(StripRedirectHeaders("X-Tenant")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/away", nil)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		r := (*received)[0]
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("X-Vendor-Key"))
+		assert.Empty(t, r.Header.Get("X-Tenant"))
+		assert.NotEmpty(t, r.Header.Get("User-Agent"))
+		assert.Equal(t, "page=2", r.URL.RawQuery)
+	})
+
+	t.Run("keeps credentials on same-host redirects", func(t *testing.T) {
+		origin, received := newRedirectServers(t)
+		client, err := New(WithBaseURL(origin.URL), WithAuth(BearerAuth("secret-token")))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/here", nil)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		assert.Equal(t, "Bearer secret-token", (*received)[0].Header.Get("Authorization"))
+		assert.Equal(t, "t0k", (*received)[0].URL.Query().Get("access_token"))
+	})
+
+	t.Run("KeepRedirectCredentials disables stripping", func(t *testing.T) {
+		origin, received := newRedirectServers(t)
+		client, err := New(
+			WithBaseURL(origin.URL),
+			WithHeader("X-Vendor-Key", "k1"),
+			WithRedirectPolicy(KeepRedirectCredentials()),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/away", nil)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		assert.Equal(t, "k1", (*received)[0].Header.Get("X-Vendor-Key"))
+		assert.Equal(t, "t0k", (*received)[0].URL.Query().Get("access_token"))
+	})
+
+	t.Run("rejects empty names", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithRedirectPolicy(StripRedirectHeaders("")))
+		assert.Error(t, err)
+		_, err = New(WithBaseURL("https://api.example.com"), WithRedirectPolicy(StripRedirectQueryParams("")))
+		assert.Error(t, err)
+	})
+}
+
+func TestRedirectPolicyWithHTTPClient(t *testing.T) {
+	t.Run("custom client is used as-is by default", func(t *testing.T) {
+		custom := &http.Client{}
+		client, err := New(WithBaseURL("https://api.example.com"), WithHTTPClient(custom))
+		require.NoError(t, err)
+		assert.Same(t, custom, client.httpClient)
+	})
+
+	t.Run("explicit policy wraps a copy of the custom client", func(t *testing.T) {
+		custom := &http.Client{}
+		client, err := New(WithBaseURL("https://api.example.com"), WithHTTPClient(custom), WithRedirectPolicy())
+		require.NoError(t, err)
+		assert.NotNil(t, client.httpClient.CheckRedirect)
+		assert.Nil(t, custom.CheckRedirect)
+	})
+}
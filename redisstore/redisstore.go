@@ -0,0 +1,209 @@
+// Package redisstore provides Redis-backed implementations of the
+// httpclient.CacheStore, httpclient.IdempotencyStore and
+// httpclient.RetryStateStore interfaces, so that several client replicas can
+// share cached responses and idempotency keys, and retry schedules survive
+// restarts.
+//
+// The package does not depend on a Redis driver. Callers supply a small
+// adapter satisfying Commander around the driver they already use.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/holgersendify/httpclient"
+)
+
+// Commander is the subset of Redis commands the stores need.
+type Commander interface {
+	// Get returns the value of key and whether it exists.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key. A zero ttl means no expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value under key only if it does not exist (SET NX PX).
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Del removes key.
+	Del(ctx context.Context, key string) error
+}
+
+// DefaultPrefix is prepended to every key written by the stores.
+const DefaultPrefix = "httpclient:"
+
+// Option configures a store.
+type Option func(*config)
+
+type config struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// WithPrefix sets the key prefix, isolating stores that share a database.
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL sets an expiry on cached responses and retry states. By default
+// they are kept until evicted by Redis.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.ttl = ttl
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// CacheStore is an httpclient.CacheStore backed by Redis.
+type CacheStore struct {
+	redis Commander
+	cfg   config
+}
+
+var _ httpclient.CacheStore = (*CacheStore)(nil)
+
+// NewCacheStore creates a cache store using redis.
+func NewCacheStore(redis Commander, opts ...Option) (*CacheStore, error) {
+	if redis == nil {
+		return nil, errors.New("redis commander cannot be nil")
+	}
+	cfg := newConfig(opts)
+	if cfg.ttl < 0 {
+		return nil, fmt.Errorf("cache ttl %v cannot be negative", cfg.ttl)
+	}
+	return &CacheStore{redis: redis, cfg: cfg}, nil
+}
+
+// Get implements httpclient.CacheStore.
+func (s *CacheStore) Get(ctx context.Context, key string) (*httpclient.CachedResponse, bool, error) {
+	data, ok, err := s.redis.Get(ctx, s.cacheKey(key))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var entry httpclient.CachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("decoding cached response: %w", err)
+	}
+	return &entry, true, nil
+}
+
+// Set implements httpclient.CacheStore.
+func (s *CacheStore) Set(ctx context.Context, key string, entry *httpclient.CachedResponse) error {
+	if entry == nil {
+		return errors.New("cache entry cannot be nil")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cached response: %w", err)
+	}
+	return s.redis.Set(ctx, s.cacheKey(key), data, s.cfg.ttl)
+}
+
+// Delete implements httpclient.CacheStore.
+func (s *CacheStore) Delete(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, s.cacheKey(key))
+}
+
+func (s *CacheStore) cacheKey(key string) string {
+	return s.cfg.prefix + "cache:" + key
+}
+
+// IdempotencyStore is an httpclient.IdempotencyStore backed by Redis.
+type IdempotencyStore struct {
+	redis Commander
+	cfg   config
+}
+
+var _ httpclient.IdempotencyStore = (*IdempotencyStore)(nil)
+
+// NewIdempotencyStore creates an idempotency-key store using redis, for
+// httpclient.WithIdempotencyStore.
+func NewIdempotencyStore(redis Commander, opts ...Option) (*IdempotencyStore, error) {
+	if redis == nil {
+		return nil, errors.New("redis commander cannot be nil")
+	}
+	return &IdempotencyStore{redis: redis, cfg: newConfig(opts)}, nil
+}
+
+// Reserve implements httpclient.IdempotencyStore using SET NX.
+func (s *IdempotencyStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, errors.New("idempotency key ttl must be positive")
+	}
+	return s.redis.SetNX(ctx, s.idempotencyKey(key), []byte("1"), ttl)
+}
+
+// Release implements httpclient.IdempotencyStore.
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, s.idempotencyKey(key))
+}
+
+func (s *IdempotencyStore) idempotencyKey(key string) string {
+	return s.cfg.prefix + "idempotency:" + key
+}
+
+// RetryStateStore is an httpclient.RetryStateStore backed by Redis.
+type RetryStateStore struct {
+	redis Commander
+	cfg   config
+}
+
+var _ httpclient.RetryStateStore = (*RetryStateStore)(nil)
+
+// NewRetryStateStore creates a retry state store using redis.
+func NewRetryStateStore(redis Commander, opts ...Option) (*RetryStateStore, error) {
+	if redis == nil {
+		return nil, errors.New("redis commander cannot be nil")
+	}
+	cfg := newConfig(opts)
+	if cfg.ttl < 0 {
+		return nil, fmt.Errorf("retry state ttl %v cannot be negative", cfg.ttl)
+	}
+	return &RetryStateStore{redis: redis, cfg: cfg}, nil
+}
+
+// Get implements httpclient.RetryStateStore.
+func (s *RetryStateStore) Get(ctx context.Context, key string) (*httpclient.RetryState, bool, error) {
+	data, ok, err := s.redis.Get(ctx, s.retryKey(key))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var state httpclient.RetryState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, fmt.Errorf("decoding retry state: %w", err)
+	}
+	return &state, true, nil
+}
+
+// Set implements httpclient.RetryStateStore.
+func (s *RetryStateStore) Set(ctx context.Context, key string, state *httpclient.RetryState) error {
+	if state == nil {
+		return errors.New("retry state cannot be nil")
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding retry state: %w", err)
+	}
+	return s.redis.Set(ctx, s.retryKey(key), data, s.cfg.ttl)
+}
+
+// Delete implements httpclient.RetryStateStore.
+func (s *RetryStateStore) Delete(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, s.retryKey(key))
+}
+
+func (s *RetryStateStore) retryKey(key string) string {
+	return s.cfg.prefix + "retry:" + key
+}
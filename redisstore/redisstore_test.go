@@ -0,0 +1,172 @@
+package redisstore
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holgersendify/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-memory Commander that records the TTLs it was given.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.data[key]
+	return v, ok, nil
+}
+
+func (f *fakeRedis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key], f.ttls[key] = value, ttl
+	return nil
+}
+
+func (f *fakeRedis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.data[key]; ok {
+		return false, nil
+	}
+	f.data[key], f.ttls[key] = value, ttl
+	return true, nil
+}
+
+func (f *fakeRedis) Del(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.data, key)
+	return nil
+}
+
+func TestCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round-trips responses", func(t *testing.T) {
+		redis := newFakeRedis()
+		store, err := NewCacheStore(redis, WithPrefix("svc:"), WithTTL(time.Hour))
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "GET /rates", &httpclient.CachedResponse{
+			StatusCode: http.StatusOK,
+			Headers:    http.Header{"Etag": {`"v1"`}},
+			Body:       []byte(`{"rates":[]}`),
+		}))
+		entry, ok, err := store.Get(ctx, "GET /rates")
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, `{"rates":[]}`, string(entry.Body))
+		assert.Equal(t, `"v1"`, entry.Headers.Get("Etag"))
+		assert.Equal(t, time.Hour, redis.ttls["svc:cache:GET /rates"])
+	})
+
+	t.Run("deletes responses", func(t *testing.T) {
+		store, err := NewCacheStore(newFakeRedis())
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set(ctx, "k", &httpclient.CachedResponse{StatusCode: http.StatusOK}))
+		require.NoError(t, store.Delete(ctx, "k"))
+
+		_, ok, err := store.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := NewCacheStore(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "redis commander cannot be nil")
+
+		_, err = NewCacheStore(newFakeRedis(), WithTTL(-time.Second))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be negative")
+	})
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dedupes keys across stores sharing redis", func(t *testing.T) {
+		redis := newFakeRedis()
+		replicaA, err := NewIdempotencyStore(redis)
+		require.NoError(t, err)
+		replicaB, err := NewIdempotencyStore(redis)
+		require.NoError(t, err)
+
+		ok, err := replicaA.Reserve(ctx, "order-1", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = replicaB.Reserve(ctx, "order-1", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, replicaA.Release(ctx, "order-1"))
+		ok, err = replicaB.Reserve(ctx, "order-1", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, redis.ttls[DefaultPrefix+"idempotency:order-1"])
+	})
+
+	t.Run("validates ttl", func(t *testing.T) {
+		store, err := NewIdempotencyStore(newFakeRedis())
+		require.NoError(t, err)
+
+		_, err = store.Reserve(ctx, "k", 0)
+		require.Error(t, err)
+	})
+}
+
+func TestRetryStateStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round-trips state across stores sharing redis", func(t *testing.T) {
+		redis := newFakeRedis()
+		before, err := NewRetryStateStore(redis, WithTTL(time.Hour))
+		require.NoError(t, err)
+		after, err := NewRetryStateStore(redis)
+		require.NoError(t, err)
+
+		next := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+		require.NoError(t, before.Set(ctx, "job-1", &httpclient.RetryState{Attempts: 3, NextRetryAt: next}))
+		assert.Equal(t, time.Hour, redis.ttls[DefaultPrefix+"retry:job-1"])
+
+		state, ok, err := after.Get(ctx, "job-1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, 3, state.Attempts)
+		assert.True(t, next.Equal(state.NextRetryAt))
+
+		require.NoError(t, after.Delete(ctx, "job-1"))
+		_, ok, err = before.Get(ctx, "job-1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := NewRetryStateStore(nil)
+		assert.Error(t, err)
+		_, err = NewRetryStateStore(newFakeRedis(), WithTTL(-time.Second))
+		assert.Error(t, err)
+
+		store, err := NewRetryStateStore(newFakeRedis())
+		require.NoError(t, err)
+		assert.Error(t, store.Set(ctx, "job", nil))
+	})
+}
@@ -0,0 +1,149 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// redactedValue replaces sensitive header values in logs and captures.
+const redactedValue = "[REDACTED]"
+
+// CapturedRequest is a serializable record of a sent request, detailed enough
+// to re-send it. Sensitive header values are redacted when captured and are
+// re-applied by the replaying client's auth provider.
+type CapturedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+}
+
+// captureRequest records the call as sent with the given headers, falling
+// back to the call's own headers if it failed before being sent. The body
+// of a sensitive call is left out.
+func captureRequest(cl *call, headers http.Header) *CapturedRequest {
+	if headers == nil {
+		headers = cl.header
+	}
+
+	redacted := make(http.Header, len(headers))
+	for name, values := range headers {
+		if isSensitiveHeader(name) {
+			redacted[name] = []string{redactedValue}
+			continue
+		}
+		redacted[name] = append([]string(nil), values...)
+	}
+
+	captured := &CapturedRequest{
+		Method:  cl.method,
+		URL:     cl.url,
+		Headers: redacted,
+	}
+	if !cl.classification.sensitive() {
+		captured.Body = cl.body
+	}
+	return captured
+}
+
+// CaptureFromError returns the request recorded on a client *Error.
+func CaptureFromError(err error) (*CapturedRequest, bool) {
+	var clientErr *Error
+	if !errors.As(err, &clientErr) || clientErr.Request == nil {
+		return nil, false
+	}
+	return clientErr.Request, true
+}
+
+// harLog is the subset of the HAR 1.2 format needed to rebuild requests.
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// ParseHAR extracts the requests from a HAR (HTTP Archive) document.
+func ParseHAR(data []byte) ([]CapturedRequest, error) {
+	var har harLog
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR document: %w", err)
+	}
+
+	requests := make([]CapturedRequest, 0, len(har.Log.Entries))
+	for i, entry := range har.Log.Entries {
+		if entry.Request.Method == "" || entry.Request.URL == "" {
+			return nil, fmt.Errorf("HAR entry %d is missing method or URL", i)
+		}
+
+		captured := CapturedRequest{
+			Method:  entry.Request.Method,
+			URL:     entry.Request.URL,
+			Headers: make(http.Header, len(entry.Request.Headers)),
+		}
+		for _, h := range entry.Request.Headers {
+			captured.Headers.Add(h.Name, h.Value)
+		}
+		if entry.Request.PostData != nil {
+			captured.Body = []byte(entry.Request.PostData.Text)
+		}
+		requests = append(requests, captured)
+	}
+	return requests, nil
+}
+
+// Replay re-sends a captured request through the client's pipeline (auth,
+// retries, logging). Header overrides from WithRequestHeader replace captured
+// values; redacted headers are dropped so the client's auth applies instead.
+func Replay(ctx context.Context, client *Client, captured *CapturedRequest, overrides ...RequestOption) (*Response, error) {
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	if captured == nil {
+		return nil, errors.New("captured request cannot be nil")
+	}
+	if captured.Method == "" || captured.URL == "" {
+		return nil, errors.New("captured request must have a method and URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, captured.Method, captured.URL, bytes.NewReader(captured.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild captured request: %w", err)
+	}
+
+	for name, values := range captured.Headers {
+		if len(values) == 1 && values[0] == redactedValue {
+			continue
+		}
+		req.Header[name] = append([]string(nil), values...)
+	}
+
+	cfg := newRequestConfig()
+	for _, opt := range overrides {
+		opt(cfg)
+	}
+	for name, values := range cfg.headers {
+		req.Header[name] = values
+	}
+
+	cl, err := client.callFromRequest(req, cfg.classification)
+	if err != nil {
+		return nil, err
+	}
+	return client.execute(ctx, cl, nil)
+}
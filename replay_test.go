@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureFromError(t *testing.T) {
+	t.Run("records the failed request with redacted credentials", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuth("secret")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/orders", map[string]int{"qty": 2}, nil,
+			WithRequestHeader("X-Tenant", "acme"))
+		require.Error(t, err)
+
+		captured, ok := CaptureFromError(err)
+		require.True(t, ok)
+		assert.Equal(t, http.MethodPost, captured.Method)
+		assert.Equal(t, server.URL+"/orders", captured.URL)
+		assert.Equal(t, `{"qty":2}`, string(captured.Body))
+		assert.Equal(t, "acme", captured.Headers.Get("X-Tenant"))
+		assert.Equal(t, "[REDACTED]", captured.Headers.Get("Authorization"))
+	})
+
+	t.Run("returns false for other errors", func(t *testing.T) {
+		_, ok := CaptureFromError(io.EOF)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestReplay(t *testing.T) {
+	t.Run("re-sends captured request with overrides", func(t *testing.T) {
+		var calls int32
+		var lastAuth, lastTenant, lastBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			lastAuth = r.Header.Get("Authorization")
+			lastTenant = r.Header.Get("X-Tenant")
+			lastBody = string(body)
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuth("secret")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/orders", `{"qty":2}`, nil, WithRequestHeader("X-Tenant", "acme"))
+		captured, ok := CaptureFromError(err)
+		require.True(t, ok)
+
+		resp, err := Replay(context.Background(), client, captured, WithRequestHeader("X-Tenant", "debug"))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Bearer secret", lastAuth)
+		assert.Equal(t, "debug", lastTenant)
+		assert.Equal(t, `{"qty":2}`, lastBody)
+	})
+
+	t.Run("refuses classified replays over plain http", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		captured := &CapturedRequest{Method: http.MethodPost, URL: "http://api.example.com/cards", Body: []byte(`{}`)}
+		_, err = Replay(context.Background(), client, captured, WithDataClassification(DataPCI))
+		assert.ErrorContains(t, err, "is not https")
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		_, err = Replay(context.Background(), nil, &CapturedRequest{})
+		assert.ErrorContains(t, err, "client cannot be nil")
+
+		_, err = Replay(context.Background(), client, nil)
+		assert.ErrorContains(t, err, "captured request cannot be nil")
+
+		_, err = Replay(context.Background(), client, &CapturedRequest{Method: http.MethodGet})
+		assert.ErrorContains(t, err, "must have a method and URL")
+	})
+}
+
+func TestParseHAR(t *testing.T) {
+	t.Run("extracts requests", func(t *testing.T) {
+		har := `{"log":{"entries":[{"request":{
+			"method":"POST",
+			"url":"https://api.example.com/charges",
+			"headers":[{"name":"Content-Type","value":"application/json"}],
+			"postData":{"text":"{\"amount\":100}"}
+		}}]}}`
+
+		requests, err := ParseHAR([]byte(har))
+
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, "https://api.example.com/charges", requests[0].URL)
+		assert.Equal(t, "application/json", requests[0].Headers.Get("Content-Type"))
+		assert.Equal(t, `{"amount":100}`, string(requests[0].Body))
+	})
+
+	t.Run("returns error for invalid documents", func(t *testing.T) {
+		_, err := ParseHAR([]byte(`not json`))
+		assert.ErrorContains(t, err, "failed to parse HAR document")
+
+		_, err = ParseHAR([]byte(`{"log":{"entries":[{"request":{"method":"GET"}}]}}`))
+		assert.ErrorContains(t, err, "HAR entry 0 is missing method or URL")
+	})
+}
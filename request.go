@@ -2,6 +2,8 @@ package httpclient
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,10 +13,25 @@ import (
 type RequestOption func(*requestConfig)
 
 type requestConfig struct {
-	timeout     time.Duration
-	headers     http.Header
-	query       url.Values
-	contentType string
+	timeout         time.Duration
+	headers         http.Header
+	query           url.Values
+	contentType     string
+	rawBody         bool
+	endpoint        string
+	priority        Priority
+	prioritySet     bool
+	tee             io.Writer
+	classification  DataClassification
+	pathFlag        string
+	flaggedPath     string
+	hostOverride    string
+	pathParams      map[string]string
+	noErrorOnStatus bool
+	errorResult     any
+	retryStateKey   string
+	baseURL         string
+	rawQuery        string
 }
 
 func newRequestConfig() *requestConfig {
@@ -62,8 +79,15 @@ type RequestBuilder struct {
 	query       url.Values
 	timeout     time.Duration
 	contentType string
+	endpoint    string
+	priority    Priority
+	pathParams  map[string]string
+	then        []func(*Response) *RequestBuilder
 }
 
+// maxChainedRequests bounds the number of requests one chain may execute.
+const maxChainedRequests = 32
+
 // Request creates a new RequestBuilder.
 func (c *Client) Request() *RequestBuilder {
 	return &RequestBuilder{
@@ -86,6 +110,15 @@ func (b *RequestBuilder) Path(path string) *RequestBuilder {
 	return b
 }
 
+// PathParam fills the {name} placeholder in the path; see WithPathParam.
+func (b *RequestBuilder) PathParam(name, value string) *RequestBuilder {
+	if b.pathParams == nil {
+		b.pathParams = make(map[string]string)
+	}
+	b.pathParams[name] = value
+	return b
+}
+
 // Body sets the request body.
 func (b *RequestBuilder) Body(body any) *RequestBuilder {
 	b.body = body
@@ -116,17 +149,92 @@ func (b *RequestBuilder) ContentType(contentType string) *RequestBuilder {
 	return b
 }
 
-// Do executes the request and returns the response.
+// EndpointTemplate names the endpoint for statistics, e.g. "/users/{id}".
+func (b *RequestBuilder) EndpointTemplate(template string) *RequestBuilder {
+	b.endpoint = template
+	return b
+}
+
+// Priority sets the request priority.
+func (b *RequestBuilder) Priority(p Priority) *RequestBuilder {
+	b.priority = p
+	return b
+}
+
+// Then chains a follow-up request built from this request's successful
+// response, e.g. polling a resource by the ID a create call returned.
+// Returning nil from next ends the chain with the current response.
+// Chained requests share the context and request ID of the first request.
+func (b *RequestBuilder) Then(next func(*Response) *RequestBuilder) *RequestBuilder {
+	if next == nil {
+		return b
+	}
+	b.then = append(b.then, next)
+	return b
+}
+
+// Do executes the request, and any chained requests, and returns the last
+// response.
 func (b *RequestBuilder) Do(ctx context.Context) (*Response, error) {
-	opts := b.toRequestOptions()
-	return b.client.doWithOptions(ctx, b.method, b.path, b.body, nil, opts)
+	if len(b.then) == 0 {
+		opts := b.toRequestOptions()
+		return b.client.doWithOptions(ctx, b.method, b.path, b.body, nil, opts)
+	}
+	return b.doChain(ctx)
 }
 
-// DoInto executes the request and unmarshals the response into result.
+// DoInto executes the request, and any chained requests, and unmarshals the
+// last response into result.
 func (b *RequestBuilder) DoInto(ctx context.Context, result any) error {
-	opts := b.toRequestOptions()
-	_, err := b.client.doWithOptions(ctx, b.method, b.path, b.body, result, opts)
-	return err
+	if len(b.then) == 0 {
+		opts := b.toRequestOptions()
+		_, err := b.client.doWithOptions(ctx, b.method, b.path, b.body, result, opts)
+		return err
+	}
+
+	resp, err := b.doChain(ctx)
+	if err != nil {
+		return err
+	}
+	if result != nil && b.client.hasResultBody(resp) {
+		return b.client.decodeResult(resp, result)
+	}
+	return nil
+}
+
+// doChain executes b and its chained requests in order. Steps registered on
+// a builder returned by a Then callback run before the remaining steps.
+func (b *RequestBuilder) doChain(ctx context.Context) (*Response, error) {
+	if GetRequestID(ctx) == "" && b.client != nil {
+		ctx = WithRequestID(ctx, newRequestID(b.client.withRequestIDGenerator(ctx)))
+	}
+
+	current := b
+	pending := b.then
+	for i := 0; i < maxChainedRequests; i++ {
+		if current.client == nil {
+			return nil, fmt.Errorf("chained request %d has no client", i)
+		}
+		opts := current.toRequestOptions()
+		resp, err := current.client.doWithOptions(ctx, current.method, current.path, current.body, nil, opts)
+		if err != nil {
+			return resp, err
+		}
+		if current != b {
+			pending = append(current.then[:len(current.then):len(current.then)], pending...)
+		}
+		if len(pending) == 0 {
+			return resp, nil
+		}
+
+		next := pending[0](resp)
+		pending = pending[1:]
+		if next == nil {
+			return resp, nil
+		}
+		current = next
+	}
+	return nil, fmt.Errorf("request chain exceeds %d requests", maxChainedRequests)
 }
 
 func (b *RequestBuilder) toRequestOptions() []RequestOption {
@@ -146,6 +254,10 @@ func (b *RequestBuilder) toRequestOptions() []RequestOption {
 		}
 	}
 
+	for name, value := range b.pathParams {
+		opts = append(opts, WithPathParam(name, value))
+	}
+
 	if b.timeout > 0 {
 		opts = append(opts, WithRequestTimeout(b.timeout))
 	}
@@ -154,5 +266,13 @@ func (b *RequestBuilder) toRequestOptions() []RequestOption {
 		opts = append(opts, WithContentType(b.contentType))
 	}
 
+	if b.endpoint != "" {
+		opts = append(opts, WithEndpointTemplate(b.endpoint))
+	}
+
+	if b.priority != PriorityNormal {
+		opts = append(opts, WithPriority(b.priority))
+	}
+
 	return opts
 }
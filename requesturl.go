@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrHostNotAllowed is returned when WithAllowedHosts refuses a request or
+// redirect to a host outside the allow list.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// allowedHosts holds the WithAllowedHosts policy.
+type allowedHosts struct {
+	hosts    map[string]bool
+	suffixes []string // from "*." patterns, with the leading dot
+}
+
+// WithAllowedHosts restricts requests to the base URLs' hosts and the named
+// hosts, so absolute URLs taken from responses, such as pagination links,
+// cannot send the client's credentials elsewhere. Hosts are matched without
+// their port and ignoring case, internationalized names in either form;
+// "*.example.com" matches any subdomain of example.com but not example.com
+// itself. Redirects, requests sent through Transport or Replay, and the
+// WithShadow base URL are checked too.
+func WithAllowedHosts(hosts ...string) ClientOption {
+	return func(c *Client) error {
+		policy := &allowedHosts{hosts: make(map[string]bool)}
+		for _, host := range hosts {
+			if host == "" {
+				return errors.New("allowed host cannot be empty")
+			}
+			pattern, wildcard := strings.CutPrefix(host, "*.")
+			pattern, err := asciiDomain(pattern)
+			if err != nil {
+				return fmt.Errorf("%w %q: %v", ErrInvalidHost, host, err)
+			}
+			if wildcard {
+				policy.suffixes = append(policy.suffixes, "."+pattern)
+				continue
+			}
+			policy.hosts[pattern] = true
+		}
+		c.allowedHosts = policy
+		return nil
+	}
+}
+
+// check returns an error if u's host is not allowed under the policy.
+func (p *allowedHosts) check(u *url.URL) error {
+	if p == nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if ascii, err := asciiDomain(host); err == nil {
+		host = ascii
+	}
+	if p.hosts[host] {
+		return nil
+	}
+	for _, suffix := range p.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Host)
+}
+
+// configureAllowedHosts allows the base URLs' hosts, checks the shadow
+// base URL and guards redirects.
+func (c *Client) configureAllowedHosts() error {
+	if c.allowedHosts == nil {
+		return nil
+	}
+	if c.baseURL != nil {
+		c.allowedHosts.hosts[strings.ToLower(c.baseURL.Hostname())] = true
+	}
+	for _, base := range c.baseURLs() {
+		c.allowedHosts.hosts[strings.ToLower(base.Hostname())] = true
+	}
+	if c.shadow != nil {
+		if err := c.allowedHosts.check(c.shadow.baseURL); err != nil {
+			return fmt.Errorf("shadow base URL: %w", err)
+		}
+	}
+
+	policy := c.allowedHosts
+	next := c.httpClient.CheckRedirect
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := policy.check(req.URL); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return defaultCheckRedirect(via)
+	}
+	c.httpClient = &httpClient
+	return nil
+}
+
+// WithRequestBaseURL resolves this request's path against baseURL instead
+// of the client's base URL, e.g. for an upload host next to the API host.
+func WithRequestBaseURL(baseURL string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.baseURL = baseURL
+	}
+}
+
+// requestURL resolves path into the URL to request. An absolute http or
+// https path, such as a pagination link, is used as it is; any other path
+// is joined to the request or client base URL. Query parameters from
+// WithQuery are merged into the URL's own, followed by WithRawQuery.
+func (c *Client) requestURL(path string, cfg *requestConfig) (*url.URL, error) {
+	reqURL, err := c.resolveURL(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizeURLHost(reqURL); err != nil {
+		return nil, err
+	}
+	if err := c.addQuery(reqURL, cfg); err != nil {
+		return nil, err
+	}
+	if err := c.checkURL(reqURL, cfg.classification); err != nil {
+		return nil, err
+	}
+	return reqURL, nil
+}
+
+// checkURL returns an error if a request of the given classification may
+// not be sent to u under WithHTTPSOnly or WithAllowedHosts.
+func (c *Client) checkURL(u *url.URL, classification DataClassification) error {
+	if err := c.httpsOnly.check(u); err != nil {
+		return err
+	}
+	if err := c.allowedHosts.check(u); err != nil {
+		return err
+	}
+	return checkClassifiedURL(classification, u.Scheme)
+}
+
+// resolveURL returns path as an absolute URL, or joined to the base URL
+// under the WithPathJoinMode mode.
+func (c *Client) resolveURL(path string, cfg *requestConfig) (*url.URL, error) {
+	if u, ok := absoluteURL(path); ok {
+		return u, nil
+	}
+	if cfg.baseURL == "" {
+		return c.joinPath(c.baseURL, path)
+	}
+	base, err := url.Parse(cfg.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request base URL: %w", err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid request base URL %q: scheme and host are required", cfg.baseURL)
+	}
+	return c.joinPath(base, path)
+}
+
+// absoluteURL parses path if it is an absolute http or https URL.
+func absoluteURL(path string) (*url.URL, bool) {
+	if !strings.Contains(path, "://") {
+		return nil, false
+	}
+	u, err := url.Parse(path)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	if !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https") {
+		return nil, false
+	}
+	return u, true
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newURLServer answers every request with its path and query.
+func newURLServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RequestURI()))
+	}))
+}
+
+// localhostURL returns the server's URL with localhost as its host, a
+// second host name for the same server.
+func localhostURL(server *httptest.Server) string {
+	return strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+}
+
+func TestRequestURL(t *testing.T) {
+	t.Run("uses absolute URL as is", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL("http://api.example.invalid/v1"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), server.URL+"/items?cursor=abc", nil,
+			WithQuery("limit", "10"))
+		require.NoError(t, err)
+		assert.Equal(t, "/items?cursor=abc&limit=10", string(resp.Body))
+	})
+
+	t.Run("joins path to request base URL", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL("http://api.example.invalid/v1"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/files", nil, WithRequestBaseURL(server.URL+"/upload"))
+		require.NoError(t, err)
+		assert.Equal(t, "/upload/files", string(resp.Body))
+	})
+
+	t.Run("rejects invalid request base URL", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/files", nil, WithRequestBaseURL("uploads"))
+		require.ErrorContains(t, err, "scheme and host are required")
+	})
+
+	t.Run("joins paths that are not http URLs", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid/v1"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		u, err := client.requestURL("ftp://files.example.invalid/a", newRequestConfig())
+		require.NoError(t, err)
+		assert.Equal(t, "api.example.invalid", u.Host)
+	})
+
+	t.Run("counts absolute URLs under their path", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithLatencyStats(time.Minute))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), server.URL+"/items?cursor=abc", nil)
+		require.NoError(t, err)
+		_, ok := client.Stats().Endpoints["GET /items"]
+		assert.True(t, ok)
+	})
+}
+
+func TestWithAllowedHosts(t *testing.T) {
+	t.Run("allows base URL host", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAllowedHosts())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), server.URL+"/items", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("refuses other hosts", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAllowedHosts())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), localhostURL(server)+"/items", nil)
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+
+		_, err = client.Get(context.Background(), "/items", nil, WithRequestBaseURL(localhostURL(server)))
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+	})
+
+	t.Run("allows listed hosts", func(t *testing.T) {
+		server := newURLServer()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAllowedHosts("LOCALHOST"))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), localhostURL(server)+"/items", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("matches wildcard subdomains", func(t *testing.T) {
+		policy := &allowedHosts{hosts: map[string]bool{}, suffixes: []string{".example.com"}}
+
+		assert.NoError(t, policy.check(&url.URL{Host: "api.example.com:8443"}))
+		assert.NoError(t, policy.check(&url.URL{Host: "a.b.example.com"}))
+		assert.ErrorIs(t, policy.check(&url.URL{Host: "example.com"}), ErrHostNotAllowed)
+		assert.ErrorIs(t, policy.check(&url.URL{Host: "evilexample.com"}), ErrHostNotAllowed)
+	})
+
+	t.Run("refuses redirects to other hosts", func(t *testing.T) {
+		target := newURLServer()
+		defer target.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, localhostURL(target)+"/elsewhere", http.StatusFound)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithAllowedHosts())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/items", nil)
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+	})
+
+	t.Run("rejects empty host", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithAllowedHosts(""))
+		require.Error(t, err)
+	})
+}
@@ -13,6 +13,28 @@ type Response struct {
 	Status     string
 	Headers    http.Header
 	Body       []byte
+
+	// CompressedSize is the size of the body on the wire when the client
+	// decompressed it (see WithCompression), or 0 if it was not compressed.
+	CompressedSize int
+
+	// FromFallback is true when the response was produced by the fallback
+	// configured with WithFallback instead of the upstream.
+	FromFallback bool
+
+	// Stale is true when the response is a cached copy served because the
+	// upstream failed (see WithStaleIfError), or while it is revalidated in
+	// the background (see WithHTTPCache).
+	Stale bool
+
+	// FromCache is true when the body was served from the HTTP cache (see
+	// WithHTTPCache), without contacting the server or after the server
+	// confirmed the cached copy with 304 Not Modified.
+	FromCache bool
+
+	// TLSResumed is true when the response came over a TLS connection that
+	// resumed an earlier session (see WithTLSSessionResumption).
+	TLSResumed bool
 }
 
 // JSON unmarshals the response body as JSON into the given target.
@@ -1,20 +1,61 @@
 package httpclient
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"math/rand/v2"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 )
 
 // RetryPolicy configures retry behavior for failed requests.
 type RetryPolicy struct {
-	MaxAttempts  int
+	MaxAttempts  int // including the first attempt; must be at least 1
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
 	Multiplier   float64
 	Jitter       float64 // 0.0 to 1.0, percentage of delay to randomize
+
+	// Budget, if set, caps retries across all requests sharing the policy,
+	// weighted by request priority.
+	Budget *RetryBudget
+
+	// MaxElapsedTime, if positive, caps the time spent on all attempts
+	// and the waits between them. A retry whose delay, e.g. from a long
+	// Retry-After, would end past the cap is not made, and an attempt still
+	// running at the cap is canceled.
+	MaxElapsedTime time.Duration
+
+	// RetryableStatusCodes replaces the default retryable statuses (408,
+	// 429, 502, 503 and 504) when non-empty, e.g. to add 500 for idempotent
+	// calls or drop 429 so callers see it immediately.
+	RetryableStatusCodes []int
+
+	// RetryIf, if set, decides whether a failed attempt is retried instead
+	// of the status codes. It receives the response for HTTP errors, whose
+	// body is already consumed, or the transport error for network failures.
+	RetryIf func(resp *http.Response, err error) bool
+
+	// RetryNonIdempotent allows retrying POST, PATCH and other
+	// non-idempotent requests after a network failure once the request was
+	// written. By default such a failure is only retried if the request
+	// carried an Idempotency-Key header, e.g. from IdempotencyKeyMiddleware,
+	// since the server may have performed the write before failing.
+	RetryNonIdempotent bool
+
+	// OnRetry, if set, is called before each retry wait with the upcoming
+	// attempt number (2 for the first retry), the delay, and the failed
+	// attempt's response, if any, and error. It runs on the request's
+	// goroutine, so it should be quick.
+	OnRetry func(attempt int, delay time.Duration, resp *Response, err error)
+
+	// StateStore, if set, checkpoints the retries of requests made with
+	// WithRetryStateKey, so long backoffs survive a restart.
+	StateStore RetryStateStore
 }
 
 // DefaultRetryPolicy returns a retry policy with sensible defaults.
@@ -57,28 +98,144 @@ func (p *RetryPolicy) Backoff(attempt int) time.Duration {
 
 // ShouldRetry returns true if the given status code should be retried.
 func (p *RetryPolicy) ShouldRetry(statusCode int) bool {
+	if len(p.RetryableStatusCodes) > 0 {
+		return slices.Contains(p.RetryableStatusCodes, statusCode)
+	}
 	switch statusCode {
-	case http.StatusRequestTimeout,      // 408
-		http.StatusTooManyRequests,   // 429
-		http.StatusBadGateway,        // 502
+	case http.StatusRequestTimeout, // 408
+		http.StatusTooManyRequests,    // 429
+		http.StatusBadGateway,         // 502
 		http.StatusServiceUnavailable, // 503
-		http.StatusGatewayTimeout:    // 504
+		http.StatusGatewayTimeout:     // 504
 		return true
 	}
 	return false
 }
 
+// shouldRetryResponse applies RetryIf, falling back to ShouldRetry for
+// responses and retrying all network errors.
+func (c *Client) shouldRetryResponse(ctx context.Context, resp *http.Response, err error) bool {
+	p := c.retryPolicy
+	if p.RetryIf != nil {
+		return c.retryIf(ctx, resp, err)
+	}
+	if resp != nil {
+		return p.ShouldRetry(resp.StatusCode)
+	}
+	return true
+}
+
+// retryIf calls the policy's RetryIf. A failure whose RetryIf panics is
+// not retried.
+func (c *Client) retryIf(ctx context.Context, resp *http.Response, err error) bool {
+	var retry bool
+	hookErr := c.runHook(ctx, "retry condition", func() error {
+		retry = c.retryPolicy.RetryIf(resp, err)
+		return nil
+	})
+	return hookErr == nil && retry
+}
+
 // ParseRetryAfter parses the Retry-After header value.
-// Supports seconds format. Returns 0 if parsing fails or value is negative.
+// Supports delay-seconds and HTTP-date formats. Returns 0 if parsing fails,
+// the value is negative or the date is in the past.
 func ParseRetryAfter(value string) time.Duration {
 	if value == "" {
 		return 0
 	}
 
 	seconds, err := strconv.Atoi(value)
-	if err != nil || seconds < 0 {
+	if err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+
+	delay := time.Until(date)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// retryBudgetReserve returns the fraction of a retry budget priority p must
+// leave untouched, so low-priority traffic exhausts the budget first and
+// critical requests keep retry capacity during partial outages. Unknown
+// priorities keep the normal reserve.
+func retryBudgetReserve(p Priority) float64 {
+	switch p {
+	case PriorityLow:
+		return 0.5
+	case PriorityHigh:
+		return 0.1
+	case PriorityCritical:
 		return 0
+	default:
+		return 0.25
+	}
+}
+
+// RetryBudget limits retries to a fraction of overall traffic. Every request
+// deposits Ratio tokens (up to MaxTokens) and every retry withdraws one.
+// It is safe for concurrent use across goroutines.
+type RetryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+}
+
+// NewRetryBudget creates a full budget that earns ratio retries per request,
+// holding at most maxTokens.
+func NewRetryBudget(ratio float64, maxTokens int) (*RetryBudget, error) {
+	if ratio <= 0 || ratio > 1 {
+		return nil, fmt.Errorf("retry budget ratio %v must be in (0, 1]", ratio)
+	}
+	if maxTokens <= 0 {
+		return nil, fmt.Errorf("retry budget max tokens %d must be positive", maxTokens)
+	}
+
+	return &RetryBudget{
+		ratio:     ratio,
+		maxTokens: float64(maxTokens),
+		tokens:    float64(maxTokens),
+	}, nil
+}
+
+// Available returns the number of retry tokens currently available.
+func (b *RetryBudget) Available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
 	}
+}
+
+// withdraw takes one token unless that would dip into the reserve kept for
+// higher priorities.
+func (b *RetryBudget) withdraw(p Priority) bool {
+	reserve := retryBudgetReserve(p)
 
-	return time.Duration(seconds) * time.Second
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens-1 < reserve*b.maxTokens {
+		return false
+	}
+	b.tokens--
+	return true
 }
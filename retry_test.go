@@ -2,8 +2,10 @@ package httpclient
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -105,6 +107,133 @@ func TestRetryPolicy_ShouldRetry(t *testing.T) {
 	}
 }
 
+func TestRetryPolicy_Customization(t *testing.T) {
+	fastPolicy := func() *RetryPolicy {
+		return &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	}
+	attemptsFor := func(t *testing.T, status int, policy *RetryPolicy) int32 {
+		t.Helper()
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/", nil)
+		require.Error(t, err)
+		return calls.Load()
+	}
+
+	t.Run("rejects a policy without attempts", func(t *testing.T) {
+		policy := &RetryPolicy{RetryIf: func(*http.Response, error) bool { return true }}
+
+		_, err := New(WithBaseURL("https://api.example.com"), WithRetry(policy))
+		assert.ErrorContains(t, err, "MaxAttempts must be at least 1")
+	})
+
+	t.Run("retryable status codes replace the defaults", func(t *testing.T) {
+		policy := fastPolicy()
+		policy.RetryableStatusCodes = []int{http.StatusInternalServerError}
+
+		assert.True(t, policy.ShouldRetry(http.StatusInternalServerError))
+		assert.False(t, policy.ShouldRetry(http.StatusTooManyRequests))
+		assert.Equal(t, int32(3), attemptsFor(t, http.StatusInternalServerError, policy))
+		assert.Equal(t, int32(1), attemptsFor(t, http.StatusTooManyRequests, policy))
+	})
+
+	t.Run("RetryIf decides for responses", func(t *testing.T) {
+		policy := fastPolicy()
+		policy.RetryIf = func(resp *http.Response, err error) bool {
+			return resp != nil && resp.StatusCode == http.StatusConflict
+		}
+
+		assert.Equal(t, int32(3), attemptsFor(t, http.StatusConflict, policy))
+		assert.Equal(t, int32(1), attemptsFor(t, http.StatusServiceUnavailable, policy))
+	})
+
+	t.Run("OnRetry reports each retry", func(t *testing.T) {
+		type retry struct {
+			attempt int
+			delay   time.Duration
+			status  int
+		}
+		var retries []retry
+		policy := fastPolicy()
+		policy.OnRetry = func(attempt int, delay time.Duration, resp *Response, err error) {
+			require.Error(t, err)
+			retries = append(retries, retry{attempt, delay, resp.StatusCode})
+		}
+
+		assert.Equal(t, int32(3), attemptsFor(t, http.StatusServiceUnavailable, policy))
+		assert.Equal(t, []retry{
+			{2, time.Millisecond, http.StatusServiceUnavailable},
+			{3, time.Millisecond, http.StatusServiceUnavailable},
+		}, retries)
+	})
+
+	t.Run("MaxElapsedTime skips retries past the cap", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		policy := fastPolicy()
+		policy.MaxElapsedTime = time.Second
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.Get(context.Background(), "/", nil)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("MaxElapsedTime cancels slow attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		policy := fastPolicy()
+		policy.MaxElapsedTime = 50 * time.Millisecond
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.Get(context.Background(), "/", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.True(t, clientErr.IsTimeout())
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("RetryIf decides for network errors", func(t *testing.T) {
+		var seen []error
+		policy := fastPolicy()
+		policy.RetryIf = func(resp *http.Response, err error) bool {
+			seen = append(seen, err)
+			return false
+		}
+
+		client, err := New(WithBaseURL("http://127.0.0.1:1"), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/", nil)
+		require.Error(t, err)
+		require.Len(t, seen, 1)
+		assert.Error(t, seen[0])
+	})
+}
+
 func TestClient_Retry(t *testing.T) {
 	t.Run("retries on retryable status codes", func(t *testing.T) {
 		var attempts int32
@@ -290,6 +419,8 @@ func TestParseRetryAfter(t *testing.T) {
 		{"zero", "0", 0},
 		{"invalid", "invalid", 0},
 		{"empty", "", 0},
+		{"negative", "-5", 0},
+		{"past HTTP date", "Wed, 21 Oct 2015 07:28:00 GMT", 0},
 	}
 
 	for _, tt := range tests {
@@ -297,4 +428,175 @@ func TestParseRetryAfter(t *testing.T) {
 			assert.Equal(t, tt.expected, ParseRetryAfter(tt.value))
 		})
 	}
+
+	t.Run("future HTTP date", func(t *testing.T) {
+		date := time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+
+		delay := ParseRetryAfter(date)
+
+		assert.Greater(t, delay, 110*time.Second)
+		assert.LessOrEqual(t, delay, 2*time.Minute)
+	})
+}
+
+func TestClient_RetryAfterOnError(t *testing.T) {
+	t.Run("exposes Retry-After when retries are exhausted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		var httpErr *Error
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, 120*time.Second, httpErr.RetryAfter)
+	})
+
+	t.Run("is zero without Retry-After header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil)
+
+		var httpErr *Error
+		require.ErrorAs(t, err, &httpErr)
+		assert.Zero(t, httpErr.RetryAfter)
+	})
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := NewRetryBudget(0, 10)
+		assert.ErrorContains(t, err, "retry budget ratio 0 must be in (0, 1]")
+
+		_, err = NewRetryBudget(0.1, 0)
+		assert.ErrorContains(t, err, "retry budget max tokens 0 must be positive")
+	})
+
+	t.Run("low priority exhausts budget before critical", func(t *testing.T) {
+		budget, err := NewRetryBudget(0.1, 10)
+		require.NoError(t, err)
+
+		low := 0
+		for budget.withdraw(PriorityLow) {
+			low++
+		}
+		critical := 0
+		for budget.withdraw(PriorityCritical) {
+			critical++
+		}
+
+		assert.Equal(t, 5, low)
+		assert.Equal(t, 5, critical)
+		assert.Zero(t, budget.Available())
+	})
+
+	t.Run("deposits refill up to max", func(t *testing.T) {
+		budget, err := NewRetryBudget(0.5, 2)
+		require.NoError(t, err)
+		require.True(t, budget.withdraw(PriorityCritical))
+
+		budget.deposit()
+		budget.deposit()
+		budget.deposit()
+
+		assert.Equal(t, 2.0, budget.Available())
+	})
+}
+
+func TestClient_RetryBudget(t *testing.T) {
+	t.Run("stops retrying low priority requests when budget is scarce", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		budget, err := NewRetryBudget(0.01, 4)
+		require.NoError(t, err)
+
+		policy := &RetryPolicy{MaxAttempts: 5, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, Budget: budget}
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/test", nil, WithPriority(PriorityLow))
+		require.Error(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+		atomic.StoreInt32(&attempts, 0)
+		_, err = client.Request().Path("/test").Priority(PriorityCritical).Do(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+}
+
+// flakyTransport fails every request with 503 until its last attempt.
+type flakyTransport struct {
+	attempts int
+	calls    atomic.Int64
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := http.StatusServiceUnavailable
+	if f.calls.Add(1)%int64(f.attempts) == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func newFlakyClient(tb testing.TB, attempts int) *Client {
+	tb.Helper()
+	client, err := New(
+		WithBaseURL("http://api.example.com"),
+		WithLoggerDisabled(),
+		WithHTTPClient(&http.Client{Transport: &flakyTransport{attempts: attempts}}),
+		WithRetry(&RetryPolicy{MaxAttempts: attempts, InitialDelay: time.Nanosecond, MaxDelay: time.Nanosecond, Multiplier: 1}),
+	)
+	require.NoError(tb, err)
+	return client
+}
+
+func BenchmarkRetryPath(b *testing.B) {
+	client := newFlakyClient(b, 5)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := client.Get(ctx, "/users/42", nil, WithQuery("expand", "orders")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRetryAllocations(t *testing.T) {
+	ctx := context.Background()
+	perRequest := func(attempts int) float64 {
+		client := newFlakyClient(t, attempts)
+		return testing.AllocsPerRun(100, func() {
+			_, err := client.Get(ctx, "/users/42", nil, WithQuery("expand", "orders"))
+			require.NoError(t, err)
+		})
+	}
+
+	// Retries rebuild only the request itself; the URL, merged headers and
+	// wait timer are shared by all attempts.
+	perRetry := (perRequest(5) - perRequest(1)) / 4
+	assert.LessOrEqual(t, perRetry, float64(24))
 }
@@ -0,0 +1,158 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RetryState is the progress of a request's retries, checkpointed in a
+// RetryStateStore before each retry wait.
+type RetryState struct {
+	Attempts    int       `json:"attempts"` // attempts made so far
+	NextRetryAt time.Time `json:"next_retry_at"`
+}
+
+// RetryStateStore persists retry progress by key, so a request retried
+// with long backoffs resumes its schedule after a restart instead of
+// starting over. Implementations must be safe for concurrent use.
+type RetryStateStore interface {
+	Get(ctx context.Context, key string) (*RetryState, bool, error)
+	Set(ctx context.Context, key string, state *RetryState) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryRetryStateStore is an in-process RetryStateStore, for tests and
+// for resuming within one process.
+type MemoryRetryStateStore struct {
+	mu     sync.Mutex
+	states map[string]RetryState
+}
+
+// NewMemoryRetryStateStore creates an empty in-process store.
+func NewMemoryRetryStateStore() *MemoryRetryStateStore {
+	return &MemoryRetryStateStore{states: make(map[string]RetryState)}
+}
+
+// Get implements RetryStateStore.
+func (s *MemoryRetryStateStore) Get(ctx context.Context, key string) (*RetryState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return &state, true, nil
+}
+
+// Set implements RetryStateStore.
+func (s *MemoryRetryStateStore) Set(ctx context.Context, key string, state *RetryState) error {
+	if state == nil {
+		return errors.New("retry state cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[key] = *state
+	return nil
+}
+
+// Delete implements RetryStateStore.
+func (s *MemoryRetryStateStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// WithRetryStateKey checkpoints this request's retries under key, e.g. a
+// job ID, in the retry policy's StateStore. A request with a checkpoint
+// resumes from it: it waits until the recorded next retry time and counts
+// the recorded attempts against MaxAttempts. The checkpoint is removed
+// once the request succeeds or gives up, and kept when its context is
+// canceled, e.g. on shutdown.
+func WithRetryStateKey(key string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.retryStateKey = key
+	}
+}
+
+// retryStateStore returns the store checkpointing the call, or nil.
+func (c *Client) retryStateStore(cl *call) RetryStateStore {
+	if cl.retryStateKey == "" || c.retryPolicy == nil {
+		return nil
+	}
+	return c.retryPolicy.StateStore
+}
+
+// errResumeDeadline reports a checkpointed retry that is due after the
+// call's deadline, e.g. its MaxElapsedTime.
+var errResumeDeadline = fmt.Errorf("checkpointed retry is due after the deadline: %w", context.DeadlineExceeded)
+
+// resumeRetries waits out a checkpointed backoff and returns the number of
+// the first attempt to make, with the timer used to wait, if any. Like a
+// retry scheduled in the call, a checkpointed one due after the deadline
+// is not waited for; it fails at once with errResumeDeadline.
+func (c *Client) resumeRetries(ctx context.Context, cl *call, maxAttempts int) (int, *time.Timer, error) {
+	store := c.retryStateStore(cl)
+	if store == nil {
+		return 1, nil, nil
+	}
+	state, ok, err := store.Get(ctx, cl.retryStateKey)
+	if err != nil {
+		c.logRetryStateError(ctx, cl, err)
+		return 1, nil, nil
+	}
+	if !ok {
+		return 1, nil, nil
+	}
+
+	first := min(max(state.Attempts+1, 1), max(maxAttempts, 1))
+	delay := time.Until(state.NextRetryAt)
+	if delay <= 0 {
+		return first, nil, nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && state.NextRetryAt.After(deadline) {
+		return first, nil, errResumeDeadline
+	}
+	return first, c.waitForRetry(ctx, nil, delay), nil
+}
+
+// saveRetryState checkpoints a scheduled retry. Attempts failed by a
+// canceled context did not reach the server and are not recorded.
+func (c *Client) saveRetryState(ctx context.Context, cl *call, attempt int, delay time.Duration) {
+	store := c.retryStateStore(cl)
+	if store == nil || ctx.Err() != nil {
+		return
+	}
+	state := &RetryState{Attempts: attempt, NextRetryAt: time.Now().Add(delay)}
+	if err := store.Set(ctx, cl.retryStateKey, state); err != nil {
+		c.logRetryStateError(ctx, cl, err)
+	}
+}
+
+// clearRetryState removes the call's checkpoint unless ctx was canceled.
+func (c *Client) clearRetryState(ctx context.Context, cl *call) {
+	store := c.retryStateStore(cl)
+	if store == nil || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+	if err := store.Delete(context.WithoutCancel(ctx), cl.retryStateKey); err != nil {
+		c.logRetryStateError(ctx, cl, err)
+	}
+}
+
+func (c *Client) logRetryStateError(ctx context.Context, cl *call, err error) {
+	if !c.logEnabled(ctx, slog.LevelWarn) {
+		return
+	}
+	c.logger.Log(ctx, slog.LevelWarn, "http_retry_state_error",
+		slog.String("method", cl.method),
+		slog.String("url", cl.url),
+		slog.String("error", err.Error()),
+	)
+}
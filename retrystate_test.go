@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRetryStateStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRetryStateStore()
+
+	_, ok, err := store.Get(ctx, "job")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	next := time.Now().Add(time.Minute)
+	require.NoError(t, store.Set(ctx, "job", &RetryState{Attempts: 2, NextRetryAt: next}))
+	state, ok, err := store.Get(ctx, "job")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 2, state.Attempts)
+	assert.True(t, next.Equal(state.NextRetryAt))
+
+	require.NoError(t, store.Delete(ctx, "job"))
+	_, ok, _ = store.Get(ctx, "job")
+	assert.False(t, ok)
+
+	assert.Error(t, store.Set(ctx, "job", nil))
+}
+
+func TestRetryStateStore(t *testing.T) {
+	ctx := context.Background()
+	newPolicy := func(store RetryStateStore, delay time.Duration) *RetryPolicy {
+		return &RetryPolicy{MaxAttempts: 3, InitialDelay: delay, MaxDelay: delay, Multiplier: 1, StateStore: store}
+	}
+
+	t.Run("checkpoints retries and clears on success", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		store := NewMemoryRetryStateStore()
+		policy := newPolicy(store, time.Millisecond)
+		var checkpoints []int
+		policy.OnRetry = func(attempt int, delay time.Duration, resp *Response, err error) {
+			state, ok, _ := store.Get(ctx, "job-1")
+			require.True(t, ok)
+			checkpoints = append(checkpoints, state.Attempts)
+		}
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/export", nil, WithRetryStateKey("job-1"))
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, checkpoints)
+		_, ok, _ := store.Get(ctx, "job-1")
+		assert.False(t, ok)
+	})
+
+	t.Run("resumes from a checkpoint", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		store := NewMemoryRetryStateStore()
+		require.NoError(t, store.Set(ctx, "job-2", &RetryState{Attempts: 2, NextRetryAt: time.Now().Add(50 * time.Millisecond)}))
+		collector := &recordingCollector{}
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(newPolicy(store, time.Millisecond)), WithMetrics(collector))
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.Get(ctx, "/export", nil, WithRetryStateKey("job-2"))
+		require.Error(t, err)
+
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+		require.Len(t, collector.observations, 1)
+		assert.Equal(t, 3, collector.observations[0].attempt)
+		_, ok, _ := store.Get(ctx, "job-2")
+		assert.False(t, ok, "cleared after giving up")
+	})
+
+	t.Run("does not wait past MaxElapsedTime", func(t *testing.T) {
+		var status, calls atomic.Int32
+		status.Store(http.StatusServiceUnavailable)
+		server := newCountingServer(&status, &calls)
+		defer server.Close()
+
+		store := NewMemoryRetryStateStore()
+		require.NoError(t, store.Set(ctx, "job-4", &RetryState{Attempts: 1, NextRetryAt: time.Now().Add(time.Hour)}))
+		policy := newPolicy(store, time.Millisecond)
+		policy.MaxElapsedTime = 50 * time.Millisecond
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(policy))
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = client.Get(ctx, "/export", nil, WithRetryStateKey("job-4"))
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.True(t, clientErr.IsTimeout())
+		assert.Less(t, time.Since(start), 40*time.Millisecond, "fails without waiting")
+		assert.Zero(t, calls.Load())
+	})
+
+	t.Run("keeps the checkpoint when canceled", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		store := NewMemoryRetryStateStore()
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(newPolicy(store, time.Hour)))
+		require.NoError(t, err)
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err = client.Get(cancelCtx, "/export", nil, WithRetryStateKey("job-3"))
+		require.Error(t, err)
+
+		state, ok, _ := store.Get(ctx, "job-3")
+		require.True(t, ok)
+		assert.Equal(t, 1, state.Attempts)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), state.NextRetryAt, time.Minute)
+	})
+}
@@ -0,0 +1,307 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxShadowInFlight bounds concurrent shadow requests; requests beyond
+	// it are not mirrored.
+	maxShadowInFlight = 8
+
+	// maxShadowDiffs bounds the divergences DiffResponses reports.
+	maxShadowDiffs = 50
+)
+
+// ShadowDiffFunc receives a primary response and the diverging response of
+// its shadow request. shadow is nil when the shadow request failed.
+type ShadowDiffFunc func(primary, shadow *Response)
+
+// shadowConfig mirrors requests to a second base URL.
+type shadowConfig struct {
+	baseURL      *url.URL
+	diff         ShadowDiffFunc
+	ignoreFields []string
+	inFlight     chan struct{}
+	sendAuth     bool
+}
+
+// WithShadow mirrors GET, HEAD and OPTIONS requests to the same path on
+// baseURL, e.g. a vendor's next API version, in the background. Shadow
+// responses never affect the caller; combine with WithShadowDiff to report
+// divergences. Unsafe methods are not mirrored so the shadow cannot repeat
+// side effects. Shadow requests carry no credentials unless WithShadowAuth
+// is given, and baseURL must pass WithHTTPSOnly and WithAllowedHosts.
+func WithShadow(baseURL string) ClientOption {
+	return func(c *Client) error {
+		if baseURL == "" {
+			return errors.New("shadow base URL cannot be empty")
+		}
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("parsing shadow base URL: %w", err)
+		}
+		if err := normalizeURLHost(u); err != nil {
+			return err
+		}
+		c.shadowConfig().baseURL = u
+		return nil
+	}
+}
+
+// WithShadowAuth sends shadow requests with the client's credentials, for a
+// shadow base URL run by the same vendor. It requires WithShadow.
+func WithShadowAuth() ClientOption {
+	return func(c *Client) error {
+		c.shadowConfig().sendAuth = true
+		return nil
+	}
+}
+
+// WithShadowDiff calls fn when a shadow response diverges from its primary
+// response in status code or normalized body, to validate a vendor API
+// upgrade before cutover. JSON bodies are compared structurally, ignoring
+// key order, whitespace and any object fields named in ignoreFields, such
+// as timestamps or request IDs. Use DiffResponses in fn to list the
+// divergences. It requires WithShadow.
+func WithShadowDiff(fn ShadowDiffFunc, ignoreFields ...string) ClientOption {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("shadow diff function cannot be nil")
+		}
+		cfg := c.shadowConfig()
+		cfg.diff = fn
+		cfg.ignoreFields = append(cfg.ignoreFields, ignoreFields...)
+		return nil
+	}
+}
+
+func (c *Client) shadowConfig() *shadowConfig {
+	if c.shadow == nil {
+		c.shadow = &shadowConfig{inFlight: make(chan struct{}, maxShadowInFlight)}
+	}
+	return c.shadow
+}
+
+// configureShadow validates the shadow options once all are applied.
+func (c *Client) configureShadow() error {
+	if c.shadow != nil && c.shadow.baseURL == nil {
+		return errors.New("shadow options require WithShadow")
+	}
+	return nil
+}
+
+// mirror sends a copy of a completed call to the shadow base URL.
+func (c *Client) mirror(ctx context.Context, cl *call, primary *Response) {
+	s := c.shadow
+	if s == nil || primary == nil || primary.FromFallback || primary.Stale || primary.FromCache {
+		return
+	}
+	if cl.method != http.MethodGet && cl.method != http.MethodHead && cl.method != http.MethodOptions {
+		return
+	}
+	if !c.shadowEnabled(ctx) {
+		return
+	}
+	shadowURL, err := s.rewrite(cl.url, c.baseURL)
+	if err != nil || c.checkURL(shadowURL, cl.classification) != nil {
+		return
+	}
+
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		return
+	}
+
+	shadowCall := *cl
+	shadowCall.url = shadowURL.String()
+	shadowCall.host = shadowURL.Host
+	shadowCall.tee = nil
+	shadowCall.sentBytes, shadowCall.receivedBytes = 0, 0
+	snapshot := &Response{
+		StatusCode: primary.StatusCode,
+		Status:     primary.Status,
+		Headers:    primary.Headers.Clone(),
+		Body:       bytes.Clone(primary.Body),
+	}
+
+	// The shadow outlives the caller's request, so it keeps only ctx's values.
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	go func() {
+		defer func() { <-s.inFlight }()
+		defer cancel()
+
+		shadow := c.sendShadow(shadowCtx, &shadowCall)
+		if s.diff != nil && (shadow == nil || len(DiffResponses(snapshot, shadow, s.ignoreFields...)) > 0) {
+			s.diff(snapshot, shadow)
+		}
+	}()
+}
+
+// rewrite moves a primary request URL onto the shadow base URL.
+func (s *shadowConfig) rewrite(rawURL string, base *url.URL) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	path := u.Path
+	if base != nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	u.Scheme = s.baseURL.Scheme
+	u.Host = s.baseURL.Host
+	u.Path = strings.TrimSuffix(s.baseURL.Path, "/") + path
+	u.RawPath = ""
+	return u, nil
+}
+
+// sendShadow sends the shadow call once, without retries, logging or
+// statistics, and returns nil if it failed.
+func (c *Client) sendShadow(ctx context.Context, cl *call) *Response {
+	var auth AuthProvider
+	if c.shadow.sendAuth {
+		auth, _ = c.auth.Load().providers(time.Now())
+	}
+	resp, res := c.send(ctx, cl, auth)
+	if res.err != nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil
+	}
+	if cl.decompress {
+		if body, _, err = c.decompressBody(resp.Header, body); err != nil {
+			return nil
+		}
+	}
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Header,
+		Body:       body,
+	}
+}
+
+// DiffResponses lists how shadow diverges from primary: status code and
+// body, with JSON bodies compared structurally by path (e.g.
+// "$.items[0].price: 10 != 12") while skipping object fields named in
+// ignoreFields. It reports at most 50 divergences.
+func DiffResponses(primary, shadow *Response, ignoreFields ...string) []string {
+	if primary == nil || shadow == nil {
+		return []string{"response missing"}
+	}
+
+	var diffs []string
+	if primary.StatusCode != shadow.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status: %d != %d", primary.StatusCode, shadow.StatusCode))
+	}
+
+	var a, b any
+	if json.Unmarshal(primary.Body, &a) != nil || json.Unmarshal(shadow.Body, &b) != nil {
+		if !bytes.Equal(bytes.TrimSpace(primary.Body), bytes.TrimSpace(shadow.Body)) {
+			diffs = append(diffs, "body differs")
+		}
+		return diffs
+	}
+	return append(diffs, diffJSON(a, b, ignoreFields)...)
+}
+
+// diffJSON compares decoded JSON values iteratively, depth first.
+func diffJSON(a, b any, ignoreFields []string) []string {
+	type node struct {
+		path string
+		a, b any
+	}
+
+	var diffs []string
+	stack := []node{{path: "$", a: a, b: b}}
+	for len(stack) > 0 && len(diffs) < maxShadowDiffs {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch av := n.a.(type) {
+		case map[string]any:
+			bv, ok := n.b.(map[string]any)
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s: object != %s", n.path, jsonKind(n.b)))
+				continue
+			}
+			keys := make([]string, 0, len(av)+len(bv))
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, dup := av[k]; !dup {
+					keys = append(keys, k)
+				}
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+			for _, k := range keys {
+				if slices.Contains(ignoreFields, k) {
+					continue
+				}
+				stack = append(stack, node{path: n.path + "." + k, a: av[k], b: bv[k]})
+			}
+		case []any:
+			bv, ok := n.b.([]any)
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s: array != %s", n.path, jsonKind(n.b)))
+				continue
+			}
+			if len(av) != len(bv) {
+				diffs = append(diffs, fmt.Sprintf("%s: length %d != %d", n.path, len(av), len(bv)))
+				continue
+			}
+			for i := len(av) - 1; i >= 0; i-- {
+				stack = append(stack, node{path: fmt.Sprintf("%s[%d]", n.path, i), a: av[i], b: bv[i]})
+			}
+		default:
+			if !reflect.DeepEqual(n.a, n.b) {
+				diffs = append(diffs, fmt.Sprintf("%s: %s != %s", n.path, jsonText(n.a), jsonText(n.b)))
+			}
+		}
+	}
+	return diffs
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonText(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithShadowDiff(t *testing.T) {
+	newServer := func(t *testing.T, body string) *httptest.Server {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	type divergence struct {
+		primary, shadow *Response
+	}
+
+	t.Run("reports diverging shadow responses", func(t *testing.T) {
+		primary := newServer(t, `{"id":1,"price":10,"updated_at":"a"}`)
+		shadow := newServer(t, `{"updated_at":"b","price":12,"id":1}`)
+		diverged := make(chan divergence, 1)
+
+		client, err := New(
+			WithBaseURL(primary.URL),
+			WithLoggerDisabled(),
+			WithShadow(shadow.URL),
+			WithShadowDiff(func(p, s *Response) { diverged <- divergence{p, s} }, "updated_at"),
+		)
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/items/1", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":1,"price":10,"updated_at":"a"}`, resp.String())
+
+		select {
+		case d := <-diverged:
+			require.NotNil(t, d.shadow)
+			assert.Equal(t, []string{"$.price: 10 != 12"}, DiffResponses(d.primary, d.shadow, "updated_at"))
+		case <-time.After(2 * time.Second):
+			t.Fatal("shadow diff not reported")
+		}
+	})
+
+	t.Run("ignores equivalent responses", func(t *testing.T) {
+		primary := newServer(t, `{"a":1,"b":[1,2]}`)
+		shadowed := make(chan string, 1)
+		shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(` {"b":[1,2], "a":1} `))
+			shadowed <- r.URL.Path
+		}))
+		defer shadow.Close()
+		diverged := make(chan divergence, 1)
+
+		client, err := New(
+			WithBaseURL(primary.URL+"/v1"),
+			WithLoggerDisabled(),
+			WithShadow(shadow.URL+"/v2"),
+			WithShadowDiff(func(p, s *Response) { diverged <- divergence{p, s} }),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/items", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "/v2/items", <-shadowed)
+		select {
+		case <-diverged:
+			t.Fatal("equivalent responses reported as diverging")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("does not mirror unsafe methods", func(t *testing.T) {
+		primary := newServer(t, `{}`)
+		shadowed := make(chan struct{}, 1)
+		shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			shadowed <- struct{}{}
+		}))
+		defer shadow.Close()
+
+		client, err := New(WithBaseURL(primary.URL), WithLoggerDisabled(), WithShadow(shadow.URL))
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/orders", map[string]int{"qty": 1}, nil)
+		require.NoError(t, err)
+
+		select {
+		case <-shadowed:
+			t.Fatal("POST was mirrored")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("strips credentials unless asked to send them", func(t *testing.T) {
+		primary := newServer(t, `{}`)
+		authorization := make(chan string, 1)
+		shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization <- r.Header.Get("Authorization")
+		}))
+		defer shadow.Close()
+
+		for _, sendAuth := range []bool{false, true} {
+			opts := []ClientOption{
+				WithBaseURL(primary.URL),
+				WithLoggerDisabled(),
+				WithAuth(BearerAuth("secret")),
+				WithShadow(shadow.URL),
+			}
+			if sendAuth {
+				opts = append(opts, WithShadowAuth())
+			}
+			client, err := New(opts...)
+			require.NoError(t, err)
+
+			_, err = client.Get(context.Background(), "/items", nil)
+			require.NoError(t, err)
+
+			want := ""
+			if sendAuth {
+				want = "Bearer secret"
+			}
+			select {
+			case got := <-authorization:
+				assert.Equal(t, want, got)
+			case <-time.After(2 * time.Second):
+				t.Fatal("request not mirrored")
+			}
+		}
+	})
+
+	t.Run("checks the shadow base URL against client policies", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithHTTPSOnly(), WithShadow("http://next.example.com"))
+		require.ErrorIs(t, err, ErrInsecureURL)
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithAllowedHosts(), WithShadow("https://next.example.org"))
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithAllowedHosts("*.example.com"), WithShadow("https://next.example.com"))
+		require.NoError(t, err)
+	})
+
+	t.Run("requires WithShadow", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithShadowDiff(func(p, s *Response) {}))
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffResponses(t *testing.T) {
+	newResponse := func(status int, body string) *Response {
+		return &Response{StatusCode: status, Body: []byte(body)}
+	}
+
+	t.Run("lists JSON divergences by path", func(t *testing.T) {
+		diffs := DiffResponses(
+			newResponse(200, `{"items":[{"id":1},{"id":2}],"total":2,"next":null,"meta":{}}`),
+			newResponse(500, `{"items":[{"id":1},{"id":3}],"total":"2","meta":[]}`),
+		)
+
+		assert.Equal(t, []string{
+			"status: 200 != 500",
+			"$.items[1].id: 2 != 3",
+			"$.meta: object != array",
+			"$.total: 2 != \"2\"",
+		}, diffs)
+	})
+
+	t.Run("compares non-JSON bodies as text", func(t *testing.T) {
+		assert.Empty(t, DiffResponses(newResponse(200, "ok\n"), newResponse(200, "ok")))
+		assert.Equal(t, []string{"body differs"}, DiffResponses(newResponse(200, "ok"), newResponse(200, "nope")))
+	})
+
+	t.Run("reports array length changes", func(t *testing.T) {
+		diffs := DiffResponses(newResponse(200, `[1,2]`), newResponse(200, `[1]`))
+		assert.Equal(t, []string{"$: length 2 != 1"}, diffs)
+	})
+}
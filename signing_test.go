@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedRequest is what a signing test server received.
+type signedRequest struct {
+	signature string
+	timestamp string
+	message   string
+}
+
+func newSigningServer(t *testing.T, signatureHeader, timestampHeader string) (*httptest.Server, *signedRequest) {
+	t.Helper()
+	got := &signedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got.signature = r.Header.Get(signatureHeader)
+		got.timestamp = r.Header.Get(timestampHeader)
+		got.message = r.Method + "\n" + r.URL.RequestURI() + "\n" + got.timestamp + "\n" + string(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func TestHMACSigningMiddleware(t *testing.T) {
+	t.Run("signs method, path, timestamp and body", func(t *testing.T) {
+		server, got := newSigningServer(t, DefaultSignatureHeader, DefaultSignatureTimestampHeader)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithMiddleware(HMACSigningMiddleware("k1", []byte("secret"), HMACSHA256)),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/v1/charges", map[string]int{"amount": 100}, nil,
+			WithQuery("expand", "customer"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "POST\n/v1/charges?expand=customer\n"+got.timestamp+"\n{\"amount\":100}", got.message)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(got.message))
+		assert.Equal(t, "k1="+hex.EncodeToString(mac.Sum(nil)), got.signature)
+	})
+
+	t.Run("uses custom headers and base64", func(t *testing.T) {
+		server, got := newSigningServer(t, "X-Sig", "X-Time")
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithMiddleware(HMACSigningMiddleware("k1", []byte("secret"), HMACSHA512,
+				HMACHeader("X-Sig"), HMACTimestampHeader("X-Time"), HMACBase64())),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/v1/balance", nil)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, got.timestamp)
+		mac := hmac.New(sha512.New, []byte("secret"))
+		mac.Write([]byte(got.message))
+		assert.Equal(t, "k1="+base64.StdEncoding.EncodeToString(mac.Sum(nil)), got.signature)
+	})
+
+	t.Run("signs with every key in the ring", func(t *testing.T) {
+		server, got := newSigningServer(t, DefaultSignatureHeader, DefaultSignatureTimestampHeader)
+		ring := NewHMACKeyRing(HMACKey{ID: "old", Secret: []byte("a")})
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithMiddleware(HMACKeyRingMiddleware(ring, HMACSHA256)),
+		)
+		require.NoError(t, err)
+
+		ring.Add(HMACKey{ID: "new", Secret: []byte("b")})
+		_, err = client.Get(context.Background(), "/v1/balance", nil)
+		require.NoError(t, err)
+		signatures := strings.Split(got.signature, ",")
+		require.Len(t, signatures, 2)
+		assert.True(t, strings.HasPrefix(signatures[0], "old="))
+		assert.True(t, strings.HasPrefix(signatures[1], "new="))
+
+		ring.Remove("old")
+		_, err = client.Get(context.Background(), "/v1/balance", nil)
+		require.NoError(t, err)
+		mac := hmac.New(sha256.New, []byte("b"))
+		mac.Write([]byte(got.message))
+		assert.Equal(t, "new="+hex.EncodeToString(mac.Sum(nil)), got.signature)
+	})
+
+	t.Run("fails without keys", func(t *testing.T) {
+		server, _ := newSigningServer(t, DefaultSignatureHeader, DefaultSignatureTimestampHeader)
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithMiddleware(HMACKeyRingMiddleware(NewHMACKeyRing(), HMACSHA256)),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/v1/balance", nil)
+		require.ErrorContains(t, err, "no HMAC keys")
+	})
+
+	t.Run("replaces keys with the same ID", func(t *testing.T) {
+		ring := NewHMACKeyRing(HMACKey{ID: "k", Secret: []byte("a")}, HMACKey{ID: "k", Secret: []byte("b")})
+
+		assert.Equal(t, []HMACKey{{ID: "k", Secret: []byte("b")}}, ring.Keys())
+	})
+
+	t.Run("names algorithms", func(t *testing.T) {
+		assert.Equal(t, "hmac-sha256", HMACSHA256.String())
+		assert.Equal(t, "hmac-sha512", HMACSHA512.String())
+		assert.Equal(t, "hmac-sha1", HMACSHA1.String())
+	})
+}
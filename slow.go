@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// SlowRequestFunc is called when a request is still in flight after the slow
+// request threshold. It runs on its own goroutine while the request continues.
+type SlowRequestFunc func(ctx context.Context, method, url string, elapsed time.Duration)
+
+// WithSlowRequestThreshold warns about requests that are still running after
+// threshold, before they time out, giving early notice of a degrading
+// upstream. A warning is logged and callback, if non-nil, is invoked.
+func WithSlowRequestThreshold(threshold time.Duration, callback SlowRequestFunc) ClientOption {
+	return func(c *Client) error {
+		if threshold <= 0 {
+			return errors.New("slow request threshold must be positive")
+		}
+		c.slowThreshold = threshold
+		c.slowCallback = callback
+		return nil
+	}
+}
+
+// watchSlow arms the slow request warning for a call. The returned function
+// disarms it and must be called when the request completes.
+func (c *Client) watchSlow(ctx context.Context, cl *call, start time.Time) func() bool {
+	if c.slowThreshold <= 0 {
+		return func() bool { return false }
+	}
+
+	timer := time.AfterFunc(c.slowThreshold, func() {
+		elapsed := time.Since(start)
+		if c.logEnabled(ctx, slog.LevelWarn) {
+			attrs := []slog.Attr{
+				slog.String("method", cl.method),
+				slog.String("url", cl.url),
+				slog.Int64("elapsed_ms", elapsed.Milliseconds()),
+				slog.Int64("threshold_ms", c.slowThreshold.Milliseconds()),
+			}
+			if c.thirdPartyCode != "" {
+				attrs = append(attrs, slog.String("third_party_code", c.thirdPartyCode))
+			}
+			if cl.operation != "" {
+				attrs = append(attrs, slog.String("operation", cl.operation))
+			}
+			c.logger.Log(ctx, slog.LevelWarn, "http_request_slow", attrs...)
+		}
+		if c.slowCallback != nil {
+			_ = c.runHook(ctx, "slow callback", func() error {
+				c.slowCallback(ctx, cl.method, cl.url, elapsed)
+				return nil
+			})
+		}
+	})
+	return timer.Stop
+}
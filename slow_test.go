@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSlowRequestThreshold(t *testing.T) {
+	t.Run("warns while a slow request is in flight", func(t *testing.T) {
+		var warnedBeforeDone atomic.Bool
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(80 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLogger(logger),
+			WithSlowRequestThreshold(20*time.Millisecond, func(ctx context.Context, method, url string, elapsed time.Duration) {
+				select {
+				case <-done:
+				default:
+					warnedBeforeDone.Store(true)
+				}
+				assert.Equal(t, http.MethodGet, method)
+				assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/slow", nil)
+		close(done)
+		require.NoError(t, err)
+
+		assert.True(t, warnedBeforeDone.Load())
+		entries := logger.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "http_request_slow", entries[0].Msg)
+		assert.Equal(t, slog.LevelWarn, entries[0].Level)
+		assert.Equal(t, int64(20), entries[0].Attrs["threshold_ms"])
+	})
+
+	t.Run("does not warn for fast requests", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithSlowRequestThreshold(time.Second, func(ctx context.Context, method, url string, elapsed time.Duration) {
+				calls.Add(1)
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/fast", nil)
+		require.NoError(t, err)
+
+		assert.Zero(t, calls.Load())
+	})
+
+	t.Run("returns error for non-positive threshold", func(t *testing.T) {
+		_, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithSlowRequestThreshold(0, nil),
+		)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "slow request threshold must be positive")
+	})
+}
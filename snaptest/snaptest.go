@@ -0,0 +1,255 @@
+// Package snaptest snapshots the requests a client sends to golden files,
+// so refactors cannot silently change what reaches a vendor. Add Record as
+// the client's last option:
+//
+//	client, err := httpclient.New(httpclient.WithBaseURL(srv.URL), snaptest.Record(t))
+//
+// When the test ends, the method, path, query, headers and body of every
+// request are compared with testdata/snapshots/<test name>.golden, and the
+// test fails on any difference. Run the tests with SNAPTEST_UPDATE=1 to
+// write the golden files after an intended change.
+package snaptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/holgersendify/httpclient"
+)
+
+// UpdateEnv is the environment variable that, when set to 1, makes Record
+// write golden files instead of comparing against them.
+const UpdateEnv = "SNAPTEST_UPDATE"
+
+// DefaultDir holds the golden files unless Dir is given.
+const DefaultDir = "testdata/snapshots"
+
+// masked replaces the values of masked headers in snapshots.
+const masked = "<masked>"
+
+// defaultMasked are headers whose values are secret, differ per run or
+// change with the library version.
+var defaultMasked = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key",
+	"X-Request-ID", "Traceparent", "Tracestate", "Idempotency-Key",
+	"User-Agent",
+}
+
+// defaultIgnored are headers added by the transport or the test setup.
+var defaultIgnored = []string{"Content-Length", "Date"}
+
+// Option configures Record.
+type Option func(*recorder)
+
+// Dir stores golden files in dir instead of DefaultDir.
+func Dir(dir string) Option {
+	return func(r *recorder) {
+		r.dir = dir
+	}
+}
+
+// Mask replaces the values of the named headers with a placeholder, in
+// addition to credentials, per-run IDs and User-Agent, so their presence is
+// checked but not their value.
+func Mask(headers ...string) Option {
+	return func(r *recorder) {
+		r.masked = append(r.masked, headers...)
+	}
+}
+
+// Ignore leaves the named headers out of snapshots.
+func Ignore(headers ...string) Option {
+	return func(r *recorder) {
+		r.ignored = append(r.ignored, headers...)
+	}
+}
+
+type recorder struct {
+	t       testing.TB
+	dir     string
+	masked  []string
+	ignored []string
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// Record returns a client option that snapshots every request the client
+// sends, retries included, in the order sent. Add it after other
+// middleware so it sees the headers they set. JSON bodies are indented and
+// query parameters sorted, so snapshots diff cleanly; the host is left out
+// because test servers listen on random ports.
+func Record(t testing.TB, opts ...Option) httpclient.ClientOption {
+	r := &recorder{
+		t:       t,
+		dir:     DefaultDir,
+		masked:  slices.Clone(defaultMasked),
+		ignored: slices.Clone(defaultIgnored),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	t.Cleanup(r.check)
+	return httpclient.WithMiddleware(r.middleware)
+}
+
+func (r *recorder) middleware(req *http.Request, next httpclient.RoundTripFunc) (*http.Response, error) {
+	snapshot, err := r.snapshot(req)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.requests = append(r.requests, snapshot)
+	r.mu.Unlock()
+	return next(req)
+}
+
+// snapshot renders req in its normalized text form.
+func (r *recorder) snapshot(req *http.Request) (string, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return "", fmt.Errorf("snaptest: reading request body: %w", err)
+	}
+
+	var b strings.Builder
+	target := req.URL.EscapedPath()
+	if query := req.URL.Query(); len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	fmt.Fprintf(&b, "%s %s\n", req.Method, target)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if !containsFold(r.ignored, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range req.Header.Values(name) {
+			if containsFold(r.masked, name) {
+				value = masked
+			}
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	if len(body) > 0 {
+		b.WriteString("\n")
+		b.Write(formatBody(body))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// check compares the recorded requests with the golden file, or writes it.
+func (r *recorder) check() {
+	r.mu.Lock()
+	got := render(r.requests)
+	r.mu.Unlock()
+
+	path := filepath.Join(r.dir, fileName(r.t.Name()))
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(r.dir, 0o755); err != nil {
+			r.t.Errorf("snaptest: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			r.t.Errorf("snaptest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Errorf("snaptest: %v; run with %s=1 to create it", err, UpdateEnv)
+		return
+	}
+	if got != string(want) {
+		r.t.Errorf("snaptest: requests differ from %s (run with %s=1 to update):\n%s", path, UpdateEnv, diff(string(want), got))
+	}
+}
+
+// render joins the snapshots of all requests, numbered in order.
+func render(requests []string) string {
+	var b strings.Builder
+	for i, request := range requests {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### request %d\n%s", i+1, request)
+	}
+	return b.String()
+}
+
+// readBody returns the request body, leaving it readable for next.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// formatBody indents JSON bodies and returns others as they are.
+func formatBody(body []byte) []byte {
+	var indented bytes.Buffer
+	if json.Valid(body) && json.Indent(&indented, body, "", "  ") == nil {
+		return indented.Bytes()
+	}
+	return body
+}
+
+// diff lists the lines of want and got that differ, by line number.
+func diff(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "line %d:\n- %s\n+ %s\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
+
+// fileName turns a test name into a golden file name.
+func fileName(testName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, testName)
+	return name + ".golden"
+}
+
+func containsFold(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+}
@@ -0,0 +1,111 @@
+package snaptest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holgersendify/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT records failures and cleanups instead of acting on them.
+type fakeT struct {
+	testing.TB
+	name     string
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Name() string      { return f.name }
+func (f *fakeT) Helper()           {}
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// run sends a charge through a recorded client and finishes the test.
+func run(t *testing.T, ft *fakeT, amount int, opts ...Option) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(server.URL),
+		httpclient.WithLoggerDisabled(),
+		httpclient.WithAuth(httpclient.BearerAuth("secret")),
+		Record(ft, opts...),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Post(context.Background(), "/v1/charges", map[string]any{"amount": amount, "currency": "eur"}, nil,
+		httpclient.WithQuery("expand", "customer"), httpclient.WithQuery("a", "1"),
+		httpclient.WithRequestHeader("X-Request-ID", "random"))
+	require.NoError(t, err)
+	ft.finish()
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("writes golden file in update mode", func(t *testing.T) {
+		t.Setenv(UpdateEnv, "1")
+		ft := &fakeT{name: "TestCharge/eur"}
+		run(t, ft, 100, Dir(dir))
+		require.Empty(t, ft.errors)
+
+		golden, err := os.ReadFile(filepath.Join(dir, "TestCharge_eur.golden"))
+		require.NoError(t, err)
+		assert.Equal(t, `### request 1
+POST /v1/charges?a=1&expand=customer
+Accept: application/json
+Authorization: <masked>
+Content-Type: application/json
+User-Agent: <masked>
+X-Request-Id: <masked>
+
+{
+  "amount": 100,
+  "currency": "eur"
+}
+`, string(golden))
+	})
+
+	t.Run("passes when requests match", func(t *testing.T) {
+		ft := &fakeT{name: "TestCharge/eur"}
+		run(t, ft, 100, Dir(dir))
+		assert.Empty(t, ft.errors)
+	})
+
+	t.Run("fails on a changed body", func(t *testing.T) {
+		ft := &fakeT{name: "TestCharge/eur"}
+		run(t, ft, 250, Dir(dir))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "-   \"amount\": 100,\n+   \"amount\": 250,")
+	})
+
+	t.Run("ignores headers", func(t *testing.T) {
+		ft := &fakeT{name: "TestCharge/eur"}
+		run(t, ft, 100, Dir(dir), Ignore("Content-Type"))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "- Content-Type: application/json")
+	})
+
+	t.Run("fails without golden file", func(t *testing.T) {
+		ft := &fakeT{name: "TestMissing"}
+		run(t, ft, 100, Dir(dir))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], UpdateEnv+"=1")
+	})
+}
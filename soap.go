@@ -3,7 +3,9 @@ package httpclient
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -17,6 +19,31 @@ type soapBody struct {
 	value   any
 	action  string
 	soap12  bool
+	options SOAPOptions
+}
+
+// SOAPOptions customizes the envelope for servers that reject the default
+// shape.
+type SOAPOptions struct {
+	// Action is sent as the SOAPAction header for SOAP 1.1 and as the
+	// Content-Type action parameter for SOAP 1.2.
+	Action string
+	// SOAP12 selects SOAP 1.2 instead of SOAP 1.1.
+	SOAP12 bool
+	// Prefix is the envelope namespace prefix, e.g. "soapenv" or "s".
+	// It defaults to "soap".
+	Prefix string
+	// Namespaces adds xmlns declarations to the Envelope, by prefix, for
+	// servers that expect them there rather than on the payload.
+	Namespaces map[string]string
+	// EncodingStyle sets the Envelope's encodingStyle attribute, e.g.
+	// "http://schemas.xmlsoap.org/soap/encoding/" for RPC/encoded services.
+	EncodingStyle string
+}
+
+// SOAPBodyWithOptions creates a SOAP body with a customized envelope.
+func SOAPBodyWithOptions(v any, opts SOAPOptions) any {
+	return &soapBody{value: v, action: opts.Action, soap12: opts.SOAP12, options: opts}
 }
 
 // SOAPBody creates a SOAP 1.1 body from the given value.
@@ -34,7 +61,8 @@ func SOAPBodyWithAction(action string, v any) any {
 	return &soapBody{value: v, action: action}
 }
 
-// SOAP12BodyWithAction creates a SOAP 1.2 body with an action.
+// SOAP12BodyWithAction creates a SOAP 1.2 body with an action, sent as the
+// action parameter of the Content-Type.
 func SOAP12BodyWithAction(action string, v any) any {
 	return &soapBody{value: v, action: action, soap12: true}
 }
@@ -77,28 +105,85 @@ func EncodeSOAPBody(v any) (io.Reader, string, map[string]string, error) {
 		return nil, "", nil, err
 	}
 
-	envelope := SOAPEnvelope{
-		NS: namespace,
-		Body: SOAPBodyXML{
-			Content: innerContent,
-		},
-	}
-
 	var buf bytes.Buffer
 	buf.WriteString(xml.Header)
-	encoder := xml.NewEncoder(&buf)
-	if err := encoder.Encode(envelope); err != nil {
+	if err := writeSOAPEnvelope(&buf, namespace, sb.options, innerContent); err != nil {
 		return nil, "", nil, err
 	}
 
+	// SOAP 1.1 sends the action in the SOAPAction header, SOAP 1.2 as the
+	// Content-Type action parameter.
 	headers := make(map[string]string)
-	if sb.action != "" {
+	if sb.action != "" && sb.soap12 {
+		contentType += `; action="` + quoteMediaParam(sb.action) + `"`
+	} else if sb.action != "" {
 		headers["SOAPAction"] = `"` + sb.action + `"`
 	}
 
 	return &buf, contentType, headers, nil
 }
 
+// quoteMediaParam escapes a value for a quoted media type parameter.
+func quoteMediaParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+// writeSOAPEnvelope writes the envelope around content. It is written by
+// hand because encoding/xml cannot choose namespace prefixes.
+func writeSOAPEnvelope(buf *bytes.Buffer, namespace string, opts SOAPOptions, content []byte) error {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = "soap"
+	}
+	if !isXMLName(prefix) {
+		return fmt.Errorf("invalid SOAP envelope prefix %q", prefix)
+	}
+
+	prefixes := make([]string, 0, len(opts.Namespaces))
+	for p := range opts.Namespaces {
+		if !isXMLName(p) || p == prefix {
+			return fmt.Errorf("invalid SOAP namespace prefix %q", p)
+		}
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	buf.WriteString("<" + prefix + ":Envelope")
+	writeXMLAttr(buf, "xmlns:"+prefix, namespace)
+	for _, p := range prefixes {
+		writeXMLAttr(buf, "xmlns:"+p, opts.Namespaces[p])
+	}
+	if opts.EncodingStyle != "" {
+		writeXMLAttr(buf, prefix+":encodingStyle", opts.EncodingStyle)
+	}
+	buf.WriteString("><" + prefix + ":Body>")
+	buf.Write(content)
+	buf.WriteString("</" + prefix + ":Body></" + prefix + ":Envelope>")
+	return nil
+}
+
+func writeXMLAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(" " + name + `="`)
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteString(`"`)
+}
+
+// isXMLName reports whether s is usable as a namespace prefix.
+func isXMLName(s string) bool {
+	return !strings.HasPrefix(strings.ToLower(s), "xml") && isNCName(s)
+}
+
+// isNCName reports whether s is an XML name without a colon, checked by
+// parsing it, since encoding/xml accepts fewer letters than unicode does.
+func isNCName(s string) bool {
+	if s == "" || strings.Contains(s, ":") {
+		return false
+	}
+	tok, err := xml.NewDecoder(strings.NewReader("<" + s + "/>")).RawToken()
+	start, ok := tok.(xml.StartElement)
+	return err == nil && ok && start.Name.Space == "" && start.Name.Local == s
+}
+
 // SOAPFault represents a SOAP fault.
 type SOAPFault struct {
 	Code   string
@@ -106,6 +191,12 @@ type SOAPFault struct {
 	Detail string
 }
 
+// Error implements the error interface, so ParseSOAPResponseStream can
+// return a fault as its error.
+func (f *SOAPFault) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.Code, f.String)
+}
+
 // ParseSOAPFault attempts to parse a SOAP fault from the response body.
 // Returns the fault and true if found, or nil and false if not a fault.
 func ParseSOAPFault(body []byte) (*SOAPFault, bool) {
@@ -3,6 +3,7 @@ package httpclient
 import (
 	"context"
 	"io"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -96,6 +97,31 @@ func TestSOAPEnvelope(t *testing.T) {
 
 		assert.Equal(t, `"http://example.com/GetWeather"`, receivedAction)
 	})
+
+	t.Run("sends the SOAP 1.2 action as a Content-Type parameter", func(t *testing.T) {
+		var receivedAction, receivedContentType string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedAction = r.Header.Get("SOAPAction")
+			receivedContentType = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		req := GetWeatherRequest{City: "Denver"}
+		_, err = client.Post(context.Background(), "/weather", SOAP12BodyWithAction(`http://example.com/Get"Weather"`, req), nil)
+		require.NoError(t, err)
+
+		assert.Empty(t, receivedAction)
+		mediaType, params, err := mime.ParseMediaType(receivedContentType)
+		require.NoError(t, err)
+		assert.Equal(t, "application/soap+xml", mediaType)
+		assert.Equal(t, "utf-8", params["charset"])
+		assert.Equal(t, `http://example.com/Get"Weather"`, params["action"])
+	})
 }
 
 func TestSOAPFault(t *testing.T) {
@@ -211,3 +237,54 @@ func TestSOAPResponseParsing(t *testing.T) {
 		assert.Equal(t, "Sunny", weather.Conditions)
 	})
 }
+
+func TestSOAPOptions(t *testing.T) {
+	encode := func(t *testing.T, body any) string {
+		t.Helper()
+		reader, _, _, err := EncodeSOAPBody(body)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("keeps the default envelope shape", func(t *testing.T) {
+		got := encode(t, SOAPBody(GetWeatherRequest{City: "Oslo"}))
+
+		assert.Contains(t, got, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetWeatherRequest><City>Oslo</City></GetWeatherRequest></soap:Body></soap:Envelope>`)
+	})
+
+	t.Run("customizes prefix, namespaces and encoding style", func(t *testing.T) {
+		got := encode(t, SOAPBodyWithOptions(GetWeatherRequest{City: "Oslo"}, SOAPOptions{
+			Prefix:        "soapenv",
+			Namespaces:    map[string]string{"wea": "urn:weather", "ns1": "urn:a&b"},
+			EncodingStyle: "http://schemas.xmlsoap.org/soap/encoding/",
+		}))
+
+		assert.Contains(t, got, `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ns1="urn:a&amp;b" xmlns:wea="urn:weather" soapenv:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><soapenv:Body>`)
+		assert.Contains(t, got, `</soapenv:Body></soapenv:Envelope>`)
+
+		var parsed GetWeatherRequest
+		require.NoError(t, ParseSOAPResponse([]byte(got), &parsed))
+		assert.Equal(t, "Oslo", parsed.City)
+	})
+
+	t.Run("applies version and action", func(t *testing.T) {
+		_, contentType, headers, err := EncodeSOAPBody(SOAPBodyWithOptions(GetWeatherRequest{}, SOAPOptions{SOAP12: true, Action: "urn:GetWeather", Prefix: "s"}))
+		require.NoError(t, err)
+		assert.Equal(t, `application/soap+xml; charset=utf-8; action="urn:GetWeather"`, contentType)
+		assert.NotContains(t, headers, "SOAPAction")
+	})
+
+	t.Run("rejects invalid prefixes", func(t *testing.T) {
+		for _, opts := range []SOAPOptions{
+			{Prefix: "1bad"},
+			{Prefix: "a b"},
+			{Namespaces: map[string]string{"xmlns": "urn:x"}},
+			{Namespaces: map[string]string{"soap": "urn:clash"}},
+		} {
+			_, _, _, err := EncodeSOAPBody(SOAPBodyWithOptions(GetWeatherRequest{}, opts))
+			assert.Error(t, err, opts)
+		}
+	})
+}
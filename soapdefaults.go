@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// SOAPVersion selects the SOAP envelope version.
+type SOAPVersion int
+
+const (
+	SOAP11 SOAPVersion = iota + 1
+	SOAP12
+)
+
+// SOAPActionFunc returns the action for a request payload, or "" for none.
+type SOAPActionFunc func(payload any) string
+
+// SOAPDecoder returns a Decoder for the content of a SOAP response's Body,
+// which returns faults as *SOAPFault errors; see ParseSOAPResponseStream.
+func SOAPDecoder() Decoder {
+	return DecoderFunc(func(body []byte, v any) error {
+		return ParseSOAPResponseStream(bytes.NewReader(body), v)
+	})
+}
+
+// soapDefaults wraps plain struct bodies in SOAP envelopes.
+type soapDefaults struct {
+	version SOAPVersion
+	action  SOAPActionFunc
+}
+
+// WithSOAPDefaults dedicates the client to a SOAP service: struct bodies
+// (or pointers to structs) are wrapped in a SOAP envelope of the given
+// version, with the action returned by action, which may be nil. Bodies
+// already wrapped, e.g. with SOAPBodyWithOptions or XMLBody, are sent as
+// they are. XML results are decoded from the envelope's Body, with faults
+// returned as *SOAPFault errors.
+func WithSOAPDefaults(version SOAPVersion, action SOAPActionFunc) ClientOption {
+	return func(c *Client) error {
+		if version != SOAP11 && version != SOAP12 {
+			return fmt.Errorf("unknown SOAP version %d", version)
+		}
+		c.soapDefaults = &soapDefaults{version: version, action: action}
+		return nil
+	}
+}
+
+// encodeBody wraps plain struct bodies, returning nil for other bodies.
+func (d *soapDefaults) encodeBody(body any) (*EncodedBody, error) {
+	if d == nil || !isPlainStruct(body) {
+		return nil, nil
+	}
+
+	opts := SOAPOptions{SOAP12: d.version == SOAP12}
+	if d.action != nil {
+		opts.Action = d.action(body)
+	}
+	reader, contentType, headers, err := EncodeSOAPBody(SOAPBodyWithOptions(body, opts))
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedBody{Body: reader, ContentType: contentType, Headers: headers}, nil
+}
+
+// isPlainStruct reports whether body is a struct, or pointer to one, that
+// is not one of the client's body wrappers.
+func isPlainStruct(body any) bool {
+	switch body.(type) {
+	case *soapBody, *xmlBody, *multipartBody, RawBody, *RawBody:
+		return false
+	}
+	t := reflect.TypeOf(body)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
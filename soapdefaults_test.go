@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSOAPDefaults(t *testing.T) {
+	var gotBody, gotAction, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotAction, gotType = string(body), r.Header.Get("SOAPAction"), r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if strings.Contains(gotBody, "Unknown") {
+			w.Write([]byte(`<soap:Envelope xmlns:soap="x"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>no such city</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+			return
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="x"><soap:Body><GetWeatherResponse><Temperature>21</Temperature></GetWeatherResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	actionFromType := func(payload any) string {
+		return "urn:" + reflect.Indirect(reflect.ValueOf(payload)).Type().Name()
+	}
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithSOAPDefaults(0, nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("wraps plain structs and decodes the envelope body", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithSOAPDefaults(SOAP11, actionFromType))
+		require.NoError(t, err)
+
+		var result GetWeatherResponse
+		_, err = client.Post(ctx, "/", &GetWeatherRequest{City: "Oslo"}, &result)
+		require.NoError(t, err)
+
+		assert.Contains(t, gotBody, `<soap:Body><GetWeatherRequest><City>Oslo</City></GetWeatherRequest></soap:Body>`)
+		assert.Equal(t, `"urn:GetWeatherRequest"`, gotAction)
+		assert.Equal(t, "text/xml; charset=utf-8", gotType)
+		assert.Equal(t, 21, result.Temperature)
+	})
+
+	t.Run("uses SOAP 1.2", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithSOAPDefaults(SOAP12, actionFromType))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", GetWeatherRequest{City: "Oslo"}, nil)
+		require.NoError(t, err)
+		assert.Contains(t, gotBody, soap12Namespace)
+		assert.Contains(t, gotType, `action="urn:GetWeatherRequest"`)
+	})
+
+	t.Run("returns faults as errors", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithSOAPDefaults(SOAP11, nil))
+		require.NoError(t, err)
+
+		var result GetWeatherResponse
+		_, err = client.Post(ctx, "/", GetWeatherRequest{City: "Unknown"}, &result)
+		var fault *SOAPFault
+		require.True(t, errors.As(err, &fault))
+		assert.Equal(t, "no such city", fault.String)
+		assert.Empty(t, gotAction)
+	})
+
+	t.Run("leaves wrapped and non-struct bodies alone", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithSOAPDefaults(SOAP11, actionFromType))
+		require.NoError(t, err)
+
+		_, err = client.Post(ctx, "/", XMLBody(GetWeatherRequest{City: "Oslo"}), nil)
+		require.NoError(t, err)
+		assert.Equal(t, `<GetWeatherRequest><City>Oslo</City></GetWeatherRequest>`, gotBody)
+
+		_, err = client.Post(ctx, "/", SOAPBodyWithAction("urn:custom", GetWeatherRequest{}), nil)
+		require.NoError(t, err)
+		assert.Equal(t, `"urn:custom"`, gotAction)
+
+		_, err = client.Post(ctx, "/", "<raw/>", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "<raw/>", gotBody)
+	})
+}
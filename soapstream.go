@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxSOAPEnvelopeTokens bounds the tokens read while looking for the SOAP
+// Body, e.g. whitespace, comments and processing instructions.
+const maxSOAPEnvelopeTokens = 10000
+
+// soapFaultXML decodes SOAP 1.1 and SOAP 1.2 faults.
+type soapFaultXML struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	Detail      string `xml:"detail"`
+	Code        struct {
+		Value string `xml:"Value"`
+	} `xml:"Code"`
+	Reason struct {
+		Text string `xml:"Text"`
+	} `xml:"Reason"`
+}
+
+// ParseSOAPResponseStream decodes the content of the SOAP Body read from r
+// into v, without buffering the response or copying the Body's inner XML as
+// ParseSOAPResponse does, so very large responses decode in one pass. If
+// the Body holds a fault, it returns the *SOAPFault as the error.
+func ParseSOAPResponseStream(r io.Reader, v any) error {
+	dec := xml.NewDecoder(r)
+	start, err := soapBodyContent(dec)
+	if err != nil {
+		return err
+	}
+
+	if start.Name.Local == "Fault" {
+		var raw soapFaultXML
+		if err := dec.DecodeElement(&raw, &start); err != nil {
+			return fmt.Errorf("decoding SOAP fault: %w", err)
+		}
+		return raw.toFault()
+	}
+	return dec.DecodeElement(v, &start)
+}
+
+// soapBodyContent advances dec to the first element inside the envelope's
+// Body, skipping the Header.
+func soapBodyContent(dec *xml.Decoder) (xml.StartElement, error) {
+	depth := 0 // 1 inside Envelope, 2 inside Body
+	for range maxSOAPEnvelopeTokens {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return xml.StartElement{}, errors.New("SOAP response has no Body content")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case depth == 0 && t.Name.Local == "Envelope",
+				depth == 1 && t.Name.Local == "Body":
+				depth++
+			case depth == 2:
+				return t, nil
+			case depth == 1:
+				if err := dec.Skip(); err != nil {
+					return xml.StartElement{}, err
+				}
+			default:
+				return xml.StartElement{}, fmt.Errorf("expected SOAP Envelope, found <%s>", t.Name.Local)
+			}
+		case xml.EndElement:
+			return xml.StartElement{}, errors.New("SOAP response has no Body content")
+		}
+	}
+	return xml.StartElement{}, fmt.Errorf("SOAP Body not found within %d tokens", maxSOAPEnvelopeTokens)
+}
+
+func (f *soapFaultXML) toFault() *SOAPFault {
+	if f.FaultCode != "" || f.FaultString != "" {
+		return &SOAPFault{Code: f.FaultCode, String: f.FaultString, Detail: f.Detail}
+	}
+	return &SOAPFault{Code: f.Code.Value, String: f.Reason.Text}
+}
@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSOAPResponseStream(t *testing.T) {
+	type item struct {
+		SKU string `xml:"sku"`
+	}
+	type manifest struct {
+		Items []item `xml:"item"`
+	}
+
+	t.Run("decodes body content and skips the header", func(t *testing.T) {
+		body := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Header><Session><Token>abc</Token></Session></soap:Header>
+  <soap:Body>
+    <Manifest><item><sku>A1</sku></item><item><sku>B2</sku></item></Manifest>
+  </soap:Body>
+</soap:Envelope>`
+
+		var got manifest
+		require.NoError(t, ParseSOAPResponseStream(strings.NewReader(body), &got))
+		assert.Equal(t, []item{{"A1"}, {"B2"}}, got.Items)
+	})
+
+	t.Run("returns SOAP 1.1 faults as errors", func(t *testing.T) {
+		body := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Manifest locked</faultstring><detail>retry later</detail></soap:Fault>
+</soap:Body></soap:Envelope>`
+
+		err := ParseSOAPResponseStream(strings.NewReader(body), &manifest{})
+		var fault *SOAPFault
+		require.True(t, errors.As(err, &fault))
+		assert.Equal(t, "soap:Server", fault.Code)
+		assert.Equal(t, "Manifest locked", fault.String)
+		assert.Equal(t, "retry later", fault.Detail)
+	})
+
+	t.Run("returns SOAP 1.2 faults as errors", func(t *testing.T) {
+		body := `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>
+<env:Fault><env:Code><env:Value>env:Receiver</env:Value></env:Code><env:Reason><env:Text>Busy</env:Text></env:Reason></env:Fault>
+</env:Body></env:Envelope>`
+
+		err := ParseSOAPResponseStream(strings.NewReader(body), &manifest{})
+		var fault *SOAPFault
+		require.True(t, errors.As(err, &fault))
+		assert.Equal(t, "env:Receiver", fault.Code)
+		assert.Equal(t, "Busy", fault.String)
+	})
+
+	t.Run("rejects documents without body content", func(t *testing.T) {
+		for _, body := range []string{
+			`<Other/>`,
+			`<soap:Envelope xmlns:soap="x"><soap:Body></soap:Body></soap:Envelope>`,
+			`<soap:Envelope xmlns:soap="x">`,
+			`not xml`,
+		} {
+			assert.Error(t, ParseSOAPResponseStream(strings.NewReader(body), &manifest{}), body)
+		}
+	})
+
+	t.Run("decodes large responses incrementally", func(t *testing.T) {
+		const items = 20000
+		r, w := io.Pipe()
+		go func() {
+			io.WriteString(w, `<Envelope><Body><Manifest>`)
+			for range items {
+				io.WriteString(w, `<item><sku>X</sku></item>`)
+			}
+			io.WriteString(w, `</Manifest></Body></Envelope>`)
+			w.Close()
+		}()
+
+		var got manifest
+		require.NoError(t, ParseSOAPResponseStream(r, &got))
+		assert.Len(t, got.Items, items)
+	})
+}
@@ -0,0 +1,329 @@
+package httpclient
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxTrackedEndpoints bounds the number of endpoints with their own
+// statistics; further endpoints are aggregated under OtherEndpoint.
+const maxTrackedEndpoints = 256
+
+// OtherEndpoint is the Stats key that aggregates endpoints, or hosts,
+// beyond the tracking limit.
+const OtherEndpoint = "other"
+
+// latencyBuckets are the upper bounds of the latency histogram buckets.
+// Observations above the last bound fall into an overflow bucket.
+var latencyBuckets = [...]time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	60 * time.Second,
+}
+
+// Stats is a point-in-time snapshot of client statistics.
+type Stats struct {
+	// ThirdParty is the client's WithThirdPartyCode, for labeling metrics.
+	ThirdParty string
+
+	// Endpoints maps "METHOD template" (or "METHOD path" for requests without
+	// an endpoint template) to statistics over the rolling window.
+	Endpoints map[string]EndpointStats
+
+	// Hosts aggregates the same statistics by target host, e.g. to attribute
+	// egress bandwidth to an integration.
+	Hosts map[string]EndpointStats
+
+	// Targets counts attempts by WithBalancer base URL, or is nil without
+	// WithBalancer.
+	Targets map[string]TargetStats
+
+	// DNS reports DNS cache effectiveness, or is nil without WithDNSCache.
+	DNS *DNSCacheStats
+
+	// BufferedBytes is the request and response body bytes held by
+	// in-flight requests. It is only tracked with WithMaxBufferedBytes.
+	BufferedBytes int64
+}
+
+// EndpointStats summarizes the requests to one endpoint.
+type EndpointStats struct {
+	Count  uint64
+	Errors uint64
+	// RequestBytes and ResponseBytes count body bytes on the wire,
+	// including every retry attempt.
+	RequestBytes  uint64
+	ResponseBytes uint64
+	// CompressedBytes counts the ResponseBytes the client decoded under
+	// WithCompression, and DecompressedBytes their size once decoded.
+	CompressedBytes   uint64
+	DecompressedBytes uint64
+	P50               time.Duration
+	P90               time.Duration
+	P99               time.Duration
+	Histogram         []HistogramBucket
+}
+
+// HistogramBucket counts observations at or below UpperBound and above the
+// previous bucket's bound. The last bucket has no upper bound (0).
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// WithLatencyStats enables per-endpoint latency histograms, exposed through
+// Client.Stats. Statistics cover between one and two windows of history.
+func WithLatencyStats(window time.Duration) ClientOption {
+	return func(c *Client) error {
+		if window <= 0 {
+			return errors.New("latency stats window must be positive")
+		}
+		c.latency = newLatencyStats(window)
+		return nil
+	}
+}
+
+// WithEndpointTemplate names the endpoint for statistics, e.g. "/users/{id}",
+// so requests to different IDs are aggregated together.
+func WithEndpointTemplate(template string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.endpoint = template
+	}
+}
+
+// endpointKey identifies an endpoint by method and template, falling back
+// to the request path when no template was given.
+func endpointKey(method, path, template string) string {
+	if template == "" {
+		template = path
+	}
+	return method + " " + template
+}
+
+// Stats returns a snapshot of the client's statistics.
+func (c *Client) Stats() Stats {
+	stats := Stats{
+		ThirdParty: c.thirdPartyCode,
+		Endpoints:  make(map[string]EndpointStats),
+		Hosts:      make(map[string]EndpointStats),
+	}
+	if c.latency != nil {
+		c.latency.snapshot(time.Now(), stats.Endpoints, stats.Hosts)
+	}
+	if c.balancing != nil {
+		stats.Targets = c.balancing.targetStats()
+	}
+	if c.dnsCache != nil {
+		stats.DNS = c.dnsCache.stats()
+	}
+	if c.buffers != nil {
+		stats.BufferedBytes = c.buffers.inUse()
+	}
+	return stats
+}
+
+// sample is the outcome of one request.
+type sample struct {
+	duration          time.Duration
+	failed            bool
+	requestBytes      uint64
+	responseBytes     uint64
+	compressedBytes   uint64
+	decompressedBytes uint64
+}
+
+// histogram is a fixed-bucket latency histogram with traffic totals.
+type histogram struct {
+	counts            [len(latencyBuckets) + 1]uint64 // last bucket is overflow
+	total             uint64
+	errors            uint64
+	requestBytes      uint64
+	responseBytes     uint64
+	compressedBytes   uint64
+	decompressedBytes uint64
+}
+
+func (h *histogram) observe(s sample) {
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return s.duration <= latencyBuckets[i] })
+	h.counts[i]++
+	h.total++
+	if s.failed {
+		h.errors++
+	}
+	h.requestBytes += s.requestBytes
+	h.responseBytes += s.responseBytes
+	h.compressedBytes += s.compressedBytes
+	h.decompressedBytes += s.decompressedBytes
+}
+
+func (h *histogram) add(other *histogram) {
+	for i := range h.counts {
+		h.counts[i] += other.counts[i]
+	}
+	h.total += other.total
+	h.errors += other.errors
+	h.requestBytes += other.requestBytes
+	h.responseBytes += other.responseBytes
+	h.compressedBytes += other.compressedBytes
+	h.decompressedBytes += other.decompressedBytes
+}
+
+// percentile returns the upper bound of the bucket holding quantile q.
+func (h *histogram) percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := uint64(q*float64(h.total) + 0.5)
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank && i < len(latencyBuckets) {
+			return latencyBuckets[i]
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
+
+func (h *histogram) toStats() EndpointStats {
+	buckets := make([]HistogramBucket, len(h.counts))
+	for i, count := range h.counts {
+		buckets[i].Count = count
+		if i < len(latencyBuckets) {
+			buckets[i].UpperBound = latencyBuckets[i]
+		}
+	}
+
+	return EndpointStats{
+		Count:             h.total,
+		Errors:            h.errors,
+		RequestBytes:      h.requestBytes,
+		ResponseBytes:     h.responseBytes,
+		CompressedBytes:   h.compressedBytes,
+		DecompressedBytes: h.decompressedBytes,
+		P50:               h.percentile(0.50),
+		P90:               h.percentile(0.90),
+		P99:               h.percentile(0.99),
+		Histogram:         buckets,
+	}
+}
+
+// rollingHistogram keeps the current and previous window.
+type rollingHistogram struct {
+	current   histogram
+	previous  histogram
+	rotatedAt time.Time
+}
+
+func (r *rollingHistogram) rotate(now time.Time, window time.Duration) {
+	elapsed := now.Sub(r.rotatedAt)
+	if elapsed < window {
+		return
+	}
+	if elapsed < 2*window {
+		r.previous = r.current
+	} else {
+		r.previous = histogram{}
+	}
+	r.current = histogram{}
+	r.rotatedAt = now
+}
+
+func (r *rollingHistogram) merged() histogram {
+	h := r.current
+	h.add(&r.previous)
+	return h
+}
+
+// latencyStats tracks rolling histograms per endpoint and per host.
+// It is safe for concurrent use across goroutines.
+type latencyStats struct {
+	mu        sync.Mutex
+	window    time.Duration
+	endpoints map[string]*rollingHistogram
+	hosts     map[string]*rollingHistogram
+}
+
+func newLatencyStats(window time.Duration) *latencyStats {
+	return &latencyStats{
+		window:    window,
+		endpoints: make(map[string]*rollingHistogram),
+		hosts:     make(map[string]*rollingHistogram),
+	}
+}
+
+func (s *latencyStats) observe(endpoint, host string, now time.Time, smp sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.track(s.endpoints, endpoint, now).current.observe(smp)
+	if host != "" {
+		s.track(s.hosts, host, now).current.observe(smp)
+	}
+}
+
+// track returns the rotated histogram for key, aggregating keys beyond the
+// tracking limit under OtherEndpoint. The caller must hold s.mu.
+func (s *latencyStats) track(m map[string]*rollingHistogram, key string, now time.Time) *rollingHistogram {
+	r, ok := m[key]
+	if !ok {
+		if len(m) >= maxTrackedEndpoints {
+			key = OtherEndpoint
+			r = m[key]
+		}
+		if r == nil {
+			r = &rollingHistogram{rotatedAt: now}
+			m[key] = r
+		}
+	}
+
+	r.rotate(now, s.window)
+	return r
+}
+
+// distribution returns the merged histogram for one endpoint.
+func (s *latencyStats) distribution(endpoint string, now time.Time) (histogram, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.endpoints[endpoint]
+	if !ok {
+		return histogram{}, false
+	}
+	r.rotate(now, s.window)
+	return r.merged(), true
+}
+
+func (s *latencyStats) snapshot(now time.Time, endpoints, hosts map[string]EndpointStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for endpoint, r := range s.endpoints {
+		r.rotate(now, s.window)
+		h := r.merged()
+		endpoints[endpoint] = h.toStats()
+	}
+	for host, r := range s.hosts {
+		r.rotate(now, s.window)
+		h := r.merged()
+		hosts[host] = h.toStats()
+	}
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Stats(t *testing.T) {
+	t.Run("aggregates latency by endpoint template", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/users/3" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithLatencyStats(time.Minute),
+		)
+		require.NoError(t, err)
+
+		for i := 1; i <= 3; i++ {
+			_, _ = client.Get(context.Background(), fmt.Sprintf("/users/%d", i), nil, WithEndpointTemplate("/users/{id}"))
+		}
+		_, err = client.Request().Path("/users/1").EndpointTemplate("/users/{id}").Do(context.Background())
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/health", nil)
+		require.NoError(t, err)
+
+		stats := client.Stats()
+		require.Len(t, stats.Endpoints, 2)
+
+		users := stats.Endpoints["GET /users/{id}"]
+		assert.Equal(t, uint64(4), users.Count)
+		assert.Equal(t, uint64(1), users.Errors)
+		assert.Greater(t, users.P99, time.Duration(0))
+		assert.LessOrEqual(t, users.P50, users.P99)
+		assert.Len(t, users.Histogram, len(latencyBuckets)+1)
+		assert.Equal(t, uint64(1), stats.Endpoints["GET /health"].Count)
+	})
+
+	t.Run("is empty when disabled", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.Stats().Endpoints)
+	})
+
+	t.Run("returns error for non-positive window", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithLatencyStats(0))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "latency stats window must be positive")
+	})
+}
+
+func TestHistogram_Percentile(t *testing.T) {
+	var h histogram
+	for i := 0; i < 98; i++ {
+		h.observe(sample{duration: 3 * time.Millisecond})
+	}
+	h.observe(sample{duration: 400 * time.Millisecond})
+	h.observe(sample{duration: 2 * time.Minute, failed: true})
+
+	assert.Equal(t, 5*time.Millisecond, h.percentile(0.50))
+	assert.Equal(t, 500*time.Millisecond, h.percentile(0.99))
+	assert.Equal(t, 60*time.Second, h.percentile(1.0))
+	assert.Equal(t, uint64(1), h.errors)
+}
+
+func TestLatencyStats_Rolling(t *testing.T) {
+	t.Run("drops observations older than two windows", func(t *testing.T) {
+		s := newLatencyStats(time.Minute)
+		start := time.Now()
+
+		s.observe("GET /a", "", start, sample{duration: time.Millisecond})
+		s.observe("GET /a", "", start.Add(90*time.Second), sample{duration: time.Millisecond})
+
+		out := make(map[string]EndpointStats)
+		s.snapshot(start.Add(90*time.Second), out, map[string]EndpointStats{})
+		assert.Equal(t, uint64(2), out["GET /a"].Count)
+
+		s.snapshot(start.Add(5*time.Minute), out, map[string]EndpointStats{})
+		assert.Equal(t, uint64(0), out["GET /a"].Count)
+	})
+
+	t.Run("bounds the number of tracked endpoints", func(t *testing.T) {
+		s := newLatencyStats(time.Minute)
+		now := time.Now()
+
+		for i := 0; i < maxTrackedEndpoints+10; i++ {
+			s.observe(fmt.Sprintf("GET /%d", i), "", now, sample{duration: time.Millisecond})
+		}
+
+		out := make(map[string]EndpointStats)
+		s.snapshot(now, out, map[string]EndpointStats{})
+		assert.Len(t, out, maxTrackedEndpoints+1)
+		assert.Equal(t, uint64(10), out[OtherEndpoint].Count)
+	})
+}
+
+func TestStats_Traffic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"1234567890"}`))
+	}))
+	defer server.Close()
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithLatencyStats(time.Minute))
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = client.Post(context.Background(), "/orders", map[string]int{"qty": 3}, nil)
+		require.NoError(t, err)
+	}
+
+	stats := client.Stats()
+	endpoint := stats.Endpoints["POST /orders"]
+	assert.Equal(t, uint64(2*len(`{"qty":3}`)), endpoint.RequestBytes)
+	assert.Equal(t, uint64(2*len(`{"id":"1234567890"}`)), endpoint.ResponseBytes)
+	host := stats.Hosts[strings.TrimPrefix(server.URL, "http://")]
+	assert.Equal(t, uint64(2), host.Count)
+	assert.Equal(t, endpoint.ResponseBytes, host.ResponseBytes)
+}
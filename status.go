@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// StatusPolicy reclassifies response status codes. By default a request
+// fails when the status is 400 or above.
+type StatusPolicy struct {
+	// Errors lists codes below 400 that fail the request, e.g. 207
+	// Multi-Status for partial failures.
+	Errors []int
+
+	// Success lists codes of 400 and above that succeed, e.g. a vendor's
+	// 404 for an empty list.
+	Success []int
+}
+
+// WithStatusPolicy reclassifies the given status codes. Reclassified
+// errors are retried as the retry policy decides for their code.
+func WithStatusPolicy(policy StatusPolicy) ClientOption {
+	return func(c *Client) error {
+		for _, code := range policy.Errors {
+			if code < 100 || code >= 400 {
+				return fmt.Errorf("status policy error code %d must be between 100 and 399", code)
+			}
+		}
+		for _, code := range policy.Success {
+			if code < 400 || code > 599 {
+				return fmt.Errorf("status policy success code %d must be between 400 and 599", code)
+			}
+		}
+		c.statusPolicy = &StatusPolicy{
+			Errors:  slices.Clone(policy.Errors),
+			Success: slices.Clone(policy.Success),
+		}
+		return nil
+	}
+}
+
+// isErrorStatus reports whether a response with code fails the request.
+func (c *Client) isErrorStatus(code int) bool {
+	if c.statusPolicy == nil {
+		return code >= 400
+	}
+	if code >= 400 {
+		return !slices.Contains(c.statusPolicy.Success, code)
+	}
+	return slices.Contains(c.statusPolicy.Errors, code)
+}
+
+// WithNoErrorOnStatus returns responses with error statuses as they are,
+// with a nil error, for callers that branch on Response.StatusCode, e.g.
+// when 404 means "not found" rather than failure. Such responses are still
+// retried as the retry policy decides, and their bodies are not decoded
+// into the result. Network failures and other errors are returned as usual.
+func WithNoErrorOnStatus() ClientOption {
+	return func(c *Client) error {
+		c.noErrorOnStatus = true
+		return nil
+	}
+}
+
+// WithRequestNoErrorOnStatus applies WithNoErrorOnStatus to this request.
+func WithRequestNoErrorOnStatus() RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.noErrorOnStatus = true
+	}
+}
+
+// WithErrorResult decodes the body of an error response, such as a 4xx or
+// 5xx, into target, so one call can decode success into the result and
+// failure into target. The error is still returned, and its Body holds the
+// raw bytes. Target is left unchanged when the body does not decode.
+func WithErrorResult(target any) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.errorResult = target
+	}
+}
+
+// settle returns the call's response and error, decoding the result of a
+// successful response.
+func (c *Client) settle(cl *call, res attemptResult, result any) (*Response, error) {
+	response, err := res.response, res.err
+	if err != nil && response != nil && isStatusError(err) {
+		if cl.errorResult != nil && c.hasResultBody(response) {
+			// The status error matters more than a body that fails to decode.
+			_ = c.decodeResult(response, cl.errorResult)
+		}
+		if cl.noErrorOnStatus {
+			return response, nil
+		}
+	}
+	if err == nil && result != nil && c.hasResultBody(response) {
+		err = c.decodeResult(response, result)
+	}
+	return response, err
+}
+
+// isStatusError reports whether err only reports an error status.
+func isStatusError(err error) bool {
+	var clientErr *Error
+	return errors.As(err, &clientErr) && clientErr.Kind == ErrKindHTTP && clientErr.Err == nil && clientErr.StatusCode != 0
+}
@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStatusPolicy(t *testing.T) {
+	t.Run("treats listed success code as error", func(t *testing.T) {
+		server := newStatusServer(http.StatusMultiStatus)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStatusPolicy(StatusPolicy{Errors: []int{http.StatusMultiStatus}}))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/batch", nil)
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindHTTP, clientErr.Kind)
+		assert.Equal(t, http.StatusMultiStatus, clientErr.StatusCode)
+	})
+
+	t.Run("treats listed error code as success", func(t *testing.T) {
+		server := newStatusServer(http.StatusNotFound)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStatusPolicy(StatusPolicy{Success: []int{http.StatusNotFound}}))
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/items", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("keeps default for unlisted codes", func(t *testing.T) {
+		server := newStatusServer(http.StatusBadRequest)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithStatusPolicy(StatusPolicy{Success: []int{http.StatusNotFound}}))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/items", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects codes on the wrong side", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithStatusPolicy(StatusPolicy{Errors: []int{http.StatusNotFound}}))
+		assert.Error(t, err)
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithStatusPolicy(StatusPolicy{Success: []int{http.StatusOK}}))
+		assert.Error(t, err)
+	})
+}
+
+func TestWithNoErrorOnStatus(t *testing.T) {
+	server := newStatusServer(http.StatusNotFound)
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("client level", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithNoErrorOnStatus())
+		require.NoError(t, err)
+
+		var result struct{ ID int }
+		resp, err := client.Get(ctx, "/users/1", &result)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("request level", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		resp, err := client.Get(ctx, "/users/1", nil, WithRequestNoErrorOnStatus())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, err = client.Get(ctx, "/users/1", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("keeps other errors", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://127.0.0.1:1"), WithLoggerDisabled(), WithNoErrorOnStatus())
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/users/1", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestWithErrorResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>"))
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"invalid_email","message":"email is invalid"}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	type apiError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+	require.NoError(t, err)
+
+	t.Run("decodes error body", func(t *testing.T) {
+		var result struct{ ID int }
+		var errBody apiError
+		_, err := client.Post(ctx, "/users", map[string]string{"email": "x"}, &result, WithErrorResult(&errBody))
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusUnprocessableEntity, clientErr.StatusCode)
+		assert.Equal(t, apiError{Code: "invalid_email", Message: "email is invalid"}, errBody)
+		assert.NotEmpty(t, clientErr.Body)
+		assert.Zero(t, result.ID)
+	})
+
+	t.Run("keeps status error when body does not decode", func(t *testing.T) {
+		var errBody apiError
+		_, err := client.Get(ctx, "/broken", nil, WithErrorResult(&errBody))
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusBadGateway, clientErr.StatusCode)
+		assert.Zero(t, errBody)
+	})
+
+	t.Run("decodes with WithNoErrorOnStatus", func(t *testing.T) {
+		var errBody apiError
+		resp, err := client.Get(ctx, "/users/1", nil, WithErrorResult(&errBody), WithRequestNoErrorOnStatus())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "invalid_email", errBody.Code)
+	})
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// JSONItemFunc receives one element of a streamed JSON array. Returning an
+// error stops the stream.
+type JSONItemFunc func(item json.RawMessage) error
+
+// JSONStream decodes the body item by item and passes each element to fn:
+// the elements of a top-level array, or of every array directly inside a
+// top-level object, such as {"data": [...]}. Other members are skipped.
+func (r *Response) JSONStream(fn JSONItemFunc) error {
+	if fn == nil {
+		return errors.New("stream function cannot be nil")
+	}
+	return decodeJSONStream(bytes.NewReader(r.Body), fn)
+}
+
+// DoIntoStream performs a request and decodes a successful JSON response
+// as it arrives, passing each item to fn as Response.JSONStream does, so
+// exports of any size are decoded in constant memory. fn runs on the
+// reading goroutine, so a slow fn slows the download instead of buffering
+// it. Failed attempts are retried as usual, but once items were delivered
+// the request is not retried. The returned Response has no Body.
+func (c *Client) DoIntoStream(ctx context.Context, method, path string, body any, fn JSONItemFunc, opts ...RequestOption) (*Response, error) {
+	if fn == nil {
+		return nil, errors.New("stream function cannot be nil")
+	}
+	return c.doStream(ctx, method, path, body, func(r io.Reader, _ http.Header) error {
+		return decodeJSONStream(r, fn)
+	}, opts)
+}
+
+// DoIntoStream executes the request, streaming a JSON response to fn as
+// Client.DoIntoStream does.
+func (b *RequestBuilder) DoIntoStream(ctx context.Context, fn JSONItemFunc) (*Response, error) {
+	return b.client.DoIntoStream(ctx, b.method, b.path, b.body, fn, b.toRequestOptions()...)
+}
+
+// streamFunc consumes a successful response body as it arrives.
+type streamFunc func(body io.Reader, headers http.Header) error
+
+// doStream performs a request whose successful response body is passed to
+// stream instead of being buffered. Fallback and stale responses are
+// already buffered and go through stream from memory.
+func (c *Client) doStream(ctx context.Context, method, path string, body any, stream streamFunc, opts []RequestOption) (*Response, error) {
+	cfg := newRequestConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	path, err := c.resolvePath(ctx, path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cl, err := c.newCall(ctx, method, path, body, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cl.stream = stream
+
+	resp, err := c.execute(ctx, cl, nil)
+	if err == nil && (resp.FromFallback || resp.Stale) {
+		err = stream(bytes.NewReader(resp.Body), resp.Headers)
+	}
+	return resp, err
+}
+
+// streamBody decodes a successful response body into the call's stream.
+func (c *Client) streamBody(cl *call, resp *http.Response, reqHeaders http.Header) attemptResult {
+	defer resp.Body.Close()
+
+	counted := &countingReader{r: resp.Body}
+	var body io.Reader = counted
+	var decoded *countingReader
+	if cl.decompress {
+		reader, err := c.decompressReader(resp.Header, body)
+		if err != nil {
+			return attemptResult{reqHeaders: reqHeaders, err: err}
+		}
+		if reader != body {
+			decoded = &countingReader{r: reader}
+			body = decoded
+		}
+	}
+	if cl.tee != nil {
+		body = io.TeeReader(body, cl.tee)
+	}
+
+	err := cl.stream(body, resp.Header)
+	cl.receivedBytes += counted.n
+	if decoded != nil {
+		cl.compressedBytes += counted.n
+		cl.decompressedBytes += decoded.n
+	}
+	response := &Response{StatusCode: resp.StatusCode, Status: resp.Status, Headers: resp.Header, TLSResumed: resp.TLS != nil && resp.TLS.DidResume}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errUnexpectedJSON) {
+		err = &Error{Kind: ErrKindParse, StatusCode: resp.StatusCode, Status: resp.Status, Method: cl.method, URL: cl.url, Err: err}
+	}
+	return attemptResult{response: response, reqHeaders: reqHeaders, err: err}
+}
+
+// errUnexpectedJSON reports a stream that is not an array or object.
+var errUnexpectedJSON = errors.New("streamed JSON must be an array or an object")
+
+// decodeJSONStream passes each array element in r to fn.
+func decodeJSONStream(r io.Reader, fn JSONItemFunc) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		return streamJSONArray(dec, fn)
+	case json.Delim('{'):
+	default:
+		return fmt.Errorf("%w, got %v", errUnexpectedJSON, tok)
+	}
+
+	for dec.More() {
+		if _, err := dec.Token(); err != nil { // member name
+			return err
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('['):
+			err = streamJSONArray(dec, fn)
+		case json.Delim('{'):
+			err = skipJSONValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // closing brace
+	return err
+}
+
+// streamJSONArray passes the elements of an opened array to fn and
+// consumes the closing bracket.
+func streamJSONArray(dec *json.Decoder, fn JSONItemFunc) error {
+	for dec.More() {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// skipJSONValue consumes the rest of an opened object or array.
+func skipJSONValue(dec *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoIntoStream(t *testing.T) {
+	t.Run("streams array items as they arrive", func(t *testing.T) {
+		const items = 5000
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("["))
+			for i := 0; i < items; i++ {
+				if i > 0 {
+					_, _ = w.Write([]byte(","))
+				}
+				fmt.Fprintf(w, `{"id":%d}`, i)
+			}
+			_, _ = w.Write([]byte("]"))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithLatencyStats(time.Minute))
+		require.NoError(t, err)
+
+		var ids []int
+		resp, err := client.DoIntoStream(context.Background(), http.MethodGet, "/export", nil, func(item json.RawMessage) error {
+			var v struct{ ID int }
+			if err := json.Unmarshal(item, &v); err != nil {
+				return err
+			}
+			ids = append(ids, v.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Body)
+		require.Len(t, ids, items)
+		assert.Equal(t, items-1, ids[items-1])
+		assert.Positive(t, client.Stats().Endpoints["GET /export"].ResponseBytes)
+	})
+
+	t.Run("streams arrays inside an object and decompresses", func(t *testing.T) {
+		server, _ := newGzipServer(t, []byte(`{"meta":{"nested":[1,{"x":[2]}]},"total":3,"data":[{"a":1},{"a":2}],"more":["x"]}`))
+		defer server.Close()
+
+		var tee bytes.Buffer
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithCompression())
+		require.NoError(t, err)
+
+		var got []string
+		_, err = client.Request().Path("/export").DoIntoStream(context.Background(), func(item json.RawMessage) error {
+			got = append(got, string(item))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"a":1}`, `{"a":2}`, `"x"`}, got)
+
+		_, err = client.DoIntoStream(context.Background(), http.MethodGet, "/export", nil,
+			func(json.RawMessage) error { return nil }, WithResponseTee(&tee))
+		require.NoError(t, err)
+		assert.Contains(t, tee.String(), `"total":3`)
+	})
+
+	t.Run("stops when the callback fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[1,2,3]`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		stop := errors.New("stop")
+		calls := 0
+		_, err = client.DoIntoStream(context.Background(), http.MethodGet, "/", nil, func(json.RawMessage) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("reports malformed bodies as parse errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[1,2,`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.DoIntoStream(context.Background(), http.MethodGet, "/", nil, func(json.RawMessage) error { return nil })
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindParse, clientErr.Kind)
+	})
+
+	t.Run("error responses are returned unstreamed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`[{"error":"missing"}]`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		called := false
+		resp, err := client.DoIntoStream(context.Background(), http.MethodGet, "/", nil, func(json.RawMessage) error {
+			called = true
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.False(t, called)
+		assert.Contains(t, resp.String(), "missing")
+	})
+}
+
+func TestResponseJSONStream(t *testing.T) {
+	resp := &Response{Body: []byte(`{"items":[1,2],"page":{"next":null}}`)}
+
+	var got []string
+	require.NoError(t, resp.JSONStream(func(item json.RawMessage) error {
+		got = append(got, string(item))
+		return nil
+	}))
+	assert.Equal(t, []string{"1", "2"}, got)
+
+	assert.ErrorIs(t, (&Response{Body: []byte(`"scalar"`)}).JSONStream(func(json.RawMessage) error { return nil }), errUnexpectedJSON)
+	assert.Error(t, resp.JSONStream(nil))
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// SuccessPredicate inspects a response that would otherwise succeed and
+// returns a non-nil error to fail the request, e.g. for an XML API that
+// answers 200 with <Response success="false">.
+type SuccessPredicate func(*Response) error
+
+// WithSuccessPredicate fails successful responses for which fn returns an
+// error. The request then returns an *Error of kind ErrKindHTTP wrapping
+// that error. Such failures are retried when the retry policy's RetryIf
+// accepts them or, without RetryIf, when fn wrapped the error with
+// RetryableError. Streamed responses (DoIntoStream) are not inspected.
+func WithSuccessPredicate(fn SuccessPredicate) ClientOption {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("success predicate cannot be nil")
+		}
+		c.successPredicate = fn
+		return nil
+	}
+}
+
+// retryableError marks an error as safe to retry.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
+// RetryableError marks err, returned by a SuccessPredicate, as retryable
+// under the client's retry policy. It returns nil for a nil err.
+func RetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// acceptResponse applies the success predicate to a successful response
+// and copies its body to the call's tee.
+func (c *Client) acceptResponse(ctx context.Context, cl *call, resp *http.Response, response *Response, reqHeaders http.Header, attempt int) attemptResult {
+	if c.successPredicate != nil {
+		err := c.runHook(ctx, "success predicate", func() error { return c.successPredicate(response) })
+		if err != nil {
+			res := c.httpErrorResult(cl, response, reqHeaders, attempt, err)
+			res.err.(*Error).Kind = internalKind(err, ErrKindHTTP)
+			res.retryable = c.shouldRetryPredicate(ctx, resp, err)
+			return res
+		}
+	}
+
+	if err := writeTee(cl.tee, response.Body); err != nil {
+		return attemptResult{reqHeaders: reqHeaders, err: err}
+	}
+	return attemptResult{response: response, reqHeaders: reqHeaders}
+}
+
+// shouldRetryPredicate reports whether a predicate failure may be retried.
+// A panic in the predicate is not.
+func (c *Client) shouldRetryPredicate(ctx context.Context, resp *http.Response, err error) bool {
+	if c.retryPolicy == nil || internalKind(err, ErrKindHTTP) == ErrKindInternal {
+		return false
+	}
+	if c.retryPolicy.RetryIf != nil {
+		return c.retryIf(ctx, resp, err)
+	}
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// httpErrorResult fails an attempt with an ErrKindHTTP error for response.
+func (c *Client) httpErrorResult(cl *call, response *Response, reqHeaders http.Header, attempt int, cause error) attemptResult {
+	return attemptResult{
+		response:   response,
+		reqHeaders: reqHeaders,
+		err: &Error{
+			Kind:       ErrKindHTTP,
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       response.Body,
+			Headers:    response.Headers,
+			Method:     cl.method,
+			URL:        cl.url,
+			Attempts:   attempt,
+			RetryAfter: ParseRetryAfter(response.Headers.Get("Retry-After")),
+			Err:        cause,
+		},
+	}
+}
+
+// rejectResponse fails an attempt that received an error status.
+func (c *Client) rejectResponse(ctx context.Context, cl *call, resp *http.Response, response *Response, reqHeaders http.Header, attempt int) attemptResult {
+	res := c.httpErrorResult(cl, response, reqHeaders, attempt, nil)
+	res.retryable = c.shouldRetryStatus(ctx, resp)
+	return res
+}
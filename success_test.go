@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOperationFailed = errors.New("operation failed")
+
+// xmlSuccess fails responses whose root carries success="false".
+func xmlSuccess(resp *Response) error {
+	if bytes.Contains(resp.Body, []byte(`success="false"`)) {
+		return errOperationFailed
+	}
+	return nil
+}
+
+// newSequenceServer answers 200 with the given XML bodies in turn, repeating
+// the last one.
+func newSequenceServer(bodies ...string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(bodies[min(n, len(bodies))-1]))
+	}))
+	return server, &calls
+}
+
+func TestWithSuccessPredicate(t *testing.T) {
+	retry := &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+	t.Run("converts failed 200 into error", func(t *testing.T) {
+		server, calls := newSequenceServer(`<Response success="false"/>`)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(retry), WithSuccessPredicate(xmlSuccess))
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/orders", nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errOperationFailed)
+
+		var clientErr *Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, ErrKindHTTP, clientErr.Kind)
+		assert.Equal(t, http.StatusOK, clientErr.StatusCode)
+		assert.Equal(t, `<Response success="false"/>`, string(clientErr.Body))
+		assert.False(t, clientErr.IsRetryable())
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("passes successful responses", func(t *testing.T) {
+		server, _ := newSequenceServer(`<Response success="true"/>`)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithSuccessPredicate(xmlSuccess))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("retries errors marked retryable", func(t *testing.T) {
+		server, calls := newSequenceServer(`<Response success="false"/>`, `<Response success="true"/>`)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(retry),
+			WithSuccessPredicate(func(resp *Response) error { return RetryableError(xmlSuccess(resp)) }))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("RetryIf decides for predicate errors", func(t *testing.T) {
+		server, calls := newSequenceServer(`<Response success="false"/>`, `<Response success="true"/>`)
+		defer server.Close()
+
+		policy := *retry
+		policy.RetryIf = func(resp *http.Response, err error) bool { return errors.Is(err, errOperationFailed) }
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRetry(&policy), WithSuccessPredicate(xmlSuccess))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/orders", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("rejects nil predicate", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithSuccessPredicate(nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestRetryableError(t *testing.T) {
+	assert.NoError(t, RetryableError(nil))
+
+	err := RetryableError(errOperationFailed)
+	assert.ErrorIs(t, err, errOperationFailed)
+	assert.Equal(t, errOperationFailed.Error(), err.Error())
+	assert.True(t, (&Error{Kind: ErrKindHTTP, StatusCode: http.StatusOK, Err: err}).IsRetryable())
+}
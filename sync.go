@@ -0,0 +1,260 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults for SyncCollection.
+const (
+	DefaultSyncItemsField        = "items"
+	DefaultSyncCursorField       = "next_cursor"
+	DefaultSyncCursorParam       = "cursor"
+	DefaultSyncUpdatedSinceParam = "updated_since"
+	DefaultSyncMaxPages          = 1000
+)
+
+// SyncState is the checkpoint SyncCollection keeps between runs.
+type SyncState struct {
+	// ETag is the entity tag of the collection's first page.
+	ETag string `json:"etag,omitempty"`
+	// UpdatedSince is when the last successful sync started.
+	UpdatedSince time.Time `json:"updated_since,omitempty"`
+}
+
+// SyncStore persists SyncState per collection path.
+type SyncStore interface {
+	Load(ctx context.Context, path string) (SyncState, error)
+	Save(ctx context.Context, path string, state SyncState) error
+}
+
+// MemorySyncStore is an in-process SyncStore.
+type MemorySyncStore struct {
+	mu     sync.Mutex
+	states map[string]SyncState
+}
+
+// NewMemorySyncStore creates an empty in-process store.
+func NewMemorySyncStore() *MemorySyncStore {
+	return &MemorySyncStore{states: make(map[string]SyncState)}
+}
+
+// Load implements SyncStore.
+func (s *MemorySyncStore) Load(ctx context.Context, path string) (SyncState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[path], nil
+}
+
+// Save implements SyncStore.
+func (s *MemorySyncStore) Save(ctx context.Context, path string, state SyncState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[path] = state
+	return nil
+}
+
+// SyncItemFunc receives each changed item of a synced collection.
+type SyncItemFunc func(ctx context.Context, item json.RawMessage) error
+
+// SyncResult summarizes a SyncCollection run.
+type SyncResult struct {
+	Pages       int
+	Items       int
+	NotModified bool
+}
+
+// SyncOption configures SyncCollection.
+type SyncOption func(*syncConfig)
+
+type syncConfig struct {
+	itemsField        string
+	cursorField       string
+	cursorParam       string
+	updatedSinceParam string
+	maxPages          int
+	requestOpts       []RequestOption
+}
+
+// WithSyncItemsField sets the JSON field holding each page's items.
+func WithSyncItemsField(field string) SyncOption {
+	return func(cfg *syncConfig) {
+		cfg.itemsField = field
+	}
+}
+
+// WithSyncCursor sets the JSON field holding the next-page cursor and the
+// query parameter it is sent back in.
+func WithSyncCursor(field, param string) SyncOption {
+	return func(cfg *syncConfig) {
+		cfg.cursorField = field
+		cfg.cursorParam = param
+	}
+}
+
+// WithSyncUpdatedSinceParam sets the query parameter used to request only
+// items changed since the previous sync.
+func WithSyncUpdatedSinceParam(param string) SyncOption {
+	return func(cfg *syncConfig) {
+		cfg.updatedSinceParam = param
+	}
+}
+
+// WithSyncMaxPages bounds how many pages one sync may fetch.
+func WithSyncMaxPages(n int) SyncOption {
+	return func(cfg *syncConfig) {
+		cfg.maxPages = n
+	}
+}
+
+// WithSyncRequestOptions applies opts to every page request.
+func WithSyncRequestOptions(opts ...RequestOption) SyncOption {
+	return func(cfg *syncConfig) {
+		cfg.requestOpts = append(cfg.requestOpts, opts...)
+	}
+}
+
+// SyncCollection fetches the changes to the collection at path since the
+// checkpoint in store and passes each item to fn. The first page is requested
+// with If-None-Match, so an unchanged collection costs a single 304, and every
+// page carries the updated-since parameter. Pages are followed through the
+// cursor field of each JSON page; a page that is a bare JSON array ends the
+// sync. The checkpoint is saved only after every item was handled.
+func (c *Client) SyncCollection(ctx context.Context, path string, store SyncStore, fn SyncItemFunc, opts ...SyncOption) (SyncResult, error) {
+	if store == nil {
+		return SyncResult{}, errors.New("sync store cannot be nil")
+	}
+	if fn == nil {
+		return SyncResult{}, errors.New("sync item function cannot be nil")
+	}
+
+	cfg, err := newSyncConfig(opts)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	state, err := store.Load(ctx, path)
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("loading sync state: %w", err)
+	}
+
+	started := time.Now()
+	next := SyncState{UpdatedSince: started}
+	var result SyncResult
+	cursor := ""
+	for result.Pages < cfg.maxPages {
+		resp, err := c.Get(ctx, path, nil, cfg.pageOptions(state, cursor)...)
+		if err != nil {
+			return result, err
+		}
+		if cursor == "" {
+			if resp.StatusCode == http.StatusNotModified {
+				result.NotModified = true
+				return result, nil
+			}
+			next.ETag = resp.Headers.Get("ETag")
+		}
+		result.Pages++
+
+		nextCursor, err := handleSyncPage(ctx, resp.Body, cfg, fn, &result)
+		if err != nil {
+			return result, err
+		}
+		if nextCursor == "" {
+			if err := store.Save(ctx, path, next); err != nil {
+				return result, fmt.Errorf("saving sync state: %w", err)
+			}
+			return result, nil
+		}
+		cursor = nextCursor
+	}
+
+	return result, fmt.Errorf("sync of %s exceeded %d pages", path, cfg.maxPages)
+}
+
+// newSyncConfig applies opts over the defaults.
+func newSyncConfig(opts []SyncOption) (syncConfig, error) {
+	cfg := syncConfig{
+		itemsField:        DefaultSyncItemsField,
+		cursorField:       DefaultSyncCursorField,
+		cursorParam:       DefaultSyncCursorParam,
+		updatedSinceParam: DefaultSyncUpdatedSinceParam,
+		maxPages:          DefaultSyncMaxPages,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxPages <= 0 {
+		return cfg, fmt.Errorf("sync max pages %d must be positive", cfg.maxPages)
+	}
+	return cfg, nil
+}
+
+// pageOptions returns the request options for the page at cursor, or for
+// the first page when cursor is empty.
+func (cfg syncConfig) pageOptions(state SyncState, cursor string) []RequestOption {
+	reqOpts := append([]RequestOption{}, cfg.requestOpts...)
+	if !state.UpdatedSince.IsZero() && cfg.updatedSinceParam != "" {
+		reqOpts = append(reqOpts, WithQuery(cfg.updatedSinceParam, state.UpdatedSince.UTC().Format(time.RFC3339)))
+	}
+	if cursor != "" {
+		return append(reqOpts, WithQuery(cfg.cursorParam, cursor))
+	}
+	if state.ETag != "" {
+		reqOpts = append(reqOpts, WithRequestHeader("If-None-Match", state.ETag))
+	}
+	return reqOpts
+}
+
+// handleSyncPage passes the items of a page body to fn, counting them in
+// result, and returns the cursor of the next page, or "" after the last.
+func handleSyncPage(ctx context.Context, body []byte, cfg syncConfig, fn SyncItemFunc, result *SyncResult) (string, error) {
+	items, cursor, err := parseSyncPage(body, cfg)
+	if err != nil {
+		return "", err
+	}
+	for _, item := range items {
+		if err := fn(ctx, item); err != nil {
+			return "", err
+		}
+		result.Items++
+	}
+	return cursor, nil
+}
+
+// parseSyncPage extracts the items and next cursor from a page body.
+func parseSyncPage(body []byte, cfg syncConfig) ([]json.RawMessage, string, error) {
+	if len(body) == 0 {
+		return nil, "", nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err == nil {
+		return items, "", nil
+	}
+
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", fmt.Errorf("parsing sync page: %w", err)
+	}
+	if raw, ok := page[cfg.itemsField]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, "", fmt.Errorf("sync page field %q is not an array: %w", cfg.itemsField, err)
+		}
+	}
+
+	var cursor string
+	if raw, ok := page[cfg.cursorField]; ok {
+		// A null cursor ends the sync; any other non-string cursor would
+		// end it early and checkpoint past pages never fetched.
+		if err := json.Unmarshal(raw, &cursor); err != nil {
+			return nil, "", fmt.Errorf("sync page field %q is not a string or null: %w", cfg.cursorField, err)
+		}
+	}
+	return items, cursor, nil
+}
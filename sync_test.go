@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCollectionServer serves two pages of items behind an ETag.
+func newCollectionServer(etag string, seen *[]*http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*seen = append(*seen, r)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set("ETag", etag)
+			_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2}],"next_cursor":"p2"}`))
+		case "p2":
+			_, _ = w.Write([]byte(`{"items":[{"id":3}],"next_cursor":null}`))
+		}
+	}))
+}
+
+func collectIDs(ids *[]int) SyncItemFunc {
+	return func(ctx context.Context, item json.RawMessage) error {
+		var v struct{ ID int }
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		*ids = append(*ids, v.ID)
+		return nil
+	}
+}
+
+func TestClient_SyncCollection(t *testing.T) {
+	t.Run("pages through collection and saves checkpoint", func(t *testing.T) {
+		var seen []*http.Request
+		server := newCollectionServer(`"v1"`, &seen)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		store := NewMemorySyncStore()
+
+		var ids []int
+		result, err := client.SyncCollection(context.Background(), "/products", store, collectIDs(&ids))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, ids)
+		assert.Equal(t, SyncResult{Pages: 2, Items: 3}, result)
+		state, _ := store.Load(context.Background(), "/products")
+		assert.Equal(t, `"v1"`, state.ETag)
+		assert.False(t, state.UpdatedSince.IsZero())
+	})
+
+	t.Run("sends etag and updated since on next run", func(t *testing.T) {
+		var seen []*http.Request
+		server := newCollectionServer(`"v1"`, &seen)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		store := NewMemorySyncStore()
+		since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, store.Save(context.Background(), "/products", SyncState{ETag: `"v1"`, UpdatedSince: since}))
+
+		var ids []int
+		result, err := client.SyncCollection(context.Background(), "/products", store, collectIDs(&ids))
+
+		require.NoError(t, err)
+		assert.True(t, result.NotModified)
+		assert.Empty(t, ids)
+		require.Len(t, seen, 1)
+		assert.Equal(t, "2024-01-02T03:04:05Z", seen[0].URL.Query().Get("updated_since"))
+	})
+
+	t.Run("accepts bare array pages and custom fields", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"id":7}]`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		var ids []int
+		_, err = client.SyncCollection(context.Background(), "/products", NewMemorySyncStore(), collectIDs(&ids),
+			WithSyncItemsField("data"), WithSyncCursor("next", "page"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{7}, ids)
+	})
+
+	t.Run("does not save checkpoint when callback fails", func(t *testing.T) {
+		var seen []*http.Request
+		server := newCollectionServer(`"v1"`, &seen)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		store := NewMemorySyncStore()
+
+		_, err = client.SyncCollection(context.Background(), "/products", store, func(ctx context.Context, item json.RawMessage) error {
+			return errors.New("db down")
+		})
+
+		require.Error(t, err)
+		state, _ := store.Load(context.Background(), "/products")
+		assert.Empty(t, state.ETag)
+	})
+
+	t.Run("rejects a cursor that is not a string", func(t *testing.T) {
+		for _, cursor := range []string{`2`, `{"page":2}`} {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2}],"next_cursor":` + cursor + `}`))
+			}))
+			client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+			require.NoError(t, err)
+			store := NewMemorySyncStore()
+
+			_, err = client.SyncCollection(context.Background(), "/products", store, collectIDs(new([]int)))
+			server.Close()
+
+			assert.ErrorContains(t, err, `"next_cursor" is not a string or null`, cursor)
+			state, _ := store.Load(context.Background(), "/products")
+			assert.Empty(t, state.ETag, "no checkpoint past unfetched pages")
+		}
+	})
+
+	t.Run("stops after max pages", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"items":[],"next_cursor":"again"}`))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		result, err := client.SyncCollection(context.Background(), "/products", NewMemorySyncStore(), collectIDs(new([]int)), WithSyncMaxPages(3))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeded 3 pages")
+		assert.Equal(t, 3, result.Pages)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		_, err = client.SyncCollection(context.Background(), "/p", nil, collectIDs(new([]int)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sync store cannot be nil")
+
+		_, err = client.SyncCollection(context.Background(), "/p", NewMemorySyncStore(), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sync item function cannot be nil")
+	})
+}
@@ -0,0 +1,27 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithResponseTee copies the body of a successful response to w once it is
+// read and decompressed, so an audit log, file or hash can consume the same
+// buffer as the result decoder instead of the caller copying it again.
+// Bodies of error responses, which may be retried, are not copied.
+func WithResponseTee(w io.Writer) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.tee = w
+	}
+}
+
+// writeTee copies body to the tee writer, if any.
+func writeTee(w io.Writer, body []byte) error {
+	if w == nil || len(body) == 0 {
+		return nil
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("writing response body to tee: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWithResponseTee(t *testing.T) {
+	t.Run("copies body to sink and decodes result", func(t *testing.T) {
+		body := `{"id":42}`
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		hash := sha256.New()
+		var result struct{ ID int }
+		_, err = client.Get(context.Background(), "/item", &result, WithResponseTee(hash))
+
+		require.NoError(t, err)
+		assert.Equal(t, 42, result.ID)
+		want := sha256.Sum256([]byte(body))
+		assert.Equal(t, want[:], hash.Sum(nil))
+	})
+
+	t.Run("copies decompressed body", func(t *testing.T) {
+		server, _ := newGzipServer(t, []byte(`{"id":42}`))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithCompression())
+		require.NoError(t, err)
+
+		var sink bytes.Buffer
+		_, err = client.Get(context.Background(), "/item", nil, WithResponseTee(&sink))
+
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":42}`, sink.String())
+	})
+
+	t.Run("skips error responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream down"))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		var sink bytes.Buffer
+		_, err = client.Get(context.Background(), "/item", nil, WithResponseTee(&sink))
+
+		require.Error(t, err)
+		assert.Empty(t, sink.String())
+	})
+
+	t.Run("returns sink errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("data"))
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/item", nil, WithResponseTee(failingWriter{}))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "disk full")
+	})
+}
@@ -0,0 +1,51 @@
+package testserver
+
+import (
+	"math"
+	"time"
+)
+
+// Distribution draws a latency from random numbers in [0, 1) returned by
+// rand.
+type Distribution func(rand func() float64) time.Duration
+
+// Fixed always returns d.
+func Fixed(d time.Duration) Distribution {
+	return func(func() float64) time.Duration {
+		return d
+	}
+}
+
+// Uniform returns latencies spread evenly between lo and hi.
+func Uniform(lo, hi time.Duration) Distribution {
+	return func(rand func() float64) time.Duration {
+		return lo + time.Duration(rand()*float64(hi-lo))
+	}
+}
+
+// Exponential returns latencies averaging mean, mostly fast with a long
+// tail, as queueing servers show.
+func Exponential(mean time.Duration) Distribution {
+	return func(rand func() float64) time.Duration {
+		return time.Duration(-math.Log(1-rand()) * float64(mean))
+	}
+}
+
+// Percentiles returns latencies whose median is p50, 99th percentile p99
+// and 99.9th percentile p999, interpolating linearly in between, to
+// reproduce a latency profile measured in production.
+func Percentiles(p50, p99, p999 time.Duration) Distribution {
+	return func(rand func() float64) time.Duration {
+		q := rand()
+		switch {
+		case q < 0.5:
+			return time.Duration(q / 0.5 * float64(p50))
+		case q < 0.99:
+			return p50 + time.Duration((q-0.5)/0.49*float64(p99-p50))
+		case q < 0.999:
+			return p99 + time.Duration((q-0.99)/0.009*float64(p999-p99))
+		default:
+			return p999
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package testserver
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributions(t *testing.T) {
+	fixed := func(q float64) func() float64 { return func() float64 { return q } }
+
+	assert.Equal(t, time.Second, Fixed(time.Second)(fixed(0.7)))
+	assert.Equal(t, 15*time.Millisecond, Uniform(10*time.Millisecond, 20*time.Millisecond)(fixed(0.5)))
+	assert.InDelta(t, float64(time.Second)*0.6931, float64(Exponential(time.Second)(fixed(0.5))), float64(time.Millisecond))
+
+	p := Percentiles(10*time.Millisecond, 100*time.Millisecond, time.Second)
+	assert.Equal(t, 10*time.Millisecond, p(fixed(0.5)))
+	assert.Equal(t, 100*time.Millisecond, p(fixed(0.99)))
+	assert.Equal(t, time.Second, p(fixed(0.9999)))
+	for _, q := range []float64{0, 0.25, 0.75, 0.995} {
+		assert.GreaterOrEqual(t, p(fixed(q)), time.Duration(0), strconv.FormatFloat(q, 'f', -1, 64))
+	}
+}
@@ -0,0 +1,357 @@
+// Package testserver provides a configurable fake HTTP API for testing
+// integrations against realistic upstream behavior: latency distributions,
+// error rates, rate limiting with Retry-After, flaky sequences, dropped
+// connections and pagination, without writing bespoke httptest handlers.
+//
+//	srv := testserver.New(testserver.WithSeed(1))
+//	defer srv.Close()
+//	srv.Handle("GET /users/{id}",
+//		testserver.Respond(http.StatusOK, map[string]any{"id": 1}),
+//		testserver.Latency(testserver.Uniform(5*time.Millisecond, 50*time.Millisecond)),
+//		testserver.ErrorRate(0.1, http.StatusServiceUnavailable),
+//	)
+//	client, err := httpclient.New(httpclient.WithBaseURL(srv.URL))
+//
+// Randomness comes from one seeded source, so a failing test can be
+// replayed with the same seed.
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Server is a fake API listening on a local address. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mux *http.ServeMux
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	routes map[string]*route
+}
+
+// Option configures a Server.
+type Option func(*serverConfig)
+
+type serverConfig struct {
+	seed uint64
+	tls  bool
+}
+
+// WithSeed seeds the server's randomness, for reproducible failures. By
+// default the seed is 1.
+func WithSeed(seed uint64) Option {
+	return func(cfg *serverConfig) {
+		cfg.seed = seed
+	}
+}
+
+// WithTLS serves HTTPS with a self-signed certificate; use Server.Client
+// or its transport to trust it.
+func WithTLS() Option {
+	return func(cfg *serverConfig) {
+		cfg.tls = true
+	}
+}
+
+// New starts a server with no routes; unknown routes answer 404.
+func New(opts ...Option) *Server {
+	cfg := serverConfig{seed: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &Server{
+		mux:    http.NewServeMux(),
+		rng:    rand.New(rand.NewPCG(cfg.seed, cfg.seed)),
+		routes: make(map[string]*route),
+	}
+	if cfg.tls {
+		s.Server = httptest.NewTLSServer(s.mux)
+	} else {
+		s.Server = httptest.NewServer(s.mux)
+	}
+	return s
+}
+
+// Handle registers a route for pattern, in http.ServeMux syntax such as
+// "GET /users/{id}". A route without Respond or Paginate answers 200 with
+// an empty body. Failure options apply in the order rate limit, sequence,
+// drop, error rate, after the latency. Like http.ServeMux, it panics when
+// pattern is invalid or already registered.
+func (s *Server) Handle(pattern string, opts ...RouteOption) {
+	r := &route{server: s, status: http.StatusOK}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	s.mu.Lock()
+	s.routes[pattern] = r
+	s.mu.Unlock()
+	s.mux.Handle(pattern, r)
+}
+
+// Requests returns how many requests the route for pattern received.
+func (s *Server) Requests(pattern string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.routes[pattern]
+	if !ok {
+		return 0
+	}
+	return r.requests
+}
+
+// random returns a random number in [0, 1).
+func (s *Server) random() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64()
+}
+
+// RouteOption configures a route.
+type RouteOption func(*route)
+
+type route struct {
+	server *Server
+
+	status int
+	body   []byte
+
+	latency   Distribution
+	errorRate float64
+	errStatus int
+	dropRate  float64
+	sequence  []int
+
+	limit       int
+	limitWindow time.Duration
+	windowStart time.Time
+	windowUsed  int
+
+	items    []json.RawMessage
+	pageSize int
+
+	requests int // guarded by server.mu
+}
+
+// Respond sets the route's normal response: status with body encoded as
+// JSON, or sent as is when it is a []byte or string.
+func Respond(status int, body any) RouteOption {
+	return func(r *route) {
+		r.status = status
+		r.body = encodeBody(body)
+	}
+}
+
+// Latency delays every response by a duration drawn from d.
+func Latency(d Distribution) RouteOption {
+	return func(r *route) {
+		r.latency = d
+	}
+}
+
+// ErrorRate fails the given fraction of requests, from 0 to 1, with status
+// and a JSON error body.
+func ErrorRate(rate float64, status int) RouteOption {
+	return func(r *route) {
+		r.errorRate = rate
+		r.errStatus = status
+	}
+}
+
+// DropRate closes the connection without a response for the given
+// fraction of requests, so clients see a network error.
+func DropRate(rate float64) RouteOption {
+	return func(r *route) {
+		r.dropRate = rate
+	}
+}
+
+// Sequence answers the route's first requests with statuses in order, then
+// normally, e.g. Sequence(503, 503) for a route that recovers on the third
+// attempt. A status of 0 drops the connection.
+func Sequence(statuses ...int) RouteOption {
+	return func(r *route) {
+		r.sequence = statuses
+	}
+}
+
+// RateLimit allows n requests per window and answers the rest 429 with
+// Retry-After until the window ends. Every response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers,
+// the reset in fractional seconds.
+func RateLimit(n int, window time.Duration) RouteOption {
+	return func(r *route) {
+		r.limit = n
+		r.limitWindow = window
+	}
+}
+
+// Paginate serves items in pages of pageSize as
+// {"items": [...], "next_cursor": "..."}, taking the cursor from the
+// "cursor" query parameter, with a Link rel="next" header on every page but
+// the last. This is the layout httpclient's SyncCollection reads by default.
+func Paginate(items []any, pageSize int) RouteOption {
+	return func(r *route) {
+		r.items = make([]json.RawMessage, len(items))
+		for i, item := range items {
+			r.items[i] = encodeBody(item)
+		}
+		r.pageSize = max(pageSize, 1)
+	}
+}
+
+func (r *route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s := r.server
+	s.mu.Lock()
+	r.requests++
+	n := r.requests
+	s.mu.Unlock()
+
+	if r.latency != nil {
+		select {
+		case <-time.After(r.latency(s.random)):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	if !r.allow(w) {
+		return
+	}
+
+	status := r.status
+	switch {
+	case n <= len(r.sequence):
+		status = r.sequence[n-1]
+		if status == 0 {
+			drop(w)
+			return
+		}
+	case r.dropRate > 0 && s.random() < r.dropRate:
+		drop(w)
+		return
+	case r.errorRate > 0 && s.random() < r.errorRate:
+		status = r.errStatus
+	}
+
+	if status >= 400 {
+		writeError(w, status)
+		return
+	}
+	if r.items != nil {
+		r.writePage(w, req)
+		return
+	}
+	if len(r.body) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	w.Write(r.body)
+}
+
+// allow applies the rate limit and reports whether the request may proceed.
+func (r *route) allow(w http.ResponseWriter) bool {
+	if r.limit <= 0 {
+		return true
+	}
+
+	s := r.server
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.windowStart) >= r.limitWindow {
+		r.windowStart, r.windowUsed = now, 0
+	}
+	reset := r.windowStart.Add(r.limitWindow).Sub(now)
+	allowed := r.windowUsed < r.limit
+	if allowed {
+		r.windowUsed++
+	}
+	remaining := r.limit - r.windowUsed
+	s.mu.Unlock()
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(r.limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatFloat(reset.Seconds(), 'f', 3, 64))
+	if !allowed {
+		// Retry-After takes whole seconds.
+		w.Header().Set("Retry-After", strconv.Itoa(int((reset+time.Second-1)/time.Second)))
+		writeError(w, http.StatusTooManyRequests)
+	}
+	return allowed
+}
+
+// writePage writes the page of items starting at the request's cursor.
+func (r *route) writePage(w http.ResponseWriter, req *http.Request) {
+	start := 0
+	if cursor := req.URL.Query().Get("cursor"); cursor != "" {
+		var err error
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 || start > len(r.items) {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+	}
+	end := min(start+r.pageSize, len(r.items))
+
+	page := struct {
+		Items      []json.RawMessage `json:"items"`
+		NextCursor string            `json:"next_cursor,omitempty"`
+	}{Items: r.items[start:end]}
+	if end < len(r.items) {
+		page.NextCursor = strconv.Itoa(end)
+		next := *req.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// drop closes the connection without writing a response.
+func drop(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
+
+func writeError(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status)})
+}
+
+// encodeBody returns body as JSON, or as is for a []byte or string.
+func encodeBody(body any) []byte {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("testserver: encoding body: %v", err))
+	}
+	return data
+}
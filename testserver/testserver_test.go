@@ -0,0 +1,140 @@
+package testserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/holgersendify/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, srv *Server, opts ...httpclient.ClientOption) *httpclient.Client {
+	t.Helper()
+	opts = append([]httpclient.ClientOption{httpclient.WithBaseURL(srv.URL), httpclient.WithLoggerDisabled()}, opts...)
+	client, err := httpclient.New(opts...)
+	require.NoError(t, err)
+	return client
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("responds and counts requests", func(t *testing.T) {
+		srv := New()
+		defer srv.Close()
+		srv.Handle("GET /users/{id}", Respond(http.StatusOK, map[string]int{"id": 7}))
+		client := newClient(t, srv)
+
+		var user struct{ ID int }
+		_, err := client.Get(ctx, "/users/7", &user)
+		require.NoError(t, err)
+		assert.Equal(t, 7, user.ID)
+		assert.Equal(t, 1, srv.Requests("GET /users/{id}"))
+
+		_, err = client.Get(ctx, "/missing", nil)
+		var clientErr *httpclient.Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusNotFound, clientErr.StatusCode)
+	})
+
+	t.Run("recovers after a flaky sequence", func(t *testing.T) {
+		srv := New()
+		defer srv.Close()
+		srv.Handle("GET /flaky", Sequence(http.StatusServiceUnavailable, 0))
+		client := newClient(t, srv, httpclient.WithRetry(&httpclient.RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1,
+		}))
+
+		resp, err := client.Get(ctx, "/flaky", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 3, srv.Requests("GET /flaky"))
+	})
+
+	t.Run("rate limits with Retry-After", func(t *testing.T) {
+		srv := New()
+		defer srv.Close()
+		srv.Handle("GET /search", RateLimit(1, time.Minute))
+		client := newClient(t, srv)
+
+		resp, err := client.Get(ctx, "/search", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "0", resp.Headers.Get("X-RateLimit-Remaining"))
+
+		_, err = client.Get(ctx, "/search", nil)
+		var clientErr *httpclient.Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusTooManyRequests, clientErr.StatusCode)
+		assert.InDelta(t, time.Minute, clientErr.RetryAfter, float64(time.Second))
+	})
+
+	t.Run("injects errors and drops at the configured rates", func(t *testing.T) {
+		srv := New(WithSeed(42))
+		defer srv.Close()
+		srv.Handle("GET /errors", ErrorRate(0.5, http.StatusBadGateway))
+		srv.Handle("GET /drops", DropRate(1))
+		client := newClient(t, srv)
+
+		failed := 0
+		for range 200 {
+			if _, err := client.Get(ctx, "/errors", nil); err != nil {
+				failed++
+			}
+		}
+		assert.InDelta(t, 100, failed, 30)
+
+		_, err := client.Get(ctx, "/drops", nil)
+		var clientErr *httpclient.Error
+		require.ErrorAs(t, err, &clientErr)
+		assert.Zero(t, clientErr.StatusCode)
+	})
+
+	t.Run("delays by the latency distribution", func(t *testing.T) {
+		srv := New()
+		defer srv.Close()
+		srv.Handle("GET /slow", Latency(Fixed(30*time.Millisecond)))
+		client := newClient(t, srv)
+
+		start := time.Now()
+		_, err := client.Get(ctx, "/slow", nil)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	})
+
+	t.Run("paginates for SyncCollection", func(t *testing.T) {
+		srv := New()
+		defer srv.Close()
+		items := make([]any, 5)
+		for i := range items {
+			items[i] = map[string]int{"id": i}
+		}
+		srv.Handle("GET /items", Paginate(items, 2))
+		client := newClient(t, srv)
+
+		var ids []int
+		result, err := client.SyncCollection(ctx, "/items", httpclient.NewMemorySyncStore(), func(ctx context.Context, item json.RawMessage) error {
+			var v struct{ ID int }
+			if err := json.Unmarshal(item, &v); err != nil {
+				return err
+			}
+			ids = append(ids, v.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Pages)
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, ids)
+
+		resp, err := client.Get(ctx, "/items", nil)
+		require.NoError(t, err)
+		next, ok := httpclient.FindLink(resp.Links(), "next")
+		require.True(t, ok)
+		assert.Equal(t, "/items?cursor=2", next.URL)
+	})
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutPhase is the stage a call was in when it ran out of time.
+type TimeoutPhase int
+
+const (
+	// TimeoutPhaseQueue is waiting before an attempt: rate limits,
+	// concurrency limits and buffer budgets.
+	TimeoutPhaseQueue TimeoutPhase = iota
+	// TimeoutPhaseConnect is obtaining a connection: DNS, dialing, the TLS
+	// handshake or waiting for a pooled connection.
+	TimeoutPhaseConnect
+	// TimeoutPhaseWrite is writing the request.
+	TimeoutPhaseWrite
+	// TimeoutPhaseWait is waiting for the response headers, i.e. for the
+	// upstream to answer.
+	TimeoutPhaseWait
+	// TimeoutPhaseRead is reading the response body.
+	TimeoutPhaseRead
+	// TimeoutPhaseBackoff is waiting to retry.
+	TimeoutPhaseBackoff
+)
+
+// String returns the phase's name, e.g. "wait".
+func (p TimeoutPhase) String() string {
+	switch p {
+	case TimeoutPhaseConnect:
+		return "connect"
+	case TimeoutPhaseWrite:
+		return "write"
+	case TimeoutPhaseWait:
+		return "wait"
+	case TimeoutPhaseRead:
+		return "read"
+	case TimeoutPhaseBackoff:
+		return "backoff"
+	default:
+		return "queue"
+	}
+}
+
+// TimeoutInfo describes the time budget of a call that failed because a
+// deadline passed, to tell a slow upstream from a timeout set too tight.
+type TimeoutInfo struct {
+	Phase TimeoutPhase
+	// Timeout is the per-request timeout in effect, from WithTimeout,
+	// WithRequestTimeout or WithAdaptiveTimeout, or 0 if there was none.
+	Timeout time.Duration
+	// MaxElapsedTime is the retry policy's limit, or 0 if there was none.
+	MaxElapsedTime time.Duration
+	// Elapsed is the time from the start of the call to the failure.
+	Elapsed  time.Duration
+	Attempts int
+	// Remaining is what was left of the caller's context deadline at the
+	// failure, negative once it had passed. It is only set if HasDeadline.
+	Remaining   time.Duration
+	HasDeadline bool
+}
+
+// String formats the information as space-separated key=value pairs.
+func (t *TimeoutInfo) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "phase=%s elapsed=%v attempts=%d", t.Phase, t.Elapsed.Round(time.Millisecond), t.Attempts)
+	if t.Timeout > 0 {
+		fmt.Fprintf(&b, " timeout=%v", t.Timeout)
+	}
+	if t.MaxElapsedTime > 0 {
+		fmt.Fprintf(&b, " max_elapsed=%v", t.MaxElapsedTime)
+	}
+	if t.HasDeadline {
+		fmt.Fprintf(&b, " remaining=%v", t.Remaining.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+// callTiming follows a call with a deadline through the retry loop for
+// TimeoutInfo. The phase is set from httptrace hooks, which run on
+// transport goroutines.
+type callTiming struct {
+	timeout time.Duration
+	phase   atomic.Int32
+}
+
+// startTiming applies the call's request timeout, if any, to ctx and
+// starts following the call if it can time out. Calls that cannot are not
+// followed, to keep them free of the allocation.
+func (c *Client) startTiming(ctx context.Context, cl *call) (context.Context, context.CancelFunc) {
+	timeout := c.requestTimeout(cl)
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	cl.timing = nil
+	if _, ok := ctx.Deadline(); ok || (c.retryPolicy != nil && c.retryPolicy.MaxElapsedTime > 0) {
+		cl.timing = &callTiming{timeout: timeout}
+	}
+	return ctx, cancel
+}
+
+// beginAttempt records the start of an attempt, which first waits in the
+// queue. An attempt begun once time has run out fails at once and is not
+// counted, so the phase the time ran out in is kept.
+func beginAttempt(ctx context.Context, cl *call, attempt int) {
+	if ctx.Err() != nil {
+		return
+	}
+	cl.attempts = attempt
+	cl.timing.enter(TimeoutPhaseQueue)
+}
+
+func (t *callTiming) enter(phase TimeoutPhase) {
+	if t != nil {
+		t.phase.Store(int32(phase))
+	}
+}
+
+// trace follows the connect, write and wait phases of an attempt.
+func (t *callTiming) trace(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn:      func(string) { t.enter(TimeoutPhaseConnect) },
+		GotConn:      func(httptrace.GotConnInfo) { t.enter(TimeoutPhaseWrite) },
+		WroteRequest: func(httptrace.WroteRequestInfo) { t.enter(TimeoutPhaseWait) },
+	})
+}
+
+// timeoutInfo describes the budget of a call started at start that ran out
+// of time; ctx is the caller's context.
+func (c *Client) timeoutInfo(ctx context.Context, cl *call, start time.Time) *TimeoutInfo {
+	now := time.Now()
+	info := &TimeoutInfo{Elapsed: now.Sub(start), Attempts: cl.attempts}
+	if cl.timing != nil {
+		info.Phase = TimeoutPhase(cl.timing.phase.Load())
+		info.Timeout = cl.timing.timeout
+	}
+	if c.retryPolicy != nil {
+		info.MaxElapsedTime = c.retryPolicy.MaxElapsedTime
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		info.Remaining, info.HasDeadline = deadline.Sub(now), true
+	}
+	return info
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timeoutError returns the client error of a call that timed out.
+func timeoutError(t *testing.T, err error) *Error {
+	t.Helper()
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotNil(t, clientErr.Timeout)
+	return clientErr
+}
+
+func TestTimeoutInfo(t *testing.T) {
+	t.Run("reports slow upstream while waiting for headers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/slow", nil, WithRequestTimeout(50*time.Millisecond))
+		clientErr := timeoutError(t, err)
+		info := clientErr.Timeout
+		assert.Equal(t, TimeoutPhaseWait, info.Phase)
+		assert.Equal(t, 50*time.Millisecond, info.Timeout)
+		assert.Equal(t, 1, info.Attempts)
+		assert.Equal(t, 1, clientErr.Attempts)
+		assert.GreaterOrEqual(t, info.Elapsed, 50*time.Millisecond)
+		assert.False(t, info.HasDeadline)
+		assert.Contains(t, err.Error(), "phase=wait")
+		assert.Contains(t, err.Error(), "timeout=50ms")
+	})
+
+	t.Run("reports slow body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/slow", nil, WithRequestTimeout(50*time.Millisecond))
+		assert.Equal(t, TimeoutPhaseRead, timeoutError(t, err).Timeout.Phase)
+	})
+
+	t.Run("reports caller deadline", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = client.Get(ctx, "/slow", nil)
+		info := timeoutError(t, err).Timeout
+		assert.Zero(t, info.Timeout)
+		assert.True(t, info.HasDeadline)
+		assert.LessOrEqual(t, info.Remaining, time.Duration(0))
+		assert.Contains(t, err.Error(), "remaining=")
+	})
+
+	t.Run("reports backoff", func(t *testing.T) {
+		server := newStatusServer(http.StatusServiceUnavailable)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: time.Second, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = client.Get(ctx, "/unavailable", nil)
+		info := timeoutError(t, err).Timeout
+		assert.Equal(t, TimeoutPhaseBackoff, info.Phase)
+		assert.Equal(t, 1, info.Attempts)
+	})
+
+	t.Run("reports queue", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled(), WithRateLimit(1, time.Hour))
+		require.NoError(t, err)
+		_, err = client.Get(context.Background(), "/first", nil)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err = client.Get(ctx, "/second", nil)
+		info := timeoutError(t, err).Timeout
+		assert.Equal(t, TimeoutPhaseQueue, info.Phase)
+		assert.Zero(t, info.Attempts)
+	})
+
+	t.Run("is only set for timeouts", func(t *testing.T) {
+		server := newStatusServer(http.StatusNotFound)
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithTimeout(time.Second), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/missing", nil)
+		var clientErr *Error
+		require.True(t, errors.As(err, &clientErr))
+		assert.Nil(t, clientErr.Timeout)
+		assert.Equal(t, 1, clientErr.Attempts)
+	})
+
+	t.Run("formats budget", func(t *testing.T) {
+		info := &TimeoutInfo{
+			Phase:          TimeoutPhaseConnect,
+			Timeout:        2 * time.Second,
+			MaxElapsedTime: 10 * time.Second,
+			Elapsed:        2001500 * time.Microsecond,
+			Attempts:       3,
+			Remaining:      -1200 * time.Microsecond,
+			HasDeadline:    true,
+		}
+		assert.Equal(t, "phase=connect elapsed=2.002s attempts=3 timeout=2s max_elapsed=10s remaining=-1ms", info.String())
+		assert.Equal(t, "phase=queue elapsed=0s attempts=0", (&TimeoutInfo{}).String())
+	})
+}
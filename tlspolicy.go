@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// TLSPolicy is a TLS security baseline applied with WithTLSPolicy.
+type TLSPolicy struct {
+	name             string
+	minVersion       uint16
+	maxVersion       uint16
+	cipherSuites     []uint16
+	curvePreferences []tls.CurveID
+}
+
+// TLSModern returns a policy allowing only TLS 1.3, whose cipher suites are
+// all considered secure.
+func TLSModern() TLSPolicy {
+	return TLSPolicy{name: "modern", minVersion: tls.VersionTLS13}
+}
+
+// TLSIntermediate returns a policy allowing TLS 1.2 and 1.3, restricting
+// TLS 1.2 to ECDHE key exchange with AEAD ciphers.
+func TLSIntermediate() TLSPolicy {
+	return TLSPolicy{
+		name:       "intermediate",
+		minVersion: tls.VersionTLS12,
+		cipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// TLSCustom builds a policy from the MinVersion, MaxVersion, CipherSuites
+// and CurvePreferences of cfg. Other fields of cfg are ignored.
+func TLSCustom(cfg *tls.Config) TLSPolicy {
+	if cfg == nil {
+		return TLSPolicy{name: "custom"}
+	}
+	return TLSPolicy{
+		name:             "custom",
+		minVersion:       cfg.MinVersion,
+		maxVersion:       cfg.MaxVersion,
+		cipherSuites:     slices.Clone(cfg.CipherSuites),
+		curvePreferences: slices.Clone(cfg.CurvePreferences),
+	}
+}
+
+// String returns the policy name.
+func (p TLSPolicy) String() string {
+	return p.name
+}
+
+// WithTLSPolicy enforces a TLS baseline on the client's transport. It
+// overrides the version and cipher settings of a transport passed with
+// WithHTTPClient and keeps its other TLS settings.
+func WithTLSPolicy(policy TLSPolicy) ClientOption {
+	return func(c *Client) error {
+		if policy.name == "" {
+			return errors.New("TLS policy must be TLSModern, TLSIntermediate or TLSCustom")
+		}
+		if policy.minVersion == 0 {
+			return errors.New("TLS policy requires a minimum version")
+		}
+		if policy.maxVersion != 0 && policy.maxVersion < policy.minVersion {
+			return errors.New("TLS policy max version is below its min version")
+		}
+
+		c.transportHooks = append(c.transportHooks, func(t *http.Transport) error {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.MinVersion = policy.minVersion
+			t.TLSClientConfig.MaxVersion = policy.maxVersion
+			t.TLSClientConfig.CipherSuites = slices.Clone(policy.cipherSuites)
+			t.TLSClientConfig.CurvePreferences = slices.Clone(policy.curvePreferences)
+			return nil
+		})
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSServer starts a TLS server limited to the given version range.
+func newTLSServer(minVersion, maxVersion uint16) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MinVersion: minVersion, MaxVersion: maxVersion}
+	server.StartTLS()
+	return server
+}
+
+func TestWithTLSPolicy(t *testing.T) {
+	t.Run("modern refuses TLS 1.2 servers", func(t *testing.T) {
+		server := newTLSServer(tls.VersionTLS12, tls.VersionTLS12)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLoggerDisabled(),
+			WithTLSPolicy(TLSModern()),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("intermediate accepts TLS 1.2 servers", func(t *testing.T) {
+		server := newTLSServer(tls.VersionTLS12, tls.VersionTLS12)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithLoggerDisabled(),
+			WithTLSPolicy(TLSIntermediate()),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", nil)
+		require.NoError(t, err)
+
+		transport := client.httpClient.Transport.(*http.Transport)
+		assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+		assert.NotEmpty(t, transport.TLSClientConfig.CipherSuites)
+		assert.NotNil(t, transport.TLSClientConfig.RootCAs)
+	})
+
+	t.Run("custom copies version and cipher fields", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithTLSPolicy(TLSCustom(&tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+				ServerName:   "ignored",
+			})),
+		)
+		require.NoError(t, err)
+
+		cfg := client.httpClient.Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, cfg.CipherSuites)
+		assert.Empty(t, cfg.ServerName)
+		assert.Equal(t, "custom", TLSCustom(nil).String())
+	})
+
+	t.Run("validates policy", func(t *testing.T) {
+		_, err := New(WithBaseURL("https://api.example.com"), WithTLSPolicy(TLSPolicy{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TLS policy must be")
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithTLSPolicy(TLSCustom(&tls.Config{})))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a minimum version")
+
+		_, err = New(WithBaseURL("https://api.example.com"), WithTLSPolicy(TLSCustom(&tls.Config{
+			MinVersion: tls.VersionTLS13,
+			MaxVersion: tls.VersionTLS12,
+		})))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "below its min version")
+	})
+}
@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// TLSObserver is implemented by collectors that also track TLS handshakes,
+// to verify that session resumption happens. It is called once per new
+// TLS connection, after the connection's first response.
+type TLSObserver interface {
+	ObserveTLSHandshake(host string, resumed bool)
+}
+
+// WithTLSSessionResumption caches up to capacity TLS sessions, so new
+// connections to a host resume an earlier session with an abbreviated
+// handshake instead of a full one. Whether a response came over a resumed
+// session is reported by Response.TLSResumed, and per connection to a
+// TLSObserver. A transport passed with WithHTTPClient keeps its other TLS
+// settings. Go's TLS client does not send 0-RTT early data, so resumed
+// sessions still take one round trip.
+func WithTLSSessionResumption(capacity int) ClientOption {
+	return func(c *Client) error {
+		if capacity <= 0 {
+			return fmt.Errorf("TLS session cache capacity %d must be positive", capacity)
+		}
+		c.transportHooks = append(c.transportHooks, func(t *http.Transport) error {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(capacity)
+			return nil
+		})
+		return nil
+	}
+}
+
+// traceConns records in the returned flag whether the attempt reused a
+// pooled connection, when a TLSObserver counts new connections. It returns
+// ctx unchanged and a nil flag otherwise.
+func (c *Client) traceConns(ctx context.Context) (context.Context, *atomic.Bool) {
+	if _, ok := c.metrics.(TLSObserver); !ok {
+		return ctx, nil
+	}
+	reused := new(atomic.Bool)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { reused.Store(info.Reused) },
+	}
+	return httptrace.WithClientTrace(ctx, trace), reused
+}
+
+// observeTLSHandshake reports the handshake of a new TLS connection.
+func (c *Client) observeTLSHandshake(cl *call, resp *http.Response, reused *atomic.Bool) {
+	if reused == nil || reused.Load() || resp.TLS == nil {
+		return
+	}
+	c.metrics.(TLSObserver).ObserveTLSHandshake(cl.host, resp.TLS.DidResume)
+}
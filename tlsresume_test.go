@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeCollector records TLS handshakes on top of recordingCollector.
+type handshakeCollector struct {
+	recordingCollector
+	resumed []bool
+}
+
+func (h *handshakeCollector) ObserveTLSHandshake(host string, resumed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resumed = append(h.resumed, resumed)
+}
+
+func TestWithTLSSessionResumption(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("resumes sessions on new connections", func(t *testing.T) {
+		metrics := &handshakeCollector{}
+		client, err := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLoggerDisabled(),
+			WithTLSSessionResumption(8), WithMetrics(metrics))
+		require.NoError(t, err)
+
+		resp, err := client.Get(ctx, "/first", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.TLSResumed)
+
+		resp, err = client.Get(ctx, "/pooled", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.TLSResumed, "same connection as the full handshake")
+
+		client.httpClient.CloseIdleConnections()
+		resp, err = client.Get(ctx, "/resumed", nil)
+		require.NoError(t, err)
+		assert.True(t, resp.TLSResumed)
+
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+		assert.Equal(t, []bool{false, true}, metrics.resumed, "one observation per new connection")
+	})
+
+	t.Run("does not resume without a session cache", func(t *testing.T) {
+		client, err := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/first", nil)
+		require.NoError(t, err)
+		client.httpClient.CloseIdleConnections()
+		resp, err := client.Get(ctx, "/second", nil)
+		require.NoError(t, err)
+		assert.False(t, resp.TLSResumed)
+	})
+
+	t.Run("validates capacity", func(t *testing.T) {
+		_, err := New(WithBaseURL(server.URL), WithTLSSessionResumption(0))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// clientTransport adapts a Client to http.RoundTripper.
+type clientTransport struct {
+	client *Client
+}
+
+// Transport returns an http.RoundTripper that sends requests through the
+// client's full pipeline: rate limiting, auth, middlewares, retries and
+// logging. Plug it into SDKs that only accept an *http.Client:
+//
+//	sdk.New(&http.Client{Transport: client.Transport()})
+//
+// The request URL is used as-is; the client's base URL is not applied, but
+// WithHTTPSOnly and WithAllowedHosts still refuse URLs they do not allow.
+// Default client headers are added only where the request does not set
+// them.
+func (c *Client) Transport() http.RoundTripper {
+	return &clientTransport{client: c}
+}
+
+// RoundTrip implements http.RoundTripper. HTTP error statuses are returned as
+// responses rather than errors, as the RoundTripper contract requires.
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+	if req.URL == nil {
+		return nil, errors.New("request URL cannot be nil")
+	}
+
+	cl, err := t.client.callFromRequest(req, DataPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.client.execute(req.Context(), cl, nil)
+	if resp == nil {
+		return nil, err
+	}
+	return resp.toHTTP(req), nil
+}
+
+// callFromRequest prepares a call of the given classification from an
+// externally built request, refusing URLs WithHTTPSOnly, WithAllowedHosts
+// or the classification forbid.
+func (c *Client) callFromRequest(req *http.Request, classification DataClassification) (*call, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		closeErr := req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if closeErr != nil {
+			return nil, closeErr
+		}
+		body = data
+	}
+	if err := c.checkURL(req.URL, classification); err != nil {
+		return nil, err
+	}
+
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	for key, values := range c.headers {
+		if _, ok := header[key]; !ok {
+			header[key] = append([]string(nil), values...)
+		}
+	}
+	if c.compression && header.Get("Accept-Encoding") == "" {
+		header.Set("Accept-Encoding", c.acceptEncoding)
+	}
+
+	return &call{
+		method:         req.Method,
+		url:            req.URL.String(),
+		header:         header,
+		body:           body,
+		contentType:    header.Get("Content-Type"),
+		decompress:     c.compression,
+		endpoint:       endpointKey(req.Method, req.URL.Path, ""),
+		host:           req.URL.Host,
+		hostOverride:   hostOverride(req),
+		classification: classification,
+	}, nil
+}
+
+// hostOverride returns the request's Host when it differs from the URL's.
+func hostOverride(req *http.Request) string {
+	if req.Host == req.URL.Host {
+		return ""
+	}
+	return req.Host
+}
+
+// toHTTP converts the buffered response back into an *http.Response.
+func (r *Response) toHTTP(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.Status,
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Headers,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// WrapTransport layers the package's rate limiting, auth, middlewares,
+// retries and logging on top of an existing RoundTripper, for vendors that
+// mandate their own transport. No base URL is required because request URLs
+// are used as-is. rt always carries the traffic, so WithHTTPClient has no
+// effect here.
+func WrapTransport(rt http.RoundTripper, opts ...ClientOption) (http.RoundTripper, error) {
+	if rt == nil {
+		return nil, errors.New("round tripper cannot be nil")
+	}
+
+	opts = append(opts[:len(opts):len(opts)], WithHTTPClient(&http.Client{Transport: rt}))
+	c, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Transport(), nil
+}
+
+// configureTransport applies transport hooks registered by options (TLS,
+// dialing, proxies) to a private copy of the client's *http.Transport, so a
+// caller-supplied http.Client is never mutated.
+func (c *Client) configureTransport() error {
+	if len(c.transportHooks) == 0 {
+		return nil
+	}
+
+	var transport *http.Transport
+	switch rt := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		return fmt.Errorf("transport options require an *http.Transport, got %T", rt)
+	}
+
+	for _, hook := range c.transportHooks {
+		if err := hook(transport); err != nil {
+			return err
+		}
+	}
+
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return nil
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Transport(t *testing.T) {
+	t.Run("applies auth and default headers to external requests", func(t *testing.T) {
+		var gotAuth, gotUA, gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			gotUA = r.Header.Get("User-Agent")
+			body, _ := io.ReadAll(r.Body)
+			gotBody = string(body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL("https://unused.example.com"),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuth("secret")),
+		)
+		require.NoError(t, err)
+
+		sdk := &http.Client{Transport: client.Transport()}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+"/charges", strings.NewReader(`{"amount":1}`))
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", "vendor-sdk/2.0")
+
+		resp, err := sdk.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, `{"id":1}`, string(body))
+		assert.Equal(t, "Bearer secret", gotAuth)
+		assert.Equal(t, "vendor-sdk/2.0", gotUA)
+		assert.Equal(t, `{"amount":1}`, gotBody)
+	})
+
+	t.Run("refuses plain http under https only", func(t *testing.T) {
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL("https://api.example.com"), WithLoggerDisabled(), WithHTTPSOnly())
+		require.NoError(t, err)
+
+		sdk := &http.Client{Transport: client.Transport()}
+		_, err = sdk.Get(server.URL + "/charges")
+		require.ErrorIs(t, err, ErrInsecureURL)
+		assert.Zero(t, hits.Load())
+	})
+
+	t.Run("refuses hosts outside the allow list", func(t *testing.T) {
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL("https://api.example.com"),
+			WithLoggerDisabled(),
+			WithAuth(BearerAuth("secret")),
+			WithAllowedHosts(),
+		)
+		require.NoError(t, err)
+
+		sdk := &http.Client{Transport: client.Transport()}
+		_, err = sdk.Get(server.URL + "/charges")
+		require.ErrorIs(t, err, ErrHostNotAllowed)
+		assert.Zero(t, hits.Load())
+	})
+
+	t.Run("retries and returns error statuses as responses", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL("https://unused.example.com"),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+
+		sdk := &http.Client{Transport: client.Transport()}
+		resp, err := sdk.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("logs requests made through the transport", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		logger := &testLogger{}
+		client, err := New(
+			WithBaseURL("https://unused.example.com"),
+			WithLogger(logger),
+			WithThirdPartyCode("vendor"),
+		)
+		require.NoError(t, err)
+
+		resp, err := (&http.Client{Transport: client.Transport()}).Get(server.URL + "/ping")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		entry := logger.LastEntry()
+		assert.Equal(t, "vendor", entry.Attrs["third_party_code"])
+		assert.Equal(t, server.URL+"/ping", entry.Attrs["url"])
+	})
+
+	t.Run("returns network errors", func(t *testing.T) {
+		client, err := New(
+			WithBaseURL("https://unused.example.com"),
+			WithLoggerDisabled(),
+		)
+		require.NoError(t, err)
+
+		_, err = (&http.Client{Transport: client.Transport()}).Get("http://127.0.0.1:1/unreachable")
+
+		require.Error(t, err)
+	})
+}
+
+func TestWrapTransport(t *testing.T) {
+	t.Run("layers retries over the wrapped transport", func(t *testing.T) {
+		var calls int32
+		vendor := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return MockErrorResponse(http.StatusBadGateway, "upstream"), nil
+			}
+			return MockJSONResponse(http.StatusOK, map[string]string{"ok": "true"}), nil
+		})
+
+		rt, err := WrapTransport(vendorTransport(vendor),
+			WithLoggerDisabled(),
+			WithRetry(&RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}),
+		)
+		require.NoError(t, err)
+
+		resp, err := (&http.Client{Transport: rt}).Get("https://vendor.example.com/items")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("returns error for nil round tripper", func(t *testing.T) {
+		_, err := WrapTransport(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "round tripper cannot be nil")
+	})
+
+	t.Run("returns option errors", func(t *testing.T) {
+		_, err := WrapTransport(http.DefaultTransport, WithTimeout(0))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timeout must be positive")
+	})
+}
+
+// vendorTransport adapts a RoundTripFunc to http.RoundTripper.
+type vendorTransport RoundTripFunc
+
+func (f vendorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,202 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultWarmupTimeout bounds Warmup when no WarmupTimeout option is given.
+const DefaultWarmupTimeout = 5 * time.Second
+
+// WarmupOption configures Warmup.
+type WarmupOption func(*warmupConfig)
+
+type warmupConfig struct {
+	timeout     time.Duration
+	connections int
+	auth        bool
+}
+
+// WarmupTimeout bounds how long Warmup may take in total.
+func WarmupTimeout(d time.Duration) WarmupOption {
+	return func(cfg *warmupConfig) {
+		cfg.timeout = d
+	}
+}
+
+// WarmupConnections sets how many connections to open per host.
+func WarmupConnections(n int) WarmupOption {
+	return func(cfg *warmupConfig) {
+		cfg.connections = n
+	}
+}
+
+// WarmupAuth also runs the auth provider once, so that token sources fetch
+// and cache their first token.
+func WarmupAuth() WarmupOption {
+	return func(cfg *warmupConfig) {
+		cfg.auth = true
+	}
+}
+
+// Warmup resolves DNS for and opens connections to the client's hosts, so
+// the first real request does not pay for DNS, TCP and TLS setup. Opened
+// connections are left idle in the transport's pool. It gives up when the
+// timeout elapses and returns every failure encountered.
+func (c *Client) Warmup(ctx context.Context, opts ...WarmupOption) error {
+	cfg, err := newWarmupConfig(opts)
+	if err != nil {
+		return err
+	}
+	return c.warmup(ctx, cfg)
+}
+
+// KeepWarm repeats Warmup every interval until ctx is done, so the pool
+// keeps the requested connections per host open and recently used, and
+// requests after an idle period do not pay for reconnecting. Run it in its
+// own goroutine; it returns ctx's error once ctx is done. Failed rounds are
+// logged and retried at the next interval. The transport's
+// MaxIdleConnsPerHost, 2 by default, caps how many connections stay idle.
+func (c *Client) KeepWarm(ctx context.Context, interval time.Duration, opts ...WarmupOption) error {
+	cfg, err := newWarmupConfig(opts)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return errors.New("keep-warm interval must be positive")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.warmup(ctx, cfg); err != nil && ctx.Err() == nil {
+			c.logKeepWarmError(ctx, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func newWarmupConfig(opts []WarmupOption) (warmupConfig, error) {
+	cfg := warmupConfig{timeout: DefaultWarmupTimeout, connections: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.timeout <= 0 {
+		return cfg, errors.New("warmup timeout must be positive")
+	}
+	if cfg.connections <= 0 {
+		return cfg, fmt.Errorf("warmup connections %d must be positive", cfg.connections)
+	}
+	return cfg, nil
+}
+
+// warmup runs one round of Warmup.
+func (c *Client) warmup(ctx context.Context, cfg warmupConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var errs []error
+	record := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range c.warmupTargets() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.warmHost(ctx, target, cfg.connections); err != nil {
+				record(err)
+			}
+		}()
+	}
+	if cfg.auth {
+		if err := c.warmAuth(ctx); err != nil {
+			record(err)
+		}
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// warmupTargets returns the URLs whose hosts Warmup connects to, every base
+// URL of WithBaseURLs or WithBalancer included so none starts cold.
+func (c *Client) warmupTargets() []*url.URL {
+	if bases := c.baseURLs(); bases != nil {
+		return bases
+	}
+	return []*url.URL{c.baseURL}
+}
+
+// warmHost resolves target's host, through the client's host mapping and
+// DNS cache, and opens n pooled connections to it.
+func (c *Client) warmHost(ctx context.Context, target *url.URL, n int) error {
+	if _, err := c.resolveHost(ctx, target.Hostname()); err != nil {
+		return fmt.Errorf("warmup: resolving %s: %w", target.Hostname(), err)
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.String(), nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			req.Header.Set("User-Agent", c.headers.Get("User-Agent"))
+			resp, err := c.httpClient.Do(req)
+			if err != nil {
+				errs[i] = fmt.Errorf("warmup: connecting to %s: %w", target.Host, err)
+				return
+			}
+			drainAndClose(resp.Body)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// warmAuth applies the auth provider to a throwaway request.
+func (c *Client) warmAuth(ctx context.Context) error {
+	primary, _ := c.auth.Load().providers(time.Now())
+	if primary == nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err := c.applyAuth(primary, req); err != nil {
+		return fmt.Errorf("warmup: auth: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) logKeepWarmError(ctx context.Context, err error) {
+	if !c.logEnabled(ctx, slog.LevelWarn) {
+		return
+	}
+	c.logger.Log(ctx, slog.LevelWarn, "http_keep_warm_failed",
+		slog.String("url", c.baseURL.String()),
+		slog.String("error", err.Error()),
+	)
+}
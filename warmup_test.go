@@ -0,0 +1,182 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Warmup(t *testing.T) {
+	t.Run("opens pooled connections that later requests reuse", func(t *testing.T) {
+		var conns atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				conns.Add(1)
+			}
+		}
+		server.Start()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		require.NoError(t, client.Warmup(context.Background(), WarmupConnections(2)))
+		assert.Equal(t, int32(2), conns.Load())
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), conns.Load())
+	})
+
+	t.Run("resolves through the client's DNS cache and host mapping", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+		port := server.URL[strings.LastIndex(server.URL, ":"):]
+
+		client, err := New(
+			WithBaseURLs("http://api.internal"+port, "http://backup.internal"+port),
+			WithLoggerDisabled(),
+			WithDNSCache(time.Minute, 10),
+			WithHostMapping(map[string]string{"backup.internal": "127.0.0.1"}),
+		)
+		require.NoError(t, err)
+		var calls atomic.Int32
+		client.dnsCache.lookup = countingLookup(&calls, []string{"127.0.0.1"}, nil)
+
+		require.NoError(t, client.Warmup(context.Background()))
+		assert.Equal(t, int32(1), calls.Load(), "only api.internal is looked up")
+
+		_, err = client.Get(context.Background(), "/test", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), calls.Load(), "requests hit the warmed cache")
+	})
+
+	t.Run("prefetches auth tokens", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		var fetched atomic.Int32
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(TokenSourceFunc(func(ctx context.Context) (string, error) {
+				fetched.Add(1)
+				return "token", nil
+			}))),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, client.Warmup(context.Background(), WarmupAuth()))
+		assert.Equal(t, int32(1), fetched.Load())
+	})
+
+	t.Run("reports auth failures", func(t *testing.T) {
+		server := newStatusServer(http.StatusOK)
+		defer server.Close()
+
+		client, err := New(
+			WithBaseURL(server.URL),
+			WithLoggerDisabled(),
+			WithAuth(TokenAuth(TokenSourceFunc(func(ctx context.Context) (string, error) {
+				return "", errors.New("idp down")
+			}))),
+		)
+		require.NoError(t, err)
+
+		err = client.Warmup(context.Background(), WarmupAuth())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "idp down")
+	})
+
+	t.Run("gives up after timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		start := time.Now()
+		err = client.Warmup(context.Background(), WarmupTimeout(20*time.Millisecond))
+
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+	})
+
+	t.Run("validates options", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		err = client.Warmup(context.Background(), WarmupTimeout(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "warmup timeout must be positive")
+
+		err = client.Warmup(context.Background(), WarmupConnections(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be positive")
+	})
+}
+
+func TestClient_KeepWarm(t *testing.T) {
+	t.Run("keeps reusing pooled connections", func(t *testing.T) {
+		var conns, heads atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				heads.Add(1)
+			}
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				conns.Add(1)
+			}
+		}
+		server.Start()
+		defer server.Close()
+
+		client, err := New(WithBaseURL(server.URL), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err = client.KeepWarm(ctx, 20*time.Millisecond, WarmupConnections(2))
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.GreaterOrEqual(t, heads.Load(), int32(6), "several rounds of two requests")
+		assert.Equal(t, int32(2), conns.Load())
+	})
+
+	t.Run("logs failed rounds and keeps going", func(t *testing.T) {
+		logger := &testLogger{}
+		client, err := New(WithBaseURL("http://127.0.0.1:1"), WithLogger(logger))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err = client.KeepWarm(ctx, 10*time.Millisecond)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		require.GreaterOrEqual(t, len(logger.Entries()), 2)
+		assert.Equal(t, "http_keep_warm_failed", logger.LastEntry().Msg)
+	})
+
+	t.Run("validates options", func(t *testing.T) {
+		client, err := New(WithBaseURL("https://api.example.com"))
+		require.NoError(t, err)
+
+		assert.Error(t, client.KeepWarm(context.Background(), 0))
+		assert.Error(t, client.KeepWarm(context.Background(), time.Second, WarmupConnections(0)))
+	})
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Workflow runs a sequence of API calls as a saga: when a step fails, the
+// compensations of the steps that already succeeded run in reverse order,
+// e.g. to cancel a reservation when the payment fails.
+//
+//	wf := httpclient.NewWorkflow()
+//	wf.Step("reserve", reserve).Compensate(cancelReservation)
+//	wf.Step("charge", charge).Compensate(refund)
+//	err := wf.Run(ctx)
+type Workflow struct {
+	steps []*WorkflowStep
+}
+
+// WorkflowStep is one step of a Workflow.
+type WorkflowStep struct {
+	name       string
+	run        func(ctx context.Context) error
+	compensate func(ctx context.Context) error
+}
+
+// NewWorkflow creates an empty workflow.
+func NewWorkflow() *Workflow {
+	return &Workflow{}
+}
+
+// Step appends a step that runs fn.
+func (w *Workflow) Step(name string, fn func(ctx context.Context) error) *WorkflowStep {
+	step := &WorkflowStep{name: name, run: fn}
+	w.steps = append(w.steps, step)
+	return step
+}
+
+// Compensate sets the function that undoes the step if a later step fails.
+func (s *WorkflowStep) Compensate(fn func(ctx context.Context) error) *WorkflowStep {
+	s.compensate = fn
+	return s
+}
+
+// WorkflowError reports the step that failed a workflow and any errors from
+// the compensations that ran afterwards.
+type WorkflowError struct {
+	Step string
+	Err  error
+
+	// CompensationErrors holds failed compensations; an empty slice means
+	// the workflow was fully rolled back.
+	CompensationErrors []error
+}
+
+// Error implements the error interface.
+func (e *WorkflowError) Error() string {
+	if len(e.CompensationErrors) == 0 {
+		return fmt.Sprintf("workflow step %q: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("workflow step %q: %v (compensation failed: %v)", e.Step, e.Err, errors.Join(e.CompensationErrors...))
+}
+
+// Unwrap returns the step error.
+func (e *WorkflowError) Unwrap() error {
+	return e.Err
+}
+
+// Run executes the steps in order. If a step fails, compensations of the
+// completed steps run in reverse order, all of them even if some fail, and
+// a *WorkflowError is returned. Compensations still run when ctx was
+// cancelled, using a context that keeps ctx's values. A step without a
+// function fails the workflow before any step runs.
+func (w *Workflow) Run(ctx context.Context) error {
+	for _, step := range w.steps {
+		if step.run == nil {
+			return fmt.Errorf("workflow step %q has no function", step.name)
+		}
+	}
+
+	for i, step := range w.steps {
+		err := ctx.Err()
+		if err == nil {
+			err = step.run(ctx)
+		}
+		if err == nil {
+			continue
+		}
+
+		wfErr := &WorkflowError{Step: step.name, Err: err}
+		undoCtx := context.WithoutCancel(ctx)
+		for j := i - 1; j >= 0; j-- {
+			done := w.steps[j]
+			if done.compensate == nil {
+				continue
+			}
+			if cerr := done.compensate(undoCtx); cerr != nil {
+				wfErr.CompensationErrors = append(wfErr.CompensationErrors, fmt.Errorf("compensating %q: %w", done.name, cerr))
+			}
+		}
+		return wfErr
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow(t *testing.T) {
+	record := func(log *[]string, entry string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			*log = append(*log, entry)
+			return err
+		}
+	}
+
+	t.Run("runs all steps", func(t *testing.T) {
+		var log []string
+		wf := NewWorkflow()
+		wf.Step("reserve", record(&log, "reserve", nil)).Compensate(record(&log, "cancel", nil))
+		wf.Step("charge", record(&log, "charge", nil))
+
+		require.NoError(t, wf.Run(context.Background()))
+		assert.Equal(t, []string{"reserve", "charge"}, log)
+	})
+
+	t.Run("compensates completed steps in reverse order", func(t *testing.T) {
+		var log []string
+		chargeErr := errors.New("card declined")
+		wf := NewWorkflow()
+		wf.Step("hold-seat", record(&log, "hold-seat", nil)).Compensate(record(&log, "release-seat", nil))
+		wf.Step("reserve", record(&log, "reserve", nil)).Compensate(record(&log, "cancel", nil))
+		wf.Step("charge", record(&log, "charge", chargeErr)).Compensate(record(&log, "refund", nil))
+		wf.Step("confirm", record(&log, "confirm", nil))
+
+		err := wf.Run(context.Background())
+
+		var wfErr *WorkflowError
+		require.ErrorAs(t, err, &wfErr)
+		assert.Equal(t, "charge", wfErr.Step)
+		assert.ErrorIs(t, err, chargeErr)
+		assert.Empty(t, wfErr.CompensationErrors)
+		assert.Equal(t, []string{"hold-seat", "reserve", "charge", "cancel", "release-seat"}, log)
+	})
+
+	t.Run("reports failed compensations and keeps going", func(t *testing.T) {
+		var log []string
+		wf := NewWorkflow()
+		wf.Step("a", record(&log, "a", nil)).Compensate(record(&log, "undo-a", nil))
+		wf.Step("b", record(&log, "b", nil)).Compensate(record(&log, "undo-b", errors.New("timeout")))
+		wf.Step("c", record(&log, "c", errors.New("boom")))
+
+		err := wf.Run(context.Background())
+
+		var wfErr *WorkflowError
+		require.ErrorAs(t, err, &wfErr)
+		require.Len(t, wfErr.CompensationErrors, 1)
+		assert.Contains(t, err.Error(), `compensating "b": timeout`)
+		assert.Equal(t, []string{"a", "b", "c", "undo-b", "undo-a"}, log)
+	})
+
+	t.Run("compensates after cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var undone bool
+		wf := NewWorkflow()
+		wf.Step("a", func(ctx context.Context) error {
+			cancel()
+			return nil
+		}).Compensate(func(ctx context.Context) error {
+			undone = ctx.Err() == nil
+			return nil
+		})
+		wf.Step("b", record(new([]string), "b", nil))
+
+		err := wf.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, undone)
+	})
+
+	t.Run("rejects a step without a function before running any", func(t *testing.T) {
+		var log []string
+		wf := NewWorkflow()
+		wf.Step("reserve", record(&log, "reserve", nil)).Compensate(record(&log, "cancel", nil))
+		wf.Step("charge", nil)
+
+		err := wf.Run(context.Background())
+
+		assert.ErrorContains(t, err, `workflow step "charge" has no function`)
+		assert.Empty(t, log)
+	})
+}
@@ -3,7 +3,9 @@ package httpclient
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // xmlBody wraps a value to be serialized as XML.
@@ -18,7 +20,9 @@ func XMLBody(v any) any {
 	return &xmlBody{value: v}
 }
 
-// XMLBodyWithRoot creates an XML body with a custom root element name.
+// XMLBodyWithRoot creates an XML body with a custom root element name,
+// optionally prefixed as in "ns:Request". Encoding fails if rootElement is
+// not a valid element name, so it cannot inject markup into the payload.
 func XMLBodyWithRoot(rootElement string, v any) any {
 	return &xmlBody{value: v, rootElement: rootElement}
 }
@@ -46,6 +50,10 @@ func EncodeXMLBody(v any) (io.Reader, string, error) {
 		return nil, "", nil
 	}
 
+	if xb.rootElement != "" && !isQName(xb.rootElement) {
+		return nil, "", fmt.Errorf("invalid XML root element %q", xb.rootElement)
+	}
+
 	var buf bytes.Buffer
 
 	if xb.declaration {
@@ -68,3 +76,12 @@ func EncodeXMLBody(v any) (io.Reader, string, error) {
 
 	return &buf, "application/xml", nil
 }
+
+// isQName reports whether s is an element name, with at most one prefix.
+func isQName(s string) bool {
+	prefix, local, ok := strings.Cut(s, ":")
+	if !ok {
+		return isNCName(s)
+	}
+	return isNCName(prefix) && isNCName(local)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -125,4 +126,28 @@ func TestXMLBodyEncoding(t *testing.T) {
 
 		assert.Contains(t, receivedBody, `<?xml version="1.0" encoding="UTF-8"?>`)
 	})
+
+	t.Run("accepts prefixed root element", func(t *testing.T) {
+		reader, _, err := EncodeXMLBody(XMLBodyWithRoot("ns:person", XMLPerson{Name: "Eve"}))
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(body), "<ns:person><"))
+	})
+
+	t.Run("rejects invalid root elements", func(t *testing.T) {
+		for _, root := range []string{"person><admin>true</admin></person><person", "person id='1'", "a:b:c", "1person", ":person"} {
+			_, _, err := EncodeXMLBody(XMLBodyWithRoot(root, XMLPerson{Name: "Mallory"}))
+			assert.ErrorContains(t, err, "invalid XML root element", root)
+		}
+	})
+
+	t.Run("fails request with invalid root element", func(t *testing.T) {
+		client, err := New(WithBaseURL("http://api.example.invalid"), WithLoggerDisabled())
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), "/test", XMLBodyWithRoot("a b", XMLPerson{}), nil)
+		require.Error(t, err)
+	})
 }